@@ -76,6 +76,7 @@ type executableDataMarshaling struct {
 type ExecutionPayloadEnvelope struct {
 	ExecutionPayload *ExecutableData `json:"executionPayload"  gencodec:"required"`
 	BlockValue       *big.Int        `json:"blockValue"  gencodec:"required"`
+	InterruptReason  string          `json:"interruptReason,omitempty"` // Why the transaction filling was cut short, empty if it completed
 }
 
 // JSON type overrides for ExecutionPayloadEnvelope.
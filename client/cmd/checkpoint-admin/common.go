@@ -13,6 +13,8 @@
 package main
 
 import (
+	"encoding/json"
+	"io"
 	"strconv"
 
 	"github.com/r5-labs/r5-core/client/accounts"
@@ -114,3 +116,10 @@ func newClefSigner(ctx *cli.Context) *bind.TransactOpts {
 	}
 	return bind.NewClefTransactor(clef, accounts.Account{Address: common.HexToAddress(ctx.String(signerFlag.Name))})
 }
+
+// printJSON writes the given query result to w as indented JSON.
+func printJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
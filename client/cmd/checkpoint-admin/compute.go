@@ -0,0 +1,113 @@
+// Copyright 2025 R5 Labs
+// This file is part of the R5 Core library.
+//
+// This software is provided "as is", without warranty of any kind,
+// express or implied, including but not limited to the warranties
+// of merchantability, fitness for a particular purpose and
+// noninfringement. In no event shall the authors or copyright
+// holders be liable for any claim, damages, or other liability,
+// whether in an action of contract, tort or otherwise, arising
+// from, out of or in connection with the software or the use or
+// other dealings in the software.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/r5-labs/r5-core/client/cmd/utils"
+	"github.com/r5-labs/r5-core/client/common"
+	"github.com/r5-labs/r5-core/client/common/hexutil"
+	"github.com/r5-labs/r5-core/client/params"
+	"github.com/urfave/cli/v2"
+)
+
+var commandCompute = &cli.Command{
+	Name:  "compute",
+	Usage: "Computes the checkpoint hash expected by the oracle contract",
+	Flags: []cli.Flag{
+		nodeURLFlag,
+		indexFlag,
+		headFlag,
+		chtRootFlag,
+		bloomRootFlag,
+		oracleFlag,
+	},
+	Action: compute,
+}
+
+// computeResult contains the checkpoint fields and the hashes derived from them.
+type computeResult struct {
+	Index      uint64          `json:"index"`
+	Head       common.Hash     `json:"head"`
+	CHTRoot    common.Hash     `json:"chtRoot"`
+	BloomRoot  common.Hash     `json:"bloomRoot"`
+	Checkpoint common.Hash     `json:"checkpoint"`
+	Oracle     *common.Address `json:"oracle,omitempty"`
+	Sighash    hexutil.Bytes   `json:"sighash,omitempty"`
+}
+
+// compute calculates the checkpoint hash registered in the oracle contract,
+// either from the checkpoint served by the connected node or from the section
+// fields supplied on the command line. If the oracle address is known, the
+// hash of the data to be signed by the admins is printed as well.
+func compute(ctx *cli.Context) error {
+	var (
+		checkpoint *params.TrustedCheckpoint
+		address    common.Address
+	)
+	if ctx.IsSet(headFlag.Name) || ctx.IsSet(chtRootFlag.Name) || ctx.IsSet(bloomRootFlag.Name) {
+		// Offline mode, assemble the checkpoint from the supplied fields
+		for _, flag := range []cli.Flag{indexFlag, headFlag, chtRootFlag, bloomRootFlag} {
+			if name := flag.Names()[0]; !ctx.IsSet(name) {
+				utils.Fatalf("Please specify --%s to compute the checkpoint in offline mode", name)
+			}
+		}
+		checkpoint = &params.TrustedCheckpoint{
+			SectionIndex: ctx.Uint64(indexFlag.Name),
+			SectionHead:  common.HexToHash(ctx.String(headFlag.Name)),
+			CHTRoot:      common.HexToHash(ctx.String(chtRootFlag.Name)),
+			BloomRoot:    common.HexToHash(ctx.String(bloomRootFlag.Name)),
+		}
+		if ctx.IsSet(oracleFlag.Name) {
+			address = common.HexToAddress(ctx.String(oracleFlag.Name))
+		}
+	} else {
+		// Interactive mode, retrieve the checkpoint from the remote node
+		node := newRPCClient(ctx.String(nodeURLFlag.Name))
+
+		checkpoint = getCheckpoint(ctx, node)
+		if ctx.IsSet(oracleFlag.Name) {
+			address = common.HexToAddress(ctx.String(oracleFlag.Name))
+		} else {
+			address = getContractAddr(node)
+		}
+	}
+	result := &computeResult{
+		Index:      checkpoint.SectionIndex,
+		Head:       checkpoint.SectionHead,
+		CHTRoot:    checkpoint.CHTRoot,
+		BloomRoot:  checkpoint.BloomRoot,
+		Checkpoint: checkpoint.Hash(),
+	}
+	if address != (common.Address{}) {
+		result.Oracle = &address
+		result.Sighash = sighash(checkpoint.SectionIndex, address, checkpoint.Hash())
+	}
+	if ctx.Bool(jsonFlag.Name) {
+		return printJSON(ctx.App.Writer, result)
+	}
+	out := ctx.App.Writer
+
+	fmt.Fprintf(out, "Index      => %d\n", result.Index)
+	fmt.Fprintf(out, "Head       => %s\n", result.Head.Hex())
+	fmt.Fprintf(out, "CHT root   => %s\n", result.CHTRoot.Hex())
+	fmt.Fprintf(out, "Bloom root => %s\n", result.BloomRoot.Hex())
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "Checkpoint => %s\n", result.Checkpoint.Hex())
+	if result.Oracle != nil {
+		fmt.Fprintf(out, "Oracle     => %s\n", result.Oracle.Hex())
+		fmt.Fprintf(out, "Sighash    => %s\n", result.Sighash)
+	}
+	return nil
+}
@@ -32,10 +32,19 @@ func init() {
 		commandDeploy,
 		commandSign,
 		commandPublish,
+		commandCompute,
 	}
 	app.Flags = []cli.Flag{
 		oracleFlag,
 		nodeURLFlag,
+		jsonFlag,
+		logJSONFlag,
+	}
+	app.Before = func(ctx *cli.Context) error {
+		if ctx.Bool(logJSONFlag.Name) {
+			log.Root().SetHandler(log.LvlFilterHandler(log.LvlInfo, log.JSONHandler(os.Stderr)))
+		}
+		return nil
 	}
 }
 
@@ -49,6 +58,18 @@ var (
 		Name:  "hash",
 		Usage: "Checkpoint hash (query latest from remote node if not specified)",
 	}
+	headFlag = &cli.StringFlag{
+		Name:  "head",
+		Usage: "Checkpoint section head hash (query latest from remote node if not specified)",
+	}
+	chtRootFlag = &cli.StringFlag{
+		Name:  "cht",
+		Usage: "Canonical hash trie root of the checkpoint section (query latest from remote node if not specified)",
+	}
+	bloomRootFlag = &cli.StringFlag{
+		Name:  "bloom",
+		Usage: "Bloom trie root of the checkpoint section (query latest from remote node if not specified)",
+	}
 	oracleFlag = &cli.StringFlag{
 		Name:  "oracle",
 		Usage: "Checkpoint oracle address (query from remote node if not specified)",
@@ -62,6 +83,14 @@ var (
 		Value: "http://localhost:8545",
 		Usage: "The rpc endpoint of a local or remote geth node",
 	}
+	jsonFlag = &cli.BoolFlag{
+		Name:  "json",
+		Usage: "Print the result of query commands as JSON",
+	}
+	logJSONFlag = &cli.BoolFlag{
+		Name:  "log.json",
+		Usage: "Format logs with JSON",
+	}
 	clefURLFlag = &cli.StringFlag{
 		Name:  "clef",
 		Value: "http://localhost:8550",
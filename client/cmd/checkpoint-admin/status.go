@@ -13,9 +13,15 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"math/big"
 
+	"github.com/r5-labs/r5-core/client"
 	"github.com/r5-labs/r5-core/client/common"
+	"github.com/r5-labs/r5-core/client/contracts/checkpointoracle"
+	"github.com/r5-labs/r5-core/client/ethclient"
 	"github.com/urfave/cli/v2"
 )
 
@@ -28,29 +34,79 @@ var commandStatus = &cli.Command{
 	Action: status,
 }
 
+// thresholdSlot is the storage slot of the signature threshold in the oracle
+// contract. The contract doesn't expose the threshold through a getter, so it
+// is read directly from the contract storage.
+var thresholdSlot = common.BigToHash(big.NewInt(7))
+
+// oracleStatus contains the signers and latest checkpoint of the oracle contract.
+type oracleStatus struct {
+	Oracle     common.Address   `json:"oracle"`
+	Admins     []common.Address `json:"admins"`
+	Threshold  uint64           `json:"threshold"`
+	Checkpoint struct {
+		Index  uint64      `json:"index"`
+		Hash   common.Hash `json:"hash"`
+		Height uint64      `json:"height"`
+	} `json:"checkpoint"`
+}
+
 // status fetches the admin list of specified registrar contract.
 func status(ctx *cli.Context) error {
 	// Create a wrapper around the checkpoint oracle contract
-	addr, oracle := newContract(newRPCClient(ctx.String(nodeURLFlag.Name)))
-	fmt.Printf("Oracle => %s\n", addr.Hex())
-	fmt.Println()
+	client := newRPCClient(ctx.String(nodeURLFlag.Name))
+	addr, oracle := newContract(client)
+
+	stat, err := queryStatus(ethclient.NewClient(client), addr, oracle)
+	if err != nil {
+		return err
+	}
+	if ctx.Bool(jsonFlag.Name) {
+		return printJSON(ctx.App.Writer, stat)
+	}
+	printStatus(ctx.App.Writer, stat)
+	return nil
+}
+
+// queryStatus retrieves the signers, the signature threshold and the latest
+// checkpoint from the oracle contract.
+func queryStatus(backend ethereum.ChainStateReader, addr common.Address, oracle *checkpointoracle.CheckpointOracle) (*oracleStatus, error) {
+	stat := &oracleStatus{Oracle: addr}
 
 	// Retrieve the list of authorized signers (admins)
 	admins, err := oracle.Contract().GetAllAdmin(nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	for i, admin := range admins {
-		fmt.Printf("Admin %d => %s\n", i+1, admin.Hex())
+	stat.Admins = admins
+
+	// Retrieve the number of signatures needed to publish
+	threshold, err := backend.StorageAt(context.Background(), addr, thresholdSlot, nil)
+	if err != nil {
+		return nil, err
 	}
-	fmt.Println()
+	stat.Threshold = common.BytesToHash(threshold).Big().Uint64()
 
 	// Retrieve the latest checkpoint
 	index, checkpoint, height, err := oracle.Contract().GetLatestCheckpoint(nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	fmt.Printf("Checkpoint (published at #%d) %d => %s\n", height, index, common.Hash(checkpoint).Hex())
+	stat.Checkpoint.Index, stat.Checkpoint.Hash, stat.Checkpoint.Height = index, checkpoint, height.Uint64()
 
-	return nil
+	return stat, nil
+}
+
+// printStatus prints the oracle status in a human readable form.
+func printStatus(w io.Writer, stat *oracleStatus) {
+	fmt.Fprintf(w, "Oracle => %s\n", stat.Oracle.Hex())
+	fmt.Fprintln(w)
+
+	for i, admin := range stat.Admins {
+		fmt.Fprintf(w, "Admin %d => %s\n", i+1, admin.Hex())
+	}
+	fmt.Fprintf(w, "\nSignatures needed to publish: %d\n", stat.Threshold)
+	fmt.Fprintln(w)
+
+	fmt.Fprintf(w, "Checkpoint (published at #%d) %d => %s\n", stat.Checkpoint.Height, stat.Checkpoint.Index, stat.Checkpoint.Hash.Hex())
 }
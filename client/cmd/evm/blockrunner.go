@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/r5-labs/r5-core/client/cmd/evm/internal/t8ntool"
 	"github.com/r5-labs/r5-core/client/log"
 	"github.com/r5-labs/r5-core/client/tests"
 	"github.com/urfave/cli/v2"
@@ -35,7 +36,7 @@ func blockTestCmd(ctx *cli.Context) error {
 		return errors.New("path-to-test argument required")
 	}
 	// Configure the go-ethereum logger
-	glogger := log.NewGlogHandler(log.StreamHandler(os.Stderr, log.TerminalFormat(false)))
+	glogger := log.NewGlogHandler(t8ntool.LogHandler(ctx))
 	glogger.Verbosity(log.Lvl(ctx.Int(VerbosityFlag.Name)))
 	log.Root().SetHandler(glogger)
 
@@ -0,0 +1,126 @@
+// Copyright 2025 R5 Labs
+// This file is part of the R5 Core library.
+//
+// This software is provided "as is", without warranty of any kind,
+// express or implied, including but not limited to the warranties
+// of merchantability, fitness for a particular purpose and
+// noninfringement. In no event shall the authors or copyright
+// holders be liable for any claim, damages, or other liability,
+// whether in an action of contract, tort or otherwise, arising
+// from, out of or in connection with the software or the use or
+// other dealings in the software.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/r5-labs/r5-core/client/common"
+	"github.com/r5-labs/r5-core/client/core/vm"
+)
+
+// debugHelp lists the commands accepted by the interactive debugger.
+const debugHelp = "commands: s(tep), c(ontinue), p(rint stack and memory), q(uit)"
+
+// errExecutionAborted is returned if the execution was quit from the debugger.
+var errExecutionAborted = errors.New("execution aborted")
+
+// interactiveDebugger is an EVMLogger pausing before every opcode and reading
+// the next action from its input, until told to continue to the end.
+type interactiveDebugger struct {
+	in  *bufio.Reader
+	out io.Writer
+	run bool    // Set once execution continues without pausing
+	env *vm.EVM // EVM being debugged, cancelled on quit
+	err error   // Set if the execution was quit
+}
+
+func newInteractiveDebugger(in io.Reader, out io.Writer) *interactiveDebugger {
+	return &interactiveDebugger{in: bufio.NewReader(in), out: out}
+}
+
+func (d *interactiveDebugger) CaptureTxStart(gasLimit uint64) {}
+
+func (d *interactiveDebugger) CaptureTxEnd(restGas uint64) {}
+
+func (d *interactiveDebugger) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	d.env = env
+	fmt.Fprintln(d.out, debugHelp)
+}
+
+func (d *interactiveDebugger) CaptureEnd(output []byte, gasUsed uint64, err error) {}
+
+func (d *interactiveDebugger) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+}
+
+func (d *interactiveDebugger) CaptureExit(output []byte, gasUsed uint64, err error) {}
+
+// CaptureState pauses before the opcode about to be executed and processes
+// commands until one of them resumes the execution.
+func (d *interactiveDebugger) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	if d.run {
+		return
+	}
+	fmt.Fprintf(d.out, "pc=%d op=%v gas=%d cost=%d depth=%d\n", pc, op, gas, cost, depth)
+	for {
+		fmt.Fprint(d.out, "> ")
+		line, err := d.in.ReadString('\n')
+		if err != nil && line == "" {
+			// Input exhausted, there's nobody left to step, run to the end.
+			fmt.Fprintln(d.out)
+			d.run = true
+			return
+		}
+		switch cmd := strings.TrimSpace(line); cmd {
+		case "s", "":
+			return
+		case "c":
+			d.run = true
+			return
+		case "p":
+			d.print(scope)
+		case "q":
+			// Cancelling stops the EVM at the next jump, don't pause until then.
+			d.env.Cancel()
+			d.err = errExecutionAborted
+			d.run = true
+			return
+		default:
+			fmt.Fprintf(d.out, "unknown command %q, %s\n", cmd, debugHelp)
+		}
+	}
+}
+
+func (d *interactiveDebugger) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+	if !d.run {
+		fmt.Fprintf(d.out, "fault at pc=%d op=%v: %v\n", pc, op, err)
+	}
+}
+
+// Err returns errExecutionAborted if the execution was quit from the debugger.
+func (d *interactiveDebugger) Err() error {
+	return d.err
+}
+
+// print writes the stack, top item first, and the memory in 32 byte words.
+func (d *interactiveDebugger) print(scope *vm.ScopeContext) {
+	stack := scope.Stack.Data()
+	fmt.Fprintf(d.out, "stack (%d items):\n", len(stack))
+	for i := len(stack) - 1; i >= 0; i-- {
+		fmt.Fprintf(d.out, "%4d: %s\n", len(stack)-1-i, stack[i].Hex())
+	}
+	memory := scope.Memory.Data()
+	fmt.Fprintf(d.out, "memory (%d bytes):\n", len(memory))
+	for i := 0; i < len(memory); i += 32 {
+		end := i + 32
+		if end > len(memory) {
+			end = len(memory)
+		}
+		fmt.Fprintf(d.out, "%04x: %x\n", i, memory[i:end])
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2025 R5 Labs
+// This file is part of the R5 Core library.
+//
+// This software is provided "as is", without warranty of any kind,
+// express or implied, including but not limited to the warranties
+// of merchantability, fitness for a particular purpose and
+// noninfringement. In no event shall the authors or copyright
+// holders be liable for any claim, damages, or other liability,
+// whether in an action of contract, tort or otherwise, arising
+// from, out of or in connection with the software or the use or
+// other dealings in the software.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/r5-labs/r5-core/client/common"
+	"github.com/r5-labs/r5-core/client/core/vm"
+)
+
+// opProfile is the gas accumulated by a single opcode during a run.
+type opProfile struct {
+	Op    vm.OpCode
+	Gas   uint64 // Total gas charged, including the dynamic part
+	Count uint64 // Number of times the opcode was executed
+}
+
+// gasProfiler is an EVMLogger accumulating the gas cost and the invocation
+// count of every executed opcode, across all call frames. Note, the cost of
+// the call opcodes includes the gas handed over to the callee.
+type gasProfiler struct {
+	ops map[vm.OpCode]*opProfile
+}
+
+func newGasProfiler() *gasProfiler {
+	return &gasProfiler{ops: make(map[vm.OpCode]*opProfile)}
+}
+
+func (p *gasProfiler) CaptureTxStart(gasLimit uint64) {}
+
+func (p *gasProfiler) CaptureTxEnd(restGas uint64) {}
+
+func (p *gasProfiler) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+}
+
+func (p *gasProfiler) CaptureEnd(output []byte, gasUsed uint64, err error) {}
+
+func (p *gasProfiler) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+}
+
+func (p *gasProfiler) CaptureExit(output []byte, gasUsed uint64, err error) {}
+
+// CaptureState accounts the cost of the opcode about to be executed.
+func (p *gasProfiler) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	prof := p.ops[op]
+	if prof == nil {
+		prof = &opProfile{Op: op}
+		p.ops[op] = prof
+	}
+	prof.Gas += cost
+	prof.Count++
+}
+
+func (p *gasProfiler) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+// Profile returns the accumulated opcode profiles, sorted by descending total
+// gas. Opcodes with the same total are ordered by descending count.
+func (p *gasProfiler) Profile() []opProfile {
+	profile := make([]opProfile, 0, len(p.ops))
+	for _, prof := range p.ops {
+		profile = append(profile, *prof)
+	}
+	sort.Slice(profile, func(i, j int) bool {
+		if profile[i].Gas != profile[j].Gas {
+			return profile[i].Gas > profile[j].Gas
+		}
+		if profile[i].Count != profile[j].Count {
+			return profile[i].Count > profile[j].Count
+		}
+		return profile[i].Op < profile[j].Op
+	})
+	return profile
+}
+
+// writeGasProfile prints the opcode profiles as a table.
+func writeGasProfile(w io.Writer, profile []opProfile) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "OPCODE\tCOUNT\tGAS\t")
+	for _, prof := range profile {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t\n", prof.Op, prof.Count, prof.Gas)
+	}
+	tw.Flush()
+}
+
+// tracerMux is an EVMLogger fanning out every event to a list of loggers.
+type tracerMux []vm.EVMLogger
+
+// addTracer returns a logger feeding the events to tracer after the existing
+// logger, if any.
+func addTracer(existing vm.EVMLogger, tracer vm.EVMLogger) vm.EVMLogger {
+	switch existing := existing.(type) {
+	case nil:
+		return tracer
+	case tracerMux:
+		return append(existing, tracer)
+	default:
+		return tracerMux{existing, tracer}
+	}
+}
+
+func (t tracerMux) CaptureTxStart(gasLimit uint64) {
+	for _, tracer := range t {
+		tracer.CaptureTxStart(gasLimit)
+	}
+}
+
+func (t tracerMux) CaptureTxEnd(restGas uint64) {
+	for _, tracer := range t {
+		tracer.CaptureTxEnd(restGas)
+	}
+}
+
+func (t tracerMux) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	for _, tracer := range t {
+		tracer.CaptureStart(env, from, to, create, input, gas, value)
+	}
+}
+
+func (t tracerMux) CaptureEnd(output []byte, gasUsed uint64, err error) {
+	for _, tracer := range t {
+		tracer.CaptureEnd(output, gasUsed, err)
+	}
+}
+
+func (t tracerMux) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	for _, tracer := range t {
+		tracer.CaptureEnter(typ, from, to, input, gas, value)
+	}
+}
+
+func (t tracerMux) CaptureExit(output []byte, gasUsed uint64, err error) {
+	for _, tracer := range t {
+		tracer.CaptureExit(output, gasUsed, err)
+	}
+}
+
+func (t tracerMux) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	for _, tracer := range t {
+		tracer.CaptureState(pc, op, gas, cost, scope, rData, depth, err)
+	}
+}
+
+func (t tracerMux) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+	for _, tracer := range t {
+		tracer.CaptureFault(pc, op, gas, cost, scope, depth, err)
+	}
+}
@@ -136,7 +136,7 @@ var (
 	}
 	RewardFlag = &cli.Int64Flag{
 		Name:  "state.reward",
-		Usage: "Mining reward. Set to -1 to disable",
+		Usage: "Mining reward. Set to -1 to disable. Ignored by the R5 ruleset, which follows the R5 emission schedule",
 		Value: 0,
 	}
 	ChainIDFlag = &cli.Int64Flag{
@@ -161,4 +161,8 @@ var (
 		Usage: "sets the verbosity level",
 		Value: 3,
 	}
+	LogJSONFlag = &cli.BoolFlag{
+		Name:  "log.json",
+		Usage: "Format logs with JSON",
+	}
 )
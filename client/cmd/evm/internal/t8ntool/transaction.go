@@ -35,6 +35,7 @@ type result struct {
 	Error        error
 	Address      common.Address
 	Hash         common.Hash
+	Type         uint8
 	IntrinsicGas uint64
 }
 
@@ -44,6 +45,7 @@ func (r *result) MarshalJSON() ([]byte, error) {
 		Error        string          `json:"error,omitempty"`
 		Address      *common.Address `json:"address,omitempty"`
 		Hash         *common.Hash    `json:"hash,omitempty"`
+		Type         *hexutil.Uint64 `json:"type,omitempty"`
 		IntrinsicGas hexutil.Uint64  `json:"intrinsicGas,omitempty"`
 	}
 	var out xx
@@ -55,6 +57,8 @@ func (r *result) MarshalJSON() ([]byte, error) {
 	}
 	if r.Hash != (common.Hash{}) {
 		out.Hash = &r.Hash
+		typ := hexutil.Uint64(r.Type)
+		out.Type = &typ
 	}
 	out.IntrinsicGas = hexutil.Uint64(r.IntrinsicGas)
 	return json.Marshal(out)
@@ -62,7 +66,7 @@ func (r *result) MarshalJSON() ([]byte, error) {
 
 func Transaction(ctx *cli.Context) error {
 	// Configure the go-ethereum logger
-	glogger := log.NewGlogHandler(log.StreamHandler(os.Stderr, log.TerminalFormat(false)))
+	glogger := log.NewGlogHandler(LogHandler(ctx))
 	glogger.Verbosity(log.Lvl(ctx.Int(VerbosityFlag.Name)))
 	log.Root().SetHandler(glogger)
 
@@ -123,10 +127,15 @@ func Transaction(ctx *cli.Context) error {
 		var tx types.Transaction
 		err := rlp.DecodeBytes(it.Value(), &tx)
 		if err != nil {
+			// Name the offending type byte, legacy list encodings can't get here
+			_, content, _, splitErr := rlp.Split(it.Value())
+			if errors.Is(err, types.ErrTxTypeNotSupported) && splitErr == nil && len(content) > 0 {
+				err = fmt.Errorf("%w: %#x", err, content[0])
+			}
 			results = append(results, result{Error: err})
 			continue
 		}
-		r := result{Hash: tx.Hash()}
+		r := result{Hash: tx.Hash(), Type: tx.Type()}
 		if sender, err := types.Sender(signer, &tx); err != nil {
 			r.Error = err
 			results = append(results, r)
@@ -79,9 +79,15 @@ type input struct {
 	TxRlp string            `json:"txsRlp,omitempty"`
 }
 
+// isR5Fork reports whether the fork name (optionally extended with extra EIPs)
+// selects the R5 ruleset, whose block rewards follow the R5 emission schedule.
+func isR5Fork(fork string) bool {
+	return strings.Split(fork, "+")[0] == "R5"
+}
+
 func Transition(ctx *cli.Context) error {
 	// Configure the go-ethereum logger
-	glogger := log.NewGlogHandler(log.StreamHandler(os.Stderr, log.TerminalFormat(false)))
+	glogger := log.NewGlogHandler(LogHandler(ctx))
 	glogger.Verbosity(log.Lvl(ctx.Int(VerbosityFlag.Name)))
 	log.Root().SetHandler(glogger)
 
@@ -289,7 +295,7 @@ func Transition(ctx *cli.Context) error {
 			env.ParentTimestamp, env.ParentDifficulty, env.ParentUncleHash)
 	}
 	// Run the test and aggregate the result
-	s, result, err := prestate.Apply(vmConfig, chainConfig, txs, ctx.Int64(RewardFlag.Name), getTracer)
+	s, result, err := prestate.Apply(vmConfig, chainConfig, txs, ctx.Int64(RewardFlag.Name), isR5Fork(ctx.String(ForknameFlag.Name)), getTracer)
 	if err != nil {
 		return err
 	}
@@ -17,6 +17,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/r5-labs/r5-core/client/log"
 	"github.com/urfave/cli/v2"
 )
 
@@ -48,3 +49,12 @@ func createBasedir(ctx *cli.Context) (string, error) {
 	}
 	return baseDir, nil
 }
+
+// LogHandler returns the handler writing log records to stderr, formatted as
+// JSON objects if requested by the user or for the terminal otherwise.
+func LogHandler(ctx *cli.Context) log.Handler {
+	if ctx.Bool(LogJSONFlag.Name) {
+		return log.JSONHandler(os.Stderr)
+	}
+	return log.StreamHandler(os.Stderr, log.TerminalFormat(false))
+}
@@ -73,7 +73,7 @@ var (
 	}
 	InputFileFlag = &cli.StringFlag{
 		Name:  "inputfile",
-		Usage: "file containing input for the EVM",
+		Usage: "file containing input for the EVM. If '-' is specified, input is read from stdin",
 	}
 	VerbosityFlag = &cli.IntFlag{
 		Name:  "verbosity",
@@ -89,12 +89,24 @@ var (
 	}
 	GenesisFlag = &cli.StringFlag{
 		Name:  "prestate",
-		Usage: "JSON file with prestate (genesis) config",
+		Usage: "JSON file with prestate (genesis) config. If '-' is specified, prestate is read from stdin",
 	}
 	MachineFlag = &cli.BoolFlag{
 		Name:  "json",
 		Usage: "output trace logs in machine readable format (json)",
 	}
+	TraceFormatFlag = &cli.StringFlag{
+		Name:  "trace-format",
+		Usage: "trace output format of the run command (text, json or jsonl)",
+	}
+	GasProfileFlag = &cli.BoolFlag{
+		Name:  "gasprofile",
+		Usage: "print the total gas and count of every executed opcode to stderr",
+	}
+	DebugInteractiveFlag = &cli.BoolFlag{
+		Name:  "debug-interactive",
+		Usage: "pause before every opcode and read commands from stdin (s=step, c=continue, p=print, q=quit)",
+	}
 	SenderFlag = &cli.StringFlag{
 		Name:  "sender",
 		Usage: "The transaction origin",
@@ -146,6 +158,7 @@ var stateTransitionCommand = &cli.Command{
 		t8ntool.ChainIDFlag,
 		t8ntool.RewardFlag,
 		t8ntool.VerbosityFlag,
+		t8ntool.LogJSONFlag,
 	},
 }
 
@@ -159,6 +172,7 @@ var transactionCommand = &cli.Command{
 		t8ntool.ChainIDFlag,
 		t8ntool.ForknameFlag,
 		t8ntool.VerbosityFlag,
+		t8ntool.LogJSONFlag,
 	},
 }
 
@@ -179,6 +193,7 @@ var blockBuilderCommand = &cli.Command{
 		t8ntool.SealEthashDirFlag,
 		t8ntool.SealEthashModeFlag,
 		t8ntool.VerbosityFlag,
+		t8ntool.LogJSONFlag,
 	},
 }
 
@@ -190,6 +205,7 @@ func init() {
 		CreateFlag,
 		DebugFlag,
 		VerbosityFlag,
+		t8ntool.LogJSONFlag,
 		CodeFlag,
 		CodeFileFlag,
 		GasFlag,
@@ -203,6 +219,9 @@ func init() {
 		StatDumpFlag,
 		GenesisFlag,
 		MachineFlag,
+		TraceFormatFlag,
+		GasProfileFlag,
+		DebugInteractiveFlag,
 		SenderFlag,
 		ReceiverFlag,
 		DisableMemoryFlag,
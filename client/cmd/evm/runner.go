@@ -21,12 +21,16 @@ import (
 	"os"
 	goruntime "runtime"
 	"runtime/pprof"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/r5-labs/r5-core/client/cmd/evm/internal/compiler"
+	"github.com/r5-labs/r5-core/client/cmd/evm/internal/t8ntool"
 	"github.com/r5-labs/r5-core/client/cmd/utils"
 	"github.com/r5-labs/r5-core/client/common"
+	"github.com/r5-labs/r5-core/client/common/hexutil"
+	"github.com/r5-labs/r5-core/client/common/math"
 	"github.com/r5-labs/r5-core/client/core"
 	"github.com/r5-labs/r5-core/client/core/rawdb"
 	"github.com/r5-labs/r5-core/client/core/state"
@@ -49,26 +53,76 @@ var runCommand = &cli.Command{
 }
 
 // readGenesis will read the given JSON format genesis file and return
-// the initialized Genesis structure
+// the initialized Genesis structure. If - is specified, the genesis is
+// read from stdin.
 func readGenesis(genesisPath string) *core.Genesis {
 	// Make sure we have a valid genesis JSON
 	//genesisPath := ctx.Args().First()
 	if len(genesisPath) == 0 {
 		utils.Fatalf("Must supply path to genesis JSON file")
 	}
-	file, err := os.Open(genesisPath)
-	if err != nil {
-		utils.Fatalf("Failed to read genesis file: %v", err)
+	var input io.Reader = os.Stdin
+	if genesisPath != "-" {
+		file, err := os.Open(genesisPath)
+		if err != nil {
+			utils.Fatalf("Failed to read genesis file: %v", err)
+		}
+		defer file.Close()
+		input = file
 	}
-	defer file.Close()
-
 	genesis := new(core.Genesis)
-	if err := json.NewDecoder(file).Decode(genesis); err != nil {
+	if err := json.NewDecoder(input).Decode(genesis); err != nil {
 		utils.Fatalf("invalid genesis file: %v", err)
 	}
 	return genesis
 }
 
+// checkStdinFlags ensures that at most one of the file flags is set to -, or
+// the interactive debugger is enabled, as stdin can only be consumed once.
+func checkStdinFlags(ctx *cli.Context) error {
+	var readers []string
+	for _, flag := range []*cli.StringFlag{CodeFileFlag, GenesisFlag, InputFileFlag} {
+		if ctx.String(flag.Name) == "-" {
+			readers = append(readers, "--"+flag.Name)
+		}
+	}
+	if ctx.Bool(DebugInteractiveFlag.Name) {
+		readers = append(readers, "--"+DebugInteractiveFlag.Name)
+	}
+	if len(readers) > 1 {
+		return fmt.Errorf("only one of %s can read from stdin", strings.Join(readers, ", "))
+	}
+	return nil
+}
+
+// traceResult is the single JSON document emitted by the json trace format,
+// holding all the execution steps followed by the call summary.
+type traceResult struct {
+	StructLogs []logger.StructLog  `json:"structLogs"`
+	Output     hexutil.Bytes       `json:"output"`
+	GasUsed    math.HexOrDecimal64 `json:"gasUsed"`
+	Err        string              `json:"error,omitempty"`
+}
+
+// writeTraceJSON writes the steps collected by the struct logger, along with
+// the execution outcome, as one indented JSON document.
+func writeTraceJSON(w io.Writer, tracer *logger.StructLogger, gasUsed uint64, err error) error {
+	result := traceResult{
+		StructLogs: tracer.StructLogs(),
+		Output:     tracer.Output(),
+		GasUsed:    math.HexOrDecimal64(gasUsed),
+	}
+	if result.StructLogs == nil {
+		result.StructLogs = []logger.StructLog{}
+	}
+	if err != nil {
+		result.Err = err.Error()
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(result)
+}
+
 type execStats struct {
 	time           time.Duration // The execution time.
 	allocs         int64         // The number of heap allocations during execution.
@@ -103,7 +157,10 @@ func timedExec(bench bool, execFunc func() ([]byte, uint64, error)) (output []by
 }
 
 func runCmd(ctx *cli.Context) error {
-	glogger := log.NewGlogHandler(log.StreamHandler(os.Stderr, log.TerminalFormat(false)))
+	if err := checkStdinFlags(ctx); err != nil {
+		return err
+	}
+	glogger := log.NewGlogHandler(t8ntool.LogHandler(ctx))
 	glogger.Verbosity(log.Lvl(ctx.Int(VerbosityFlag.Name)))
 	log.Root().SetHandler(glogger)
 	logconfig := &logger.Config{
@@ -124,13 +181,43 @@ func runCmd(ctx *cli.Context) error {
 		genesisConfig *core.Genesis
 		preimages     = ctx.Bool(DumpFlag.Name)
 	)
-	if ctx.Bool(MachineFlag.Name) {
+	traceFormat := ctx.String(TraceFormatFlag.Name)
+	if traceFormat == "" {
+		// Map the legacy tracing flags onto their trace formats.
+		if ctx.Bool(MachineFlag.Name) {
+			traceFormat = "jsonl"
+		} else if ctx.Bool(DebugFlag.Name) {
+			traceFormat = "text"
+		}
+	}
+	switch traceFormat {
+	case "jsonl":
+		// The JSON logger encodes each step with a single write into the
+		// unbuffered stdout, so every line is flushed as soon as it's emitted.
 		tracer = logger.NewJSONLogger(logconfig, os.Stdout)
-	} else if ctx.Bool(DebugFlag.Name) {
+	case "text", "json":
 		debugLogger = logger.NewStructLogger(logconfig)
 		tracer = debugLogger
-	} else {
+	case "":
 		debugLogger = logger.NewStructLogger(logconfig)
+	default:
+		return fmt.Errorf("unknown trace format %q, expected text, json or jsonl", traceFormat)
+	}
+	// The gas profiler and the debugger run alongside the selected tracer, if any
+	var (
+		profiler  *gasProfiler
+		debugger  *interactiveDebugger
+		evmTracer = tracer
+	)
+	if ctx.Bool(GasProfileFlag.Name) {
+		profiler = newGasProfiler()
+		evmTracer = addTracer(evmTracer, profiler)
+	}
+	// The interactive debugger comes last, so that the other tracers have
+	// already seen the opcode it pauses at.
+	if ctx.Bool(DebugInteractiveFlag.Name) {
+		debugger = newInteractiveDebugger(os.Stdin, os.Stdout)
+		evmTracer = addTracer(evmTracer, debugger)
 	}
 	if ctx.String(GenesisFlag.Name) != "" {
 		gen := readGenesis(ctx.String(GenesisFlag.Name))
@@ -213,7 +300,7 @@ func runCmd(ctx *cli.Context) error {
 		Coinbase:    genesisConfig.Coinbase,
 		BlockNumber: new(big.Int).SetUint64(genesisConfig.Number),
 		EVMConfig: vm.Config{
-			Tracer: tracer,
+			Tracer: evmTracer,
 		},
 	}
 
@@ -237,7 +324,13 @@ func runCmd(ctx *cli.Context) error {
 	}
 
 	var hexInput []byte
-	if inputFileFlag := ctx.String(InputFileFlag.Name); inputFileFlag != "" {
+	if inputFileFlag := ctx.String(InputFileFlag.Name); inputFileFlag == "-" {
+		var err error
+		if hexInput, err = io.ReadAll(os.Stdin); err != nil {
+			fmt.Printf("could not load input from stdin: %v\n", err)
+			os.Exit(1)
+		}
+	} else if inputFileFlag != "" {
 		var err error
 		if hexInput, err = os.ReadFile(inputFileFlag); err != nil {
 			fmt.Printf("could not load input from file: %v\n", err)
@@ -271,6 +364,9 @@ func runCmd(ctx *cli.Context) error {
 
 	bench := ctx.Bool(BenchFlag.Name)
 	output, leftOverGas, stats, err := timedExec(bench, execFunc)
+	if debugger != nil && debugger.Err() != nil {
+		return debugger.Err()
+	}
 
 	if ctx.Bool(DumpFlag.Name) {
 		statedb.Commit(true)
@@ -291,8 +387,14 @@ func runCmd(ctx *cli.Context) error {
 		f.Close()
 	}
 
-	if ctx.Bool(DebugFlag.Name) {
-		if debugLogger != nil {
+	if traceFormat == "json" {
+		if err := writeTraceJSON(os.Stdout, debugLogger, initialGas-leftOverGas, err); err != nil {
+			return err
+		}
+	}
+
+	if ctx.Bool(DebugFlag.Name) || traceFormat == "text" {
+		if debugLogger != nil && traceFormat == "text" {
 			fmt.Fprintln(os.Stderr, "#### TRACE ####")
 			logger.WriteTrace(os.Stderr, debugLogger.StructLogs())
 		}
@@ -307,6 +409,10 @@ allocations:     %d
 allocated bytes: %d
 `, initialGas-leftOverGas, stats.time, stats.allocs, stats.bytesAllocated)
 	}
+	if profiler != nil {
+		fmt.Fprintln(os.Stderr, "#### GAS PROFILE ####")
+		writeGasProfile(os.Stderr, profiler.Profile())
+	}
 	if tracer == nil {
 		fmt.Printf("%#x\n", output)
 		if err != nil {
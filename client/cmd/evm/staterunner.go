@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/r5-labs/r5-core/client/cmd/evm/internal/t8ntool"
 	"github.com/r5-labs/r5-core/client/common"
 	"github.com/r5-labs/r5-core/client/core/state"
 	"github.com/r5-labs/r5-core/client/core/vm"
@@ -50,7 +51,7 @@ func stateTestCmd(ctx *cli.Context) error {
 		return errors.New("path-to-test argument required")
 	}
 	// Configure the go-ethereum logger
-	glogger := log.NewGlogHandler(log.StreamHandler(os.Stderr, log.TerminalFormat(false)))
+	glogger := log.NewGlogHandler(t8ntool.LogHandler(ctx))
 	glogger.Verbosity(log.Lvl(ctx.Int(VerbosityFlag.Name)))
 	log.Root().SetHandler(glogger)
 
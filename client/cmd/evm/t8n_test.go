@@ -15,6 +15,7 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"os"
 	"reflect"
 	"strings"
@@ -22,7 +23,13 @@ import (
 
 	"github.com/docker/docker/pkg/reexec"
 	"github.com/r5-labs/r5-core/client/cmd/evm/internal/t8ntool"
+	"github.com/r5-labs/r5-core/client/common"
+	"github.com/r5-labs/r5-core/client/common/hexutil"
+	"github.com/r5-labs/r5-core/client/common/math"
+	"github.com/r5-labs/r5-core/client/consensus/ethash"
+	"github.com/r5-labs/r5-core/client/core/asm"
 	"github.com/r5-labs/r5-core/client/internal/cmdtest"
+	"github.com/r5-labs/r5-core/client/params"
 )
 
 func TestMain(m *testing.M) {
@@ -291,6 +298,283 @@ func TestT8n(t *testing.T) {
 	}
 }
 
+// Tests that the R5 ruleset credits the block reward from the R5 emission
+// schedule, ignoring the flat --state.reward, on top of the transaction fees.
+func TestT8nR5Rewards(t *testing.T) {
+	tt := new(testT8n)
+	tt.TestCmd = cmdtest.NewTestCmd(t, tt)
+
+	var (
+		base   = "./testdata/28"
+		input  = t8nInput{"alloc.json", "txs.json", "env.json", "R5", "0x80"}
+		output = t8nOutput{alloc: true, result: true}
+	)
+	args := append([]string{"t8n"}, output.get()...)
+	args = append(args, input.get(base)...)
+	tt.Run("evm-test", args...)
+
+	have := tt.Output()
+	want, err := os.ReadFile(fmt.Sprintf("%v/%v", base, "exp.json"))
+	if err != nil {
+		t.Fatalf("could not read expected output: %v", err)
+	}
+	if ok, err := cmpJson(have, want); err != nil {
+		t.Fatalf("json parsing failed: %v", err)
+	} else if !ok {
+		t.Fatalf("output wrong, have \n%v\nwant\n%v\n", string(have), string(want))
+	}
+	tt.WaitExit()
+	if status := tt.ExitStatus(); status != 0 {
+		t.Fatalf("wrong exit code, have %d, want 0", status)
+	}
+	// Block 4000001 is the first block of the second super epoch, rewarding
+	// 1 R5, and the only transaction pays 21000 gas at 1 gwei.
+	var result struct {
+		Alloc map[common.Address]struct {
+			Balance *math.HexOrDecimal256 `json:"balance"`
+		} `json:"alloc"`
+	}
+	if err := json.Unmarshal(have, &result); err != nil {
+		t.Fatalf("failed to decode output: %v", err)
+	}
+	coinbase := common.HexToAddress("0xc94f5374fce5edbc8e2a8697c15331677e6ebf0b")
+	expected := new(big.Int).Add(ethash.BlockReward(4000001), big.NewInt(21000*params.GWei))
+	if expected.Cmp(big.NewInt(params.Ether+21000*params.GWei)) != 0 {
+		t.Fatalf("unexpected emission schedule reward: %v", ethash.BlockReward(4000001))
+	}
+	if balance := (*big.Int)(result.Alloc[coinbase].Balance); balance == nil || balance.Cmp(expected) != 0 {
+		t.Fatalf("coinbase balance mismatch: have %v, want %v", balance, expected)
+	}
+}
+
+// TestRunTraceFormatJSONL runs a straight-line snippet with the jsonl trace
+// format and checks that every executed opcode emits exactly one line, followed
+// by the summary object.
+func TestRunTraceFormatJSONL(t *testing.T) {
+	tt := new(testT8n)
+	tt.TestCmd = cmdtest.NewTestCmd(t, tt)
+
+	// PUSH1 1, PUSH1 2, ADD, PUSH1 0, MSTORE, PUSH1 32, PUSH1 0, RETURN
+	code := "600160020160005260206000f3"
+
+	var ops int
+	for it := asm.NewInstructionIterator(common.FromHex(code)); it.Next(); {
+		ops++
+	}
+	tt.Run("evm-test", "--code", code, "--trace-format", "jsonl", "run")
+	have := tt.Output()
+	tt.WaitExit()
+	if status := tt.ExitStatus(); status != 0 {
+		t.Fatalf("wrong exit code, have %d, want 0", status)
+	}
+	lines := strings.Split(strings.TrimSpace(string(have)), "\n")
+	if len(lines) != ops+1 {
+		t.Fatalf("line count mismatch: have %d, want %d\n%s", len(lines), ops+1, have)
+	}
+	for i, line := range lines[:ops] {
+		var step struct {
+			Pc     *uint64 `json:"pc"`
+			OpName string  `json:"opName"`
+		}
+		if err := json.Unmarshal([]byte(line), &step); err != nil {
+			t.Fatalf("line %d: invalid json: %v", i, err)
+		}
+		if step.Pc == nil || step.OpName == "" {
+			t.Fatalf("line %d: not an execution step: %s", i, line)
+		}
+	}
+	var summary struct {
+		Output  *string              `json:"output"`
+		GasUsed *math.HexOrDecimal64 `json:"gasUsed"`
+		Error   string               `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(lines[ops]), &summary); err != nil {
+		t.Fatalf("invalid summary: %v", err)
+	}
+	if summary.Output == nil || summary.GasUsed == nil {
+		t.Fatalf("incomplete summary: %s", lines[ops])
+	}
+	if want := common.Bytes2Hex(common.LeftPadBytes([]byte{3}, 32)); *summary.Output != want {
+		t.Errorf("output mismatch: have %s, want %s", *summary.Output, want)
+	}
+	if summary.Error != "" {
+		t.Errorf("unexpected error: %s", summary.Error)
+	}
+}
+
+// TestRunPrestateStdin pipes a prestate document through stdin and checks that
+// the executed code observes the storage it defines.
+func TestRunPrestateStdin(t *testing.T) {
+	tt := new(testT8n)
+	tt.TestCmd = cmdtest.NewTestCmd(t, tt)
+
+	// The default receiver returns its storage slot 0: SLOAD(0), MSTORE(0), RETURN(0, 32)
+	prestate := `{
+		"gasLimit": "0x989680",
+		"difficulty": "0x1",
+		"alloc": {
+			"0x0000000000000000000000007265636569766572": {
+				"balance": "0x0",
+				"code": "0x60005460005260206000f3",
+				"storage": {"0x00": "0x2a"}
+			}
+		}
+	}`
+	tt.Run("evm-test", "--prestate", "-", "run")
+	tt.InputLine(prestate)
+	tt.CloseStdin()
+	have := strings.TrimSpace(string(tt.Output()))
+	tt.WaitExit()
+	if status := tt.ExitStatus(); status != 0 {
+		t.Fatalf("wrong exit code, have %d, want 0", status)
+	}
+	if want := hexutil.Encode(common.LeftPadBytes([]byte{0x2a}, 32)); have != want {
+		t.Fatalf("output mismatch: have %s, want %s", have, want)
+	}
+}
+
+// TestRunStdinConflict checks that requesting stdin for more than one input is
+// rejected instead of splitting the stream between them.
+func TestRunStdinConflict(t *testing.T) {
+	tt := new(testT8n)
+	tt.TestCmd = cmdtest.NewTestCmd(t, tt)
+
+	tt.Run("evm-test", "--prestate", "-", "--codefile", "-", "run")
+	tt.CloseStdin()
+	tt.WaitExit()
+	if status := tt.ExitStatus(); status != 1 {
+		t.Fatalf("wrong exit code, have %d, want 1", status)
+	}
+	if want := "only one of --codefile, --prestate can read from stdin"; !strings.Contains(tt.StderrText(), want) {
+		t.Fatalf("missing error %q in output: %s", want, tt.StderrText())
+	}
+}
+
+// TestRunGasProfile runs a countdown loop with the gas profiler enabled and
+// checks that the loop's jump dominates the printed profile.
+func TestRunGasProfile(t *testing.T) {
+	tt := new(testT8n)
+	tt.TestCmd = cmdtest.NewTestCmd(t, tt)
+
+	// PUSH1 255, JUMPDEST, PUSH1 1, SWAP1, SUB, DUP1, PUSH1 2, JUMPI, STOP
+	code := "60ff5b600190038060025700"
+
+	tt.Run("evm-test", "--code", code, "--gasprofile", "run")
+	tt.Output()
+	tt.WaitExit()
+	if status := tt.ExitStatus(); status != 0 {
+		t.Fatalf("wrong exit code, have %d, want 0", status)
+	}
+	stderr := tt.StderrText()
+	_, table, found := strings.Cut(stderr, "#### GAS PROFILE ####\n")
+	if !found {
+		t.Fatalf("missing gas profile in output: %s", stderr)
+	}
+	rows := strings.Split(strings.TrimSpace(table), "\n")
+	if len(rows) < 2 {
+		t.Fatalf("empty gas profile: %s", table)
+	}
+	var (
+		op         string
+		count, gas uint64
+	)
+	if _, err := fmt.Sscan(rows[1], &op, &count, &gas); err != nil {
+		t.Fatalf("invalid profile row %q: %v", rows[1], err)
+	}
+	// The jump runs once per iteration at 10 gas, more than any other opcode
+	if op != "JUMPI" {
+		t.Fatalf("dominant opcode mismatch: have %s, want JUMPI\n%s", op, table)
+	}
+	if count != 255 || gas != 255*10 {
+		t.Fatalf("JUMPI profile mismatch: have count %d gas %d, want count 255 gas %d", count, gas, 255*10)
+	}
+}
+
+// TestRunDebugInteractive drives the interactive debugger with scripted commands
+// and checks that it paused at every stepped opcode.
+func TestRunDebugInteractive(t *testing.T) {
+	// PUSH1 1, PUSH1 2, ADD, PUSH1 0, MSTORE
+	code := "600160020160005200"
+
+	for i, tc := range []struct {
+		commands []string
+		steps    int
+		want     string
+		exit     int
+	}{
+		// Step through the first opcodes, printing in between, then run to the end
+		{[]string{"s", "s", "p", "s", "c"}, 4, "   0: 0x2\n   1: 0x1\n", 0},
+		// An exhausted input runs to the end as well
+		{[]string{"s"}, 2, "", 0},
+		// Quitting cancels the execution without pausing again and fails the run
+		{[]string{"s", "s", "s", "q"}, 4, "", 1},
+		// Unknown commands don't resume the execution
+		{[]string{"x", "s", "c"}, 2, "unknown command \"x\"", 0},
+	} {
+		tt := new(testT8n)
+		tt.TestCmd = cmdtest.NewTestCmd(t, tt)
+		tt.Run("evm-test", "--code", code, "--debug-interactive", "run")
+		for _, cmd := range tc.commands {
+			tt.InputLine(cmd)
+		}
+		tt.CloseStdin()
+		output := string(tt.Output())
+		tt.WaitExit()
+		if status := tt.ExitStatus(); status != tc.exit {
+			t.Fatalf("test %d: wrong exit code, have %d, want %d", i, status, tc.exit)
+		}
+		if tc.exit != 0 && !strings.Contains(tt.StderrText(), errExecutionAborted.Error()) {
+			t.Errorf("test %d: missing abort error in stderr:\n%s", i, tt.StderrText())
+		}
+		if steps := strings.Count(output, "pc="); steps != tc.steps {
+			t.Errorf("test %d: step count mismatch: have %d, want %d\n%s", i, steps, tc.steps, output)
+		}
+		if !strings.Contains(output, tc.want) {
+			t.Errorf("test %d: missing %q in output:\n%s", i, tc.want, output)
+		}
+	}
+}
+
+// Tests that the R5 ruleset reports the block reward minted by the emission
+// schedule in the transition result.
+func TestT8nR5BlockReward(t *testing.T) {
+	for i, tc := range []struct {
+		base   string
+		number uint64
+	}{
+		{"./testdata/28", 4000001},
+		{"./testdata/29", 128000001},
+	} {
+		tt := new(testT8n)
+		tt.TestCmd = cmdtest.NewTestCmd(t, tt)
+
+		var (
+			input  = t8nInput{"alloc.json", "txs.json", "env.json", "R5", "0x80"}
+			output = t8nOutput{result: true}
+		)
+		args := append([]string{"t8n"}, output.get()...)
+		args = append(args, input.get(tc.base)...)
+		tt.Run("evm-test", args...)
+
+		var result struct {
+			Result struct {
+				BlockReward *math.HexOrDecimal256 `json:"blockReward"`
+			} `json:"result"`
+		}
+		if err := json.Unmarshal(tt.Output(), &result); err != nil {
+			t.Fatalf("test %d: failed to decode output: %v", i, err)
+		}
+		tt.WaitExit()
+		if status := tt.ExitStatus(); status != 0 {
+			t.Fatalf("test %d: wrong exit code, have %d, want 0", i, status)
+		}
+		want := ethash.BlockReward(tc.number)
+		if have := (*big.Int)(result.Result.BlockReward); have == nil || have.Cmp(want) != 0 {
+			t.Errorf("test %d: block reward mismatch: have %v, want %v", i, have, want)
+		}
+	}
+}
+
 type t9nInput struct {
 	inTxs  string
 	stFork string
@@ -357,6 +641,14 @@ func TestT9n(t *testing.T) {
 			},
 			expOut: "exp.json",
 		},
+		{ // One transaction of each supported type, and an unknown type
+			base: "./testdata/30",
+			input: t9nInput{
+				inTxs:  "signed_txs.rlp",
+				stFork: "London",
+			},
+			expOut: "exp.json",
+		},
 		{ // Invalid RLP
 			base: "./testdata/18",
 			input: t9nInput{
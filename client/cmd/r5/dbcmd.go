@@ -14,6 +14,7 @@ package main
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
@@ -29,10 +30,12 @@ import (
 	"github.com/r5-labs/r5-core/client/console/prompt"
 	"github.com/r5-labs/r5-core/client/core/rawdb"
 	"github.com/r5-labs/r5-core/client/core/state/snapshot"
+	"github.com/r5-labs/r5-core/client/core/types"
 	"github.com/r5-labs/r5-core/client/crypto"
 	"github.com/r5-labs/r5-core/client/ethdb"
 	"github.com/r5-labs/r5-core/client/internal/flags"
 	"github.com/r5-labs/r5-core/client/log"
+	"github.com/r5-labs/r5-core/client/rlp"
 	"github.com/r5-labs/r5-core/client/trie"
 	"github.com/olekukonko/tablewriter"
 	"github.com/urfave/cli/v2"
@@ -65,6 +68,7 @@ Remove blockchain and state databases`,
 			dbExportCmd,
 			dbMetadataCmd,
 			dbCheckStateContentCmd,
+			dbVerifyTrieCmd,
 		},
 	}
 	dbInspectCmd = &cli.Command{
@@ -86,6 +90,17 @@ Remove blockchain and state databases`,
 		Description: `This command iterates the entire database for 32-byte keys, looking for rlp-encoded trie nodes.
 For each trie node encountered, it checks that the key corresponds to the keccak256(value). If this is not true, this indicates
 a data corruption.`,
+	}
+	dbVerifyTrieCmd = &cli.Command{
+		Action:    verifyTrie,
+		Name:      "verify-trie",
+		ArgsUsage: "<root (optional)>",
+		Flags:     flags.Merge([]cli.Flag{stateSchemeFlag}, utils.NetworkFlags, utils.DatabasePathFlags),
+		Usage:     "Verify that the trie nodes of a state are cryptographically correct",
+		Description: `This command walks the account trie of the given state root (the HEAD state by default) and
+all the storage tries it references. For each trie node loaded, it checks that keccak256(value) matches the hash
+the node is referenced by, and reports the ones that don't. Unlike check-state-content, only the nodes reachable
+from the root are checked, looked up according to the --state.scheme of the database (hash or path).`,
 	}
 	dbStatCmd = &cli.Command{
 		Action: dbStats,
@@ -336,6 +351,84 @@ func checkStateContent(ctx *cli.Context) error {
 	return nil
 }
 
+func verifyTrie(ctx *cli.Context) error {
+	if ctx.NArg() > 1 {
+		return fmt.Errorf("max 1 argument: %v", ctx.Command.ArgsUsage)
+	}
+	scheme, err := parseScheme(ctx.String(stateSchemeFlag.Name))
+	if err != nil {
+		return err
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, true)
+	defer db.Close()
+
+	var root common.Hash
+	if ctx.NArg() == 1 {
+		if root, err = parseRoot(ctx.Args().First()); err != nil {
+			return fmt.Errorf("failed to resolve state root: %v", err)
+		}
+	} else {
+		head := rawdb.ReadHeadBlock(db)
+		if head == nil {
+			return errors.New("no head block")
+		}
+		root = head.Root()
+	}
+	log.Info("Verifying the state tries", "root", root, "scheme", scheme)
+
+	var (
+		reader    = schemeNodeReader(db, scheme)
+		errs      int
+		nodes     int
+		accounts  int
+		startTime = time.Now()
+		lastLog   = time.Now()
+	)
+	report := func(owner common.Hash, corrupted []common.Hash) {
+		for _, hash := range corrupted {
+			errs++
+			fmt.Printf("Error at %#x\n", hash)
+			if owner != (common.Hash{}) {
+				fmt.Printf("  Owner: %#x\n", owner)
+			}
+		}
+	}
+	accountNodes, corrupted, err := trie.VerifyNodeBlobs(trie.StateTrieID(root), reader, func(key, value []byte) error {
+		accounts++
+		var acc types.StateAccount
+		if err := rlp.DecodeBytes(value, &acc); err != nil {
+			return fmt.Errorf("invalid account %#x: %v", key, err)
+		}
+		if acc.Root != types.EmptyRootHash {
+			owner := common.BytesToHash(key)
+			storageNodes, corrupted, err := trie.VerifyNodeBlobs(trie.StorageTrieID(root, owner, acc.Root), reader, nil)
+			if err != nil {
+				return err
+			}
+			nodes += storageNodes
+			report(owner, corrupted)
+		}
+		if time.Since(lastLog) > 8*time.Second {
+			log.Info("Verifying the state tries", "at", fmt.Sprintf("%#x", key), "accounts", accounts, "elapsed", common.PrettyDuration(time.Since(startTime)))
+			lastLog = time.Now()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	nodes += accountNodes
+	report(common.Hash{}, corrupted)
+	log.Info("Verified the state tries", "errors", errs, "nodes", nodes, "accounts", accounts, "elapsed", common.PrettyDuration(time.Since(startTime)))
+	if errs > 0 {
+		return fmt.Errorf("%d corrupted trie nodes", errs)
+	}
+	return nil
+}
+
 func showLeveldbStats(db ethdb.KeyValueStater) {
 	if stats, err := db.Stat("leveldb.stats"); err != nil {
 		log.Warn("Failed to read database stats", "error", err)
@@ -0,0 +1,183 @@
+// Copyright 2025 R5 Labs
+// This file is part of the R5 Core library.
+//
+// This software is provided "as is", without warranty of any kind,
+// express or implied, including but not limited to the warranties
+// of merchantability, fitness for a particular purpose and
+// noninfringement. In no event shall the authors or copyright
+// holders be liable for any claim, damages, or other liability,
+// whether in an action of contract, tort or otherwise, arising
+// from, out of or in connection with the software or the use or
+// other dealings in the software.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+
+	"github.com/r5-labs/r5-core/client/cmd/utils"
+	"github.com/r5-labs/r5-core/client/common"
+	"github.com/r5-labs/r5-core/client/common/hexutil"
+	"github.com/r5-labs/r5-core/client/common/math"
+	"github.com/r5-labs/r5-core/client/consensus/ethash"
+	"github.com/r5-labs/r5-core/client/core/rawdb"
+	"github.com/r5-labs/r5-core/client/ethdb"
+	"github.com/r5-labs/r5-core/client/internal/flags"
+	cli "github.com/urfave/cli/v2"
+)
+
+var (
+	historyFromFlag = &cli.Uint64Flag{
+		Name:  "from",
+		Usage: "Number of the first block to report",
+	}
+	historyToFlag = &cli.Uint64Flag{
+		Name:  "to",
+		Usage: "Number of the last block to report (default = head block)",
+	}
+	difficultyHistoryCommand = &cli.Command{
+		Name:   "difficulty-history",
+		Usage:  "Print the block time and difficulty history of the canonical chain",
+		Action: difficultyHistory,
+		Flags: flags.Merge([]cli.Flag{
+			historyFromFlag,
+			historyToFlag,
+		}, utils.NetworkFlags, utils.DatabasePathFlags),
+		Description: `
+r5 difficulty-history --from <number> --to <number>
+will print the number, the time elapsed since the parent block and the difficulty
+of each canonical block in the given range, allowing to audit the difficulty
+adjustment towards the 7 second block time target against the real block times.
+The range defaults to the whole chain up to the HEAD block.
+`,
+	}
+	diffToTargetCommand = &cli.Command{
+		Name:      "diff2target",
+		Usage:     "Convert a proof-of-work difficulty to its mining target",
+		ArgsUsage: "<difficulty>",
+		Action:    diffToTarget,
+		Description: `
+r5 diff2target <difficulty>
+will print the target 2^256 / difficulty a block hash must not exceed, in the
+same format as the target returned by eth_getWork. The difficulty may be given
+in decimal or as 0x prefixed hex.
+`,
+	}
+	targetToDiffCommand = &cli.Command{
+		Name:      "target2diff",
+		Usage:     "Convert a proof-of-work mining target to its difficulty",
+		ArgsUsage: "<target>",
+		Action:    targetToDiff,
+		Description: `
+r5 target2diff <target>
+will print the difficulty 2^256 / target corresponding to a mining target, such
+as the one returned by eth_getWork. The target may be given in decimal or as 0x
+prefixed hex.
+`,
+	}
+)
+
+func difficultyHistory(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, true)
+	defer db.Close()
+
+	head := rawdb.ReadHeadHeader(db)
+	if head == nil {
+		return errors.New("no head block found")
+	}
+	to := head.Number.Uint64()
+	if ctx.IsSet(historyToFlag.Name) {
+		to = ctx.Uint64(historyToFlag.Name)
+	}
+	return writeDifficultyHistory(os.Stdout, db, ctx.Uint64(historyFromFlag.Name), to)
+}
+
+// writeDifficultyHistory writes the number, the timestamp delta from the parent
+// and the difficulty of the canonical blocks in the [from, to] range to w. The
+// delta of the genesis block is reported as zero.
+func writeDifficultyHistory(w io.Writer, db ethdb.Reader, from, to uint64) error {
+	if from > to {
+		return fmt.Errorf("invalid block range: from %d > to %d", from, to)
+	}
+	fmt.Fprintf(w, "%-12s %-8s %s\n", "number", "delta", "difficulty")
+
+	var parentTime uint64
+	if from > 0 {
+		hash := rawdb.ReadCanonicalHash(db, from-1)
+		parent := rawdb.ReadHeader(db, hash, from-1)
+		if parent == nil {
+			return fmt.Errorf("header for block %d not found", from-1)
+		}
+		parentTime = parent.Time
+	}
+	for number := from; number <= to; number++ {
+		hash := rawdb.ReadCanonicalHash(db, number)
+		header := rawdb.ReadHeader(db, hash, number)
+		if header == nil {
+			return fmt.Errorf("header for block %d not found", number)
+		}
+		var delta uint64
+		if number > 0 {
+			delta = header.Time - parentTime
+		}
+		fmt.Fprintf(w, "%-12d %-8d %v\n", number, delta, header.Difficulty)
+		parentTime = header.Time
+	}
+	return nil
+}
+
+func diffToTarget(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		utils.Fatalf("difficulty must be given as the only argument")
+	}
+	target, err := difficultyToTarget(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	fmt.Println(target)
+	return nil
+}
+
+func targetToDiff(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		utils.Fatalf("target must be given as the only argument")
+	}
+	difficulty, err := targetToDifficulty(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	fmt.Println(difficulty)
+	return nil
+}
+
+// difficultyToTarget converts a decimal or hex difficulty to the mining target
+// enforced by the seal verification, formatted like the eth_getWork target. The
+// target of difficulty 1 does not fit into 32 bytes and is printed unpadded.
+func difficultyToTarget(arg string) (string, error) {
+	difficulty, ok := math.ParseBig256(arg)
+	if !ok || difficulty.Sign() <= 0 {
+		return "", fmt.Errorf("invalid difficulty %q", arg)
+	}
+	target := ethash.DifficultyToTarget(difficulty)
+	if target.BitLen() > 256 {
+		return hexutil.EncodeBig(target), nil
+	}
+	return common.BigToHash(target).Hex(), nil
+}
+
+// targetToDifficulty converts a decimal or hex mining target to the difficulty
+// it corresponds to.
+func targetToDifficulty(arg string) (*big.Int, error) {
+	target, ok := math.ParseBig256(arg)
+	if !ok || target.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid target %q", arg)
+	}
+	return ethash.TargetToDifficulty(target), nil
+}
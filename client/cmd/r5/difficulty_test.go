@@ -0,0 +1,121 @@
+// Copyright 2025 R5 Labs
+// This file is part of the R5 Core library.
+//
+// This software is provided "as is", without warranty of any kind,
+// express or implied, including but not limited to the warranties
+// of merchantability, fitness for a particular purpose and
+// noninfringement. In no event shall the authors or copyright
+// holders be liable for any claim, damages, or other liability,
+// whether in an action of contract, tort or otherwise, arising
+// from, out of or in connection with the software or the use or
+// other dealings in the software.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/r5-labs/r5-core/client/common"
+	"github.com/r5-labs/r5-core/client/consensus/ethash"
+	"github.com/r5-labs/r5-core/client/core"
+	"github.com/r5-labs/r5-core/client/core/rawdb"
+	"github.com/r5-labs/r5-core/client/core/vm"
+	"github.com/r5-labs/r5-core/client/params"
+)
+
+func TestDifficultyHistory(t *testing.T) {
+	gspec := &core.Genesis{Config: params.TestChainConfig}
+	offsets := []int64{0, -5, 3, -9, 20, 0}
+	_, blocks, _ := core.GenerateChainWithGenesis(gspec, ethash.NewFaker(), len(offsets), func(i int, gen *core.BlockGen) {
+		gen.OffsetTime(offsets[i])
+	})
+	db := rawdb.NewMemoryDatabase()
+	chain, err := core.NewBlockChain(db, nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	defer chain.Stop()
+
+	var buf bytes.Buffer
+	if err := writeDifficultyHistory(&buf, db, 2, uint64(len(blocks))); err != nil {
+		t.Fatalf("failed to write difficulty history: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if want := 1 + len(blocks) - 1; len(lines) != want { // title plus blocks 2..6
+		t.Fatalf("line count mismatch: have %d, want %d", len(lines), want)
+	}
+	for i, line := range lines[1:] {
+		var (
+			block  = blocks[i+1]
+			parent = blocks[i]
+			want   = fmt.Sprintf("%d %d %v", block.NumberU64(), block.Time()-parent.Time(), block.Difficulty())
+		)
+		if have := strings.Join(strings.Fields(line), " "); have != want {
+			t.Errorf("block %d: history mismatch: have %q, want %q", block.NumberU64(), have, want)
+		}
+		if delta := int64(block.Time() - parent.Time()); delta != 10+offsets[i+1] {
+			t.Errorf("block %d: delta mismatch: have %d, want %d", block.NumberU64(), delta, 10+offsets[i+1])
+		}
+	}
+	// The genesis block is reported without a delta.
+	buf.Reset()
+	if err := writeDifficultyHistory(&buf, db, 0, 0); err != nil {
+		t.Fatalf("failed to write genesis history: %v", err)
+	}
+	if have, want := strings.Fields(strings.Split(buf.String(), "\n")[1]), []string{"0", "0", gspec.ToBlock().Difficulty().String()}; strings.Join(have, " ") != strings.Join(want, " ") {
+		t.Errorf("genesis history mismatch: have %v, want %v", have, want)
+	}
+	// Ranges beyond the chain are rejected.
+	if err := writeDifficultyHistory(&buf, db, 0, uint64(len(blocks)+1)); err == nil {
+		t.Error("reported history beyond the head block")
+	}
+	if err := writeDifficultyHistory(&buf, db, 3, 2); err == nil {
+		t.Error("reported history for inverted range")
+	}
+}
+
+func TestDifficultyTargetConversion(t *testing.T) {
+	two256 := new(big.Int).Lsh(big.NewInt(1), 256)
+	for _, arg := range []string{"1", "2", "131072", "0x20000", "1000000007", "0xffffffffffffffffffffff"} {
+		difficulty, _ := new(big.Int).SetString(arg, 0)
+
+		have, err := difficultyToTarget(arg)
+		if err != nil {
+			t.Fatalf("difficulty %s: failed to convert: %v", arg, err)
+		}
+		// The target must match the boundary enforced by the seal verification.
+		want := new(big.Int).Div(two256, difficulty)
+		if target, _ := new(big.Int).SetString(have[2:], 16); target.Cmp(want) != 0 {
+			t.Errorf("difficulty %s: target mismatch: have %s, want %#x", arg, have, want)
+		}
+		if difficulty.Cmp(big.NewInt(1)) == 0 {
+			continue
+		}
+		if want := common.BigToHash(want).Hex(); have != want {
+			t.Errorf("difficulty %s: target format mismatch: have %s, want %s", arg, have, want)
+		}
+		// Converting back must yield the original difficulty.
+		back, err := targetToDifficulty(have)
+		if err != nil {
+			t.Fatalf("difficulty %s: failed to convert target back: %v", arg, err)
+		}
+		if back.Cmp(difficulty) != 0 && ethash.DifficultyToTarget(back).Cmp(want) != 0 {
+			t.Errorf("difficulty %s: inverse mismatch: have %v", arg, back)
+		}
+	}
+	for _, arg := range []string{"", "0", "-1", "0xzz", "hello"} {
+		if _, err := difficultyToTarget(arg); err == nil {
+			t.Errorf("converted invalid difficulty %q", arg)
+		}
+		if _, err := targetToDifficulty(arg); err == nil {
+			t.Errorf("converted invalid target %q", arg)
+		}
+	}
+}
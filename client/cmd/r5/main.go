@@ -76,6 +76,7 @@ var (
 		utils.TxPoolRejournalFlag,
 		utils.TxPoolPriceLimitFlag,
 		utils.TxPoolPriceBumpFlag,
+		utils.TxPoolMinReplacementTipFlag,
 		utils.TxPoolAccountSlotsFlag,
 		utils.TxPoolGlobalSlotsFlag,
 		utils.TxPoolAccountQueueFlag,
@@ -229,10 +230,18 @@ func init() {
 		dumpConfigCommand,
 		// see dbcmd.go
 		dbCommand,
+		// See difficulty.go
+		difficultyHistoryCommand,
+		diffToTargetCommand,
+		targetToDiffCommand,
 		// See cmd/utils/flags_legacy.go
 		utils.ShowDeprecated,
 		// See snapshot.go
 		snapshotCommand,
+		// See supply.go
+		supplyAuditCommand,
+		// See triemigrate.go
+		trieMigrateCommand,
 		// See verkle.go
 		verkleCommand,
 	}
@@ -14,27 +14,63 @@ package main
 
 import (
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"math/big"
 	"os"
+	"sort"
 	"time"
 
 	"github.com/r5-labs/r5-core/client/cmd/utils"
 	"github.com/r5-labs/r5-core/client/common"
+	"github.com/r5-labs/r5-core/client/common/hexutil"
+	"github.com/r5-labs/r5-core/client/consensus/ethash"
 	"github.com/r5-labs/r5-core/client/core/rawdb"
 	"github.com/r5-labs/r5-core/client/core/state"
 	"github.com/r5-labs/r5-core/client/core/state/pruner"
 	"github.com/r5-labs/r5-core/client/core/state/snapshot"
 	"github.com/r5-labs/r5-core/client/core/types"
 	"github.com/r5-labs/r5-core/client/crypto"
+	"github.com/r5-labs/r5-core/client/ethdb"
 	"github.com/r5-labs/r5-core/client/internal/flags"
 	"github.com/r5-labs/r5-core/client/log"
+	"github.com/r5-labs/r5-core/client/params"
 	"github.com/r5-labs/r5-core/client/rlp"
 	"github.com/r5-labs/r5-core/client/trie"
 	cli "github.com/urfave/cli/v2"
 )
 
 var (
+	stateSchemeFlag = &cli.StringFlag{
+		Name:  "state.scheme",
+		Usage: "State scheme the trie nodes are stored with (hash or path)",
+		Value: "hash",
+	}
+	verifyParallelFlag = &cli.IntFlag{
+		Name:  "parallel",
+		Usage: "Number of account ranges to verify concurrently (1 verifies serially)",
+		Value: 1,
+	}
+	dumpFormatFlag = &cli.StringFlag{
+		Name:  "format",
+		Usage: "Output format of the dumped accounts (json or csv)",
+		Value: "json",
+	}
+	pruneDryRunFlag = &cli.BoolFlag{
+		Name:  "dry-run",
+		Usage: "Report the pruning target and the number of state entries to delete without pruning",
+	}
+	highlightRewardAccountsFlag = &cli.BoolFlag{
+		Name:  "highlight-reward-accounts",
+		Usage: "Annotate the coinbase account with its balance change from the parent block",
+	}
+	histogramTopFlag = &cli.UintFlag{
+		Name:  "top",
+		Usage: "Number of accounts with the largest balances to list",
+	}
 	snapshotCommand = &cli.Command{
 		Name:        "snapshot",
 		Usage:       "A set of commands based on the snapshot",
@@ -48,6 +84,7 @@ var (
 				Flags: flags.Merge([]cli.Flag{
 					utils.CacheTrieJournalFlag,
 					utils.BloomFilterSizeFlag,
+					pruneDryRunFlag,
 				}, utils.NetworkFlags, utils.DatabasePathFlags),
 				Description: `
 geth snapshot prune-state <state-root>
@@ -58,6 +95,10 @@ two version states are available: genesis and the specific one.
 
 The default pruning target is the HEAD-127 state.
 
+With --dry-run, the pruning target is resolved and the trie nodes and contract
+codes to retain and delete are counted using the same bloom filter, without
+modifying the database.
+
 WARNING: It's necessary to delete the trie clean cache after the pruning.
 If you specify another directory for the trie clean cache via "--cache.trie.journal"
 during the use of Geth, please also specify it here for correct deletion. Otherwise
@@ -69,12 +110,15 @@ the trie clean cache with default directory will be deleted.
 				Usage:     "Recalculate state hash based on the snapshot for verification",
 				ArgsUsage: "<root>",
 				Action:    verifyState,
-				Flags:     flags.Merge(utils.NetworkFlags, utils.DatabasePathFlags),
+				Flags:     flags.Merge([]cli.Flag{verifyParallelFlag}, utils.NetworkFlags, utils.DatabasePathFlags),
 				Description: `
 geth snapshot verify-state <state-root>
 will traverse the whole accounts and storages set based on the specified
 snapshot and recalculate the root hash of state for verification.
 In other words, this command does the snapshot to trie conversion.
+
+With --parallel N, the account key space is split into N ranges whose storage
+is verified concurrently, while the state root is still recalculated in full.
 `,
 			},
 			{
@@ -119,13 +163,14 @@ It's also usable without snapshot enabled.
 				Usage:     "Traverse the state with given root hash and perform detailed verification",
 				ArgsUsage: "<root>",
 				Action:    traverseRawState,
-				Flags:     flags.Merge(utils.NetworkFlags, utils.DatabasePathFlags),
+				Flags:     flags.Merge([]cli.Flag{stateSchemeFlag}, utils.NetworkFlags, utils.DatabasePathFlags),
 				Description: `
 geth snapshot traverse-rawstate <state-root>
 will traverse the whole state from the given root and will abort if any referenced
 trie node or contract code is missing. This command can be used for state integrity
 verification. The default checking target is the HEAD state. It's basically identical
-to traverse-state, but the check granularity is smaller. 
+to traverse-state, but the check granularity is smaller. The trie nodes are
+looked up according to the --state.scheme of the database (hash or path).
 
 It's also usable without snapshot enabled.
 `,
@@ -140,6 +185,8 @@ It's also usable without snapshot enabled.
 					utils.ExcludeStorageFlag,
 					utils.StartKeyFlag,
 					utils.DumpLimitFlag,
+					dumpFormatFlag,
+					highlightRewardAccountsFlag,
 				}, utils.NetworkFlags, utils.DatabasePathFlags),
 				Description: `
 This command is semantically equivalent to 'geth dump', but uses the snapshots
@@ -147,6 +194,29 @@ as the backend data source, making this command a lot faster.
 
 The argument is interpreted as block number or hash. If none is provided, the latest
 block is used.
+
+With --highlight-reward-accounts, the coinbase of the dumped block is annotated
+with its role, its balance change from the parent block and the block reward.
+
+With --format csv, the accounts are written as comma-separated rows of
+hash,balance,nonce,codeHash,root after a header line. The code is appended in
+hex unless --exclude-code is set, and the number of storage slots is appended
+unless --exclude-storage is set.
+`,
+			},
+			{
+				Name:      "balance-histogram",
+				Usage:     "Print the distribution of account balances of a specific block",
+				ArgsUsage: "[? <blockHash> | <blockNum>]",
+				Action:    balanceHistogram,
+				Flags:     flags.Merge([]cli.Flag{histogramTopFlag}, utils.NetworkFlags, utils.DatabasePathFlags),
+				Description: `
+r5 snapshot balance-histogram [? <blockHash> | <blockNum>]
+iterates all accounts in the snapshot of the given block and counts them in
+buckets of balances by powers of ten, along with the total supply held by the
+accounts. If no block is provided, the latest block is used.
+
+With --top N, the N accounts holding the largest balances are listed too.
 `,
 			},
 		},
@@ -159,7 +229,8 @@ func pruneState(ctx *cli.Context) error {
 	stack, config := makeConfigNode(ctx)
 	defer stack.Close()
 
-	chaindb := utils.MakeChainDatabase(ctx, stack, false)
+	dryRun := ctx.Bool(pruneDryRunFlag.Name)
+	chaindb := utils.MakeChainDatabase(ctx, stack, dryRun)
 	defer chaindb.Close()
 
 	prunerconfig := pruner.Config{
@@ -184,6 +255,16 @@ func pruneState(ctx *cli.Context) error {
 			return err
 		}
 	}
+	if dryRun {
+		estimate, err := pruner.DryRun(targetRoot)
+		if err != nil {
+			log.Error("Failed to estimate state pruning", "err", err)
+			return err
+		}
+		log.Info("State pruning dry run finished", "root", estimate.Root, "retained", estimate.Retained,
+			"deleted", estimate.Deleted, "size", estimate.DeletedSize)
+		return nil
+	}
 	if err = pruner.Prune(targetRoot); err != nil {
 		log.Error("Failed to prune state", "err", err)
 		return err
@@ -226,7 +307,7 @@ func verifyState(ctx *cli.Context) error {
 			return err
 		}
 	}
-	if err := snaptree.Verify(root); err != nil {
+	if err := snaptree.VerifyParallel(root, ctx.Int(verifyParallelFlag.Name)); err != nil {
 		log.Error("Failed to verify state", "root", root, "err", err)
 		return err
 	}
@@ -337,6 +418,10 @@ func traverseState(ctx *cli.Context) error {
 // contract codes are present. It's basically identical to traverseState
 // but it will check each trie node.
 func traverseRawState(ctx *cli.Context) error {
+	scheme, err := parseScheme(ctx.String(stateSchemeFlag.Name))
+	if err != nil {
+		return err
+	}
 	stack, _ := makeConfigNode(ctx)
 	defer stack.Close()
 
@@ -350,121 +435,83 @@ func traverseRawState(ctx *cli.Context) error {
 		log.Error("Too many arguments given")
 		return errors.New("too many arguments")
 	}
-	var (
-		root common.Hash
-		err  error
-	)
+	var root common.Hash
 	if ctx.NArg() == 1 {
 		root, err = parseRoot(ctx.Args().First())
 		if err != nil {
 			log.Error("Failed to resolve state root", "err", err)
 			return err
 		}
-		log.Info("Start traversing the state", "root", root)
+		log.Info("Start traversing the state", "root", root, "scheme", scheme)
 	} else {
 		root = headBlock.Root()
-		log.Info("Start traversing the state", "root", root, "number", headBlock.NumberU64())
-	}
-	triedb := trie.NewDatabase(chaindb)
-	t, err := trie.NewStateTrie(trie.StateTrieID(root), triedb)
-	if err != nil {
-		log.Error("Failed to open trie", "root", root, "err", err)
-		return err
+		log.Info("Start traversing the state", "root", root, "number", headBlock.NumberU64(), "scheme", scheme)
 	}
+	return traverseRawStateTrie(chaindb, root, scheme)
+}
+
+// traverseRawStateTrie walks the whole state of the given root, checking that
+// every referenced trie node is present under the key of the given scheme and
+// matches its hash, and that all the contract codes are available.
+func traverseRawStateTrie(chaindb ethdb.Database, root common.Hash, scheme string) error {
 	var (
+		reader     = schemeNodeReader(chaindb, scheme)
 		nodes      int
+		invalid    int
 		accounts   int
 		slots      int
 		codes      int
 		lastReport time.Time
 		start      = time.Now()
-		hasher     = crypto.NewKeccakState()
-		got        = make([]byte, 32)
 	)
-	accIter := t.NodeIterator(nil)
-	for accIter.Next(true) {
-		nodes += 1
-		node := accIter.Hash()
-
-		// Check the present for non-empty hash node(embedded node doesn't
-		// have their own hash).
-		if node != (common.Hash{}) {
-			blob := rawdb.ReadLegacyTrieNode(chaindb, node)
-			if len(blob) == 0 {
-				log.Error("Missing trie node(account)", "hash", node)
-				return errors.New("missing account")
-			}
-			hasher.Reset()
-			hasher.Write(blob)
-			hasher.Read(got)
-			if !bytes.Equal(got, node.Bytes()) {
-				log.Error("Invalid trie node(account)", "hash", node.Hex(), "value", blob)
-				return errors.New("invalid account node")
-			}
+	onAccount := func(key, value []byte) error {
+		accounts += 1
+		var acc types.StateAccount
+		if err := rlp.DecodeBytes(value, &acc); err != nil {
+			log.Error("Invalid account encountered during traversal", "err", err)
+			return errors.New("invalid account")
 		}
-		// If it's a leaf node, yes we are touching an account,
-		// dig into the storage trie further.
-		if accIter.Leaf() {
-			accounts += 1
-			var acc types.StateAccount
-			if err := rlp.DecodeBytes(accIter.LeafBlob(), &acc); err != nil {
-				log.Error("Invalid account encountered during traversal", "err", err)
-				return errors.New("invalid account")
-			}
-			if acc.Root != types.EmptyRootHash {
-				id := trie.StorageTrieID(root, common.BytesToHash(accIter.LeafKey()), acc.Root)
-				storageTrie, err := trie.NewStateTrie(id, triedb)
-				if err != nil {
-					log.Error("Failed to open storage trie", "root", acc.Root, "err", err)
-					return errors.New("missing storage trie")
-				}
-				storageIter := storageTrie.NodeIterator(nil)
-				for storageIter.Next(true) {
-					nodes += 1
-					node := storageIter.Hash()
-
-					// Check the presence for non-empty hash node(embedded node doesn't
-					// have their own hash).
-					if node != (common.Hash{}) {
-						blob := rawdb.ReadLegacyTrieNode(chaindb, node)
-						if len(blob) == 0 {
-							log.Error("Missing trie node(storage)", "hash", node)
-							return errors.New("missing storage")
-						}
-						hasher.Reset()
-						hasher.Write(blob)
-						hasher.Read(got)
-						if !bytes.Equal(got, node.Bytes()) {
-							log.Error("Invalid trie node(storage)", "hash", node.Hex(), "value", blob)
-							return errors.New("invalid storage node")
-						}
-					}
-					// Bump the counter if it's leaf node.
-					if storageIter.Leaf() {
-						slots += 1
-					}
-				}
-				if storageIter.Error() != nil {
-					log.Error("Failed to traverse storage trie", "root", acc.Root, "err", storageIter.Error())
-					return storageIter.Error()
-				}
+		if acc.Root != types.EmptyRootHash {
+			id := trie.StorageTrieID(root, common.BytesToHash(key), acc.Root)
+			storageNodes, corrupted, err := trie.VerifyNodeBlobs(id, reader, func(key, value []byte) error {
+				slots += 1
+				return nil
+			})
+			if err != nil {
+				log.Error("Failed to traverse storage trie", "root", acc.Root, "err", err)
+				return err
 			}
-			if !bytes.Equal(acc.CodeHash, types.EmptyCodeHash.Bytes()) {
-				if !rawdb.HasCode(chaindb, common.BytesToHash(acc.CodeHash)) {
-					log.Error("Code is missing", "account", common.BytesToHash(accIter.LeafKey()))
-					return errors.New("missing code")
-				}
-				codes += 1
+			nodes += storageNodes
+			for _, hash := range corrupted {
+				log.Error("Invalid trie node(storage)", "owner", common.BytesToHash(key), "hash", hash)
 			}
-			if time.Since(lastReport) > time.Second*8 {
-				log.Info("Traversing state", "nodes", nodes, "accounts", accounts, "slots", slots, "codes", codes, "elapsed", common.PrettyDuration(time.Since(start)))
-				lastReport = time.Now()
+			invalid += len(corrupted)
+		}
+		if !bytes.Equal(acc.CodeHash, types.EmptyCodeHash.Bytes()) {
+			if !rawdb.HasCode(chaindb, common.BytesToHash(acc.CodeHash)) {
+				log.Error("Code is missing", "account", common.BytesToHash(key))
+				return errors.New("missing code")
 			}
+			codes += 1
+		}
+		if time.Since(lastReport) > time.Second*8 {
+			log.Info("Traversing state", "accounts", accounts, "slots", slots, "codes", codes, "elapsed", common.PrettyDuration(time.Since(start)))
+			lastReport = time.Now()
 		}
+		return nil
+	}
+	accountNodes, corrupted, err := trie.VerifyNodeBlobs(trie.StateTrieID(root), reader, onAccount)
+	if err != nil {
+		log.Error("Failed to traverse state trie", "root", root, "err", err)
+		return err
+	}
+	nodes += accountNodes
+	for _, hash := range corrupted {
+		log.Error("Invalid trie node(account)", "hash", hash)
 	}
-	if accIter.Error() != nil {
-		log.Error("Failed to traverse state trie", "root", root, "err", accIter.Error())
-		return accIter.Error()
+	invalid += len(corrupted)
+	if invalid > 0 {
+		return fmt.Errorf("%d invalid trie nodes", invalid)
 	}
 	log.Info("State is complete", "nodes", nodes, "accounts", accounts, "slots", slots, "codes", codes, "elapsed", common.PrettyDuration(time.Since(start)))
 	return nil
@@ -482,20 +529,58 @@ func dumpState(ctx *cli.Context) error {
 	stack, _ := makeConfigNode(ctx)
 	defer stack.Close()
 
-	conf, db, root, err := parseDumpConfig(ctx, stack)
+	conf, db, header, err := parseDumpConfig(ctx, stack)
 	if err != nil {
 		return err
 	}
+	format := ctx.String(dumpFormatFlag.Name)
+	if format != "json" && format != "csv" {
+		return fmt.Errorf("unknown dump format %q, want json or csv", format)
+	}
+	return dumpSnapshot(os.Stdout, db, header, conf, format, ctx.Bool(highlightRewardAccountsFlag.Name))
+}
+
+// rewardDumpAccount is a dumped account annotated with the balance change the
+// dumped block caused to it.
+type rewardDumpAccount struct {
+	*state.DumpAccount
+	Role         string `json:"role"`
+	BalanceDelta string `json:"balanceDelta"`
+	BlockReward  string `json:"blockReward"`
+}
+
+// dumpSnapshot writes the state of the given block to w from the snapshot, one
+// account per line, either as JSON objects or as CSV rows. If highlight is set,
+// the coinbase account is annotated with its balance change from the parent block.
+func dumpSnapshot(w io.Writer, db ethdb.Database, header *types.Header, conf *state.DumpConfig, format string, highlight bool) error {
 	snapConfig := snapshot.Config{
 		CacheSize:  256,
 		Recovery:   false,
 		NoBuild:    true,
 		AsyncBuild: false,
 	}
+	root := header.Root
 	snaptree, err := snapshot.New(snapConfig, db, trie.NewDatabase(db), root)
 	if err != nil {
 		return err
 	}
+	var (
+		coinbaseHash common.Hash
+		parentState  *state.StateDB
+	)
+	if highlight {
+		if header.Number.Sign() == 0 {
+			return errors.New("genesis block has no parent to compare rewards with")
+		}
+		parent := rawdb.ReadHeader(db, header.ParentHash, header.Number.Uint64()-1)
+		if parent == nil {
+			return fmt.Errorf("parent block %x not found", header.ParentHash)
+		}
+		if parentState, err = state.New(parent.Root, state.NewDatabase(db), snaptree); err != nil {
+			return fmt.Errorf("parent state unavailable: %v", err)
+		}
+		coinbaseHash = crypto.Keccak256Hash(header.Coinbase.Bytes())
+	}
 	accIt, err := snaptree.AccountIterator(root, common.BytesToHash(conf.Start))
 	if err != nil {
 		return err
@@ -508,10 +593,31 @@ func dumpState(ctx *cli.Context) error {
 		logged   = time.Now()
 		accounts uint64
 	)
-	enc := json.NewEncoder(os.Stdout)
-	enc.Encode(struct {
-		Root common.Hash `json:"root"`
-	}{root})
+	var (
+		enc    *json.Encoder
+		csvEnc *csv.Writer
+	)
+	if format == "csv" {
+		csvEnc = csv.NewWriter(w)
+		defer csvEnc.Flush()
+
+		columns := []string{"hash", "balance", "nonce", "codeHash", "root"}
+		if !conf.SkipCode {
+			columns = append(columns, "code")
+		}
+		if !conf.SkipStorage {
+			columns = append(columns, "storage")
+		}
+		if highlight {
+			columns = append(columns, "role", "balanceDelta", "blockReward")
+		}
+		csvEnc.Write(columns)
+	} else {
+		enc = json.NewEncoder(w)
+		enc.Encode(struct {
+			Root common.Hash `json:"root"`
+		}{root})
+	}
 	for accIt.Next() {
 		account, err := snapshot.FullAccount(accIt.Account())
 		if err != nil {
@@ -538,7 +644,41 @@ func dumpState(ctx *cli.Context) error {
 				da.Storage[stIt.Hash()] = common.Bytes2Hex(stIt.Slot())
 			}
 		}
-		enc.Encode(da)
+		var annotated *rewardDumpAccount
+		if highlight && accIt.Hash() == coinbaseHash {
+			da.Address = &header.Coinbase
+			delta := new(big.Int).Sub(account.Balance, parentState.GetBalance(header.Coinbase))
+			annotated = &rewardDumpAccount{
+				DumpAccount:  da,
+				Role:         "coinbase",
+				BalanceDelta: delta.String(),
+				BlockReward:  ethash.BlockReward(header.Number.Uint64()).String(),
+			}
+		}
+		switch {
+		case csvEnc != nil:
+			row := []string{accIt.Hash().Hex(), da.Balance, fmt.Sprint(da.Nonce), hexutil.Encode(da.CodeHash), hexutil.Encode(da.Root)}
+			if !conf.SkipCode {
+				row = append(row, hexutil.Encode(da.Code))
+			}
+			if !conf.SkipStorage {
+				row = append(row, fmt.Sprint(len(da.Storage)))
+			}
+			if highlight {
+				if annotated != nil {
+					row = append(row, annotated.Role, annotated.BalanceDelta, annotated.BlockReward)
+				} else {
+					row = append(row, "", "", "")
+				}
+			}
+			if err := csvEnc.Write(row); err != nil {
+				return err
+			}
+		case annotated != nil:
+			enc.Encode(annotated)
+		default:
+			enc.Encode(da)
+		}
 		accounts++
 		if time.Since(logged) > 8*time.Second {
 			log.Info("Snapshot dumping in progress", "at", accIt.Hash(), "accounts", accounts,
@@ -554,6 +694,129 @@ func dumpState(ctx *cli.Context) error {
 	return nil
 }
 
+func balanceHistogram(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	_, db, header, err := parseDumpConfig(ctx, stack)
+	if err != nil {
+		return err
+	}
+	hist, err := snapshotBalances(db, header.Root, int(ctx.Uint(histogramTopFlag.Name)))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Balances at block #%d (root %x)\n\n", header.Number, header.Root)
+	hist.print(os.Stdout, db)
+	return nil
+}
+
+// richAccount is an account listed among the largest balances.
+type richAccount struct {
+	hash    common.Hash
+	balance *big.Int
+}
+
+// balanceStats is the distribution of the account balances of a state.
+type balanceStats struct {
+	accounts uint64        // Number of accounts iterated
+	zero     uint64        // Number of accounts without any balance
+	buckets  []uint64      // Number of accounts with a balance in [10^i, 10^(i+1)) wei at index i
+	supply   *big.Int      // Sum of all the balances
+	top      []richAccount // Accounts with the largest balances, in decreasing order
+}
+
+// snapshotBalances iterates the accounts in the snapshot of the given state root
+// and gathers the distribution of their balances, tracking the given number of
+// largest ones.
+func snapshotBalances(db ethdb.Database, root common.Hash, top int) (*balanceStats, error) {
+	snapConfig := snapshot.Config{
+		CacheSize:  256,
+		Recovery:   false,
+		NoBuild:    true,
+		AsyncBuild: false,
+	}
+	snaptree, err := snapshot.New(snapConfig, db, trie.NewDatabase(db), root)
+	if err != nil {
+		return nil, err
+	}
+	accIt, err := snaptree.AccountIterator(root, common.Hash{})
+	if err != nil {
+		return nil, err
+	}
+	defer accIt.Release()
+
+	log.Info("Snapshot balance iteration started", "root", root)
+	var (
+		stats  = &balanceStats{supply: new(big.Int)}
+		start  = time.Now()
+		logged = time.Now()
+	)
+	for accIt.Next() {
+		account, err := snapshot.FullAccount(accIt.Account())
+		if err != nil {
+			return nil, err
+		}
+		stats.accounts++
+		stats.supply.Add(stats.supply, account.Balance)
+
+		if account.Balance.Sign() == 0 {
+			stats.zero++
+		} else {
+			// The number of decimal digits selects the power of ten
+			bucket := len(account.Balance.String()) - 1
+			for len(stats.buckets) <= bucket {
+				stats.buckets = append(stats.buckets, 0)
+			}
+			stats.buckets[bucket]++
+		}
+		if top > 0 && (len(stats.top) < top || account.Balance.Cmp(stats.top[len(stats.top)-1].balance) > 0) {
+			i := sort.Search(len(stats.top), func(i int) bool {
+				return stats.top[i].balance.Cmp(account.Balance) < 0
+			})
+			stats.top = append(stats.top, richAccount{})
+			copy(stats.top[i+1:], stats.top[i:])
+			stats.top[i] = richAccount{hash: accIt.Hash(), balance: account.Balance}
+			if len(stats.top) > top {
+				stats.top = stats.top[:top]
+			}
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Snapshot balance iteration in progress", "at", accIt.Hash(), "accounts", stats.accounts,
+				"elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := accIt.Error(); err != nil {
+		return nil, err
+	}
+	log.Info("Snapshot balance iteration complete", "accounts", stats.accounts,
+		"elapsed", common.PrettyDuration(time.Since(start)))
+	return stats, nil
+}
+
+// print writes the histogram and the largest accounts to w. The addresses of the
+// largest accounts are resolved from the preimages in db, if available.
+func (stats *balanceStats) print(w io.Writer, db ethdb.Database) {
+	fmt.Fprintf(w, "%-18s %d\n", "0 wei", stats.zero)
+	for i, count := range stats.buckets {
+		fmt.Fprintf(w, "%-18s %d\n", fmt.Sprintf("1e%d - 1e%d wei", i, i+1), count)
+	}
+	supply := new(big.Float).Quo(new(big.Float).SetInt(stats.supply), big.NewFloat(params.Ether))
+	fmt.Fprintf(w, "\nAccounts: %d\nSupply:   %v wei (%v R5)\n", stats.accounts, stats.supply, supply)
+
+	if len(stats.top) > 0 {
+		fmt.Fprintf(w, "\nLargest balances:\n")
+		for i, account := range stats.top {
+			owner := account.hash.Hex()
+			if preimage := rawdb.ReadPreimage(db, account.hash); len(preimage) == common.AddressLength {
+				owner = common.BytesToAddress(preimage).Hex()
+			}
+			fmt.Fprintf(w, "%4d. %s %v wei\n", i+1, owner, account.balance)
+		}
+	}
+}
+
 // checkAccount iterates the snap data layers, and looks up the given account
 // across all layers.
 func checkAccount(ctx *cli.Context) error {
@@ -0,0 +1,271 @@
+// Copyright 2025 R5 Labs
+// This file is part of the R5 Core library.
+//
+// This software is provided "as is", without warranty of any kind,
+// express or implied, including but not limited to the warranties
+// of merchantability, fitness for a particular purpose and
+// noninfringement. In no event shall the authors or copyright
+// holders be liable for any claim, damages, or other liability,
+// whether in an action of contract, tort or otherwise, arising
+// from, out of or in connection with the software or the use or
+// other dealings in the software.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/r5-labs/r5-core/client/common"
+	"github.com/r5-labs/r5-core/client/consensus/ethash"
+	"github.com/r5-labs/r5-core/client/core"
+	"github.com/r5-labs/r5-core/client/core/rawdb"
+	"github.com/r5-labs/r5-core/client/core/state"
+	"github.com/r5-labs/r5-core/client/core/types"
+	"github.com/r5-labs/r5-core/client/core/vm"
+	"github.com/r5-labs/r5-core/client/crypto"
+	"github.com/r5-labs/r5-core/client/params"
+)
+
+func TestTraverseRawStatePathScheme(t *testing.T) {
+	hashdb, root := seedState(t)
+	if err := traverseRawStateTrie(hashdb, root, rawdb.HashScheme); err != nil {
+		t.Fatalf("failed to traverse hash-based state: %v", err)
+	}
+	pathdb := rawdb.NewMemoryDatabase()
+	if _, _, err := migrateTrieScheme(hashdb, pathdb, root, rawdb.HashScheme, rawdb.PathScheme); err != nil {
+		t.Fatalf("failed to migrate state: %v", err)
+	}
+	if err := traverseRawStateTrie(pathdb, root, rawdb.PathScheme); err != nil {
+		t.Fatalf("failed to traverse path-based state: %v", err)
+	}
+	// The path-based database holds no node keyed by hash.
+	if err := traverseRawStateTrie(pathdb, root, rawdb.HashScheme); err == nil {
+		t.Fatal("traversed path-based state with the hash scheme")
+	}
+	// Drop the root of a storage trie and ensure it's detected.
+	owner := crypto.Keccak256Hash(common.BytesToAddress([]byte{0}).Bytes())
+	if blob, _ := rawdb.ReadStorageTrieNode(pathdb, owner, nil); len(blob) == 0 {
+		t.Fatalf("storage trie root of %x not found", owner)
+	}
+	rawdb.DeleteStorageTrieNode(pathdb, owner, nil)
+	if err := traverseRawStateTrie(pathdb, root, rawdb.PathScheme); err == nil {
+		t.Fatal("traversed path-based state with missing storage trie")
+	}
+}
+
+func TestDumpSnapshotRewardAccounts(t *testing.T) {
+	var (
+		key, _   = crypto.GenerateKey()
+		sender   = crypto.PubkeyToAddress(key.PublicKey)
+		coinbase = common.Address{0xc0}
+		gspec    = &core.Genesis{
+			Config:  params.AllEthashProtocolChanges,
+			Alloc:   core.GenesisAlloc{sender: {Balance: big.NewInt(params.Ether)}},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		signer = types.LatestSigner(gspec.Config)
+		tip    = big.NewInt(params.GWei)
+	)
+	_, blocks, receipts := core.GenerateChainWithGenesis(gspec, ethash.NewFaker(), 2, func(i int, gen *core.BlockGen) {
+		gen.SetCoinbase(coinbase)
+		tx, _ := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+			Nonce:     uint64(i),
+			To:        &common.Address{0xaa},
+			Gas:       params.TxGas,
+			GasTipCap: tip,
+			GasFeeCap: new(big.Int).Add(gen.BaseFee(), tip),
+		})
+		gen.AddTx(tx)
+	})
+	db := rawdb.NewMemoryDatabase()
+	chain, err := core.NewBlockChain(db, nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	chain.Stop()
+
+	header := blocks[len(blocks)-1].Header()
+	dumpAccounts := func(highlight bool) []map[string]interface{} {
+		var buf bytes.Buffer
+		if err := dumpSnapshot(&buf, db, header, &state.DumpConfig{SkipStorage: true}, "json", highlight); err != nil {
+			t.Fatalf("failed to dump snapshot: %v", err)
+		}
+		var accounts []map[string]interface{}
+		scanner := bufio.NewScanner(&buf)
+		for scanner.Scan() {
+			var account map[string]interface{}
+			if err := json.Unmarshal(scanner.Bytes(), &account); err != nil {
+				t.Fatalf("failed to decode dumped line %q: %v", scanner.Text(), err)
+			}
+			accounts = append(accounts, account)
+		}
+		return accounts[1:] // Skip the root line
+	}
+	// The coinbase is annotated with the block reward and the collected tips.
+	fees := new(big.Int).Mul(tip, new(big.Int).SetUint64(receipts[1][0].GasUsed))
+	delta := new(big.Int).Add(ethash.BlockReward(header.Number.Uint64()), fees)
+
+	var annotated int
+	for _, account := range dumpAccounts(true) {
+		role, ok := account["role"]
+		if !ok {
+			continue
+		}
+		annotated++
+		if role != "coinbase" {
+			t.Errorf("role mismatch: have %v, want coinbase", role)
+		}
+		if addr, _ := account["address"].(string); !strings.EqualFold(addr, coinbase.Hex()) {
+			t.Errorf("annotated address mismatch: have %v, want %v", addr, coinbase)
+		}
+		if have := account["balanceDelta"]; have != delta.String() {
+			t.Errorf("balance delta mismatch: have %v, want %v", have, delta)
+		}
+		if have := account["blockReward"]; have != ethash.BlockReward(header.Number.Uint64()).String() {
+			t.Errorf("block reward mismatch: have %v, want %v", have, ethash.BlockReward(header.Number.Uint64()))
+		}
+	}
+	if annotated != 1 {
+		t.Fatalf("annotated account count mismatch: have %d, want 1", annotated)
+	}
+	// Without the flag, no annotations are emitted.
+	for _, account := range dumpAccounts(false) {
+		if _, ok := account["role"]; ok {
+			t.Fatalf("unexpected annotation without highlighting: %v", account)
+		}
+	}
+}
+
+func TestDumpSnapshotCSV(t *testing.T) {
+	var (
+		addrs = []common.Address{{0x01}, {0x02}, {0x03}}
+		gspec = &core.Genesis{
+			Config: params.AllEthashProtocolChanges,
+			Alloc: core.GenesisAlloc{
+				addrs[0]: {Balance: big.NewInt(1)},
+				addrs[1]: {Balance: big.NewInt(params.Ether)},
+				addrs[2]: {Balance: big.NewInt(3), Storage: map[common.Hash]common.Hash{{0x01}: {0x01}, {0x02}: {0x02}}},
+			},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+	)
+	_, blocks, _ := core.GenerateChainWithGenesis(gspec, ethash.NewFaker(), 1, nil)
+	db := rawdb.NewMemoryDatabase()
+	chain, err := core.NewBlockChain(db, nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	chain.Stop()
+
+	var buf bytes.Buffer
+	if err := dumpSnapshot(&buf, db, blocks[0].Header(), &state.DumpConfig{SkipCode: true}, "csv", false); err != nil {
+		t.Fatalf("failed to dump snapshot: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to parse dumped csv: %v", err)
+	}
+	if want := []string{"hash", "balance", "nonce", "codeHash", "root", "storage"}; strings.Join(rows[0], ",") != strings.Join(want, ",") {
+		t.Fatalf("header mismatch: have %v, want %v", rows[0], want)
+	}
+	// The allocated accounts and the coinbase credited with the block reward.
+	if have, want := len(rows)-1, len(addrs)+1; have != want {
+		t.Fatalf("row count mismatch: have %d, want %d", have, want)
+	}
+	var found bool
+	for _, row := range rows[1:] {
+		switch row[0] {
+		case crypto.Keccak256Hash(addrs[1].Bytes()).Hex():
+			found = true
+			if have, want := row[1], big.NewInt(params.Ether).String(); have != want {
+				t.Errorf("balance mismatch: have %v, want %v", have, want)
+			}
+		case crypto.Keccak256Hash(addrs[2].Bytes()).Hex():
+			if row[5] != "2" {
+				t.Errorf("storage count mismatch: have %v, want 2", row[5])
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("account %x missing from the dump", addrs[1])
+	}
+}
+
+func TestSnapshotBalances(t *testing.T) {
+	ether := big.NewInt(params.Ether)
+	balances := []*big.Int{
+		big.NewInt(0), big.NewInt(1), big.NewInt(9), big.NewInt(10), big.NewInt(999),
+		new(big.Int).Mul(big.NewInt(5), ether), new(big.Int).Mul(big.NewInt(7), ether),
+	}
+	alloc := make(core.GenesisAlloc)
+	for i, balance := range balances {
+		alloc[common.Address{byte(i + 1)}] = core.GenesisAccount{Balance: balance}
+	}
+	gspec := &core.Genesis{
+		Config:  params.AllEthashProtocolChanges,
+		Alloc:   alloc,
+		BaseFee: big.NewInt(params.InitialBaseFee),
+	}
+	_, blocks, _ := core.GenerateChainWithGenesis(gspec, ethash.NewFaker(), 1, nil)
+	db := rawdb.NewMemoryDatabase()
+	chain, err := core.NewBlockChain(db, nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	chain.Stop()
+
+	stats, err := snapshotBalances(db, blocks[0].Root(), 2)
+	if err != nil {
+		t.Fatalf("failed to iterate balances: %v", err)
+	}
+	// The coinbase of the block holds the block reward of 2 R5 on top of the
+	// allocated balances.
+	reward := ethash.BlockReward(1)
+	supply := new(big.Int).Set(reward)
+	for _, balance := range balances {
+		supply.Add(supply, balance)
+	}
+	if stats.supply.Cmp(supply) != 0 {
+		t.Errorf("supply mismatch: have %v, want %v", stats.supply, supply)
+	}
+	if have, want := stats.accounts, uint64(len(balances)+1); have != want {
+		t.Errorf("account count mismatch: have %d, want %d", have, want)
+	}
+	if stats.zero != 1 {
+		t.Errorf("zero balance count mismatch: have %d, want 1", stats.zero)
+	}
+	want := make([]uint64, 19)
+	want[0], want[1], want[2], want[18] = 2, 1, 1, 3
+	if len(stats.buckets) != len(want) {
+		t.Fatalf("bucket count mismatch: have %d, want %d", len(stats.buckets), len(want))
+	}
+	for i, count := range stats.buckets {
+		if count != want[i] {
+			t.Errorf("bucket 1e%d count mismatch: have %d, want %d", i, count, want[i])
+		}
+	}
+	// The two largest balances are listed in decreasing order.
+	if len(stats.top) != 2 {
+		t.Fatalf("top account count mismatch: have %d, want 2", len(stats.top))
+	}
+	for i, want := range []*big.Int{balances[6], balances[5]} {
+		if have := stats.top[i].balance; have.Cmp(want) != 0 {
+			t.Errorf("top account %d balance mismatch: have %v, want %v", i, have, want)
+		}
+	}
+}
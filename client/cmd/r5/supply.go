@@ -0,0 +1,128 @@
+// Copyright 2025 R5 Labs
+// This file is part of the R5 Core library.
+//
+// This software is provided "as is", without warranty of any kind,
+// express or implied, including but not limited to the warranties
+// of merchantability, fitness for a particular purpose and
+// noninfringement. In no event shall the authors or copyright
+// holders be liable for any claim, damages, or other liability,
+// whether in an action of contract, tort or otherwise, arising
+// from, out of or in connection with the software or the use or
+// other dealings in the software.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+
+	"github.com/r5-labs/r5-core/client/cmd/utils"
+	"github.com/r5-labs/r5-core/client/consensus/ethash"
+	"github.com/r5-labs/r5-core/client/core"
+	"github.com/r5-labs/r5-core/client/core/vm"
+	"github.com/r5-labs/r5-core/client/internal/flags"
+	cli "github.com/urfave/cli/v2"
+)
+
+var supplyAuditCommand = &cli.Command{
+	Name:   "supply-audit",
+	Usage:  "Compare the scheduled circulating supply against the actually minted supply",
+	Action: supplyAudit,
+	Flags: flags.Merge([]cli.Flag{
+		historyFromFlag,
+		historyToFlag,
+	}, utils.NetworkFlags, utils.DatabasePathFlags),
+	Description: `
+r5 supply-audit --from <number> --to <number>
+will replay the transactions of each canonical block in the given range and
+measure the amount credited to the coinbase on top of them, i.e. the minted
+block reward. The scheduled circulating supply of the emission schedule and the
+actual supply, accumulated from the minted rewards, are printed per block and
+every block minting a different amount than scheduled is reported. The state of
+the parent and the block itself must be available, so auditing old blocks needs
+an archive node. The range defaults to the whole chain up to the HEAD block.
+`,
+}
+
+func supplyAudit(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, db := utils.MakeChain(ctx, stack, true)
+	defer db.Close()
+	defer chain.Stop()
+
+	to := chain.CurrentBlock().Number.Uint64()
+	if ctx.IsSet(historyToFlag.Name) {
+		to = ctx.Uint64(historyToFlag.Name)
+	}
+	return writeSupplyAudit(os.Stdout, chain, ctx.Uint64(historyFromFlag.Name), to)
+}
+
+// writeSupplyAudit writes the scheduled and the actual circulating supply of the
+// canonical blocks in the [from, to] range to w. The actual supply starts from
+// the scheduled supply before the range and accumulates the rewards minted by
+// each block, measured as the coinbase credit left after replaying its
+// transactions, so that tips and transfers don't count. An error listing the
+// drifting blocks is returned if any block minted a different amount than
+// scheduled. The genesis block is skipped as it mints nothing.
+func writeSupplyAudit(w io.Writer, chain *core.BlockChain, from, to uint64) error {
+	if from > to {
+		return fmt.Errorf("invalid block range: from %d > to %d", from, to)
+	}
+	if from == 0 {
+		from = 1
+	}
+	fmt.Fprintf(w, "%-12s %-30s %-30s %s\n", "number", "scheduled", "actual", "drift")
+
+	var (
+		config  = chain.Config()
+		actual  = ethash.CalculateCirculatingSupply(from - 1)
+		drifted []uint64
+	)
+	for number := from; number <= to; number++ {
+		block := chain.GetBlockByNumber(number)
+		if block == nil {
+			return fmt.Errorf("block %d not found", number)
+		}
+		parent := chain.GetHeader(block.ParentHash(), number-1)
+		if parent == nil {
+			return fmt.Errorf("parent of block %d not found", number)
+		}
+		statedb, err := chain.StateAt(parent.Root)
+		if err != nil {
+			return fmt.Errorf("state of block %d not available: %v", number-1, err)
+		}
+		var (
+			header   = block.Header()
+			gp       = new(core.GasPool).AddGas(block.GasLimit())
+			usedGas  uint64
+			coinbase = header.Coinbase
+		)
+		for i, tx := range block.Transactions() {
+			statedb.SetTxContext(tx.Hash(), i)
+			if _, err := core.ApplyTransaction(config, chain, &coinbase, gp, statedb, header, tx, &usedGas, vm.Config{}); err != nil {
+				return fmt.Errorf("could not apply tx %d of block %d: %v", i, number, err)
+			}
+		}
+		poststate, err := chain.StateAt(header.Root)
+		if err != nil {
+			return fmt.Errorf("state of block %d not available: %v", number, err)
+		}
+		minted := new(big.Int).Sub(poststate.GetBalance(coinbase), statedb.GetBalance(coinbase))
+		actual.Add(actual, minted)
+
+		scheduled := ethash.CalculateCirculatingSupply(number)
+		expected := new(big.Int).Sub(scheduled, ethash.CalculateCirculatingSupply(number-1))
+		if minted.Cmp(expected) != 0 {
+			drifted = append(drifted, number)
+		}
+		fmt.Fprintf(w, "%-12d %-30v %-30v %v\n", number, scheduled, actual, new(big.Int).Sub(actual, scheduled))
+	}
+	if len(drifted) > 0 {
+		return fmt.Errorf("supply drift in %d blocks: %v", len(drifted), drifted)
+	}
+	return nil
+}
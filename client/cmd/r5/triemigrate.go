@@ -0,0 +1,238 @@
+// Copyright 2025 R5 Labs
+// This file is part of the R5 Core library.
+//
+// This software is provided "as is", without warranty of any kind,
+// express or implied, including but not limited to the warranties
+// of merchantability, fitness for a particular purpose and
+// noninfringement. In no event shall the authors or copyright
+// holders be liable for any claim, damages, or other liability,
+// whether in an action of contract, tort or otherwise, arising
+// from, out of or in connection with the software or the use or
+// other dealings in the software.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/r5-labs/r5-core/client/cmd/utils"
+	"github.com/r5-labs/r5-core/client/common"
+	"github.com/r5-labs/r5-core/client/core/rawdb"
+	"github.com/r5-labs/r5-core/client/core/types"
+	"github.com/r5-labs/r5-core/client/ethdb"
+	"github.com/r5-labs/r5-core/client/internal/flags"
+	"github.com/r5-labs/r5-core/client/log"
+	"github.com/r5-labs/r5-core/client/rlp"
+	"github.com/r5-labs/r5-core/client/trie"
+	cli "github.com/urfave/cli/v2"
+)
+
+var (
+	migrateFromFlag = &cli.StringFlag{
+		Name:  "from",
+		Usage: "State scheme of the source database (hash or path)",
+		Value: "hash",
+	}
+	migrateToFlag = &cli.StringFlag{
+		Name:  "to",
+		Usage: "State scheme of the target database (hash or path)",
+		Value: "path",
+	}
+	migrateTargetFlag = &cli.StringFlag{
+		Name:  "target",
+		Usage: "Directory of the fresh database to write the migrated state into",
+	}
+	trieMigrateCommand = &cli.Command{
+		Name:      "trie-migrate",
+		Usage:     "Convert the state trie between the hash-based and path-based schemes",
+		ArgsUsage: "<root>",
+		Action:    trieMigrate,
+		Flags: flags.Merge([]cli.Flag{
+			migrateFromFlag,
+			migrateToFlag,
+			migrateTargetFlag,
+		}, utils.NetworkFlags, utils.DatabasePathFlags),
+		Description: `
+r5 trie-migrate --from hash --to path --target <dir> [<state-root>]
+will re-encode all the trie nodes of the given state, along with the contract
+codes it references, from the source scheme into a fresh database at <dir>.
+The resulting state root is identical to the source one. Only the state is
+written, the target is not a complete chain database and can't be used to run
+a node from. The default migration target is the HEAD state.
+`,
+	}
+)
+
+// parseScheme converts the user facing scheme name into the database scheme.
+func parseScheme(name string) (string, error) {
+	switch name {
+	case "hash":
+		return rawdb.HashScheme, nil
+	case "path":
+		return rawdb.PathScheme, nil
+	default:
+		return "", fmt.Errorf("unknown state scheme %q, expected hash or path", name)
+	}
+}
+
+// schemeNodeReader returns a trie node reader on top of the given database,
+// resolving nodes according to the provided state scheme.
+func schemeNodeReader(db ethdb.Database, scheme string) trie.NodeReader {
+	if scheme == rawdb.PathScheme {
+		return trie.NewPathNodeReader(db)
+	}
+	return trie.NewDatabase(db)
+}
+
+func trieMigrate(ctx *cli.Context) error {
+	from, err := parseScheme(ctx.String(migrateFromFlag.Name))
+	if err != nil {
+		return err
+	}
+	to, err := parseScheme(ctx.String(migrateToFlag.Name))
+	if err != nil {
+		return err
+	}
+	if from == to {
+		return fmt.Errorf("source and target schemes are both %s", ctx.String(migrateFromFlag.Name))
+	}
+	target := ctx.String(migrateTargetFlag.Name)
+	if target == "" {
+		return errors.New("missing target database directory (--target)")
+	}
+	if common.FileExist(target) {
+		return fmt.Errorf("target database %s already exists", target)
+	}
+	if ctx.NArg() > 1 {
+		log.Error("Too many arguments given")
+		return errors.New("too many arguments")
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chaindb := utils.MakeChainDatabase(ctx, stack, true)
+	defer chaindb.Close()
+
+	var root common.Hash
+	if ctx.NArg() == 1 {
+		if root, err = parseRoot(ctx.Args().First()); err != nil {
+			log.Error("Failed to resolve state root", "err", err)
+			return err
+		}
+	} else {
+		headBlock := rawdb.ReadHeadBlock(chaindb)
+		if headBlock == nil {
+			log.Error("Failed to load head block")
+			return errors.New("no head block")
+		}
+		root = headBlock.Root()
+	}
+	targetdb, err := rawdb.Open(rawdb.OpenOptions{
+		Type:      ctx.String(utils.DBEngineFlag.Name),
+		Directory: target,
+		Namespace: "eth/db/migration/",
+		Cache:     ctx.Int(utils.CacheFlag.Name) * ctx.Int(utils.CacheDatabaseFlag.Name) / 100,
+		Handles:   utils.MakeDatabaseHandles(ctx.Int(utils.FDLimitFlag.Name)),
+	})
+	if err != nil {
+		return err
+	}
+	defer targetdb.Close()
+
+	log.Info("Start migrating the state", "root", root, "from", from, "to", to, "target", target)
+	start := time.Now()
+	nodes, codes, err := migrateTrieScheme(chaindb, targetdb, root, from, to)
+	if err != nil {
+		return err
+	}
+	log.Info("State migrated", "root", root, "nodes", nodes, "codes", codes, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// migrateTrieScheme copies the account trie of the given state root, all the
+// storage tries and the contract codes it references from src, stored in the
+// from scheme, into dst using the to scheme. The trie nodes are copied verbatim,
+// only their database keys change, so the state root stays identical.
+func migrateTrieScheme(src, dst ethdb.Database, root common.Hash, from, to string) (int, int, error) {
+	var (
+		reader     = schemeNodeReader(src, from)
+		batch      = dst.NewBatch()
+		nodes      int
+		codes      int
+		lastReport time.Time
+		start      = time.Now()
+	)
+	// copyTrie writes all the hashed nodes of a single trie into the batch,
+	// invoking onLeaf for each leaf encountered.
+	copyTrie := func(id *trie.ID, onLeaf func(key, blob []byte) error) error {
+		t, err := trie.New(id, reader)
+		if err != nil {
+			return err
+		}
+		it := t.NodeIterator(nil)
+		for it.Next(true) {
+			// Embedded nodes don't have their own hash and are stored as part
+			// of their parent.
+			if hash := it.Hash(); hash != (common.Hash{}) {
+				rawdb.WriteTrieNode(batch, id.Owner, it.Path(), hash, it.NodeBlob(), to)
+				nodes++
+			}
+			if it.Leaf() && onLeaf != nil {
+				if err := onLeaf(it.LeafKey(), it.LeafBlob()); err != nil {
+					return err
+				}
+			}
+			if batch.ValueSize() > ethdb.IdealBatchSize {
+				if err := batch.Write(); err != nil {
+					return err
+				}
+				batch.Reset()
+			}
+		}
+		return it.Error()
+	}
+	err := copyTrie(trie.StateTrieID(root), func(key, blob []byte) error {
+		var acc types.StateAccount
+		if err := rlp.DecodeBytes(blob, &acc); err != nil {
+			return fmt.Errorf("invalid account %x: %v", key, err)
+		}
+		if acc.Root != types.EmptyRootHash {
+			id := trie.StorageTrieID(root, common.BytesToHash(key), acc.Root)
+			if err := copyTrie(id, nil); err != nil {
+				return fmt.Errorf("failed to migrate storage trie of %x: %v", key, err)
+			}
+		}
+		if !bytes.Equal(acc.CodeHash, types.EmptyCodeHash.Bytes()) {
+			code := rawdb.ReadCode(src, common.BytesToHash(acc.CodeHash))
+			if len(code) == 0 {
+				return fmt.Errorf("missing code %x of %x", acc.CodeHash, key)
+			}
+			rawdb.WriteCode(batch, common.BytesToHash(acc.CodeHash), code)
+			codes++
+		}
+		if time.Since(lastReport) > time.Second*8 {
+			log.Info("Migrating state", "nodes", nodes, "codes", codes, "elapsed", common.PrettyDuration(time.Since(start)))
+			lastReport = time.Now()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := batch.Write(); err != nil {
+		return 0, 0, err
+	}
+	// Make sure the state is resolvable from the target scheme and the root
+	// is unchanged.
+	t, err := trie.New(trie.StateTrieID(root), schemeNodeReader(dst, to))
+	if err != nil {
+		return 0, 0, fmt.Errorf("migrated state is not resolvable: %v", err)
+	}
+	if have := t.Hash(); have != root {
+		return 0, 0, fmt.Errorf("migrated state root mismatch: have %x, want %x", have, root)
+	}
+	return nodes, codes, nil
+}
@@ -0,0 +1,143 @@
+// Copyright 2025 R5 Labs
+// This file is part of the R5 Core library.
+//
+// This software is provided "as is", without warranty of any kind,
+// express or implied, including but not limited to the warranties
+// of merchantability, fitness for a particular purpose and
+// noninfringement. In no event shall the authors or copyright
+// holders be liable for any claim, damages, or other liability,
+// whether in an action of contract, tort or otherwise, arising
+// from, out of or in connection with the software or the use or
+// other dealings in the software.
+
+package main
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/r5-labs/r5-core/client/common"
+	"github.com/r5-labs/r5-core/client/core/rawdb"
+	"github.com/r5-labs/r5-core/client/core/state"
+	"github.com/r5-labs/r5-core/client/core/types"
+	"github.com/r5-labs/r5-core/client/ethdb"
+	"github.com/r5-labs/r5-core/client/rlp"
+	"github.com/r5-labs/r5-core/client/trie"
+)
+
+// seedState creates a small hash-based state with plain accounts, contracts and
+// storage slots, returning its root.
+func seedState(t *testing.T) (ethdb.Database, common.Hash) {
+	db := rawdb.NewMemoryDatabase()
+	sdb := state.NewDatabase(db)
+	statedb, _ := state.New(types.EmptyRootHash, sdb, nil)
+	for i := byte(0); i < 32; i++ {
+		addr := common.BytesToAddress([]byte{i})
+		statedb.AddBalance(addr, big.NewInt(int64(i)+1))
+		statedb.SetNonce(addr, uint64(i))
+		if i%4 == 0 {
+			statedb.SetCode(addr, []byte{0x60, i, 0x00})
+			for j := byte(0); j < 16; j++ {
+				statedb.SetState(addr, common.BytesToHash([]byte{j}), common.BytesToHash([]byte{i, j + 1}))
+			}
+		}
+	}
+	root, err := statedb.Commit(false)
+	if err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	if err := sdb.TrieDB().Commit(root, false); err != nil {
+		t.Fatalf("failed to flush state: %v", err)
+	}
+	return db, root
+}
+
+// collectState iterates the whole state in the given scheme, returning all the
+// account and storage leaves keyed by their owner and key.
+func collectState(t *testing.T, reader trie.NodeReader, root common.Hash) map[string][]byte {
+	leaves := make(map[string][]byte)
+	accTrie, err := trie.New(trie.StateTrieID(root), reader)
+	if err != nil {
+		t.Fatalf("failed to open account trie: %v", err)
+	}
+	if have := accTrie.Hash(); have != root {
+		t.Fatalf("account trie root mismatch: have %x, want %x", have, root)
+	}
+	accIt := trie.NewIterator(accTrie.NodeIterator(nil))
+	for accIt.Next() {
+		leaves[string(accIt.Key)] = accIt.Value
+
+		var acc types.StateAccount
+		if err := rlp.DecodeBytes(accIt.Value, &acc); err != nil {
+			t.Fatalf("invalid account: %v", err)
+		}
+		if acc.Root == types.EmptyRootHash {
+			continue
+		}
+		owner := common.BytesToHash(accIt.Key)
+		storageTrie, err := trie.New(trie.StorageTrieID(root, owner, acc.Root), reader)
+		if err != nil {
+			t.Fatalf("failed to open storage trie %x: %v", owner, err)
+		}
+		storageIt := trie.NewIterator(storageTrie.NodeIterator(nil))
+		for storageIt.Next() {
+			leaves[string(accIt.Key)+string(storageIt.Key)] = storageIt.Value
+		}
+		if storageIt.Err != nil {
+			t.Fatalf("failed to iterate storage trie %x: %v", owner, storageIt.Err)
+		}
+	}
+	if accIt.Err != nil {
+		t.Fatalf("failed to iterate account trie: %v", accIt.Err)
+	}
+	return leaves
+}
+
+func TestTrieMigrate(t *testing.T) {
+	srcdb, root := seedState(t)
+	want := collectState(t, trie.NewDatabase(srcdb), root)
+
+	// Migrate the hash-based state into a fresh path-based database.
+	pathdb := rawdb.NewMemoryDatabase()
+	nodes, codes, err := migrateTrieScheme(srcdb, pathdb, root, rawdb.HashScheme, rawdb.PathScheme)
+	if err != nil {
+		t.Fatalf("failed to migrate to path scheme: %v", err)
+	}
+	if nodes == 0 || codes != 8 {
+		t.Fatalf("unexpected migration counters: nodes %d, codes %d", nodes, codes)
+	}
+	if rawdb.HasLegacyTrieNode(pathdb, root) {
+		t.Fatal("root node stored by hash in path-based database")
+	}
+	blob, hash := rawdb.ReadAccountTrieNode(pathdb, nil)
+	if hash != root || !bytes.Equal(blob, rawdb.ReadLegacyTrieNode(srcdb, root)) {
+		t.Fatalf("root node mismatch: have %x, want %x", hash, root)
+	}
+	have := collectState(t, trie.NewPathNodeReader(pathdb), root)
+	if len(have) != len(want) {
+		t.Fatalf("leaf count mismatch: have %d, want %d", len(have), len(want))
+	}
+	for key, val := range want {
+		if !bytes.Equal(have[key], val) {
+			t.Fatalf("leaf %x mismatch: have %x, want %x", key, have[key], val)
+		}
+	}
+	// Migrating back must produce the same state in a hash-based database.
+	hashdb := rawdb.NewMemoryDatabase()
+	if _, _, err := migrateTrieScheme(pathdb, hashdb, root, rawdb.PathScheme, rawdb.HashScheme); err != nil {
+		t.Fatalf("failed to migrate back to hash scheme: %v", err)
+	}
+	if have := collectState(t, trie.NewDatabase(hashdb), root); len(have) != len(want) {
+		t.Fatalf("leaf count mismatch after round trip: have %d, want %d", len(have), len(want))
+	}
+}
+
+func TestTrieMigrateMissingNode(t *testing.T) {
+	srcdb, root := seedState(t)
+	rawdb.DeleteLegacyTrieNode(srcdb, root)
+
+	if _, _, err := migrateTrieScheme(srcdb, rawdb.NewMemoryDatabase(), root, rawdb.HashScheme, rawdb.PathScheme); err == nil {
+		t.Fatal("migration succeeded with missing root node")
+	}
+}
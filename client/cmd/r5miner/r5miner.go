@@ -2,7 +2,8 @@
 // Standalone R5 miner that connects to a remote RPC endpoint,
 // obtains work via eth_getWork, mines using the internal Ethash-R5 algorithm (light version),
 // and submits solutions via eth_submitWork.
-// Flags: -p (RPC URL), -a (reward address), -w (worker name), -cpu (number of CPU cores)
+// Flags: -p (RPC URL), -nodes (comma-separated failover RPC URLs), -a (reward address),
+// -w (worker name), -cpu (number of CPU cores), -stats (status report interval)
 
 // #cgo windows LDFLAGS: -lmingw32 -lmingwex -lmsvcrt
 
@@ -13,15 +14,18 @@ import (
 	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"hash"
 	"log"
+	"math"
 	"math/big"
 	"os"
 	"reflect"
 	"runtime"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -1273,6 +1277,77 @@ type Work struct {
 	BlockNumber string
 }
 
+// two256 is a big integer representing 2^256.
+var two256 = new(big.Int).Exp(big.NewInt(2), big.NewInt(256), big.NewInt(0))
+
+// target parses the boundary condition of the work package.
+func (w Work) target() (*big.Int, error) {
+	target, ok := new(big.Int).SetString(strings.TrimPrefix(w.Target, "0x"), 16)
+	if !ok || target.Sign() <= 0 {
+		return nil, fmt.Errorf("failed to parse target")
+	}
+	return target, nil
+}
+
+// estimateBlockTime returns the expected time to find a block for the given
+// target at the given hashrate, 0 if the hashrate is unknown. Each hash being
+// below the target with probability target/2^256, a block takes 2^256/target
+// hashes on average.
+func estimateBlockTime(target *big.Int, hashrate float64) time.Duration {
+	if hashrate <= 0 || target.Sign() <= 0 {
+		return 0
+	}
+	hashes, _ := new(big.Float).Quo(new(big.Float).SetInt(two256), new(big.Float).SetInt(target)).Float64()
+	seconds := hashes / hashrate
+	if seconds >= float64(math.MaxInt64/int64(time.Second)) {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// miningStats tracks the hashes computed and the current work target for the
+// periodic status report.
+type miningStats struct {
+	hashes uint64 // Hashes computed since the last report, accessed atomically
+
+	lock   sync.Mutex
+	target *big.Int
+}
+
+var stats = new(miningStats)
+
+func (s *miningStats) setTarget(target *big.Int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.target = target
+}
+
+// report periodically logs the measured hashrate and the expected time to find
+// a block for the current work.
+func (s *miningStats) report(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := time.Now()
+	for now := range ticker.C {
+		hashrate := float64(atomic.SwapUint64(&s.hashes, 0)) / now.Sub(last).Seconds()
+		last = now
+
+		s.lock.Lock()
+		target := s.target
+		s.lock.Unlock()
+		if target == nil {
+			continue
+		}
+		eta := estimateBlockTime(target, hashrate)
+		if eta == 0 {
+			log.Printf("INFO: Hashrate: %.2f H/s, Difficulty: %v, ETA: unknown", hashrate, new(big.Int).Div(two256, target))
+			continue
+		}
+		log.Printf("INFO: Hashrate: %.2f H/s, Difficulty: %v, ETA: %v", hashrate, new(big.Int).Div(two256, target), eta.Round(time.Second))
+	}
+}
+
 func getWork(client *rpc.Client) (Work, error) {
 	var result []string
 	err := client.CallContext(context.Background(), &result, "eth_getWork")
@@ -1319,11 +1394,11 @@ func mineWork(work Work, cpuCores int, client *rpc.Client) (uint64, []byte, []by
     if err != nil {
         return 0, nil, nil, fmt.Errorf("failed to decode seed hash from seedHash: %v", err)
     }
-    target := new(big.Int)
-    _, ok := target.SetString(work.Target[2:], 16)
-    if !ok {
-        return 0, nil, nil, fmt.Errorf("failed to parse target")
+    target, err := work.target()
+    if err != nil {
+        return 0, nil, nil, err
     }
+    stats.setTarget(target)
     blockNumber, err := strconv.ParseUint(work.BlockNumber[2:], 16, 64)
     if err != nil {
         return 0, nil, nil, fmt.Errorf("failed to parse block number from work: %v", err)
@@ -1384,6 +1459,7 @@ func mineWork(work Work, cpuCores int, client *rpc.Client) (uint64, []byte, []by
                     lastCheck = time.Now()
                 }
                 mixDigest, finalHash := hashimotoFull(dataset, headerBytes, nonce)
+                atomic.AddUint64(&stats.hashes, 1)
                 finalInt := new(big.Int).SetBytes(finalHash)
                 if finalInt.Cmp(target) < 0 {
                     if atomic.CompareAndSwapInt32(&found, 0, 1) {
@@ -1420,24 +1496,161 @@ func mineWork(work Work, cpuCores int, client *rpc.Client) (uint64, []byte, []by
     }
 }
 
-func StartMiner(rpcURL string, reward common.Address, cpuCores int, workerName string) error {
-	client, err := rpc.Dial(rpcURL)
+// maxSubmissions is the number of recent solutions remembered to avoid
+// resubmitting the same one.
+const maxSubmissions = 64
+
+// errDuplicateSolution is returned when a solution was already submitted.
+var errDuplicateSolution = errors.New("solution already submitted")
+
+// submission identifies a solution by the header it seals and its nonce.
+type submission struct {
+	header string
+	nonce  uint64
+}
+
+// submissionCache tracks the most recently submitted solutions, so a header
+// refetched after a transient error is not solved and submitted twice.
+type submissionCache struct {
+	seen  map[submission]struct{}
+	order []submission
+}
+
+func newSubmissionCache() *submissionCache {
+	return &submissionCache{seen: make(map[submission]struct{})}
+}
+
+// add records a solution, reporting false if it was already present. The
+// oldest entry is evicted once the cache is full.
+func (c *submissionCache) add(header string, nonce uint64) bool {
+	key := submission{header: header, nonce: nonce}
+	if _, ok := c.seen[key]; ok {
+		return false
+	}
+	if len(c.order) >= maxSubmissions {
+		delete(c.seen, c.order[0])
+		c.order = c.order[1:]
+	}
+	c.seen[key] = struct{}{}
+	c.order = append(c.order, key)
+	return true
+}
+
+// submitSolution submits a solution for the given work unless the same one was
+// already submitted before.
+func submitSolution(client *rpc.Client, cache *submissionCache, work Work, nonce uint64, mixDigest []byte, sealHash []byte) (bool, error) {
+	if !cache.add(work.PowHash, nonce) {
+		return false, errDuplicateSolution
+	}
+	return submitWork(client, nonce, mixDigest, "0x"+hex.EncodeToString(sealHash))
+}
+
+// nodePool is an ordered set of RPC endpoints the miner fails over between.
+// Work is always submitted to the node that served it.
+type nodePool struct {
+	urls    []string
+	clients []*rpc.Client
+	current int // Index of the node currently used for retrieving work
+}
+
+// parseNodes merges the primary RPC URL with the comma-separated failover list,
+// dropping empty and duplicate entries.
+func parseNodes(primary string, nodes string) []string {
+	var (
+		urls []string
+		seen = make(map[string]bool)
+	)
+	for _, url := range append([]string{primary}, strings.Split(nodes, ",")...) {
+		url = strings.TrimSpace(url)
+		if url == "" || seen[url] {
+			continue
+		}
+		seen[url] = true
+		urls = append(urls, url)
+	}
+	return urls
+}
+
+// newNodePool connects to the given RPC endpoints, skipping the ones that can't
+// be reached. It only fails if none of them can.
+func newNodePool(urls []string) (*nodePool, error) {
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("no RPC nodes specified")
+	}
+	pool := new(nodePool)
+	for _, url := range urls {
+		client, err := rpc.Dial(url)
+		if err != nil {
+			log.Printf("WARN: Skipping RPC at %s: %v", url, err)
+			continue
+		}
+		pool.urls = append(pool.urls, url)
+		pool.clients = append(pool.clients, client)
+	}
+	if len(pool.clients) == 0 {
+		return nil, fmt.Errorf("failed to connect to any of %d RPC nodes", len(urls))
+	}
+	return pool, nil
+}
+
+// getWork retrieves work from the current node, moving on to the next ones in
+// order if it fails. The index of the node that served the work is returned.
+func (p *nodePool) getWork() (Work, int, error) {
+	for i := 0; i < len(p.clients); i++ {
+		idx := (p.current + i) % len(p.clients)
+		work, err := getWork(p.clients[idx])
+		if err != nil {
+			log.Printf("WARN: Error retrieving work from %s: %v", p.urls[idx], err)
+			continue
+		}
+		if idx != p.current {
+			log.Printf("INFO: Switched to RPC at %s", p.urls[idx])
+			p.current = idx
+		}
+		return work, idx, nil
+	}
+	return Work{}, -1, fmt.Errorf("all %d RPC nodes failed to serve work", len(p.clients))
+}
+
+// failover moves work retrieval off the given node if it is the current one.
+func (p *nodePool) failover(idx int) {
+	if idx == p.current && len(p.clients) > 1 {
+		p.current = (idx + 1) % len(p.clients)
+		log.Printf("INFO: Failing over from %s to %s", p.urls[idx], p.urls[p.current])
+	}
+}
+
+func (p *nodePool) Close() {
+	for _, client := range p.clients {
+		client.Close()
+	}
+}
+
+func StartMiner(urls []string, reward common.Address, cpuCores int, workerName string, statsInterval time.Duration) error {
+	pool, err := newNodePool(urls)
 	if err != nil {
 		return fmt.Errorf("WARN: Failed to connect to RPC: %v", err)
 	}
-	defer client.Close()
+	defer pool.Close()
 
-	log.Printf("INFO: Connected to RPC at %s", rpcURL)
+	submitted := newSubmissionCache()
+	if statsInterval > 0 {
+		go stats.report(statsInterval)
+	}
+
+	log.Printf("INFO: Connected to RPC at %s", strings.Join(pool.urls, ", "))
 	log.Printf("----------------------------------------------------------------------------------")
 
 	for {
-		work, err := getWork(client)
+		work, idx, err := pool.getWork()
 		if err != nil {
 			log.Printf("WARN: Error retrieving work: %v", err)
 			time.Sleep(5 * time.Second)
 			continue
 		}
-		log.Printf("INFO: ✓ Received Work:")
+		client := pool.clients[idx]
+
+		log.Printf("INFO: ✓ Received Work from %s:", pool.urls[idx])
 		log.Printf(":       PoW Hash: %s", work.PowHash)
 		log.Printf(":       Target: %s", work.Target)
 
@@ -1463,9 +1676,14 @@ func StartMiner(rpcURL string, reward common.Address, cpuCores int, workerName s
 		log.Printf(":     Nonce: %d", nonce)
 		log.Printf(":     Final Hash=%x", finalHash)
 
-		ok, err := submitWork(client, nonce, mixDigest, "0x"+hex.EncodeToString(finalHash))
-		if err != nil {
-			log.Printf("WARN: ✕ Error submitting work: %v", err)
+		// The solution is only valid for the node that served the work, so
+		// submit it there and fail over for the next round if that fails.
+		ok, err := submitSolution(client, submitted, work, nonce, mixDigest, finalHash)
+		if errors.Is(err, errDuplicateSolution) {
+			log.Printf("WARN: Skipping duplicate solution for %s", work.PowHash)
+		} else if err != nil {
+			log.Printf("WARN: ✕ Error submitting work to %s: %v", pool.urls[idx], err)
+			pool.failover(idx)
 		} else if ok {
 			log.Printf("INFO: ✓ Work submitted successfully")
 		} else {
@@ -1477,25 +1695,28 @@ func StartMiner(rpcURL string, reward common.Address, cpuCores int, workerName s
 
 func main() {
 	rpcURL := flag.String("p", "", "Node RPC address (e.g. http://127.0.0.1:8545)")
+	nodes := flag.String("nodes", "", "Comma-separated failover node RPC addresses, tried in order after -p")
 	rewardAddr := flag.String("a", "", "Reward address (your wallet address)")
 	workerName := flag.String("w", "Worker", "Worker name identifier")
 	cpuCores := flag.Int("cpu", runtime.NumCPU(), "Number of CPU cores to use for mining")
+	statsInterval := flag.Duration("stats", 30*time.Second, "Interval of the hashrate and block ETA report (0 = disabled)")
 	flag.Parse()
 
-	if *rpcURL == "" || *rewardAddr == "" {
-		fmt.Println("Usage: r5miner -p <rpc_url> -a <reward_address> [-w <worker_name>] [-cpu <cores>]")
+	urls := parseNodes(*rpcURL, *nodes)
+	if len(urls) == 0 || *rewardAddr == "" {
+		fmt.Println("Usage: r5miner -p <rpc_url> [-nodes <rpc_url>,...] -a <reward_address> [-w <worker_name>] [-cpu <cores>] [-stats <interval>]")
 		os.Exit(1)
 	}
 
 	addr := common.HexToAddress(*rewardAddr)
 	log.Printf("----------------------------------------------------------------------------------")
 	log.Printf("Worker    : %s", *workerName)
-	log.Printf("RPC URL   : %s", *rpcURL)
+	log.Printf("RPC URL   : %s", strings.Join(urls, ", "))
 	log.Printf("Wallet    : %s", addr.Hex())
 	log.Printf("CPU Cores : %d", *cpuCores)
 	log.Printf("----------------------------------------------------------------------------------")
 
-	if err := StartMiner(*rpcURL, addr, *cpuCores, *workerName); err != nil {
+	if err := StartMiner(urls, addr, *cpuCores, *workerName, *statsInterval); err != nil {
 		log.Fatalf("Mining failed: %v", err)
 	}
 }
@@ -0,0 +1,215 @@
+// Copyright 2025 R5 Labs
+// This file is part of the R5 Core library.
+//
+// This software is provided "as is", without warranty of any kind,
+// express or implied, including but not limited to the warranties
+// of merchantability, fitness for a particular purpose and
+// noninfringement. In no event shall the authors or copyright
+// holders be liable for any claim, damages, or other liability,
+// whether in an action of contract, tort or otherwise, arising
+// from, out of or in connection with the software or the use or
+// other dealings in the software.
+
+package main
+
+import (
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/r5-labs/r5-core/client/rpc"
+)
+
+// mockNode is a minimal eth namespace serving mining work.
+type mockNode struct {
+	fail      atomic.Bool
+	work      []string
+	submitted atomic.Int32
+}
+
+func (n *mockNode) GetWork() ([]string, error) {
+	if n.fail.Load() {
+		return nil, errors.New("no mining work available yet")
+	}
+	return n.work, nil
+}
+
+func (n *mockNode) SubmitWork(nonce string, mixDigest string, powHash string) (bool, error) {
+	if n.fail.Load() {
+		return false, errors.New("node unavailable")
+	}
+	n.submitted.Add(1)
+	return true, nil
+}
+
+func startMockNode(t *testing.T, node *mockNode) string {
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", node); err != nil {
+		t.Fatalf("failed to register mock node: %v", err)
+	}
+	http := httptest.NewServer(server)
+	t.Cleanup(func() {
+		http.Close()
+		server.Stop()
+	})
+	return http.URL
+}
+
+func TestParseNodes(t *testing.T) {
+	tests := []struct {
+		primary, nodes string
+		want           []string
+	}{
+		{"http://a", "", []string{"http://a"}},
+		{"", "http://a,http://b", []string{"http://a", "http://b"}},
+		{"http://a", " http://b , ,http://a", []string{"http://a", "http://b"}},
+		{"", "", nil},
+	}
+	for i, tt := range tests {
+		if have := parseNodes(tt.primary, tt.nodes); !reflect.DeepEqual(have, tt.want) {
+			t.Errorf("test %d: node list mismatch: have %v, want %v", i, have, tt.want)
+		}
+	}
+}
+
+func TestNodePoolFailover(t *testing.T) {
+	var (
+		primary = &mockNode{work: []string{"0x01", "0x02", "0x03", "0x04"}}
+		backup  = &mockNode{work: []string{"0x05", "0x06", "0x07", "0x08"}}
+	)
+	primary.fail.Store(true)
+
+	pool, err := newNodePool([]string{startMockNode(t, primary), startMockNode(t, backup)})
+	if err != nil {
+		t.Fatalf("failed to create node pool: %v", err)
+	}
+	defer pool.Close()
+
+	// The failing primary must be skipped in favour of the backup.
+	work, idx, err := pool.getWork()
+	if err != nil {
+		t.Fatalf("failed to retrieve work: %v", err)
+	}
+	if idx != 1 || work.PowHash != "0x05" {
+		t.Fatalf("work served by wrong node: index %d, pow hash %s", idx, work.PowHash)
+	}
+	// Solutions go to the node that served the work, which stays current.
+	if _, err := submitWork(pool.clients[idx], 1, []byte{0x01}, work.PowHash); err != nil {
+		t.Fatalf("failed to submit work: %v", err)
+	}
+	if primary.submitted.Load() != 0 || backup.submitted.Load() != 1 {
+		t.Fatalf("submission mismatch: primary %d, backup %d", primary.submitted.Load(), backup.submitted.Load())
+	}
+	primary.fail.Store(false)
+	if _, idx, _ := pool.getWork(); idx != 1 {
+		t.Fatalf("work retrieval moved off healthy node: index %d", idx)
+	}
+	// A failed submission moves retrieval on to the next node.
+	pool.failover(1)
+	if _, idx, _ := pool.getWork(); idx != 0 {
+		t.Fatalf("work retrieval did not fail over: index %d", idx)
+	}
+	// With every node failing, retrieval must error out.
+	primary.fail.Store(true)
+	backup.fail.Store(true)
+	if _, _, err := pool.getWork(); err == nil {
+		t.Fatal("retrieved work with all nodes failing")
+	}
+}
+
+func TestNodePoolSkipsUnreachable(t *testing.T) {
+	node := &mockNode{work: []string{"0x01", "0x02", "0x03", "0x04"}}
+	url := startMockNode(t, node)
+
+	// Nodes that can't be dialed are dropped, keeping the reachable ones.
+	pool, err := newNodePool([]string{"unknown://node", url})
+	if err != nil {
+		t.Fatalf("failed to create node pool: %v", err)
+	}
+	defer pool.Close()
+
+	if !reflect.DeepEqual(pool.urls, []string{url}) || len(pool.clients) != 1 {
+		t.Fatalf("node pool mismatch: urls %v, %d clients", pool.urls, len(pool.clients))
+	}
+	if work, idx, err := pool.getWork(); err != nil || idx != 0 || work.PowHash != "0x01" {
+		t.Fatalf("work retrieval mismatch: index %d, work %v, error %v", idx, work, err)
+	}
+	// The pool can't be created if no node is reachable.
+	if _, err := newNodePool([]string{"unknown://a", "unknown://b"}); err == nil {
+		t.Fatal("created node pool without reachable nodes")
+	}
+}
+
+func TestSubmitSolutionDeduplication(t *testing.T) {
+	node := &mockNode{work: []string{"0x01", "0x02", "0x03", "0x04"}}
+	client, err := rpc.Dial(startMockNode(t, node))
+	if err != nil {
+		t.Fatalf("failed to connect to mock node: %v", err)
+	}
+	defer client.Close()
+
+	var (
+		cache = newSubmissionCache()
+		work  = Work{PowHash: "0x01"}
+	)
+	if ok, err := submitSolution(client, cache, work, 1, []byte{0x01}, []byte{0x02}); !ok || err != nil {
+		t.Fatalf("failed to submit solution: %v, %v", ok, err)
+	}
+	// The same solution for the same header must not reach the node again.
+	if _, err := submitSolution(client, cache, work, 1, []byte{0x01}, []byte{0x02}); !errors.Is(err, errDuplicateSolution) {
+		t.Fatalf("error mismatch: have %v, want %v", err, errDuplicateSolution)
+	}
+	if submitted := node.submitted.Load(); submitted != 1 {
+		t.Fatalf("submission count mismatch: have %d, want 1", submitted)
+	}
+	// Other nonces and headers are still submitted.
+	if _, err := submitSolution(client, cache, work, 2, []byte{0x01}, []byte{0x02}); err != nil {
+		t.Fatalf("failed to submit solution with new nonce: %v", err)
+	}
+	if _, err := submitSolution(client, cache, Work{PowHash: "0x02"}, 1, []byte{0x01}, []byte{0x02}); err != nil {
+		t.Fatalf("failed to submit solution for new header: %v", err)
+	}
+	if submitted := node.submitted.Load(); submitted != 3 {
+		t.Fatalf("submission count mismatch: have %d, want 3", submitted)
+	}
+	// The oldest solutions are forgotten once the cache is full.
+	for i := 0; i < maxSubmissions; i++ {
+		cache.add("0x03", uint64(i))
+	}
+	if !cache.add("0x01", 1) {
+		t.Fatal("evicted solution still cached")
+	}
+}
+
+func TestEstimateBlockTime(t *testing.T) {
+	tests := []struct {
+		difficulty int64
+		hashrate   float64
+		want       time.Duration
+	}{
+		{1000000, 100000, 10 * time.Second},
+		{1000000, 1000, 1000 * time.Second},
+		{3000000, 500, 100 * time.Minute},
+		{1000000, 0, 0},
+	}
+	for i, tt := range tests {
+		target := new(big.Int).Div(two256, big.NewInt(tt.difficulty))
+		work := Work{Target: "0x" + target.Text(16)}
+
+		parsed, err := work.target()
+		if err != nil {
+			t.Fatalf("test %d: failed to parse target: %v", i, err)
+		}
+		have := estimateBlockTime(parsed, tt.hashrate)
+		if diff := have - tt.want; diff < -time.Millisecond || diff > time.Millisecond {
+			t.Errorf("test %d: block time mismatch: have %v, want %v", i, have, tt.want)
+		}
+	}
+	if _, err := (Work{Target: "0x0"}).target(); err == nil {
+		t.Error("parsed zero target")
+	}
+}
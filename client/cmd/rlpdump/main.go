@@ -25,6 +25,8 @@ import (
 	"strings"
 
 	"github.com/r5-labs/r5-core/client/common"
+	"github.com/r5-labs/r5-core/client/core/types"
+	"github.com/r5-labs/r5-core/client/crypto"
 	"github.com/r5-labs/r5-core/client/rlp"
 )
 
@@ -33,11 +35,21 @@ var (
 	reverseMode = flag.Bool("reverse", false, "convert ASCII to rlp")
 	noASCII     = flag.Bool("noascii", false, "don't print ASCII strings readably")
 	single      = flag.Bool("single", false, "print only the first element, discard the rest")
+	strictMode  = flag.Bool("strict", false, "check that the input is canonically encoded")
+	hashMode    = flag.Bool("hash", false, "print the keccak256 hash of each top-level element")
+	typeMode    = flag.String("type", "", "decode the input as the given type (supported: tx)")
 )
 
+// typedTxNames are the readable names of the typed transactions known to R5.
+// Legacy transactions are plain RLP lists and carry no type byte.
+var typedTxNames = map[byte]string{
+	types.AccessListTxType: "access list",
+	types.DynamicFeeTxType: "dynamic fee",
+}
+
 func init() {
 	flag.Usage = func() {
-		fmt.Fprintln(os.Stderr, "Usage:", os.Args[0], "[-noascii] [-hex <data>][-reverse] [filename]")
+		fmt.Fprintln(os.Stderr, "Usage:", os.Args[0], "[-noascii] [-hex <data>][-reverse] [-strict] [-hash] [-type tx] [filename]")
 		flag.PrintDefaults()
 		fmt.Fprintln(os.Stderr, `
 Dumps RLP data from the given file in readable form.
@@ -74,6 +86,37 @@ func main() {
 		os.Exit(2)
 	}
 	out := os.Stdout
+	if *typeMode != "" {
+		if *typeMode != "tx" {
+			die(fmt.Sprintf("unsupported type %q", *typeMode))
+		}
+		data, err := io.ReadAll(r)
+		if err != nil {
+			die(err)
+		}
+		if err := txToText(data, out); err != nil {
+			die(err)
+		}
+		return
+	}
+	if *strictMode {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			die(err)
+		}
+		issues, err := checkCanonical(data)
+		for _, issue := range issues {
+			fmt.Fprintln(out, issue)
+		}
+		if err != nil {
+			die(err)
+		}
+		if len(issues) > 0 {
+			die(fmt.Sprintf("input is not canonically encoded (%d non-canonical items)", len(issues)))
+		}
+		fmt.Fprintln(out, "input is canonically encoded")
+		return
+	}
 	if *reverseMode {
 		data, err := textToRlp(r)
 		if err != nil {
@@ -92,7 +135,13 @@ func main() {
 func rlpToText(r io.Reader, out io.Writer) error {
 	s := rlp.NewStream(r, 0)
 	for {
-		if err := dump(s, 0, out); err != nil {
+		var err error
+		if *hashMode {
+			err = dumpWithHash(s, out)
+		} else {
+			err = dump(s, 0, out)
+		}
+		if err != nil {
 			if err != io.EOF {
 				return err
 			}
@@ -106,6 +155,77 @@ func rlpToText(r io.Reader, out io.Writer) error {
 	return nil
 }
 
+// txToText dumps the transactions in data, which is either a single typed
+// transaction in its binary form, or a sequence of RLP encoded transactions.
+func txToText(data []byte, out io.Writer) error {
+	if len(data) > 0 && data[0] < 0x80 {
+		return dumpTx(data, out)
+	}
+	s := rlp.NewStream(bytes.NewReader(data), 0)
+	for {
+		raw, err := s.Raw()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		// Typed transactions are wrapped in an RLP string, legacy ones aren't
+		kind, content, _, err := rlp.Split(raw)
+		if err != nil {
+			return err
+		}
+		if kind == rlp.List {
+			content = raw
+		}
+		if err := dumpTx(content, out); err != nil {
+			return err
+		}
+		if *single {
+			return nil
+		}
+	}
+}
+
+// dumpTx identifies the type of the binary encoded transaction, and dumps its
+// payload after checking that it decodes into a valid transaction.
+func dumpTx(enc []byte, out io.Writer) error {
+	var (
+		typ     = byte(types.LegacyTxType)
+		name    = "legacy"
+		payload = enc
+	)
+	if len(enc) > 0 && enc[0] < 0x80 {
+		typ, payload = enc[0], enc[1:]
+		if name = typedTxNames[typ]; name == "" {
+			return fmt.Errorf("unknown transaction type %#x", typ)
+		}
+	}
+	var tx types.Transaction
+	if err := tx.UnmarshalBinary(enc); err != nil {
+		return fmt.Errorf("invalid %s transaction: %v", name, err)
+	}
+	fmt.Fprintf(out, "%s transaction (type %#x), hash %#x\n", name, typ, tx.Hash())
+	if err := dump(rlp.NewStream(bytes.NewReader(payload), 0), 0, out); err != nil {
+		return err
+	}
+	fmt.Fprintln(out)
+	return nil
+}
+
+// dumpWithHash dumps the next top-level element, followed by the keccak256 hash
+// of its raw encoding.
+func dumpWithHash(s *rlp.Stream, out io.Writer) error {
+	raw, err := s.Raw()
+	if err != nil {
+		return err
+	}
+	if err := dump(rlp.NewStream(bytes.NewReader(raw), 0), 0, out); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, " keccak256=%#x", crypto.Keccak256(raw))
+	return nil
+}
+
 func dump(s *rlp.Stream, depth int, out io.Writer) error {
 	kind, size, err := s.Kind()
 	if err != nil {
@@ -145,6 +265,93 @@ func dump(s *rlp.Stream, depth int, out io.Writer) error {
 	return nil
 }
 
+// canonIssue describes an RLP item which is not canonically encoded.
+type canonIssue struct {
+	offset int    // Offset of the item's prefix byte in the input
+	reason string // Description of the offending encoding
+}
+
+func (issue canonIssue) String() string {
+	return fmt.Sprintf("offset %d: %s", issue.offset, issue.reason)
+}
+
+// checkCanonical walks all RLP items in data and reports those which are
+// valid, but not in the canonical form mandated by the RLP specification.
+// An error is returned if the input cannot be parsed as RLP at all.
+func checkCanonical(data []byte) ([]canonIssue, error) {
+	var issues []canonIssue
+	if err := checkCanonicalItems(data, 0, &issues); err != nil {
+		return issues, err
+	}
+	return issues, nil
+}
+
+// checkCanonicalItems checks the sequence of RLP items in data, which starts at
+// the given offset of the original input.
+func checkCanonicalItems(data []byte, base int, issues *[]canonIssue) error {
+	for pos := 0; pos < len(data); {
+		var (
+			offset = base + pos
+			prefix = data[pos]
+			head   int    // Number of prefix and size bytes
+			size   uint64 // Number of content bytes
+			list   bool
+		)
+		switch {
+		case prefix < 0x80:
+			pos++
+			continue
+
+		case prefix < 0xB8, prefix >= 0xC0 && prefix < 0xF8:
+			if prefix >= 0xC0 {
+				size, list = uint64(prefix-0xC0), true
+			} else {
+				size = uint64(prefix - 0x80)
+			}
+			head = 1
+
+		default:
+			var lenOfSize int
+			if prefix >= 0xF8 {
+				lenOfSize, list = int(prefix-0xF7), true
+			} else {
+				lenOfSize = int(prefix - 0xB7)
+			}
+			if pos+1+lenOfSize > len(data) {
+				return fmt.Errorf("offset %d: size truncated", offset)
+			}
+			sizeBytes := data[pos+1 : pos+1+lenOfSize]
+			if sizeBytes[0] == 0 {
+				*issues = append(*issues, canonIssue{offset, "size has leading zero bytes"})
+			}
+			for _, b := range sizeBytes {
+				if size > (1<<56)-1 {
+					return fmt.Errorf("offset %d: size overflows", offset)
+				}
+				size = size<<8 | uint64(b)
+			}
+			if size < 56 {
+				*issues = append(*issues, canonIssue{offset, fmt.Sprintf("size %d uses the long form, should be encoded in the prefix", size)})
+			}
+			head = 1 + lenOfSize
+		}
+		if uint64(len(data)-pos-head) < size {
+			return fmt.Errorf("offset %d: value size %d exceeds available input", offset, size)
+		}
+		content := data[pos+head : pos+head+int(size)]
+		switch {
+		case list:
+			if err := checkCanonicalItems(content, offset+head, issues); err != nil {
+				return err
+			}
+		case size == 1 && content[0] < 0x80:
+			*issues = append(*issues, canonIssue{offset, fmt.Sprintf("single byte %#x below 0x80 should be encoded as itself", content[0])})
+		}
+		pos += head + int(size)
+	}
+	return nil
+}
+
 func isASCII(b []byte) bool {
 	for _, c := range b {
 		if c < 32 || c > 126 {
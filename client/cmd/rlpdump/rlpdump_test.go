@@ -20,6 +20,8 @@ import (
 
 	"github.com/r5-labs/r5-core/client/common"
 	"github.com/r5-labs/r5-core/client/common/hexutil"
+	"github.com/r5-labs/r5-core/client/crypto"
+	"github.com/r5-labs/r5-core/client/rlp"
 )
 
 func TestRoundtrip(t *testing.T) {
@@ -75,3 +77,131 @@ func TestTextToRlp(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckCanonical(t *testing.T) {
+	long := strings.Repeat("aa", 56)
+	for i, tc := range []struct {
+		input   string
+		offsets []int
+		err     bool
+	}{
+		// Canonical encodings
+		{input: "0x00"},
+		{input: "0x80"},
+		{input: "0x8180"},
+		{input: "0xc780c0c1c0825208"},
+		{input: "0xd5c0d3cb84746573742a2a808213378667617a6f6e6b"},
+		{input: "0xb838" + long},
+		{input: "0x0102c0"}, // multiple top-level elements
+
+		// Non-canonical encodings
+		{input: "0x8105", offsets: []int{0}},               // single byte wrapped in a string
+		{input: "0xb803616263", offsets: []int{0}},         // short string in long form
+		{input: "0xb90038" + long, offsets: []int{0}},      // size with leading zero
+		{input: "0xf80180", offsets: []int{0}},             // short list in long form
+		{input: "0xc3c28105", offsets: []int{2}},           // nested single byte
+		{input: "0xc0b8026162", offsets: []int{1}},         // second top-level element
+		{input: "0xc5b801618105", offsets: []int{1, 1, 4}}, // multiple issues in a list
+		{input: "0xb9000105", offsets: []int{0, 0, 0}},     // leading zero, long form, single byte
+		{input: "0xf900028105", offsets: []int{0, 0, 3}},   // list versions of the same
+
+		// Malformed input
+		{input: "0x836162", err: true},
+		{input: "0xc28180c38405", err: true},
+		{input: "0xb9", err: true},
+	} {
+		issues, err := checkCanonical(common.FromHex(tc.input))
+		if tc.err {
+			if err == nil {
+				t.Errorf("test %d: expected error for %s", i, tc.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("test %d: unexpected error for %s: %v", i, tc.input, err)
+			continue
+		}
+		var offsets []int
+		for _, issue := range issues {
+			offsets = append(offsets, issue.offset)
+		}
+		if fmt.Sprint(offsets) != fmt.Sprint(tc.offsets) {
+			t.Errorf("test %d: non-canonical offsets mismatch for %s: have %v, want %v (%v)", i, tc.input, offsets, tc.offsets, issues)
+		}
+	}
+}
+
+func TestDumpHash(t *testing.T) {
+	*hashMode = true
+	defer func() { *hashMode = false }()
+
+	elems := []string{"0xc780c0c1c0825208", "0x820102", "0x05", "0xd5c0d3cb84746573742a2a808213378667617a6f6e6b"}
+
+	var input []byte
+	for _, elem := range elems {
+		input = append(input, common.FromHex(elem)...)
+	}
+	var out strings.Builder
+	if err := rlpToText(bytes.NewReader(input), &out); err != nil {
+		t.Fatal(err)
+	}
+	var hashes []string
+	for _, line := range strings.Split(out.String(), "\n") {
+		if i := strings.Index(line, "keccak256="); i >= 0 {
+			hashes = append(hashes, line[i+len("keccak256="):])
+		}
+	}
+	if len(hashes) != len(elems) {
+		t.Fatalf("hash count mismatch: have %d, want %d\n%s", len(hashes), len(elems), out.String())
+	}
+	for i, elem := range elems {
+		if want := hexutil.Encode(crypto.Keccak256(common.FromHex(elem))); hashes[i] != want {
+			t.Errorf("element %d: hash mismatch: have %s, want %s", i, hashes[i], want)
+		}
+	}
+}
+
+func TestDumpTx(t *testing.T) {
+	var (
+		legacy     = "0xf85f8001825208941111111111111111111111111111111111111111018026a0bcc931e4be113024cef98417c2bcd82a6905301f80c6d8acc4fe556905c8c6efa078ea7f3673c90c8c9f61e4f793cf899bab3065fa31dfb6b1e0b55390b354a8f2"
+		accessList = "0x01f8610101018252089411111111111111111111111111111111111111110180c080a00cac26b4238869425701ce9d13949aede97ce2ff1edf0abb686644026e749786a064b6a353d09f9d6fee252deefdcb9d91aa9176f9d097b47a077e184239b3f6eb"
+		dynamicFee = "0x02f862010201028252089411111111111111111111111111111111111111110180c080a027faf3bf9d774654cfd93ab30043a0d7a73961a34b2fc8b01aafa4ca02523f1ea03c84c5e220164f1e6856ce9670eda4bee7c5495c250756b90652a96e52a7c326"
+	)
+	for i, tc := range []struct {
+		input string
+		want  string
+		err   string
+	}{
+		{input: legacy, want: "legacy transaction (type 0x0), hash 0x01669027a58b6cc6cc191baff5d8b26dcfa1368b65404470cf4b378b3413e7a5"},
+		{input: accessList, want: "access list transaction (type 0x1), hash 0x6d23f64cb47f9c6dd9351796963dc85bf60f79f5569e8d047df2510bdc9f5a43"},
+		{input: dynamicFee, want: "dynamic fee transaction (type 0x2), hash 0x9e1b8447b269acecce2f73af9428c23e6f2de1c8fe83823716098c3325451540"},
+		{input: "0x00" + legacy[2:], err: "unknown transaction type 0x0"},
+		{input: "0x03" + dynamicFee[4:], err: "unknown transaction type 0x3"},
+		{input: "0x7f" + dynamicFee[4:], err: "unknown transaction type 0x7f"},
+		{input: "0x02c0", err: "invalid dynamic fee transaction: rlp: too few elements for types.DynamicFeeTx"},
+	} {
+		// Typed transactions are accepted both in binary form and wrapped into
+		// an RLP string, as they appear within blocks.
+		inputs := [][]byte{common.FromHex(tc.input)}
+		if enc := inputs[0]; enc[0] < 0x80 {
+			wrapped, _ := rlp.EncodeToBytes(enc)
+			inputs = append(inputs, wrapped)
+		}
+		for _, input := range inputs {
+			var out strings.Builder
+			err := txToText(input, &out)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tc.err)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("test %d: unexpected error: %v", i, err)
+			}
+			if have := strings.SplitN(out.String(), "\n", 2)[0]; have != tc.want {
+				t.Errorf("test %d: header mismatch: have %q, want %q", i, have, tc.want)
+			}
+		}
+	}
+}
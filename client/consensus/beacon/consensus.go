@@ -16,6 +16,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/r5-labs/r5-core/client/common"
 	"github.com/r5-labs/r5-core/client/consensus"
@@ -64,6 +65,16 @@ func New(ethone consensus.Engine) *Beacon {
 	return &Beacon{ethone: ethone}
 }
 
+// MaxFutureBlockTime returns the number of seconds a block timestamp may be ahead
+// of the local clock before the block is considered a future block, deferring
+// to the eth1 engine if it overrides the protocol default.
+func (beacon *Beacon) MaxFutureBlockTime() int64 {
+	if engine, ok := beacon.ethone.(interface{ MaxFutureBlockTime() int64 }); ok {
+		return engine.MaxFutureBlockTime()
+	}
+	return params.AllowedFutureBlockTime
+}
+
 // Author implements consensus.Engine, returning the verified author of the block.
 func (beacon *Beacon) Author(header *types.Header) (common.Address, error) {
 	if !beacon.IsPoSHeader(header) {
@@ -218,7 +229,7 @@ func (beacon *Beacon) VerifyUncles(chain consensus.ChainReader, block *types.Blo
 //   - unclehash is expected to be Hash(emptyHeader)
 //     to be the desired constants
 //
-// (b) we don't verify if a block is in the future anymore
+// (b) the future block window follows the eth1 engine
 // (c) the extradata is limited to 32 bytes
 func (beacon *Beacon) verifyHeader(chain consensus.ChainHeaderReader, header, parent *types.Header) error {
 	// Ensure that the header's extra-data section is of a reasonable size
@@ -232,7 +243,10 @@ func (beacon *Beacon) verifyHeader(chain consensus.ChainHeaderReader, header, pa
 	if header.UncleHash != types.EmptyUncleHash {
 		return errInvalidUncleHash
 	}
-	// Verify the timestamp
+	// Verify the timestamp, against the same future block window as ethash
+	if header.Time > uint64(time.Now().Unix()+beacon.MaxFutureBlockTime()) {
+		return consensus.ErrFutureBlock
+	}
 	if header.Time <= parent.Time {
 		return errInvalidTimestamp
 	}
@@ -271,6 +271,14 @@ func generateDatasetItem(cache []uint32, index uint32, keccak512 hasher) []byte
 // generateDataset generates the entire ethash dataset for mining.
 // This method places the result into dest in machine byte order.
 func generateDataset(dest []uint32, epoch uint64, cache []uint32) {
+	generateDatasetWithProgress(dest, epoch, cache, nil)
+}
+
+// generateDatasetWithProgress is identical to generateDataset, but additionally
+// invokes the given callback, if any, with the increasing percentage of the
+// dataset generated so far. The callback invocations are serialized and the
+// last one always reports 100.
+func generateDatasetWithProgress(dest []uint32, epoch uint64, cache []uint32, progressFn func(percent uint64)) {
 	// Print some debug logs to allow analysis on low end devices
 	logger := log.New("epoch", epoch)
 
@@ -303,7 +311,23 @@ func generateDataset(dest []uint32, epoch uint64, cache []uint32) {
 	var pend sync.WaitGroup
 	pend.Add(threads)
 
-	var progress atomic.Uint64
+	var (
+		progress atomic.Uint64
+		reportMu sync.Mutex
+		reported uint64
+	)
+	report := func(status uint64) {
+		if progressFn == nil {
+			return
+		}
+		reportMu.Lock()
+		defer reportMu.Unlock()
+
+		if percent := status * 100 / (size / hashBytes); percent > reported {
+			reported = percent
+			progressFn(percent)
+		}
+	}
 	for i := 0; i < threads; i++ {
 		go func(id int) {
 			defer pend.Done()
@@ -320,6 +344,9 @@ func generateDataset(dest []uint32, epoch uint64, cache []uint32) {
 			}
 			// Calculate the dataset segment
 			percent := size / hashBytes / 100
+			if percent == 0 {
+				percent = 1
+			}
 			for index := first; index < limit; index++ {
 				item := generateDatasetItem(cache, uint32(index), keccak512)
 				if swapped {
@@ -329,12 +356,14 @@ func generateDataset(dest []uint32, epoch uint64, cache []uint32) {
 
 				if status := progress.Add(1); status%percent == 0 {
 					logger.Info("Generating DAG in progress", "percentage", (status*100)/(size/hashBytes), "elapsed", common.PrettyDuration(time.Since(start)))
+					report(status)
 				}
 			}
 		}(i)
 	}
 	// Wait for all the generators to finish and return
 	pend.Wait()
+	report(size / hashBytes)
 }
 
 // hashimoto aggregates data from the full dataset in order to produce our final
@@ -657,6 +657,31 @@ func TestDatasetGeneration(t *testing.T) {
 	}
 }
 
+// Tests that the dataset generation reports a monotonically increasing progress
+// reaching 100%, and that the reported dataset matches the plain generation.
+func TestDatasetGenerationProgress(t *testing.T) {
+	var reports []uint64
+	d := &dataset{epoch: 0, progress: func(percent uint64) { reports = append(reports, percent) }}
+	d.generate("", 0, false, true)
+
+	if len(reports) == 0 || reports[len(reports)-1] != 100 {
+		t.Fatalf("progress did not reach 100%%: %v", reports)
+	}
+	for i := 1; i < len(reports); i++ {
+		if reports[i] <= reports[i-1] {
+			t.Fatalf("progress not increasing: %v", reports)
+		}
+	}
+	cache := make([]uint32, 1024/4)
+	generateCache(cache, 0, seedHash(1))
+
+	want := make([]uint32, 32*1024/4)
+	generateDataset(want, 0, cache)
+	if !reflect.DeepEqual(d.dataset, want) {
+		t.Fatal("dataset content mismatch")
+	}
+}
+
 // Tests whether the hashimoto lookup works for both light as well as the full
 // datasets.
 func TestHashimoto(t *testing.T) {
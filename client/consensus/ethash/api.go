@@ -57,6 +57,36 @@ func (api *API) GetWork() ([4]string, error) {
 	}
 }
 
+// WorkPackage is the detailed form of a work package, carrying the block number
+// and the DAG epoch explicitly so external miners don't have to derive them.
+type WorkPackage struct {
+	HeaderHash  common.Hash    `json:"headerHash"`
+	SeedHash    common.Hash    `json:"seedHash"`
+	Target      common.Hash    `json:"target"`
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	Epoch       hexutil.Uint64 `json:"epoch"`
+}
+
+// GetWorkDetailed returns the same work package as GetWork, together with the
+// DAG epoch of the block being sealed.
+func (api *API) GetWorkDetailed() (*WorkPackage, error) {
+	work, err := api.GetWork()
+	if err != nil {
+		return nil, err
+	}
+	number, err := hexutil.DecodeUint64(work[3])
+	if err != nil {
+		return nil, err
+	}
+	return &WorkPackage{
+		HeaderHash:  common.HexToHash(work[0]),
+		SeedHash:    common.HexToHash(work[1]),
+		Target:      common.HexToHash(work[2]),
+		BlockNumber: hexutil.Uint64(number),
+		Epoch:       hexutil.Uint64(number / epochLength),
+	}, nil
+}
+
 // SubmitWork can be used by external miner to submit their POW solution.
 // It returns an indication if the work was accepted.
 // Note either an invalid solution, a stale work a non-existent work will return false.
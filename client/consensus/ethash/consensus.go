@@ -39,12 +39,11 @@ var (
 	ByzantiumBlockReward          	= big.NewInt(1e+18)		// Block reward in wei for successfully mining a block upward from Byzantium
 	ConstantinopleBlockReward     	= big.NewInt(1e+18)		// Block reward in wei for successfully mining a block upward from Constantinople
 	maxUncles                     	= 2						// Maximum number of uncles allowed in a single block
-	allowedFutureBlockTimeSeconds 	= int64(8)				// Max seconds from current time allowed for blocks, before they're considered future blocks
 	
 	// Supply cap definitions, SupplyCap needs to be validated by finalBlock, according
 	// to the emission schedule
 	SupplyCap 						= new(big.Int).Mul(big.NewInt(66337700), big.NewInt(1e18))
-	preminedSupply 					= new(big.Int).Mul(big.NewInt(2000000), big.NewInt(1000000000000000000))
+	preminedSupply 					= params.PreminedSupply
 	finalBlock 						= uint64(1290406400)
 
 	// calcDifficultyEip5133 is the difficulty adjustment algorithm as specified by EIP 5133.
@@ -81,83 +80,79 @@ var (
 	calcDifficultyByzantium = makeDifficultyCalculator()
 )
 
+// superEpochs is the emission schedule shared by the block reward, the epoch and
+// the circulating supply calculations: the last block of each super epoch and the
+// block reward (in wei) paid up to it. No rewards are issued from finalBlock on.
+var superEpochs = []struct {
+	last   uint64
+	reward *big.Int
+}{
+	{4000000, big.NewInt(2e18)},           // Super Epoch 1: 2 R5 per block.
+	{8000000, big.NewInt(1e18)},           // Super Epoch 2: 1 R5 per block.
+	{16000000, big.NewInt(5e17)},          // Super Epoch 3: 0.5 R5 per block.
+	{32000000, big.NewInt(25e16)},         // Super Epoch 4: 0.25 R5 per block.
+	{64000000, big.NewInt(125e15)},        // Super Epoch 5: 0.125 R5 per block.
+	{128000000, big.NewInt(625e14)},       // Super Epoch 6: 0.0625 R5 per block.
+	{finalBlock - 1, big.NewInt(3125e13)}, // Super Epoch 7: 0.03125 R5 per block.
+}
+
 // CalculateCirculatingSupply returns the current circulating supply (in wei) at the given block number.
 // It sums the pre-mined supply and the cumulative block rewards as defined by the Super Epoch schedule.
 // For blocks >= finalBlock, it returns the maximum supply (i.e. premined supply plus all block rewards).
 func CalculateCirculatingSupply(blockNum uint64) *big.Int {
-	// 1 R5 = 1e18 wei.
-	weiPerR5 := big.NewInt(1000000000000000000)
-	// Start with the pre-mined supply.
-	supply := new(big.Int).Set(preminedSupply)
-
-	// Define epoch endpoints (block numbers).
-	const (
-		epoch1End = 4000000
-		epoch2End = 8000000
-		epoch3End = 16000000
-		epoch4End = 32000000
-		epoch5End = 64000000
-		epoch6End = 128000000
-	)
 	// If blockNum is at or beyond the final block number, return the full issuance.
 	if blockNum >= finalBlock {
 		// When blockNum is at or beyond finalBlock, the total block rewards issued
 		// should equal 66,337,700 - 2,000,000 = 64,337,700 R5.
-		totalBlockRewards := new(big.Int).Mul(big.NewInt(64337700), weiPerR5)
+		totalBlockRewards := new(big.Int).Mul(big.NewInt(64337700), big.NewInt(1e18))
 		return new(big.Int).Add(preminedSupply, totalBlockRewards)
-	}	
+	}
+	// Start with the pre-mined supply and add the rewards of every block mined
+	// so far, epoch by epoch.
+	supply := new(big.Int).Set(preminedSupply)
 
-	// Helper: minBlocks returns the number of blocks in the interval [start, min(blockNum, end)].
-	minBlocks := func(start, end uint64) uint64 {
-		if blockNum < start {
-			return 0
+	first := uint64(1)
+	for _, epoch := range superEpochs {
+		if blockNum < first {
+			break
 		}
-		if blockNum > end {
-			return end - start + 1
+		last := epoch.last
+		if blockNum < last {
+			last = blockNum
 		}
-		return blockNum - start + 1
+		blocks := new(big.Int).SetUint64(last - first + 1)
+		supply.Add(supply, blocks.Mul(blocks, epoch.reward))
+		first = epoch.last + 1
 	}
+	return supply
+}
 
-	var epochReward *big.Int
-
-	// Epoch 1: Blocks 1 to 4,000,000, reward 2 R5 per block.
-	blocks := minBlocks(1, epoch1End)
-	epochReward = new(big.Int).Mul(big.NewInt(2), weiPerR5)
-	supply.Add(supply, new(big.Int).Mul(big.NewInt(int64(blocks)), epochReward))
-
-	// Epoch 2: Blocks 4,000,001 to 8,000,000, reward 1 R5 per block.
-	blocks = minBlocks(epoch1End+1, epoch2End)
-	epochReward = new(big.Int).Set(weiPerR5)
-	supply.Add(supply, new(big.Int).Mul(big.NewInt(int64(blocks)), epochReward))
-
-	// Epoch 3: Blocks 8,000,001 to 16,000,000, reward 0.5 R5 per block.
-	blocks = minBlocks(epoch2End+1, epoch3End)
-	epochReward = big.NewInt(500000000000000000) // 0.5 R5 in wei
-	supply.Add(supply, new(big.Int).Mul(big.NewInt(int64(blocks)), epochReward))
-
-	// Epoch 4: Blocks 16,000,001 to 32,000,000, reward 0.25 R5 per block.
-	blocks = minBlocks(epoch3End+1, epoch4End)
-	epochReward = big.NewInt(250000000000000000) // 0.25 R5 in wei
-	supply.Add(supply, new(big.Int).Mul(big.NewInt(int64(blocks)), epochReward))
+// ReconcileSupply cross-checks the scheduled circulating supply at the given
+// block against the sum of all account balances in the state, to help debugging
+// minting bugs. The returned diff is actual minus scheduled, so burnt base fees
+// show up as a negative discrepancy. Only the committed state is iterated, and
+// accounts are visited without needing their address preimages.
+func ReconcileSupply(statedb *state.StateDB, blockNum uint64) (scheduled, actual *big.Int, diff *big.Int) {
+	summer := &balanceSummer{total: new(big.Int)}
+	statedb.DumpToCollector(summer, &state.DumpConfig{SkipCode: true, SkipStorage: true})
+
+	scheduled = CalculateCirculatingSupply(blockNum)
+	return scheduled, summer.total, new(big.Int).Sub(summer.total, scheduled)
+}
 
-	// Epoch 5: Blocks 32,000,001 to 64,000,000, reward 0.125 R5 per block.
-	blocks = minBlocks(epoch4End+1, epoch5End)
-	epochReward = big.NewInt(125000000000000000) // 0.125 R5 in wei
-	supply.Add(supply, new(big.Int).Mul(big.NewInt(int64(blocks)), epochReward))
+// balanceSummer is a state dump collector summing up all account balances.
+type balanceSummer struct {
+	total *big.Int
+}
 
-	// Epoch 6: Blocks 64,000,001 to 128,000,000, reward 0.0625 R5 per block.
-	blocks = minBlocks(epoch5End+1, epoch6End)
-	epochReward = big.NewInt(62500000000000000) // 0.0625 R5 in wei
-	supply.Add(supply, new(big.Int).Mul(big.NewInt(int64(blocks)), epochReward))
+// OnRoot implements state.DumpCollector.
+func (s *balanceSummer) OnRoot(common.Hash) {}
 
-	// Epoch 7: Blocks 128,000,001 up to blockNum, reward 0.03125 R5 per block.
-	if blockNum > epoch6End {
-		blocks = minBlocks(epoch6End+1, blockNum)
-		epochReward = big.NewInt(31250000000000000) // 0.03125 R5 in wei
-		supply.Add(supply, new(big.Int).Mul(big.NewInt(int64(blocks)), epochReward))
+// OnAccount implements state.DumpCollector.
+func (s *balanceSummer) OnAccount(addr common.Address, account state.DumpAccount) {
+	if balance, ok := new(big.Int).SetString(account.Balance, 10); ok {
+		s.total.Add(s.total, balance)
 	}
-
-	return supply
 }
 
 // Various error messages to mark blocks invalid. These should be private to
@@ -287,7 +282,12 @@ func (ethash *Ethash) VerifyUncles(chain consensus.ChainReader, block *types.Blo
 	if ethash.config.PowMode == ModeFullFake {
 		return nil
 	}
-	// Verify that there are at most 2 uncles included in this block
+	// Uncles earn no reward on R5, so most blocks have none. The header commits
+	// to that already, a body not matching it is rejected by the body validation.
+	if block.UncleHash() == types.EmptyUncleHash {
+		return nil
+	}
+	// Verify that there are at most the allowed number of uncles in this block
 	if len(block.Uncles()) > maxUncles {
 		return errTooManyUncles
 	}
@@ -343,6 +343,51 @@ func (ethash *Ethash) VerifyUncles(chain consensus.ChainReader, block *types.Blo
 	return nil
 }
 
+// MaxUncles returns the maximum number of uncles the local miner includes in a
+// block, which is the protocol limit unless configured lower. It has no effect
+// on the uncles accepted from other miners.
+func (ethash *Ethash) MaxUncles() int {
+	if ethash.config.MaxUncles != nil && *ethash.config.MaxUncles < maxUncles {
+		return *ethash.config.MaxUncles
+	}
+	return maxUncles
+}
+
+// MaxFutureBlockTime returns the number of seconds a block timestamp may be ahead
+// of the local clock before the block is considered a future block. A block
+// stamped exactly at the boundary is accepted, one second later is rejected.
+// The configured Config.AllowedFutureBlockTime takes precedence over the window
+// shared with the other consensus engines through the params package.
+func (ethash *Ethash) MaxFutureBlockTime() int64 {
+	if ethash.config.AllowedFutureBlockTime > 0 {
+		return ethash.config.AllowedFutureBlockTime
+	}
+	return params.AllowedFutureBlockTime
+}
+
+// allowEqualTimestamps reports whether the chain accepts blocks with the same
+// timestamp as their parent.
+func allowEqualTimestamps(config *params.ChainConfig) bool {
+	return config.Ethash != nil && config.Ethash.AllowEqualTimestamps
+}
+
+// VerifyChainConfig checks whether ethash is able to run the given chain. The
+// Shanghai and Cancun forks are only supported after the merge, when headers are
+// no longer verified by ethash, so scheduling them on a chain which never leaves
+// proof-of-work would fail every header past the fork.
+func VerifyChainConfig(config *params.ChainConfig) error {
+	if config.TerminalTotalDifficulty != nil {
+		return nil
+	}
+	if config.ShanghaiTime != nil {
+		return fmt.Errorf("ethash does not support shanghai fork (scheduled at %d) without a terminal total difficulty", *config.ShanghaiTime)
+	}
+	if config.CancunTime != nil {
+		return fmt.Errorf("ethash does not support cancun fork (scheduled at %d) without a terminal total difficulty", *config.CancunTime)
+	}
+	return nil
+}
+
 // verifyHeader checks whether a header conforms to the consensus rules of the
 // stock Ethereum ethash engine.
 // See YP section 4.3.4. "Block Header Validity"
@@ -351,13 +396,13 @@ func (ethash *Ethash) verifyHeader(chain consensus.ChainHeaderReader, header, pa
 	if uint64(len(header.Extra)) > params.MaximumExtraDataSize {
 		return fmt.Errorf("extra-data too long: %d > %d", len(header.Extra), params.MaximumExtraDataSize)
 	}
-	// Verify the header's timestamp
-	if !uncle {
-		if header.Time > uint64(unixNow+allowedFutureBlockTimeSeconds) {
+	// Verify the header's timestamp, fake engines are exempt to tolerate clock drift
+	if !uncle && ethash.config.PowMode != ModeFake && ethash.config.PowMode != ModeFullFake {
+		if header.Time > uint64(unixNow+ethash.MaxFutureBlockTime()) {
 			return consensus.ErrFutureBlock
 		}
 	}
-	if header.Time <= parent.Time {
+	if header.Time < parent.Time || (header.Time == parent.Time && !allowEqualTimestamps(chain.Config())) {
 		return errOlderBlockTime
 	}
 	// Verify the block's difficulty based on its timestamp and parent's difficulty
@@ -391,12 +436,6 @@ func (ethash *Ethash) verifyHeader(chain consensus.ChainHeaderReader, header, pa
 	if diff := new(big.Int).Sub(header.Number, parent.Number); diff.Cmp(big.NewInt(1)) != 0 {
 		return consensus.ErrInvalidNumber
 	}
-	if chain.Config().IsShanghai(header.Time) {
-		return fmt.Errorf("ethash does not support shanghai fork")
-	}
-	if chain.Config().IsCancun(header.Time) {
-		return fmt.Errorf("ethash does not support cancun fork")
-	}
 	// Verify the engine specific seal securing the block
 	if seal {
 		if err := ethash.verifySeal(chain, header, false); err != nil {
@@ -422,26 +461,46 @@ func (ethash *Ethash) CalcDifficulty(chain consensus.ChainHeaderReader, time uin
 // given the parent block's time and difficulty.
 func CalcDifficulty(config *params.ChainConfig, time uint64, parent *types.Header) *big.Int {
 	next := new(big.Int).Add(parent.Number, big1)
+	floor := difficultyFloor(config)
 	switch {
 	case config.IsGrayGlacier(next):
-		return calcDifficultyEip5133(time, parent)
+		return calcDifficultyEip5133(time, parent, floor)
 	case config.IsArrowGlacier(next):
-		return calcDifficultyEip4345(time, parent)
+		return calcDifficultyEip4345(time, parent, floor)
 	case config.IsLondon(next):
-		return calcDifficultyEip3554(time, parent)
+		return calcDifficultyEip3554(time, parent, floor)
 	case config.IsMuirGlacier(next):
-		return calcDifficultyEip2384(time, parent)
+		return calcDifficultyEip2384(time, parent, floor)
 	case config.IsConstantinople(next):
-		return calcDifficultyConstantinople(time, parent)
+		return calcDifficultyConstantinople(time, parent, floor)
 	case config.IsByzantium(next):
-		return calcDifficultyByzantium(time, parent)
+		return calcDifficultyByzantium(time, parent, floor)
 	case config.IsHomestead(next):
-		return calcDifficultyHomestead(time, parent)
+		return calcDifficultyHomestead(time, parent, floor)
 	default:
-		return calcDifficultyFrontier(time, parent)
+		return calcDifficultyFrontier(time, parent, floor, durationLimit(config))
 	}
 }
 
+// difficultyFloor returns the minimum difficulty of the chain, which is the
+// protocol default unless overridden in the ethash config.
+func difficultyFloor(config *params.ChainConfig) *big.Int {
+	if config.Ethash != nil && config.Ethash.MinimumDifficulty != nil && config.Ethash.MinimumDifficulty.Sign() > 0 {
+		return config.Ethash.MinimumDifficulty
+	}
+	return params.MinimumDifficulty
+}
+
+// durationLimit returns the block time boundary of the Frontier difficulty
+// adjustment, which is the protocol default unless overridden in the ethash
+// config.
+func durationLimit(config *params.ChainConfig) *big.Int {
+	if config.Ethash != nil && config.Ethash.DurationLimit != 0 {
+		return new(big.Int).SetUint64(config.Ethash.DurationLimit)
+	}
+	return params.DurationLimit
+}
+
 // Some weird constants to avoid constant memory allocs for them.
 var (
 	big1			= big.NewInt(1)
@@ -453,10 +512,11 @@ var (
 )
 
 // makeDifficultyCalculator creates a difficulty calculator using Byzantium rules,
-// with an adjustment factor computed for a 7-second target.
-func makeDifficultyCalculator() func(time uint64, parent *types.Header) *big.Int {
+// with an adjustment factor computed for a 7-second target, never returning a
+// difficulty below the given floor.
+func makeDifficultyCalculator() func(time uint64, parent *types.Header, floor *big.Int) *big.Int {
 	// Note: calculations below use parent's block time (which is one less than the block number).
-	return func(time uint64, parent *types.Header) *big.Int {
+	return func(time uint64, parent *types.Header, floor *big.Int) *big.Int {
 		/*
 			Byzantium adjustment:
 			child_diff = parent_diff + (parent_diff / 2048) * adjustment_factor
@@ -488,17 +548,17 @@ func makeDifficultyCalculator() func(time uint64, parent *types.Header) *big.Int
 		} else {
 			y.Add(pDiff, z)
 		}
-		if y.LtUint64(minimumDifficulty) {
-			y.SetUint64(minimumDifficulty)
+		if diff := y.ToBig(); diff.Cmp(floor) >= 0 {
+			return diff
 		}
-		return y.ToBig()
+		return new(big.Int).Set(floor)
 	}
 }
 
 // calcDifficultyHomestead computes the block difficulty using Homestead rules
 // without applying any exponential bomb factor.
 // New formula: diff = parent_diff + (parent_diff / 2048 * max(1 - ((time - parent.Time) // 7), -99))
-func calcDifficultyHomestead(time uint64, parent *types.Header) *big.Int {
+func calcDifficultyHomestead(time uint64, parent *types.Header, floor *big.Int) *big.Int {
 	bigTime := new(big.Int).SetUint64(time)
 	bigParentTime := new(big.Int).SetUint64(parent.Time)
 
@@ -526,15 +586,16 @@ func calcDifficultyHomestead(time uint64, parent *types.Header) *big.Int {
 	x.Add(parent.Difficulty, x)
 
 	// Ensure difficulty does not fall below the minimum threshold.
-	if x.Cmp(params.MinimumDifficulty) < 0 {
-		x.Set(params.MinimumDifficulty)
+	if x.Cmp(floor) < 0 {
+		x.Set(floor)
 	}
 	return x
 }
 
 // calcDifficultyFrontier computes the block difficulty using Frontier rules
-// without any exponential bomb component.
-func calcDifficultyFrontier(time uint64, parent *types.Header) *big.Int {
+// without any exponential bomb component. Blocks mined faster than the given
+// duration limit raise the difficulty, slower ones lower it.
+func calcDifficultyFrontier(time uint64, parent *types.Header, floor *big.Int, limit *big.Int) *big.Int {
 	diff := new(big.Int)
 	// Calculate adjustment = parent_diff / 2048
 	adjust := new(big.Int).Div(parent.Difficulty, params.DifficultyBoundDivisor)
@@ -544,21 +605,25 @@ func calcDifficultyFrontier(time uint64, parent *types.Header) *big.Int {
 
 	// If the time difference is less than the duration limit, increase difficulty;
 	// otherwise, decrease it.
-	if bigTime.Sub(bigTime, bigParentTime).Cmp(params.DurationLimit) < 0 {
+	if bigTime.Sub(bigTime, bigParentTime).Cmp(limit) < 0 {
 		diff.Add(parent.Difficulty, adjust)
 	} else {
 		diff.Sub(parent.Difficulty, adjust)
 	}
 	// Ensure the difficulty does not drop below the minimum
-	if diff.Cmp(params.MinimumDifficulty) < 0 {
-		diff.Set(params.MinimumDifficulty)
+	if diff.Cmp(floor) < 0 {
+		diff.Set(floor)
 	}
 	return diff
 }
 
-// Exported for fuzzing
-var FrontierDifficultyCalculator = calcDifficultyFrontier
-var HomesteadDifficultyCalculator = calcDifficultyHomestead
+// Exported for fuzzing, clamping to the default minimum difficulty
+var FrontierDifficultyCalculator = func(time uint64, parent *types.Header) *big.Int {
+	return calcDifficultyFrontier(time, parent, params.MinimumDifficulty, params.DurationLimit)
+}
+var HomesteadDifficultyCalculator = func(time uint64, parent *types.Header) *big.Int {
+	return calcDifficultyHomestead(time, parent, params.MinimumDifficulty)
+}
 var DynamicDifficultyCalculator = makeDifficultyCalculator
 
 // verifySeal checks whether a block satisfies the PoW difficulty requirements,
@@ -568,7 +633,12 @@ func (ethash *Ethash) verifySeal(chain consensus.ChainHeaderReader, header *type
 	// If we're running a fake PoW, accept any seal as valid
 	if ethash.config.PowMode == ModeFake || ethash.config.PowMode == ModeFullFake {
 		time.Sleep(ethash.fakeDelay)
-		if ethash.fakeFail == header.Number.Uint64() {
+
+		ethash.lock.Lock()
+		from, to := ethash.fakeFailFrom, ethash.fakeFailTo
+		ethash.lock.Unlock()
+
+		if number := header.Number.Uint64(); number >= from && number <= to {
 			return errInvalidPoW
 		}
 		return nil
@@ -620,7 +690,7 @@ func (ethash *Ethash) verifySeal(chain consensus.ChainHeaderReader, header *type
 	if !bytes.Equal(header.MixDigest[:], digest) {
 		return errInvalidMixDigest
 	}
-	target := new(big.Int).Div(two256, header.Difficulty)
+	target := DifficultyToTarget(header.Difficulty)
 	if new(big.Int).SetBytes(result).Cmp(target) > 0 {
 		return errInvalidPoW
 	}
@@ -646,35 +716,34 @@ func calculateBlockReward(blockNumber uint64, totalSupply *big.Int) *big.Int {
 	if totalSupply.Cmp(SupplyCap) >= 0 {
 		return big.NewInt(0)
 	}
+	epoch, _, _ := EmissionEpoch(blockNumber)
+	if epoch > len(superEpochs) {
+		epoch = len(superEpochs)
+	}
+	return new(big.Int).Set(superEpochs[epoch-1].reward)
+}
 
-	// Define 1 R5 in wei.
-	oneR5 := new(big.Int).Mul(big.NewInt(1), big.NewInt(1e18))
-	var reward *big.Int
+// EmissionEpoch returns the 1 based super epoch of the given block number and
+// the first block of the next epoch, at which the block reward changes. Blocks
+// from finalBlock on belong to the terminal epoch without rewards, for which ok
+// is false as there is no further reward step.
+func EmissionEpoch(blockNumber uint64) (epoch int, next uint64, ok bool) {
+	for i, step := range superEpochs {
+		if blockNumber <= step.last {
+			return i + 1, step.last + 1, true
+		}
+	}
+	return len(superEpochs) + 1, 0, false
+}
 
-	switch {
-	case blockNumber <= 4000000:
-		// Super Epoch 1: 2 R5 per block.
-		reward = new(big.Int).Mul(big.NewInt(2), oneR5)
-	case blockNumber <= 8000000:
-		// Super Epoch 2: 1 R5 per block.
-		reward = new(big.Int).Set(oneR5)
-	case blockNumber <= 16000000:
-		// Super Epoch 3: 0.5 R5 per block.
-		reward = new(big.Int).Div(oneR5, big.NewInt(2))
-	case blockNumber <= 32000000:
-		// Super Epoch 4: 0.25 R5 per block.
-		reward = new(big.Int).Div(oneR5, big.NewInt(4))
-	case blockNumber <= 64000000:
-		// Super Epoch 5: 0.125 R5 per block.
-		reward = new(big.Int).Div(oneR5, big.NewInt(8))
-	case blockNumber <= 128000000:
-		// Super Epoch 6: 0.0625 R5 per block.
-		reward = new(big.Int).Div(oneR5, big.NewInt(16))
-	default:
-		// Super Epoch 7: 0.03125 R5 per block.
-		reward = new(big.Int).Div(oneR5, big.NewInt(32))
+// BlockReward returns the block reward (in wei) credited to the miner of the block
+// with the given number, following the super epoch schedule and the supply cap.
+// The genesis block isn't mined, so it carries no reward.
+func BlockReward(blockNumber uint64) *big.Int {
+	if blockNumber == 0 {
+		return new(big.Int)
 	}
-	return reward
+	return calculateBlockReward(blockNumber, CalculateCirculatingSupply(blockNumber))
 }
 
 // Finalize implements consensus.Engine, accumulating the block and uncle rewards.
@@ -738,11 +807,8 @@ var (
 // In accumulateRewards, replace the call to state.GetTotalSupply() with CalculateCirculatingSupply.
 // (Assuming your state does not provide a GetTotalSupply method.)
 func accumulateRewards(config *params.ChainConfig, state *state.StateDB, header *types.Header, uncles []*types.Header) {
-	// Calculate current total supply from the block number.
-	totalSupply := CalculateCirculatingSupply(header.Number.Uint64())
-
 	// Calculate the block reward for the current block.
-	reward := calculateBlockReward(header.Number.Uint64(), totalSupply)
+	reward := BlockReward(header.Number.Uint64())
 
 	// Credit the block reward to the miner's balance.
 	state.AddBalance(header.Coinbase, reward)
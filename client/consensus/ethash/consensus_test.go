@@ -20,11 +20,18 @@ import (
 	"math/rand"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/r5-labs/r5-core/client/common"
 	"github.com/r5-labs/r5-core/client/common/math"
+	"github.com/r5-labs/r5-core/client/consensus"
+	"github.com/r5-labs/r5-core/client/core"
+	"github.com/r5-labs/r5-core/client/core/rawdb"
+	"github.com/r5-labs/r5-core/client/core/state"
 	"github.com/r5-labs/r5-core/client/core/types"
+	"github.com/r5-labs/r5-core/client/core/vm"
+	"github.com/r5-labs/r5-core/client/crypto"
 	"github.com/r5-labs/r5-core/client/params"
 )
 
@@ -147,7 +154,7 @@ func BenchmarkDifficultyCalculator(b *testing.B) {
 	b.Run("big-frontier", func(b *testing.B) {
 		b.ReportAllocs()
 		for i := 0; i < b.N; i++ {
-			calcDifficultyFrontier(1000014, h)
+			calcDifficultyFrontier(1000014, h, params.MinimumDifficulty, params.DurationLimit)
 		}
 	})
 	b.Run("u256-frontier", func(b *testing.B) {
@@ -159,7 +166,7 @@ func BenchmarkDifficultyCalculator(b *testing.B) {
 	b.Run("big-homestead", func(b *testing.B) {
 		b.ReportAllocs()
 		for i := 0; i < b.N; i++ {
-			calcDifficultyHomestead(1000014, h)
+			calcDifficultyHomestead(1000014, h, params.MinimumDifficulty)
 		}
 	})
 	b.Run("u256-homestead", func(b *testing.B) {
@@ -171,13 +178,555 @@ func BenchmarkDifficultyCalculator(b *testing.B) {
 	b.Run("big-generic", func(b *testing.B) {
 		b.ReportAllocs()
 		for i := 0; i < b.N; i++ {
-			x1(1000014, h)
+			x1(1000014, h, params.MinimumDifficulty)
 		}
 	})
 	b.Run("u256-generic", func(b *testing.B) {
 		b.ReportAllocs()
 		for i := 0; i < b.N; i++ {
-			x2(1000014, h)
+			x2(1000014, h, params.MinimumDifficulty)
+		}
+	})
+}
+
+// difficultyForks returns a chain config activating each difficulty related
+// fork at its own block, along with the parent block number hitting each fork
+// path of CalcDifficulty.
+func difficultyForks() (*params.ChainConfig, []struct {
+	name   string
+	number int64
+}) {
+	config := &params.ChainConfig{
+		ChainID:             big.NewInt(1),
+		HomesteadBlock:      big.NewInt(10),
+		ByzantiumBlock:      big.NewInt(20),
+		ConstantinopleBlock: big.NewInt(30),
+		PetersburgBlock:     big.NewInt(30),
+		IstanbulBlock:       big.NewInt(30),
+		MuirGlacierBlock:    big.NewInt(40),
+		BerlinBlock:         big.NewInt(50),
+		LondonBlock:         big.NewInt(50),
+		ArrowGlacierBlock:   big.NewInt(60),
+		GrayGlacierBlock:    big.NewInt(70),
+		Ethash:              new(params.EthashConfig),
+	}
+	return config, []struct {
+		name   string
+		number int64
+	}{
+		{"frontier", 0},
+		{"homestead", 9},
+		{"byzantium", 19},
+		{"constantinople", 29},
+		{"muirglacier", 39},
+		{"london", 49},
+		{"arrowglacier", 59},
+		{"grayglacier", 69},
+	}
+}
+
+func TestCalcDifficultyForkPaths(t *testing.T) {
+	config, forks := difficultyForks()
+	for _, fork := range forks {
+		parent := &types.Header{
+			UncleHash:  types.EmptyUncleHash,
+			Difficulty: big.NewInt(0xffffff),
+			Number:     big.NewInt(fork.number),
+			Time:       1000000,
+		}
+		// Cover blocks faster and slower than the 7 second target.
+		for _, delta := range []uint64{1, 7, 14, 1000} {
+			if diff := CalcDifficulty(config, parent.Time+delta, parent); diff.Sign() <= 0 {
+				t.Errorf("%s: non-positive difficulty %v after %ds", fork.name, diff, delta)
+			}
+		}
+	}
+}
+
+func TestCalcDifficultyCustomFloor(t *testing.T) {
+	config, forks := difficultyForks()
+	floor := big.NewInt(50000)
+
+	for _, fork := range forks {
+		// With the default floor, the difficulty bottoms out at the protocol minimum.
+		config.Ethash = new(params.EthashConfig)
+		parent := &types.Header{
+			UncleHash:  types.EmptyUncleHash,
+			Difficulty: new(big.Int).Set(params.MinimumDifficulty),
+			Number:     big.NewInt(fork.number),
+			Time:       1000000,
+		}
+		if diff := CalcDifficulty(config, parent.Time+1000, parent); diff.Cmp(params.MinimumDifficulty) != 0 {
+			t.Errorf("%s: default floor difficulty mismatch: have %v, want %v", fork.name, diff, params.MinimumDifficulty)
+		}
+		// A custom floor lets slow blocks descend to it, but never below.
+		config.Ethash = &params.EthashConfig{MinimumDifficulty: floor}
+		for i := 0; i < 2000; i++ {
+			diff := CalcDifficulty(config, parent.Time+1000, parent)
+			if diff.Cmp(floor) < 0 {
+				t.Fatalf("%s: difficulty %v below floor %v", fork.name, diff, floor)
+			}
+			parent = &types.Header{
+				UncleHash:  types.EmptyUncleHash,
+				Difficulty: diff,
+				Number:     parent.Number,
+				Time:       parent.Time + 1000,
+			}
+		}
+		if parent.Difficulty.Cmp(floor) != 0 {
+			t.Errorf("%s: difficulty %v did not descend to floor %v", fork.name, parent.Difficulty, floor)
+		}
+	}
+	// Sealing and verification must agree on the custom floor, checked on a
+	// Byzantium block to avoid the London base fee rules.
+	engine := NewFaker()
+	defer engine.Close()
+
+	var (
+		chain  = &configChainReader{config: config}
+		parent = &types.Header{
+			UncleHash:  types.EmptyUncleHash,
+			Difficulty: new(big.Int).Add(floor, big1),
+			Number:     big.NewInt(24),
+			GasLimit:   8000000,
+			Time:       1000000,
+		}
+		header = &types.Header{
+			ParentHash: parent.Hash(),
+			UncleHash:  types.EmptyUncleHash,
+			Number:     big.NewInt(25),
+			GasLimit:   parent.GasLimit,
+			Time:       parent.Time + 1000,
+		}
+	)
+	header.Difficulty = engine.CalcDifficulty(chain, header.Time, parent)
+	if header.Difficulty.Cmp(floor) != 0 {
+		t.Fatalf("sealing difficulty mismatch: have %v, want %v", header.Difficulty, floor)
+	}
+	if err := engine.verifyHeader(chain, header, parent, false, false, int64(header.Time)); err != nil {
+		t.Fatalf("failed to verify header at custom floor: %v", err)
+	}
+}
+
+func TestCalcDifficultyFrontierDurationLimit(t *testing.T) {
+	config, _ := difficultyForks()
+	parent := &types.Header{
+		UncleHash:  types.EmptyUncleHash,
+		Difficulty: big.NewInt(0xffffff),
+		Number:     big.NewInt(0),
+		Time:       1000000,
+	}
+	tests := []struct {
+		limit uint64 // Configured duration limit, zero for the default
+		delta uint64 // Block time
+		raise bool   // Whether the difficulty is expected to go up
+	}{
+		{0, 5, true},
+		{0, 10, false},
+		{7, 5, true},
+		{7, 6, true},
+		{7, 7, false},
+		{7, 10, false},
+		{12, 10, true},
+		{12, 12, false},
+	}
+	for i, tt := range tests {
+		config.Ethash = &params.EthashConfig{DurationLimit: tt.limit}
+		diff := CalcDifficulty(config, parent.Time+tt.delta, parent)
+		if raised := diff.Cmp(parent.Difficulty) > 0; raised != tt.raise {
+			t.Errorf("test %d: limit %ds, %ds block: difficulty %v from %v, want raise %v", i, tt.limit, tt.delta, diff, parent.Difficulty, tt.raise)
+		}
+	}
+}
+
+func BenchmarkCalcDifficulty(b *testing.B) {
+	config, forks := difficultyForks()
+	for _, fork := range forks {
+		parent := &types.Header{
+			UncleHash:  types.EmptyUncleHash,
+			Difficulty: big.NewInt(0xffffff),
+			Number:     big.NewInt(fork.number),
+			Time:       1000000,
+		}
+		b.Run(fork.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				CalcDifficulty(config, 1000014, parent)
+			}
+		})
+	}
+}
+
+func TestVerifyDifficultyTransition(t *testing.T) {
+	config := params.AllEthashProtocolChanges
+	parent := &types.Header{
+		UncleHash:  types.EmptyUncleHash,
+		Difficulty: big.NewInt(0xffffff),
+		Number:     big.NewInt(100),
+		Time:       1000000,
+	}
+	want := CalcDifficultyHomesteadU256(parent.Time+7, parent)
+	if err := VerifyDifficultyTransition(config, parent, parent.Time+7, want); err != nil {
+		t.Fatalf("failed to verify valid difficulty: %v", err)
+	}
+	if err := VerifyDifficultyTransition(config, parent, parent.Time+7, new(big.Int).Add(want, big1)); err == nil {
+		t.Fatal("verified mismatching difficulty")
+	}
+	if err := VerifyDifficultyTransition(config, parent, parent.Time-1, want); err != errOlderBlockTime {
+		t.Fatalf("error mismatch: have %v, want %v", err, errOlderBlockTime)
+	}
+	// A configured minimum difficulty replaces the protocol default floor.
+	custom := *config
+	custom.Ethash = &params.EthashConfig{MinimumDifficulty: big.NewInt(1000)}
+	parent.Difficulty = big.NewInt(1000)
+
+	if err := VerifyDifficultyTransition(&custom, parent, parent.Time+1000, big.NewInt(1000)); err != nil {
+		t.Fatalf("failed to verify difficulty clamped to the configured floor: %v", err)
+	}
+	if err := VerifyDifficultyTransition(config, parent, parent.Time+1000, big.NewInt(1000)); err == nil {
+		t.Fatal("verified difficulty below the default floor")
+	}
+}
+
+func FuzzDifficultyTransition(f *testing.F) {
+	f.Add([]byte{0xff, 0xff, 0xff}, uint64(1000000), uint16(1), uint16(7), false)
+	f.Add([]byte{0x02, 0x00, 0x00}, uint64(0), uint16(0), uint16(1000), true)
+	f.Add([]byte{0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, uint64(1<<40), uint16(14), uint16(21), false)
+
+	f.Fuzz(func(t *testing.T, diff []byte, time uint64, short uint16, long uint16, uncles bool) {
+		// Difficulties close to 2^256 overflow the 256 bit calculator, skip them
+		if len(diff) > 31 || short >= long || time > math.MaxUint64-uint64(long) {
+			return
+		}
+		parent := &types.Header{
+			UncleHash:  types.EmptyUncleHash,
+			Difficulty: new(big.Int).SetBytes(diff),
+			Number:     big.NewInt(100),
+			Time:       time,
+		}
+		if uncles {
+			parent.UncleHash = common.Hash{0x01}
+		}
+		if parent.Difficulty.Cmp(params.MinimumDifficulty) < 0 {
+			parent.Difficulty.Set(params.MinimumDifficulty)
+		}
+		fast := calcDifficultyEip5133(time+uint64(short), parent, params.MinimumDifficulty)
+		slow := calcDifficultyEip5133(time+uint64(long), parent, params.MinimumDifficulty)
+		for _, tt := range []struct {
+			delta uint16
+			diff  *big.Int
+		}{{short, fast}, {long, slow}} {
+			if err := VerifyDifficultyTransition(params.AllEthashProtocolChanges, parent, time+uint64(tt.delta), tt.diff); err != nil {
+				t.Fatalf("delta %d: invalid difficulty transition: %v", tt.delta, err)
+			}
+			// Blocks under the target raise the difficulty, blocks over it lower it.
+			target := uint64(targetDurationLimit)
+			if uncles {
+				target *= 2
+			}
+			switch {
+			case uint64(tt.delta) < target && tt.diff.Cmp(parent.Difficulty) <= 0:
+				t.Fatalf("delta %d: difficulty %v not raised from %v", tt.delta, tt.diff, parent.Difficulty)
+			case uint64(tt.delta) >= target+targetDurationLimit && tt.diff.Cmp(parent.Difficulty) >= 0 && tt.diff.Cmp(params.MinimumDifficulty) != 0:
+				t.Fatalf("delta %d: difficulty %v not lowered from %v", tt.delta, tt.diff, parent.Difficulty)
+			}
+		}
+		// Longer block times must never yield a higher difficulty.
+		if slow.Cmp(fast) > 0 {
+			t.Fatalf("difficulty after %ds (%v) above difficulty after %ds (%v)", long, slow, short, fast)
+		}
+	})
+}
+
+// configChainReader is a consensus.ChainHeaderReader only serving the config.
+type configChainReader struct {
+	consensus.ChainHeaderReader
+	config *params.ChainConfig
+}
+
+func (r *configChainReader) Config() *params.ChainConfig { return r.config }
+
+func TestVerifyHeaderFutureBoundary(t *testing.T) {
+	config := &params.ChainConfig{
+		ChainID:        big.NewInt(1),
+		HomesteadBlock: big.NewInt(0),
+		ByzantiumBlock: big.NewInt(0),
+		Ethash:         new(params.EthashConfig),
+	}
+	engine := NewTester(nil, false)
+	defer engine.Close()
+
+	var (
+		chain  = &configChainReader{config: config}
+		now    = int64(1000000)
+		window = engine.MaxFutureBlockTime()
+		parent = &types.Header{
+			UncleHash:  types.EmptyUncleHash,
+			Difficulty: big.NewInt(0xffffff),
+			Number:     big.NewInt(100),
+			GasLimit:   8000000,
+			Time:       uint64(now),
+		}
+	)
+	if window != params.AllowedFutureBlockTime {
+		t.Fatalf("future block window mismatch: have %d, want %d", window, params.AllowedFutureBlockTime)
+	}
+	for _, tt := range []struct {
+		offset int64
+		err    error
+	}{
+		{window - 1, nil},
+		{window, nil},
+		{window + 1, consensus.ErrFutureBlock},
+	} {
+		header := &types.Header{
+			ParentHash: parent.Hash(),
+			UncleHash:  types.EmptyUncleHash,
+			Number:     big.NewInt(101),
+			GasLimit:   parent.GasLimit,
+			Time:       uint64(now + tt.offset),
+		}
+		header.Difficulty = CalcDifficulty(config, header.Time, parent)
+		if err := engine.verifyHeader(chain, header, parent, false, false, now); err != tt.err {
+			t.Errorf("offset %d: verification error mismatch: have %v, want %v", tt.offset, err, tt.err)
+		}
+		// Uncles are exempt from the future block check.
+		if err := engine.verifyHeader(chain, header, parent, true, false, now); err != nil {
+			t.Errorf("offset %d: uncle verification failed: %v", tt.offset, err)
+		}
+	}
+}
+
+func TestVerifyHeaderFutureAllowance(t *testing.T) {
+	config := &params.ChainConfig{
+		ChainID:        big.NewInt(1),
+		HomesteadBlock: big.NewInt(0),
+		ByzantiumBlock: big.NewInt(0),
+		Ethash:         new(params.EthashConfig),
+	}
+	var (
+		chain  = &configChainReader{config: config}
+		now    = int64(1000000)
+		parent = &types.Header{
+			UncleHash:  types.EmptyUncleHash,
+			Difficulty: big.NewInt(0xffffff),
+			Number:     big.NewInt(100),
+			GasLimit:   8000000,
+			Time:       uint64(now),
+		}
+		header = &types.Header{
+			ParentHash: parent.Hash(),
+			UncleHash:  types.EmptyUncleHash,
+			Number:     big.NewInt(101),
+			GasLimit:   parent.GasLimit,
+			Time:       uint64(now + 10),
+		}
+	)
+	header.Difficulty = CalcDifficulty(config, header.Time, parent)
+
+	for _, tt := range []struct {
+		name   string
+		engine *Ethash
+		err    error
+	}{
+		{"normal", New(Config{}, nil, false), consensus.ErrFutureBlock},
+		{"fake", NewFaker(), nil},
+		{"allowance", New(Config{AllowedFutureBlockTime: 15}, nil, false), nil},
+		{"short allowance", New(Config{AllowedFutureBlockTime: 5}, nil, false), consensus.ErrFutureBlock},
+	} {
+		if err := tt.engine.verifyHeader(chain, header, parent, false, false, now); err != tt.err {
+			t.Errorf("%s: verification error mismatch: have %v, want %v", tt.name, err, tt.err)
+		}
+		tt.engine.Close()
+	}
+}
+
+func TestVerifyHeaderEqualTimestamps(t *testing.T) {
+	engine := NewFaker()
+	defer engine.Close()
+
+	parent := &types.Header{
+		UncleHash:  types.EmptyUncleHash,
+		Difficulty: big.NewInt(0xffffff),
+		Number:     big.NewInt(100),
+		GasLimit:   8000000,
+		Time:       1000000,
+	}
+	for _, tt := range []struct {
+		allow bool
+		delta int64
+		err   error
+	}{
+		{false, 1, nil},
+		{false, 0, errOlderBlockTime},
+		{false, -1, errOlderBlockTime},
+		{true, 1, nil},
+		{true, 0, nil},
+		{true, -1, errOlderBlockTime},
+	} {
+		config := &params.ChainConfig{
+			ChainID:        big.NewInt(1),
+			HomesteadBlock: big.NewInt(0),
+			ByzantiumBlock: big.NewInt(0),
+			Ethash:         &params.EthashConfig{AllowEqualTimestamps: tt.allow},
+		}
+		header := &types.Header{
+			ParentHash: parent.Hash(),
+			UncleHash:  types.EmptyUncleHash,
+			Number:     big.NewInt(101),
+			GasLimit:   parent.GasLimit,
+			Time:       uint64(int64(parent.Time) + tt.delta),
+		}
+		header.Difficulty = CalcDifficulty(config, header.Time, parent)
+		if err := engine.verifyHeader(&configChainReader{config: config}, header, parent, false, false, int64(parent.Time)); err != tt.err {
+			t.Errorf("allow %v, delta %d: verification error mismatch: have %v, want %v", tt.allow, tt.delta, err, tt.err)
+		}
+	}
+}
+
+// Tests that the reconciled supply matches the scheduled one, apart from the
+// base fees burnt by the included transactions.
+func TestReconcileSupply(t *testing.T) {
+	// Split the premine over a few genesis accounts
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		share   = new(big.Int).Div(preminedSupply, big.NewInt(4))
+		genesis = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: core.GenesisAlloc{
+				addr:                 {Balance: share},
+				common.Address{0x01}: {Balance: share},
+				common.Address{0x02}: {Balance: share},
+				common.Address{0x03}: {Balance: new(big.Int).Sub(preminedSupply, new(big.Int).Mul(share, big.NewInt(3)))},
+			},
+		}
+		signer = types.LatestSigner(genesis.Config)
+		burnt  = new(big.Int)
+	)
+	// Mine a few empty blocks, followed by some with transfers burning fees
+	db, blocks, receipts := core.GenerateChainWithGenesis(genesis, NewFaker(), 6, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{0xaa, byte(i)})
+		if i >= 3 {
+			tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(addr), common.Address{0xbb}, big.NewInt(1000), params.TxGas, b.BaseFee(), nil), signer, key)
+			b.AddTx(tx)
+		}
+	})
+	for i, block := range blocks {
+		for _, receipt := range receipts[i] {
+			burnt.Add(burnt, new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), block.BaseFee()))
+		}
+		statedb, err := state.New(block.Root(), state.NewDatabase(db), nil)
+		if err != nil {
+			t.Fatalf("block %d: failed to open state: %v", block.NumberU64(), err)
+		}
+		scheduled, actual, diff := ReconcileSupply(statedb, block.NumberU64())
+		if want := CalculateCirculatingSupply(block.NumberU64()); scheduled.Cmp(want) != 0 {
+			t.Errorf("block %d: scheduled supply mismatch: have %v, want %v", block.NumberU64(), scheduled, want)
+		}
+		if want := new(big.Int).Sub(scheduled, burnt); actual.Cmp(want) != 0 {
+			t.Errorf("block %d: actual supply mismatch: have %v, want %v", block.NumberU64(), actual, want)
+		}
+		if want := new(big.Int).Neg(burnt); diff.Cmp(want) != 0 {
+			t.Errorf("block %d: supply discrepancy mismatch: have %v, want %v", block.NumberU64(), diff, want)
+		}
+	}
+	if burnt.Sign() == 0 {
+		t.Fatal("no fees burnt")
+	}
+}
+
+// newUncleTestChain creates a chain of three blocks, the last of which includes
+// an uncle, and returns it along with the blocks.
+func newUncleTestChain(tb testing.TB) (*core.BlockChain, []*types.Block) {
+	genesis := &core.Genesis{Config: params.TestChainConfig}
+	_, blocks, _ := core.GenerateChainWithGenesis(genesis, NewFaker(), 3, func(i int, b *core.BlockGen) {
+		if i == 2 {
+			uncle := b.PrevBlock(1).Header()
+			uncle.Extra = []byte("uncle")
+			b.AddUncle(uncle)
+		}
+	})
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), nil, genesis, nil, NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		tb.Fatalf("failed to create chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks[:2]); err != nil {
+		tb.Fatalf("failed to insert chain: %v", err)
+	}
+	return chain, blocks
+}
+
+// Tests that blocks claiming to have no uncles skip the uncle verification, but
+// are still rejected if their body contains some.
+func TestVerifyUnclesEmptyUncleHash(t *testing.T) {
+	chain, blocks := newUncleTestChain(t)
+	defer chain.Stop()
+
+	engine := NewFaker()
+	block := blocks[2]
+	if err := engine.VerifyUncles(chain, block); err != nil {
+		t.Fatalf("failed to verify uncles: %v", err)
+	}
+	if err := chain.Validator().ValidateBody(block); err != nil {
+		t.Fatalf("failed to validate body: %v", err)
+	}
+	// Claim no uncles in the header while keeping more of them than allowed in
+	// the body, which only the body validation can catch.
+	header := block.Header()
+	header.UncleHash = types.EmptyUncleHash
+	uncles := []*types.Header{block.Uncles()[0], block.Uncles()[0], block.Uncles()[0]}
+	forged := types.NewBlockWithHeader(header).WithBody(block.Transactions(), uncles)
+
+	if err := engine.VerifyUncles(chain, forged); err != nil {
+		t.Fatalf("uncles of empty uncle hash block verified: %v", err)
+	}
+	if err := chain.Validator().ValidateBody(forged); err == nil || !strings.Contains(err.Error(), "uncle root hash mismatch") {
+		t.Fatalf("body of empty uncle hash block with uncles: have %v, want uncle root hash mismatch", err)
+	}
+}
+
+// Tests that the configured maximum uncle count only caps the uncles the local
+// miner includes, while the uncle verification keeps to the protocol limit.
+func TestVerifyUnclesMaxUncles(t *testing.T) {
+	chain, blocks := newUncleTestChain(t)
+	defer chain.Stop()
+
+	if have := NewFaker().MaxUncles(); have != maxUncles {
+		t.Errorf("default limit mismatch: have %d, want %d", have, maxUncles)
+	}
+	for _, local := range []int{0, 1, 5} {
+		local := local
+		engine := New(Config{PowMode: ModeFake, MaxUncles: &local}, nil, false)
+		defer engine.Close()
+
+		if err := engine.VerifyUncles(chain, blocks[2]); err != nil {
+			t.Errorf("local %d: failed to verify uncles: %v", local, err)
+		}
+		want := local
+		if want > maxUncles {
+			want = maxUncles
+		}
+		if have := engine.MaxUncles(); have != want {
+			t.Errorf("local %d: inclusion limit mismatch: have %d, want %d", local, have, want)
+		}
+	}
+}
+
+func BenchmarkVerifyUncles(b *testing.B) {
+	chain, blocks := newUncleTestChain(b)
+	defer chain.Stop()
+
+	engine := NewFaker()
+	empty, uncled := blocks[1], blocks[2]
+	b.Run("empty-uncle-hash", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			engine.VerifyUncles(chain, empty)
+		}
+	})
+	b.Run("one-uncle", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			engine.VerifyUncles(chain, uncled)
 		}
 	})
 }
@@ -13,10 +13,12 @@
 package ethash
 
 import (
+	"fmt"
 	"math/big"
 
 	"github.com/holiman/uint256"
 	"github.com/r5-labs/r5-core/client/core/types"
+	"github.com/r5-labs/r5-core/client/params"
 )
 
 const (
@@ -130,3 +132,45 @@ func MakeDifficultyCalculatorU256(bombDelay *big.Int) func(time uint64, parent *
 		return y.ToBig()
 	}
 }
+
+// DifficultyToTarget returns the proof-of-work target of the given difficulty,
+// 2^256 / difficulty, which a sealed block's hash must not exceed.
+func DifficultyToTarget(difficulty *big.Int) *big.Int {
+	return new(big.Int).Div(two256, difficulty)
+}
+
+// TargetToDifficulty is the inverse of DifficultyToTarget, returning the
+// difficulty 2^256 / target of the given proof-of-work target.
+func TargetToDifficulty(target *big.Int) *big.Int {
+	return new(big.Int).Div(two256, target)
+}
+
+// VerifyDifficultyTransition checks a child difficulty against the difficulty
+// adjustment of the given chain. Besides matching the recomputed difficulty, the
+// value must never drop below the minimum difficulty of the chain, and never
+// move further than 99/2048 of the parent difficulty unless clamped to it.
+//
+// The method is exported for fuzzing the difficulty invariants.
+func VerifyDifficultyTransition(config *params.ChainConfig, parent *types.Header, time uint64, got *big.Int) error {
+	if time < parent.Time {
+		return errOlderBlockTime
+	}
+	if parent.Difficulty.Sign() <= 0 || parent.Difficulty.BitLen() > 256 {
+		return fmt.Errorf("invalid parent difficulty %v", parent.Difficulty)
+	}
+	if want := CalcDifficulty(config, time, parent); got.Cmp(want) != 0 {
+		return fmt.Errorf("difficulty mismatch: have %v, want %v", got, want)
+	}
+	floor := difficultyFloor(config)
+	if got.Cmp(floor) < 0 {
+		return fmt.Errorf("difficulty %v below minimum %v", got, floor)
+	}
+	bound := new(big.Int).Div(parent.Difficulty, params.DifficultyBoundDivisor)
+	bound.Mul(bound, big.NewInt(99))
+
+	delta := new(big.Int).Sub(got, parent.Difficulty)
+	if delta.CmpAbs(bound) > 0 && got.Cmp(floor) != 0 {
+		return fmt.Errorf("difficulty adjustment %v exceeds bound %v", delta, bound)
+	}
+	return nil
+}
@@ -224,6 +224,16 @@ func (lru *lru[T]) get(epoch uint64) (item, future T) {
 	return item, future
 }
 
+// purge drops all the items from the cache, including the future item.
+func (lru *lru[T]) purge() {
+	lru.mu.Lock()
+	defer lru.mu.Unlock()
+
+	var empty T
+	lru.cache.Purge()
+	lru.future, lru.futureItem = 0, empty
+}
+
 // cache wraps an ethash cache with some metadata to allow easier concurrent use.
 type cache struct {
 	epoch uint64    // Epoch for which this cache is relevant
@@ -310,6 +320,8 @@ type dataset struct {
 	dataset []uint32    // The actual cache data content
 	once    sync.Once   // Ensures the cache is generated only once
 	done    atomic.Bool // Atomic flag to determine generation status
+
+	progress func(percent uint64) // Optional callback reporting the generation progress
 }
 
 // newDataset creates a new ethash mining dataset and returns it as a plain Go
@@ -337,7 +349,7 @@ func (d *dataset) generate(dir string, limit int, lock bool, test bool) {
 			generateCache(cache, d.epoch, seed)
 
 			d.dataset = make([]uint32, dsize/4)
-			generateDataset(d.dataset, d.epoch, cache)
+			generateDatasetWithProgress(d.dataset, d.epoch, cache, d.progress)
 
 			return
 		}
@@ -358,6 +370,9 @@ func (d *dataset) generate(dir string, limit int, lock bool, test bool) {
 		d.dump, d.mmap, d.dataset, err = memoryMap(path, lock)
 		if err == nil {
 			logger.Debug("Loaded old ethash dataset from disk")
+			if d.progress != nil {
+				d.progress(100)
+			}
 			return
 		}
 		logger.Debug("Failed to load old ethash dataset", "err", err)
@@ -366,12 +381,12 @@ func (d *dataset) generate(dir string, limit int, lock bool, test bool) {
 		cache := make([]uint32, csize/4)
 		generateCache(cache, d.epoch, seed)
 
-		d.dump, d.mmap, d.dataset, err = memoryMapAndGenerate(path, dsize, lock, func(buffer []uint32) { generateDataset(buffer, d.epoch, cache) })
+		d.dump, d.mmap, d.dataset, err = memoryMapAndGenerate(path, dsize, lock, func(buffer []uint32) { generateDatasetWithProgress(buffer, d.epoch, cache, d.progress) })
 		if err != nil {
 			logger.Error("Failed to generate mapped ethash dataset", "err", err)
 
 			d.dataset = make([]uint32, dsize/4)
-			generateDataset(d.dataset, d.epoch, cache)
+			generateDatasetWithProgress(d.dataset, d.epoch, cache, d.progress)
 		}
 		// Iterate over all previous instances and delete old ones
 		for ep := int(d.epoch) - limit; ep >= 0; ep-- {
@@ -406,7 +421,14 @@ func MakeCache(block uint64, dir string) {
 
 // MakeDataset generates a new ethash dataset and optionally stores it to disk.
 func MakeDataset(block uint64, dir string) {
-	d := dataset{epoch: block / epochLength}
+	MakeDatasetWithProgress(block, dir, nil)
+}
+
+// MakeDatasetWithProgress is identical to MakeDataset, but reports the progress
+// of the generation through the given callback as a percentage. A dataset found
+// on disk is reported as complete right away.
+func MakeDatasetWithProgress(block uint64, dir string, progress func(percent uint64)) {
+	d := dataset{epoch: block / epochLength, progress: progress}
 	d.generate(dir, math.MaxInt32, false, false)
 }
 
@@ -433,6 +455,14 @@ type Config struct {
 	DatasetsLockMmap bool
 	PowMode          Mode
 
+	// Seconds a block timestamp may be ahead of the local clock before
+	// the block is considered a future block, 0 for the chain default.
+	AllowedFutureBlockTime int64
+
+	// Maximum number of uncles the local miner includes in a block, nil for
+	// the protocol limit of 2. Setting it to 0 stops including uncles.
+	MaxUncles *int `toml:",omitempty"`
+
 	// When set, notifications sent by the remote sealer will
 	// be block header JSON objects instead of work package arrays.
 	NotifyFull bool
@@ -456,9 +486,14 @@ type Ethash struct {
 	remote   *remoteSealer
 
 	// The fields below are hooks for testing
-	shared    *Ethash       // Shared PoW verifier to avoid cache regeneration
-	fakeFail  uint64        // Block number which fails PoW check even in fake mode
-	fakeDelay time.Duration // Time delay to sleep for before returning from verify
+	shared       *Ethash       // Shared PoW verifier to avoid cache regeneration
+	fakeFailFrom uint64        // First block number which fails PoW check even in fake mode
+	fakeFailTo   uint64        // Last block number which fails PoW check even in fake mode
+	fakeDelay    time.Duration // Time delay to sleep for before returning from verify
+
+	sharedRefs  atomic.Int32 // Number of open verifiers delegating to this instance
+	releaseOnce sync.Once    // Ensures the reference to the shared instance is released only once
+	closed      bool         // Whether the instance itself was closed, guarded by lock
 
 	lock      sync.Mutex // Ensures thread safety for the in-memory caches and mining fields
 	closeOnce sync.Once  // Ensures exit channel will not be closed twice.
@@ -490,6 +525,7 @@ func New(config Config, notify []string, noverify bool) *Ethash {
 	}
 	if config.PowMode == ModeShared {
 		ethash.shared = sharedEthash
+		sharedEthash.sharedRefs.Add(1)
 	}
 	ethash.remote = startRemoteSealer(ethash, notify, noverify)
 	return ethash
@@ -522,10 +558,21 @@ func NewFakeFailer(fail uint64) *Ethash {
 			PowMode: ModeFake,
 			Log:     log.Root(),
 		},
-		fakeFail: fail,
+		fakeFailFrom: fail,
+		fakeFailTo:   fail,
 	}
 }
 
+// SetFakeFailRange makes all blocks numbered from the first to the last given
+// one, inclusive, fail the PoW check in fake mode, replacing any previously
+// failing blocks. It's meant to simulate sustained seal failures in tests.
+func (ethash *Ethash) SetFakeFailRange(from, to uint64) {
+	ethash.lock.Lock()
+	defer ethash.lock.Unlock()
+
+	ethash.fakeFailFrom, ethash.fakeFailTo = from, to
+}
+
 // NewFakeDelayer creates a ethash consensus engine with a fake PoW scheme that
 // accepts all blocks as valid, but delays verifications by some time, though
 // they still have to conform to the Ethereum consensus rules.
@@ -553,11 +600,65 @@ func NewFullFaker() *Ethash {
 // NewShared creates a full sized ethash PoW shared between all requesters running
 // in the same process.
 func NewShared() *Ethash {
+	sharedEthash.sharedRefs.Add(1)
 	return &Ethash{shared: sharedEthash}
 }
 
+// NewSharedVerifier creates a lightweight ethash verifier delegating the PoW
+// verification to the full sized ethash shared between all requesters running
+// in the same process, so multiple nodes don't each generate their own caches.
+func NewSharedVerifier() *Ethash {
+	return sharedEthash.SharedVerifier()
+}
+
+// SharedVerifier creates a lightweight ethash verifier delegating the PoW
+// verification to this instance, reusing its caches and datasets. The shared
+// instance is reference counted: its caches are only dropped once it is closed
+// itself and the last of its verifiers is closed too.
+func (ethash *Ethash) SharedVerifier() *Ethash {
+	if ethash.shared != nil {
+		return ethash.shared.SharedVerifier()
+	}
+	ethash.sharedRefs.Add(1)
+	return &Ethash{
+		config: Config{
+			PowMode: ModeShared,
+			Log:     ethash.config.Log,
+		},
+		shared: ethash,
+	}
+}
+
+// release drops a reference to the shared instance, purging its caches and
+// datasets if it was closed and no verifier uses them any more.
+func (ethash *Ethash) release() {
+	ethash.sharedRefs.Add(-1)
+	ethash.purgeUnused()
+}
+
+// purgeUnused drops the caches and datasets of a closed instance once none of
+// its verifiers is open any more.
+func (ethash *Ethash) purgeUnused() {
+	ethash.lock.Lock()
+	defer ethash.lock.Unlock()
+
+	if !ethash.closed || ethash.sharedRefs.Load() > 0 || ethash.caches == nil {
+		return
+	}
+	ethash.caches.purge()
+	ethash.datasets.purge()
+}
+
 // Close closes the exit channel to notify all backend threads exiting.
 func (ethash *Ethash) Close() error {
+	if ethash.shared != nil {
+		ethash.releaseOnce.Do(ethash.shared.release)
+	} else {
+		ethash.lock.Lock()
+		ethash.closed = true
+		ethash.lock.Unlock()
+		ethash.purgeUnused()
+	}
 	return ethash.StopRemoteSealer()
 }
 
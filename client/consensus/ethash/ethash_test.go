@@ -17,6 +17,7 @@ import (
 	"math/rand"
 	"os"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -49,6 +50,33 @@ func TestTestMode(t *testing.T) {
 	}
 }
 
+// Tests that fake failers reject the seals of the configured blocks only.
+func TestFakeFailRange(t *testing.T) {
+	ethash := NewFakeFailer(5)
+	defer ethash.Close()
+
+	check := func(from, to uint64) {
+		for number := uint64(1); number <= 10; number++ {
+			header := &types.Header{Number: new(big.Int).SetUint64(number), Difficulty: big.NewInt(100)}
+			err := ethash.verifySeal(nil, header, false)
+			if fail := number >= from && number <= to; fail && err != errInvalidPoW {
+				t.Errorf("range [%d, %d]: block %d: error mismatch: have %v, want %v", from, to, number, err, errInvalidPoW)
+			} else if !fail && err != nil {
+				t.Errorf("range [%d, %d]: block %d: unexpected error: %v", from, to, number, err)
+			}
+		}
+	}
+	// A single failing block keeps working as before
+	check(5, 5)
+
+	// Ranges replace the previously failing blocks
+	ethash.SetFakeFailRange(3, 7)
+	check(3, 7)
+
+	ethash.SetFakeFailRange(9, 9)
+	check(9, 9)
+}
+
 // This test checks that cache lru logic doesn't crash under load.
 // It reproduces https://github.com/r5-labs/r5-core/client/issues/14943
 func TestCacheFileEvict(t *testing.T) {
@@ -132,6 +160,41 @@ func TestRemoteSealer(t *testing.T) {
 	}
 }
 
+func TestRemoteSealerDetailedWork(t *testing.T) {
+	ethash := NewTester(nil, false)
+	defer ethash.Close()
+
+	api := &API{ethash}
+	if _, err := api.GetWorkDetailed(); err != errNoMiningWork {
+		t.Error("expect to return an error indicate there is no mining work")
+	}
+	for _, number := range []uint64{1, epochLength - 1, epochLength, 3*epochLength + 17} {
+		header := &types.Header{Number: new(big.Int).SetUint64(number), Difficulty: big.NewInt(100)}
+		ethash.Seal(nil, types.NewBlockWithHeader(header), make(chan *types.Block), nil)
+
+		work, err := api.GetWork()
+		if err != nil {
+			t.Fatalf("block %d: failed to get work: %v", number, err)
+		}
+		detailed, err := api.GetWorkDetailed()
+		if err != nil {
+			t.Fatalf("block %d: failed to get detailed work: %v", number, err)
+		}
+		if detailed.HeaderHash != ethash.SealHash(header) || detailed.HeaderHash.Hex() != work[0] {
+			t.Errorf("block %d: header hash mismatch: have %x, want %x", number, detailed.HeaderHash, ethash.SealHash(header))
+		}
+		if detailed.SeedHash.Hex() != work[1] || detailed.Target.Hex() != work[2] {
+			t.Errorf("block %d: seed or target mismatch with the plain work package", number)
+		}
+		if uint64(detailed.BlockNumber) != number {
+			t.Errorf("block %d: number mismatch: have %d", number, detailed.BlockNumber)
+		}
+		if uint64(detailed.Epoch) != number/epochLength {
+			t.Errorf("block %d: epoch mismatch: have %d, want %d", number, detailed.Epoch, number/epochLength)
+		}
+	}
+}
+
 func TestHashrate(t *testing.T) {
 	var (
 		hashrate = []hexutil.Uint64{100, 200, 300}
@@ -171,3 +234,77 @@ func TestClosedRemoteSealer(t *testing.T) {
 		t.Error("expect to return false when submit hashrate to a stopped ethash")
 	}
 }
+
+func TestSharedVerifier(t *testing.T) {
+	base := NewTester(nil, false)
+	defer base.Close()
+
+	// Track the cache generations of the shared base.
+	var generated atomic.Int32
+	base.caches.new = func(epoch uint64) *cache {
+		if epoch == 0 {
+			generated.Add(1)
+		}
+		return newCache(epoch)
+	}
+	header := &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(100)}
+
+	results := make(chan *types.Block)
+	if err := base.Seal(nil, types.NewBlockWithHeader(header), results, nil); err != nil {
+		t.Fatalf("failed to seal block: %v", err)
+	}
+	select {
+	case block := <-results:
+		header.Nonce = types.EncodeNonce(block.Nonce())
+		header.MixDigest = block.MixDigest()
+	case <-time.NewTimer(4 * time.Second).C:
+		t.Fatal("sealing result timeout")
+	}
+	first, second := base.SharedVerifier(), base.SharedVerifier()
+	third := first.SharedVerifier()
+	for i, verifier := range []*Ethash{first, second, third} {
+		if verifier.shared != base {
+			t.Fatalf("verifier %d: not delegating to the shared base", i)
+		}
+		if err := verifier.verifySeal(nil, header, false); err != nil {
+			t.Fatalf("verifier %d: unexpected verification error: %v", i, err)
+		}
+	}
+	if n := generated.Load(); n != 1 {
+		t.Fatalf("cache generation count mismatch: have %d, want 1", n)
+	}
+	if refs := base.sharedRefs.Load(); refs != 3 {
+		t.Fatalf("reference count mismatch: have %d, want 3", refs)
+	}
+	// Closing a verifier (even repeatedly) must keep the caches of the others.
+	first.Close()
+	first.Close()
+	if err := second.verifySeal(nil, header, false); err != nil {
+		t.Fatalf("unexpected verification error after close: %v", err)
+	}
+	if n := generated.Load(); n != 1 {
+		t.Fatalf("cache regenerated after closing a verifier: %d generations", n)
+	}
+	if refs := base.sharedRefs.Load(); refs != 2 {
+		t.Fatalf("reference count mismatch after close: have %d, want 2", refs)
+	}
+	// Closing the last verifier must keep the caches of the still open base.
+	second.Close()
+	third.Close()
+	if err := base.verifySeal(nil, header, false); err != nil {
+		t.Fatalf("unexpected verification error after closing all verifiers: %v", err)
+	}
+	if n := generated.Load(); n != 1 {
+		t.Fatalf("cache regenerated after closing all verifiers: %d generations", n)
+	}
+	// Closing the base before its last verifier defers dropping the caches.
+	fourth := base.SharedVerifier()
+	base.Close()
+	if n := base.caches.cache.Len(); n == 0 {
+		t.Fatal("shared caches dropped while a verifier is open")
+	}
+	fourth.Close()
+	if n := base.caches.cache.Len(); n != 0 {
+		t.Fatalf("shared caches retained after closing the base and all verifiers: %d", n)
+	}
+}
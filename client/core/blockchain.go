@@ -132,6 +132,7 @@ type CacheConfig struct {
 	TrieTimeLimit       time.Duration // Time limit after which to flush the current in-memory trie to disk
 	SnapshotLimit       int           // Memory allowance (MB) to use for caching snapshot entries in memory
 	Preimages           bool          // Whether to store preimage of trie key to the disk
+	MaxReorgDepth       uint64        // Maximum number of canonical blocks a reorg may drop (0 = unlimited)
 
 	SnapshotNoBuild bool // Whether the background generation is allowed
 	SnapshotWait    bool // Wait for snapshot construction on startup. TODO(karalabe): This is a dirty hack for testing, nuke it
@@ -145,6 +146,7 @@ var defaultCacheConfig = &CacheConfig{
 	TrieTimeLimit:  5 * time.Minute,
 	SnapshotLimit:  256,
 	SnapshotWait:   true,
+	MaxReorgDepth:  3 * params.FullImmutabilityThreshold,
 }
 
 // BlockChain represents the canonical chain given a database with a genesis
@@ -1337,6 +1339,12 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 	// Make sure no inconsistent state is leaked during insertion
 	externTd := new(big.Int).Add(block.Difficulty(), ptd)
 
+	// Receipts might already be persisted for the block, e.g. if it's being
+	// re-executed after a reorg. Flag them if they diverge from the fresh ones
+	// before overwriting.
+	if err := bc.verifyStoredReceipts(block, receipts); err != nil {
+		log.Error("Stored receipts mismatch", "number", block.Number(), "hash", block.Hash(), "err", err)
+	}
 	// Irrelevant of the canonical status, write the block itself to the database.
 	//
 	// Note all the components of block(td, hash->number map, header, body, receipts)
@@ -1479,8 +1487,8 @@ func (bc *BlockChain) addFutureBlock(block *types.Block) error {
 		return fmt.Errorf("future block timestamp %v > allowed %v", block.Time(), max)
 	}
 	if block.Difficulty().Cmp(common.Big0) == 0 {
-		// Never add PoS blocks into the future queue
-		return nil
+		// Never add PoS blocks into the future queue, reject them instead
+		return fmt.Errorf("%w: timestamp %v", consensus.ErrFutureBlock, block.Time())
 	}
 	bc.futureBlocks.Add(block.Hash(), block)
 	return nil
@@ -1725,7 +1733,7 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals, setHead bool)
 		}
 
 		// Enable prefetching to pull in trie node paths while processing transactions
-		statedb.StartPrefetcher("chain")
+		statedb.StartPrefetcher("chain", 1)
 		activeState = statedb
 
 		// If we have a followup block, run that against the current state to pre-cache
@@ -2041,6 +2049,30 @@ func (bc *BlockChain) recoverAncestors(block *types.Block) (common.Hash, error)
 	return block.Hash(), nil
 }
 
+// verifyStoredReceipts checks the receipts persisted for the given block against
+// the ones produced by processing it. Only the consensus fields stored on disk
+// can go stale, the contextual ones (e.g. effective gas prices or log indices)
+// are derived from the block on every read. Nothing is checked if there are no
+// receipts persisted for the block.
+func (bc *BlockChain) verifyStoredReceipts(block *types.Block, receipts types.Receipts) error {
+	stored := rawdb.ReadRawReceipts(bc.db, block.Hash(), block.NumberU64())
+	if stored == nil {
+		return nil
+	}
+	if len(stored) != len(receipts) {
+		return fmt.Errorf("receipt count mismatch (have %d, want %d)", len(stored), len(receipts))
+	}
+	for i, receipt := range stored {
+		if receipt.CumulativeGasUsed != receipts[i].CumulativeGasUsed {
+			return fmt.Errorf("receipt %d: cumulative gas mismatch (have %d, want %d)", i, receipt.CumulativeGasUsed, receipts[i].CumulativeGasUsed)
+		}
+		if len(receipt.Logs) != len(receipts[i].Logs) {
+			return fmt.Errorf("receipt %d: log count mismatch (have %d, want %d)", i, len(receipt.Logs), len(receipts[i].Logs))
+		}
+	}
+	return nil
+}
+
 // collectLogs collects the logs that were generated or removed during
 // the processing of a block. These logs are later announced as deleted or reborn.
 func (bc *BlockChain) collectLogs(b *types.Block, removed bool) []*types.Log {
@@ -2127,6 +2159,13 @@ func (bc *BlockChain) reorg(oldHead *types.Header, newHead *types.Block) error {
 		}
 	}
 
+	// Refuse reorgs deeper than the configured safety bound, they are almost
+	// certainly an attack or a bug rather than a legitimate fork.
+	if limit := bc.cacheConfig.MaxReorgDepth; limit > 0 && uint64(len(oldChain)) > limit {
+		log.Error("Rejected deep chain reorg", "number", commonBlock.Number(), "hash", commonBlock.Hash(),
+			"drop", len(oldChain), "limit", limit, "add", len(newChain))
+		return fmt.Errorf("%w: dropping %d blocks, limit %d", ErrReorgTooDeep, len(oldChain), limit)
+	}
 	// Ensure the user sees large reorgs
 	if len(oldChain) > 0 && len(newChain) > 0 {
 		logFn := log.Info
@@ -18,6 +18,7 @@ import (
 	"math/big"
 	"math/rand"
 	"os"
+	"reflect"
 	"sync"
 	"testing"
 	"time"
@@ -34,6 +35,7 @@ import (
 	"github.com/r5-labs/r5-core/client/crypto"
 	"github.com/r5-labs/r5-core/client/eth/tracers/logger"
 	"github.com/r5-labs/r5-core/client/ethdb"
+	"github.com/r5-labs/r5-core/client/log"
 	"github.com/r5-labs/r5-core/client/params"
 	"github.com/r5-labs/r5-core/client/trie"
 )
@@ -2473,6 +2475,53 @@ func TestReorgToShorterRemovesCanonMapping(t *testing.T) {
 	}
 }
 
+// Tests that reorgs dropping more canonical blocks than the configured maximum
+// reorg depth are rejected, while shallower ones are accepted.
+func TestReorgDepthLimit(t *testing.T) {
+	engine := ethash.NewFaker()
+	genesis := &Genesis{
+		Config:  params.TestChainConfig,
+		BaseFee: big.NewInt(params.InitialBaseFee),
+	}
+	genDb, canon, _ := GenerateChainWithGenesis(genesis, engine, 10, nil)
+
+	// Generate two competing chains outweighing the canonical one, forking off
+	// at blocks 8 and 4 respectively.
+	fork := func(parent *types.Block, n int) []*types.Block {
+		blocks, _ := GenerateChain(genesis.Config, parent, engine, genDb, n, func(i int, b *BlockGen) {
+			b.SetCoinbase(common.Address{0x01})
+		})
+		return blocks
+	}
+	shallow := fork(canon[7], 4)
+	deep := fork(canon[3], 10)
+
+	cacheConfig := *defaultCacheConfig
+	cacheConfig.MaxReorgDepth = 4
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), &cacheConfig, genesis, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if n, err := chain.InsertChain(canon); err != nil {
+		t.Fatalf("block %d: failed to insert canonical chain: %v", n, err)
+	}
+	if n, err := chain.InsertChain(shallow); err != nil {
+		t.Fatalf("block %d: failed to insert shallow fork: %v", n, err)
+	}
+	head := shallow[len(shallow)-1]
+	if have := chain.CurrentBlock().Hash(); have != head.Hash() {
+		t.Fatalf("shallow reorg not applied: head %x, want %x", have, head.Hash())
+	}
+	if _, err := chain.InsertChain(deep); !errors.Is(err, ErrReorgTooDeep) {
+		t.Fatalf("deep reorg error mismatch: have %v, want %v", err, ErrReorgTooDeep)
+	}
+	if have := chain.CurrentBlock().Hash(); have != head.Hash() {
+		t.Fatalf("head changed by rejected reorg: head %x, want %x", have, head.Hash())
+	}
+}
+
 // TestReorgToShorterRemovesCanonMappingHeaderChain is the same scenario
 // as TestReorgToShorterRemovesCanonMapping, but applied on headerchain
 // imports -- that is, for fast sync
@@ -4334,3 +4383,159 @@ func TestEIP3651(t *testing.T) {
 		t.Fatalf("sender balance incorrect: expected %d, got %d", expected, actual)
 	}
 }
+
+// Tests that a block stamped too far in the future is rejected alike by the
+// proof-of-work header verification and by the beacon import paths, all of them
+// enforcing the shared future block window.
+func TestFutureBlockRejection(t *testing.T) {
+	ahead := uint64(time.Now().Unix()) + 20
+
+	// Proof-of-work blocks are verified by ethash, the fake engines are exempt
+	// from the timestamp check so the header is verified by a tester engine.
+	gspec := &Genesis{Config: params.TestChainConfig, BaseFee: big.NewInt(params.InitialBaseFee)}
+	_, blocks, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 1, func(i int, gen *BlockGen) {
+		gen.OffsetTime(int64(ahead - gen.header.Time))
+	})
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	engine := ethash.NewTester(nil, false)
+	defer engine.Close()
+	if err := engine.VerifyHeader(chain, blocks[0].Header(), false); !errors.Is(err, consensus.ErrFutureBlock) {
+		t.Errorf("proof-of-work header: error mismatch: have %v, want %v", err, consensus.ErrFutureBlock)
+	}
+	// Proof-of-stake blocks are verified by the beacon engine, both when inserted
+	// by the downloader and when handed over by the consensus client.
+	gspec = &Genesis{Config: params.AllEthashProtocolChanges, BaseFee: big.NewInt(params.InitialBaseFee)}
+	config := *gspec.Config
+	config.TerminalTotalDifficulty = common.Big0
+	config.TerminalTotalDifficultyPassed = true
+	gspec.Config = &config
+
+	posEngine := beacon.New(ethash.NewFaker())
+	_, blocks, _ = GenerateChainWithGenesis(gspec, posEngine, 1, func(i int, gen *BlockGen) {
+		// OffsetTime would recalculate a proof-of-work difficulty.
+		gen.header.Time = ahead
+		gen.header.Difficulty = common.Big0
+	})
+	chain, err = NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, posEngine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if err := posEngine.VerifyHeader(chain, blocks[0].Header(), false); !errors.Is(err, consensus.ErrFutureBlock) {
+		t.Errorf("proof-of-stake header: error mismatch: have %v, want %v", err, consensus.ErrFutureBlock)
+	}
+	if _, err := chain.InsertChain(blocks); !errors.Is(err, consensus.ErrFutureBlock) {
+		t.Errorf("chain insertion: error mismatch: have %v, want %v", err, consensus.ErrFutureBlock)
+	}
+	if err := chain.InsertBlockWithoutSetHead(blocks[0]); !errors.Is(err, consensus.ErrFutureBlock) {
+		t.Errorf("beacon insertion: error mismatch: have %v, want %v", err, consensus.ErrFutureBlock)
+	}
+	if chain.HasBlock(blocks[0].Hash(), blocks[0].NumberU64()) {
+		t.Error("future block imported")
+	}
+	// The future block allowance configured for ethash applies to the beacon
+	// engine wrapping it too.
+	lenient := ethash.New(ethash.Config{PowMode: ethash.ModeTest, AllowedFutureBlockTime: 30}, nil, false)
+	defer lenient.Close()
+	if err := beacon.New(lenient).VerifyHeader(chain, blocks[0].Header(), false); err != nil {
+		t.Errorf("proof-of-stake header within the configured allowance rejected: %v", err)
+	}
+}
+
+// Tests that receipts already persisted for a block are flagged when they don't
+// match the ones produced by importing it, and are replaced by the latter.
+func TestStaleStoredReceipts(t *testing.T) {
+	var (
+		key, _  = crypto.GenerateKey()
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		logAddr = common.HexToAddress("0x10")
+		config  = params.TestChainConfig
+		signer  = types.LatestSigner(config)
+		engine  = ethash.NewFaker()
+		gspec   = &Genesis{
+			Config: config,
+			Alloc: GenesisAlloc{
+				addr:    {Balance: big.NewInt(params.Ether)},
+				logAddr: {Balance: new(big.Int), Code: common.FromHex("60006000a0")}, // LOG0(0, 0)
+			},
+		}
+	)
+	_, blocks, receipts := GenerateChainWithGenesis(gspec, engine, 4, func(i int, gen *BlockGen) {
+		for j := 0; j < 2; j++ {
+			gen.AddTx(types.MustSignNewTx(key, signer, &types.DynamicFeeTx{
+				ChainID:   config.ChainID,
+				Nonce:     gen.TxNonce(addr),
+				To:        &logAddr,
+				Gas:       50000,
+				GasTipCap: big.NewInt(params.GWei),
+				GasFeeCap: big.NewInt(10 * params.GWei),
+			}))
+		}
+	})
+	// Persist matching receipts for the first block and stale ones for the
+	// others, as if their transactions were executed in another block context.
+	db := rawdb.NewMemoryDatabase()
+	rawdb.WriteReceipts(db, blocks[0].Hash(), blocks[0].NumberU64(), receipts[0])
+
+	noLogs := *receipts[1][1]
+	noLogs.Logs = nil
+	rawdb.WriteReceipts(db, blocks[1].Hash(), blocks[1].NumberU64(), types.Receipts{receipts[1][0], &noLogs})
+
+	moreGas := *receipts[2][1]
+	moreGas.CumulativeGasUsed++
+	rawdb.WriteReceipts(db, blocks[2].Hash(), blocks[2].NumberU64(), types.Receipts{receipts[2][0], &moreGas})
+
+	rawdb.WriteReceipts(db, blocks[3].Hash(), blocks[3].NumberU64(), receipts[3][:1])
+
+	flagged := make(map[uint64]string)
+	handler := log.Root().GetHandler()
+	defer log.Root().SetHandler(handler)
+	log.Root().SetHandler(log.FuncHandler(func(r *log.Record) error {
+		if r.Msg != "Stored receipts mismatch" {
+			return nil
+		}
+		var (
+			number uint64
+			reason string
+		)
+		for i := 0; i+1 < len(r.Ctx); i += 2 {
+			switch r.Ctx[i] {
+			case "number":
+				number = r.Ctx[i+1].(*big.Int).Uint64()
+			case "err":
+				reason = r.Ctx[i+1].(error).Error()
+			}
+		}
+		flagged[number] = reason
+		return nil
+	}))
+	chain, err := NewBlockChain(db, nil, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to import chain: %v", err)
+	}
+	want := map[uint64]string{
+		2: "receipt 1: log count mismatch (have 0, want 1)",
+		3: fmt.Sprintf("receipt 1: cumulative gas mismatch (have %d, want %d)", moreGas.CumulativeGasUsed, receipts[2][1].CumulativeGasUsed),
+		4: "receipt count mismatch (have 1, want 2)",
+	}
+	if !reflect.DeepEqual(flagged, want) {
+		t.Fatalf("flagged blocks mismatch: have %v, want %v", flagged, want)
+	}
+	// The stale receipts are replaced by the imported ones
+	for i, block := range blocks {
+		if have := chain.GetReceiptsByHash(block.Hash()); len(have) != 2 || len(have[1].Logs) != 1 || have[1].CumulativeGasUsed != receipts[i][1].CumulativeGasUsed {
+			t.Errorf("block %d: stale receipts not overwritten", block.NumberU64())
+		}
+	}
+}
@@ -28,6 +28,10 @@ var (
 	// ErrNoGenesis is returned when there is no Genesis Block.
 	ErrNoGenesis = errors.New("genesis not found in chain")
 
+	// ErrReorgTooDeep is returned if setting a new head would drop more blocks
+	// from the canonical chain than the configured maximum reorg depth.
+	ErrReorgTooDeep = errors.New("reorg too deep")
+
 	errSideChainReceipts = errors.New("side blocks can't be accepted as ancient chain data")
 )
 
@@ -61,18 +65,18 @@ var (
 
 	// ErrMaxInitCodeSizeExceeded is returned if creation transaction provides the init code bigger
 	// than init code size limit.
-	ErrMaxInitCodeSizeExceeded = errors.New("max initcode size exceeded")
+	ErrMaxInitCodeSizeExceeded = types.ErrMaxInitCodeSizeExceeded
 
 	// ErrInsufficientFunds is returned if the total cost of executing a transaction
 	// is higher than the balance of the user's account.
 	ErrInsufficientFunds = errors.New("insufficient funds for gas * price + value")
 
 	// ErrGasUintOverflow is returned when calculating gas usage.
-	ErrGasUintOverflow = errors.New("gas uint64 overflow")
+	ErrGasUintOverflow = types.ErrGasUintOverflow
 
 	// ErrIntrinsicGas is returned if the transaction is specified to use less gas
 	// than required to start the invocation.
-	ErrIntrinsicGas = errors.New("intrinsic gas too low")
+	ErrIntrinsicGas = types.ErrIntrinsicGas
 
 	// ErrTxTypeNotSupported is returned if a transaction is not supported in the
 	// current network configuration.
@@ -80,19 +84,19 @@ var (
 
 	// ErrTipAboveFeeCap is a sanity error to ensure no one is able to specify a
 	// transaction with a tip higher than the total fee cap.
-	ErrTipAboveFeeCap = errors.New("max priority fee per gas higher than max fee per gas")
+	ErrTipAboveFeeCap = types.ErrTipAboveFeeCap
 
 	// ErrTipVeryHigh is a sanity error to avoid extremely big numbers specified
 	// in the tip field.
-	ErrTipVeryHigh = errors.New("max priority fee per gas higher than 2^256-1")
+	ErrTipVeryHigh = types.ErrTipVeryHigh
 
 	// ErrFeeCapVeryHigh is a sanity error to avoid extremely big numbers specified
 	// in the fee cap field.
-	ErrFeeCapVeryHigh = errors.New("max fee per gas higher than 2^256-1")
+	ErrFeeCapVeryHigh = types.ErrFeeCapVeryHigh
 
 	// ErrFeeCapTooLow is returned if the transaction fee cap is less than the
 	// base fee of the block.
-	ErrFeeCapTooLow = errors.New("max fee per gas less than block base fee")
+	ErrFeeCapTooLow = types.ErrFeeCapTooLow
 
 	// ErrSenderNoEOA is returned if the sender of a transaction is a contract.
 	ErrSenderNoEOA = errors.New("sender not an eoa")
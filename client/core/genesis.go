@@ -364,6 +364,9 @@ func SetupGenesisBlockWithOverride(db ethdb.Database, triedb *trie.Database, gen
 	if head == nil {
 		return newcfg, stored, fmt.Errorf("missing head header")
 	}
+	if err := storedcfg.CheckEthashCompatible(newcfg, head.Number.Uint64()); err != nil {
+		return newcfg, stored, err
+	}
 	compatErr := storedcfg.CheckCompatible(newcfg, head.Number.Uint64(), head.Time)
 	if compatErr != nil && ((head.Number.Uint64() != 0 && compatErr.RewindToBlock != 0) || (head.Time != 0 && compatErr.RewindToTime != 0)) {
 		return newcfg, stored, compatErr
@@ -375,11 +378,9 @@ func SetupGenesisBlockWithOverride(db ethdb.Database, triedb *trie.Database, gen
 	return newcfg, stored, nil
 }
 
-// LoadCliqueConfig loads the stored clique config if the chain config
-// is already present in database, otherwise, return the config in the
-// provided genesis specification. Note the returned clique config can
-// be nil if we are not in the clique network.
-func LoadCliqueConfig(db ethdb.Database, genesis *Genesis) (*params.CliqueConfig, error) {
+// LoadChainConfig loads the stored chain config if it is already present in
+// database, otherwise, return the config in the provided genesis specification.
+func LoadChainConfig(db ethdb.Database, genesis *Genesis) (*params.ChainConfig, error) {
 	// Load the stored chain config from the database. It can be nil
 	// in case the database is empty. Notably, we only care about the
 	// chain config corresponds to the canonical chain.
@@ -387,10 +388,10 @@ func LoadCliqueConfig(db ethdb.Database, genesis *Genesis) (*params.CliqueConfig
 	if stored != (common.Hash{}) {
 		storedcfg := rawdb.ReadChainConfig(db, stored)
 		if storedcfg != nil {
-			return storedcfg.Clique, nil
+			return storedcfg, nil
 		}
 	}
-	// Load the clique config from the provided genesis specification.
+	// Load the config from the provided genesis specification.
 	if genesis != nil {
 		// Reject invalid genesis spec without valid chain config
 		if genesis.Config == nil {
@@ -403,12 +404,11 @@ func LoadCliqueConfig(db ethdb.Database, genesis *Genesis) (*params.CliqueConfig
 		if stored != (common.Hash{}) && genesis.ToBlock().Hash() != stored {
 			return nil, &GenesisMismatchError{stored, genesis.ToBlock().Hash()}
 		}
-		return genesis.Config.Clique, nil
+		return genesis.Config, nil
 	}
 	// There is no stored chain config and no new config provided,
-	// In this case the default chain config(mainnet) will be used,
-	// namely ethash is the specified consensus engine, return nil.
-	return nil, nil
+	// In this case the default chain config(mainnet) will be used.
+	return params.MainnetChainConfig, nil
 }
 
 func (g *Genesis) configOrDefault(ghash common.Hash) *params.ChainConfig {
@@ -515,6 +515,9 @@ func (g *Genesis) MustCommit(db ethdb.Database) *types.Block {
 	return block
 }
 
+// mainnetPremineHolder is the account holding the premined supply on the main net.
+var mainnetPremineHolder = common.HexToAddress("0xc657de8D48cAB170e98782815670f8B019005473")
+
 // DefaultGenesisBlock returns the R5 main net genesis block.
 func DefaultGenesisBlock() *Genesis {
 	return &Genesis{
@@ -524,13 +527,42 @@ func DefaultGenesisBlock() *Genesis {
 		GasLimit:   147000000,
 		Difficulty: big.NewInt(1),
 		Alloc: map[common.Address]GenesisAccount{
-			common.HexToAddress("0xc657de8D48cAB170e98782815670f8B019005473"): {
-				Balance: new(big.Int).Mul(big.NewInt(2000000), big.NewInt(1000000000000000000)),
-			},
+			mainnetPremineHolder: {Balance: new(big.Int).Set(params.PreminedSupply)},
 		},
 	}
 }
 
+// DefaultR5GenesisBlock returns an R5 genesis block on the main net rules, with
+// the premined supply allocated to the given accounts. If premine is nil, it's
+// allocated to the main net holder, yielding the main net genesis block. An
+// error is returned if the allocated balances don't sum up to the premined
+// supply exactly.
+func DefaultR5GenesisBlock(premine GenesisAlloc) (*Genesis, error) {
+	genesis := DefaultGenesisBlock()
+	if premine != nil {
+		genesis.Alloc = premine
+	}
+	if err := ValidatePremine(genesis.Alloc); err != nil {
+		return nil, err
+	}
+	return genesis, nil
+}
+
+// ValidatePremine checks that the balances in the genesis allocation sum up to
+// the premined supply the R5 emission schedule is built upon.
+func ValidatePremine(alloc GenesisAlloc) error {
+	total := new(big.Int)
+	for _, account := range alloc {
+		if account.Balance != nil {
+			total.Add(total, account.Balance)
+		}
+	}
+	if total.Cmp(params.PreminedSupply) != 0 {
+		return fmt.Errorf("genesis premine mismatch: have %v, want %v", total, params.PreminedSupply)
+	}
+	return nil
+}
+
 // DefaultRinkebyGenesisBlock returns the Rinkeby network genesis block.
 func DefaultRinkebyGenesisBlock() *Genesis {
 	return &Genesis{
@@ -14,6 +14,7 @@ package core
 
 import (
 	"encoding/json"
+	"math"
 	"math/big"
 	"reflect"
 	"testing"
@@ -159,6 +160,48 @@ func TestSetupGenesis(t *testing.T) {
 	}
 }
 
+// Tests that changing the ethash rules of a chain is only accepted as long as
+// it's still at genesis, and refused with a hard error afterwards.
+func TestSetupGenesisEthashRules(t *testing.T) {
+	config := *params.TestChainConfig
+	config.Ethash = new(params.EthashConfig)
+	oldg := &Genesis{Config: &config, BaseFee: big.NewInt(params.InitialBaseFee)}
+
+	newConfig := config
+	newConfig.Ethash = &params.EthashConfig{MinimumDifficulty: big.NewInt(1000)}
+	newg := &Genesis{Config: &newConfig, BaseFee: big.NewInt(params.InitialBaseFee)}
+
+	// A chain still at genesis takes the new rules over
+	db := rawdb.NewMemoryDatabase()
+	oldg.MustCommit(db)
+	if _, _, err := SetupGenesisBlock(db, trie.NewDatabase(db), newg); err != nil {
+		t.Fatalf("rules change at genesis refused: %v", err)
+	}
+	// A chain past genesis refuses them, keeping the stored ones
+	db = rawdb.NewMemoryDatabase()
+	genesis := oldg.MustCommit(db)
+	bc, err := NewBlockChain(db, nil, oldg, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	blocks, _ := GenerateChain(oldg.Config, genesis, ethash.NewFaker(), db, 2, nil)
+	if _, err := bc.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to import chain: %v", err)
+	}
+	bc.Stop()
+
+	_, _, err = SetupGenesisBlock(db, trie.NewDatabase(db), newg)
+	if err == nil {
+		t.Fatal("rules change past genesis accepted")
+	}
+	if _, ok := err.(*params.ConfigCompatError); ok {
+		t.Fatalf("rules change reported as rewindable: %v", err)
+	}
+	if stored := rawdb.ReadChainConfig(db, genesis.Hash()); stored.Ethash.MinimumDifficulty != nil {
+		t.Errorf("stored rules overwritten: minimum difficulty %v", stored.Ethash.MinimumDifficulty)
+	}
+}
+
 // TestGenesisHashes checks the congruity of default genesis data to
 // corresponding hardcoded genesis hash values.
 func TestGenesisHashes(t *testing.T) {
@@ -182,6 +225,52 @@ func TestGenesisHashes(t *testing.T) {
 	}
 }
 
+func TestDefaultR5GenesisBlock(t *testing.T) {
+	premine := new(big.Int).Mul(big.NewInt(2000000), big.NewInt(1e18))
+
+	// The default premine should reproduce the main net genesis
+	genesis, err := DefaultR5GenesisBlock(nil)
+	if err != nil {
+		t.Fatalf("failed to create default genesis: %v", err)
+	}
+	total := new(big.Int)
+	for _, account := range genesis.Alloc {
+		total.Add(total, account.Balance)
+	}
+	if total.Cmp(premine) != 0 {
+		t.Errorf("premine mismatch: have %v, want %v", total, premine)
+	}
+	if have, want := genesis.ToBlock().Hash(), DefaultGenesisBlock().ToBlock().Hash(); have != want {
+		t.Errorf("genesis hash mismatch: have %x, want %x", have, want)
+	}
+	// The chain must stay on proof-of-work, with the emission schedule ending
+	// exactly at the supply cap on top of the premine.
+	if genesis.Config.TerminalTotalDifficulty != nil || genesis.Config.TerminalTotalDifficultyPassed {
+		t.Errorf("terminal total difficulty set: %v, passed %v", genesis.Config.TerminalTotalDifficulty, genesis.Config.TerminalTotalDifficultyPassed)
+	}
+	if supply := ethash.CalculateCirculatingSupply(math.MaxUint64); supply.Cmp(ethash.SupplyCap) != 0 {
+		t.Errorf("final supply mismatch: have %v, want %v", supply, ethash.SupplyCap)
+	}
+	// Custom premine splits are accepted only if they sum up to the premine
+	half := new(big.Int).Div(premine, big.NewInt(2))
+	split := GenesisAlloc{
+		common.Address{0x01}: {Balance: half},
+		common.Address{0x02}: {Balance: new(big.Int).Sub(premine, half)},
+	}
+	if genesis, err := DefaultR5GenesisBlock(split); err != nil {
+		t.Errorf("failed to create genesis with split premine: %v", err)
+	} else if !reflect.DeepEqual(genesis.Alloc, split) {
+		t.Errorf("split premine not allocated: %v", genesis.Alloc)
+	}
+	split[common.Address{0x03}] = GenesisAccount{Balance: big.NewInt(1)}
+	if _, err := DefaultR5GenesisBlock(split); err == nil {
+		t.Error("oversized premine accepted")
+	}
+	if _, err := DefaultR5GenesisBlock(GenesisAlloc{}); err == nil {
+		t.Error("empty premine accepted")
+	}
+}
+
 func TestGenesis_Commit(t *testing.T) {
 	genesis := &Genesis{
 		BaseFee: big.NewInt(params.InitialBaseFee),
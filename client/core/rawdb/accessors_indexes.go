@@ -171,3 +171,31 @@ func DeleteBloombits(db ethdb.Database, bit uint, from uint64, to uint64) {
 		log.Crit("Failed to delete bloom bits", "err", it.Error())
 	}
 }
+
+// CountBloombits counts the bloom bits of the given bloom bit index in the
+// [from, to) section range, which DeleteBloombits would remove, and their
+// approximate size on disk.
+func CountBloombits(db ethdb.Iteratee, bit uint, from uint64, to uint64) (int, common.StorageSize) {
+	start, end := bloomBitsKey(bit, from, common.Hash{}), bloomBitsKey(bit, to, common.Hash{})
+	it := db.NewIterator(nil, start)
+	defer it.Release()
+
+	var (
+		count int
+		size  common.StorageSize
+	)
+	for it.Next() {
+		if bytes.Compare(it.Key(), end) >= 0 {
+			break
+		}
+		if len(it.Key()) != len(bloomBitsPrefix)+2+8+32 {
+			continue
+		}
+		count++
+		size += common.StorageSize(len(it.Key()) + len(it.Value()))
+	}
+	if it.Error() != nil {
+		log.Crit("Failed to count bloom bits", "err", it.Error())
+	}
+	return count, size
+}
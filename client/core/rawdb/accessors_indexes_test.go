@@ -154,8 +154,19 @@ func TestDeleteBloomBits(t *testing.T) {
 	check(0, 0, params.MainnetGenesisHash, true)
 	check(0, 0, params.RinkebyGenesisHash, true)
 
+	// Check the counting of the data to be deleted.
+	if count, size := CountBloombits(db, 0, 0, 1); count != 2 || size == 0 {
+		t.Fatalf("Bloombits count mismatch: have %d (%v), want 2", count, size)
+	}
+	if count, _ := CountBloombits(db, 0, 0, 2); count != 4 {
+		t.Fatalf("Bloombits count mismatch: have %d, want 4", count)
+	}
+
 	// Check the existence of deleted data.
 	DeleteBloombits(db, 0, 0, 1)
+	if count, _ := CountBloombits(db, 0, 0, 1); count != 0 {
+		t.Fatalf("Bloombits count mismatch after deletion: have %d, want 0", count)
+	}
 	check(0, 0, params.MainnetGenesisHash, false)
 	check(0, 0, params.RinkebyGenesisHash, false)
 	check(0, 1, params.MainnetGenesisHash, true)
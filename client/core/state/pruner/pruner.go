@@ -133,20 +133,11 @@ func prune(snaptree *snapshot.Tree, root common.Hash, maindb ethdb.Database, sta
 		// - trie node
 		// - legacy contract code
 		// - new-scheme contract code
-		isCode, codeKey := rawdb.IsCodeKey(key)
-		if len(key) == common.HashLength || isCode {
-			checkKey := key
-			if isCode {
-				checkKey = codeKey
-			}
-			if _, exist := middleStateRoots[common.BytesToHash(checkKey)]; exist {
-				log.Debug("Forcibly delete the middle state roots", "hash", common.BytesToHash(checkKey))
-			} else {
-				if ok, err := stateBloom.Contain(checkKey); err != nil {
-					return err
-				} else if ok {
-					continue
-				}
+		if isStateKey(key) {
+			if stale, err := isStale(key, stateBloom, middleStateRoots); err != nil {
+				return err
+			} else if !stale {
+				continue
 			}
 			count += 1
 			size += common.StorageSize(len(key) + len(iter.Value()))
@@ -226,6 +217,32 @@ func prune(snaptree *snapshot.Tree, root common.Hash, maindb ethdb.Database, sta
 	return nil
 }
 
+// isStateKey reports whether the database key is a trie node or contract
+// code entry, the kinds of entries the pruning deletes.
+func isStateKey(key []byte) bool {
+	isCode, _ := rawdb.IsCodeKey(key)
+	return len(key) == common.HashLength || isCode
+}
+
+// isStale reports whether the trie node or contract code entry with the given
+// database key doesn't belong to the state recorded in the bloom filter, or is
+// one of the middle state roots which are deleted unconditionally.
+func isStale(key []byte, stateBloom *stateBloom, middleStateRoots map[common.Hash]struct{}) (bool, error) {
+	checkKey := key
+	if isCode, codeKey := rawdb.IsCodeKey(key); isCode {
+		checkKey = codeKey
+	}
+	if _, exist := middleStateRoots[common.BytesToHash(checkKey)]; exist {
+		log.Debug("Forcibly delete the middle state roots", "hash", common.BytesToHash(checkKey))
+		return true, nil
+	}
+	ok, err := stateBloom.Contain(checkKey)
+	if err != nil {
+		return false, err
+	}
+	return !ok, nil
+}
+
 // Prune deletes all historical state nodes except the nodes belong to the
 // specified state version. If user doesn't specify the state version, use
 // the bottom-most snapshot diff layer as the target.
@@ -241,6 +258,112 @@ func (p *Pruner) Prune(root common.Hash) error {
 	if stateBloomRoot != (common.Hash{}) {
 		return RecoverPruning(p.config.Datadir, p.db, p.config.Cachedir)
 	}
+	root, middleRoots, err := p.resolveTarget(root)
+	if err != nil {
+		return err
+	}
+	// Before start the pruning, delete the clean trie cache first.
+	// It's necessary otherwise in the next restart we will hit the
+	// deleted state root in the "clean cache" so that the incomplete
+	// state is picked for usage.
+	deleteCleanTrieCache(p.config.Cachedir)
+
+	// Traverse the target state, re-construct the whole state trie and
+	// commit to the given bloom filter.
+	start := time.Now()
+	if err := p.fillBloom(root); err != nil {
+		return err
+	}
+	filterName := bloomFilterName(p.config.Datadir, root)
+
+	log.Info("Writing state bloom to disk", "name", filterName)
+	if err := p.stateBloom.Commit(filterName, filterName+stateBloomFileTempSuffix); err != nil {
+		return err
+	}
+	log.Info("State bloom filter committed", "name", filterName)
+	return prune(p.snaptree, root, p.db, p.stateBloom, filterName, middleRoots, start)
+}
+
+// PruneEstimate is the outcome of a pruning dry run.
+type PruneEstimate struct {
+	Root        common.Hash        // State root the pruning would retain
+	Retained    int                // Number of trie nodes and codes retained
+	Deleted     int                // Number of trie nodes and codes deleted
+	DeletedSize common.StorageSize // Total size of the deleted entries
+}
+
+// DryRun resolves the pruning target the same way Prune does and counts the
+// state entries the pruning would retain and delete, without modifying the
+// database or writing the state bloom filter to disk. A small part of the
+// stale entries is counted as retained due to false positives of the bloom
+// filter, as it would be by the pruning.
+func (p *Pruner) DryRun(root common.Hash) (*PruneEstimate, error) {
+	if path, _, err := findBloomFilter(p.config.Datadir); err != nil {
+		return nil, err
+	} else if path != "" {
+		return nil, fmt.Errorf("interrupted pruning found (%s), it has to be resumed first", path)
+	}
+	root, middleRoots, err := p.resolveTarget(root)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	if err := p.fillBloom(root); err != nil {
+		return nil, err
+	}
+	var (
+		estimate = &PruneEstimate{Root: root}
+		logged   = time.Now()
+		iter     = p.db.NewIterator(nil, nil)
+	)
+	defer iter.Release()
+
+	for iter.Next() {
+		key := iter.Key()
+		if !isStateKey(key) {
+			continue
+		}
+		stale, err := isStale(key, p.stateBloom, middleRoots)
+		if err != nil {
+			return nil, err
+		}
+		if !stale {
+			estimate.Retained++
+		} else {
+			estimate.Deleted++
+			estimate.DeletedSize += common.StorageSize(len(key) + len(iter.Value()))
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Estimating state pruning", "retained", estimate.Retained, "deleted", estimate.Deleted,
+				"size", estimate.DeletedSize, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+	log.Info("Estimated state pruning", "root", root, "retained", estimate.Retained, "deleted", estimate.Deleted,
+		"size", estimate.DeletedSize, "elapsed", common.PrettyDuration(time.Since(start)))
+	return estimate, nil
+}
+
+// fillBloom records the trie nodes and contract codes of the target and the
+// genesis state in the state bloom filter.
+func (p *Pruner) fillBloom(root common.Hash) error {
+	// Traverse the target state, re-construct the whole state trie and
+	// commit to the given bloom filter.
+	if err := snapshot.GenerateTrie(p.snaptree, root, p.db, p.stateBloom); err != nil {
+		return err
+	}
+	// Traverse the genesis, put all genesis state entries into the
+	// bloom filter too.
+	return extractGenesis(p.db, p.stateBloom)
+}
+
+// resolveTarget returns the state root to prune to, along with the roots of
+// the snapshot layers above it, which have to be forcibly deleted. If no root
+// is given, the bottom-most snapshot diff layer is picked.
+func (p *Pruner) resolveTarget(root common.Hash) (common.Hash, map[common.Hash]struct{}, error) {
 	// If the target state root is not specified, use the HEAD-127 as the
 	// target. The reason for picking it is:
 	// - in most of the normal cases, the related state is available
@@ -255,7 +378,7 @@ func (p *Pruner) Prune(root common.Hash) error {
 			// Reject if the accumulated diff layers are less than 128. It
 			// means in most of normal cases, there is no associated state
 			// with bottom-most diff layer.
-			return fmt.Errorf("snapshot not old enough yet: need %d more blocks", 128-len(layers))
+			return common.Hash{}, nil, fmt.Errorf("snapshot not old enough yet: need %d more blocks", 128-len(layers))
 		}
 		// Use the bottom-most diff layer as the target
 		root = layers[len(layers)-1].Root()
@@ -286,9 +409,9 @@ func (p *Pruner) Prune(root common.Hash) error {
 		}
 		if !found {
 			if len(layers) > 0 {
-				return errors.New("no snapshot paired state")
+				return common.Hash{}, nil, errors.New("no snapshot paired state")
 			}
-			return fmt.Errorf("associated state[%x] is not present", root)
+			return common.Hash{}, nil, fmt.Errorf("associated state[%x] is not present", root)
 		}
 	} else {
 		if len(layers) > 0 {
@@ -297,12 +420,6 @@ func (p *Pruner) Prune(root common.Hash) error {
 			log.Info("Selecting user-specified state as the pruning target", "root", root)
 		}
 	}
-	// Before start the pruning, delete the clean trie cache first.
-	// It's necessary otherwise in the next restart we will hit the
-	// deleted state root in the "clean cache" so that the incomplete
-	// state is picked for usage.
-	deleteCleanTrieCache(p.config.Cachedir)
-
 	// All the state roots of the middle layer should be forcibly pruned,
 	// otherwise the dangling state will be left.
 	middleRoots := make(map[common.Hash]struct{})
@@ -312,25 +429,7 @@ func (p *Pruner) Prune(root common.Hash) error {
 		}
 		middleRoots[layer.Root()] = struct{}{}
 	}
-	// Traverse the target state, re-construct the whole state trie and
-	// commit to the given bloom filter.
-	start := time.Now()
-	if err := snapshot.GenerateTrie(p.snaptree, root, p.db, p.stateBloom); err != nil {
-		return err
-	}
-	// Traverse the genesis, put all genesis state entries into the
-	// bloom filter too.
-	if err := extractGenesis(p.db, p.stateBloom); err != nil {
-		return err
-	}
-	filterName := bloomFilterName(p.config.Datadir, root)
-
-	log.Info("Writing state bloom to disk", "name", filterName)
-	if err := p.stateBloom.Commit(filterName, filterName+stateBloomFileTempSuffix); err != nil {
-		return err
-	}
-	log.Info("State bloom filter committed", "name", filterName)
-	return prune(p.snaptree, root, p.db, p.stateBloom, filterName, middleRoots, start)
+	return root, middleRoots, nil
 }
 
 // RecoverPruning will resume the pruning procedure during the system restart.
@@ -0,0 +1,117 @@
+// Copyright 2025 R5 Labs
+// This file is part of the R5 Core library.
+//
+// This software is provided "as is", without warranty of any kind,
+// express or implied, including but not limited to the warranties
+// of merchantability, fitness for a particular purpose and
+// noninfringement. In no event shall the authors or copyright
+// holders be liable for any claim, damages, or other liability,
+// whether in an action of contract, tort or otherwise, arising
+// from, out of or in connection with the software or the use or
+// other dealings in the software.
+
+package pruner
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/r5-labs/r5-core/client/common"
+	"github.com/r5-labs/r5-core/client/consensus/ethash"
+	"github.com/r5-labs/r5-core/client/core"
+	"github.com/r5-labs/r5-core/client/core/rawdb"
+	"github.com/r5-labs/r5-core/client/core/vm"
+	"github.com/r5-labs/r5-core/client/ethdb"
+	"github.com/r5-labs/r5-core/client/params"
+)
+
+// dumpDatabase returns a copy of all the entries in the database.
+func dumpDatabase(db ethdb.Iteratee) map[string][]byte {
+	entries := make(map[string][]byte)
+	iter := db.NewIterator(nil, nil)
+	defer iter.Release()
+	for iter.Next() {
+		entries[string(iter.Key())] = common.CopyBytes(iter.Value())
+	}
+	return entries
+}
+
+// Tests that the pruning dry run estimates the stale state of the historical
+// blocks and leaves the database untouched.
+func TestPruneDryRun(t *testing.T) {
+	gspec := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc:  core.GenesisAlloc{common.Address{0x01}: {Balance: big.NewInt(1)}},
+	}
+	_, blocks, _ := core.GenerateChainWithGenesis(gspec, ethash.NewFaker(), 8, func(i int, gen *core.BlockGen) {
+		gen.SetCoinbase(common.Address{0xc0, byte(i)})
+	})
+	// Run in archive mode, keeping the state of all blocks around to be pruned.
+	db := rawdb.NewMemoryDatabase()
+	cacheConfig := &core.CacheConfig{
+		TrieCleanLimit:    256,
+		TrieDirtyLimit:    256,
+		TrieDirtyDisabled: true,
+		TrieTimeLimit:     5 * time.Minute,
+		SnapshotLimit:     256,
+		SnapshotWait:      true,
+	}
+	chain, err := core.NewBlockChain(db, cacheConfig, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	chain.Stop()
+
+	pruner, err := NewPruner(db, Config{Datadir: t.TempDir(), Cachedir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create pruner: %v", err)
+	}
+	head := blocks[len(blocks)-1]
+	before := dumpDatabase(db)
+
+	estimate, err := pruner.DryRun(head.Root())
+	if err != nil {
+		t.Fatalf("failed to run pruning dry run: %v", err)
+	}
+	if estimate.Root != head.Root() {
+		t.Errorf("target root mismatch: have %x, want %x", estimate.Root, head.Root())
+	}
+	if estimate.Deleted == 0 || estimate.DeletedSize == 0 {
+		t.Errorf("no stale state estimated: %+v", estimate)
+	}
+	if estimate.Retained == 0 {
+		t.Errorf("no live state estimated: %+v", estimate)
+	}
+	after := dumpDatabase(db)
+	if len(after) != len(before) {
+		t.Fatalf("database entry count changed: have %d, want %d", len(after), len(before))
+	}
+	for key, value := range before {
+		if !bytes.Equal(after[key], value) {
+			t.Fatalf("database entry %x changed", key)
+		}
+	}
+	// Without an explicit root, the HEAD-127 layer is not available yet.
+	if _, err := pruner.DryRun(common.Hash{}); err == nil {
+		t.Error("dry run picked a target without enough snapshot layers")
+	}
+	// The actual pruning deletes exactly the estimated entries.
+	if err := pruner.Prune(head.Root()); err != nil {
+		t.Fatalf("failed to prune state: %v", err)
+	}
+	var deleted int
+	pruned := dumpDatabase(db)
+	for key := range before {
+		if _, ok := pruned[key]; !ok && isStateKey([]byte(key)) {
+			deleted++
+		}
+	}
+	if deleted != estimate.Deleted {
+		t.Errorf("deleted entry count mismatch: have %d, estimated %d", deleted, estimate.Deleted)
+	}
+}
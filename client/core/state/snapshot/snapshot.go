@@ -15,8 +15,11 @@ package snapshot
 
 import (
 	"bytes"
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"math"
+	"strings"
 	"sync"
 
 	"github.com/r5-labs/r5-core/client/common"
@@ -794,6 +797,98 @@ func (t *Tree) Verify(root common.Hash) error {
 	return nil
 }
 
+// VerifyParallel is a concurrent version of Verify. The account key space is
+// split into the given number of ranges, each walked by its own iterator to
+// recalculate the storage roots of its accounts, while the account trie root is
+// recalculated over the whole space and matched exactly against the given root.
+// The failures of all ranges are aggregated into the returned error.
+func (t *Tree) VerifyParallel(root common.Hash, ranges int) error {
+	if ranges <= 1 {
+		return t.Verify(root)
+	}
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, ranges+1)
+		step = math.MaxUint64/uint64(ranges) + 1
+	)
+	for i := 0; i < ranges; i++ {
+		var start, end common.Hash
+		binary.BigEndian.PutUint64(start[:8], uint64(i)*step)
+		if i < ranges-1 {
+			binary.BigEndian.PutUint64(end[:8], uint64(i+1)*step)
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = t.verifyStorageRange(root, start, end)
+		}(i)
+	}
+	// Recalculate the account trie root while the ranges are checked.
+	errs[ranges] = func() error {
+		acctIt, err := t.AccountIterator(root, common.Hash{})
+		if err != nil {
+			return err
+		}
+		defer acctIt.Release()
+
+		got, err := GenerateAccountTrieRoot(acctIt)
+		if err != nil {
+			return err
+		}
+		if got != root {
+			return fmt.Errorf("state root hash mismatch: got %x, want %x", got, root)
+		}
+		return nil
+	}()
+	wg.Wait()
+
+	var failures []string
+	for _, err := range errs {
+		if err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// verifyStorageRange recalculates the storage roots of the accounts whose hash
+// is in [start, end) and checks them against the ones the accounts commit to.
+// A zero end hash means the range is unbounded.
+func (t *Tree) verifyStorageRange(root common.Hash, start, end common.Hash) error {
+	acctIt, err := t.AccountIterator(root, start)
+	if err != nil {
+		return err
+	}
+	defer acctIt.Release()
+
+	for acctIt.Next() {
+		hash := acctIt.Hash()
+		if end != (common.Hash{}) && bytes.Compare(hash[:], end[:]) >= 0 {
+			break
+		}
+		account, err := FullAccount(acctIt.Account())
+		if err != nil {
+			return err
+		}
+		storageIt, err := t.StorageIterator(root, hash, common.Hash{})
+		if err != nil {
+			return err
+		}
+		subroot, err := GenerateStorageTrieRoot(hash, storageIt)
+		storageIt.Release()
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(account.Root, subroot.Bytes()) {
+			return fmt.Errorf("invalid subroot(path %x), want %x, have %x", hash, account.Root, subroot)
+		}
+	}
+	return acctIt.Error()
+}
+
 // disklayer is an internal helper function to return the disk layer.
 // The lock of snapTree is assumed to be held already.
 func (t *Tree) disklayer() *diskLayer {
@@ -482,3 +482,58 @@ func TestReadStateDuringFlattening(t *testing.T) {
 		t.Fatal("Unexpected blocker")
 	}
 }
+
+// Tests that the parallel state verification agrees with the serial one, both
+// on an intact snapshot and on one with a corrupted storage slot.
+func TestVerifyParallel(t *testing.T) {
+	helper := newHelper()
+	for i := 0; i < 64; i++ {
+		acckey := fmt.Sprintf("acc-%d", i)
+		root := types.EmptyRootHash.Bytes()
+		if i%4 == 0 {
+			root = helper.makeStorageTrie(common.Hash{}, hashData([]byte(acckey)), []string{"key-1", "key-2", "key-3"}, []string{"val-1", "val-2", "val-3"}, true)
+			helper.addSnapStorage(acckey, []string{"key-1", "key-2", "key-3"}, []string{"val-1", "val-2", "val-3"})
+		}
+		helper.addAccount(acckey, &Account{Balance: big.NewInt(int64(i)), Root: root, CodeHash: types.EmptyCodeHash.Bytes()})
+	}
+	root := helper.Commit()
+	rawdb.WriteSnapshotRoot(helper.diskdb, root)
+
+	snaps := &Tree{
+		layers: map[common.Hash]snapshot{
+			root: &diskLayer{
+				diskdb: helper.diskdb,
+				triedb: helper.triedb,
+				cache:  fastcache.New(500 * 1024),
+				root:   root,
+			},
+		},
+	}
+	if err := snaps.Verify(root); err != nil {
+		t.Fatalf("serial verification failed: %v", err)
+	}
+	for _, ranges := range []int{1, 2, 3, 16} {
+		if err := snaps.VerifyParallel(root, ranges); err != nil {
+			t.Fatalf("parallel verification with %d ranges failed: %v", ranges, err)
+		}
+	}
+	// Corrupt a storage slot, both must reject the state.
+	rawdb.WriteStorageSnapshot(helper.diskdb, hashData([]byte("acc-8")), hashData([]byte("key-2")), []byte("val-x"))
+	if err := snaps.Verify(root); err == nil {
+		t.Fatal("serial verification accepted corrupted state")
+	}
+	for _, ranges := range []int{1, 2, 3, 16} {
+		if err := snaps.VerifyParallel(root, ranges); err == nil {
+			t.Fatalf("parallel verification with %d ranges accepted corrupted state", ranges)
+		}
+	}
+	// Restore the slot and corrupt an account instead, changing the state root.
+	helper.addSnapStorage("acc-8", []string{"key-2"}, []string{"val-2"})
+	helper.addSnapAccount("acc-5", &Account{Balance: big.NewInt(500), Root: types.EmptyRootHash.Bytes(), CodeHash: types.EmptyCodeHash.Bytes()})
+	if err := snaps.Verify(root); err == nil {
+		t.Fatal("serial verification accepted corrupted account")
+	}
+	if err := snaps.VerifyParallel(root, 4); err == nil {
+		t.Fatal("parallel verification accepted corrupted account")
+	}
+}
@@ -158,17 +158,23 @@ func New(root common.Hash, db Database, snaps *snapshot.Tree) (*StateDB, error)
 
 // StartPrefetcher initializes a new trie prefetcher to pull in nodes from the
 // state trie concurrently while the state is mutated so that when we reach the
-// commit phase, most of the needed data is already hot.
-func (s *StateDB) StartPrefetcher(namespace string) {
+// commit phase, most of the needed data is already hot. Each trie is loaded by
+// the given number of goroutines, anything below one meaning a single one.
+func (s *StateDB) StartPrefetcher(namespace string, workers int) {
 	if s.prefetcher != nil {
 		s.prefetcher.close()
 		s.prefetcher = nil
 	}
 	if s.snap != nil {
-		s.prefetcher = newTriePrefetcher(s.db, s.originalRoot, namespace)
+		s.prefetcher = newTriePrefetcher(s.db, s.originalRoot, namespace, workers)
 	}
 }
 
+// Prefetching reports whether an active trie prefetcher is running.
+func (s *StateDB) Prefetching() bool {
+	return s.prefetcher != nil && s.prefetcher.fetches == nil
+}
+
 // StopPrefetcher terminates a running prefetcher and reports any leftover stats
 // from the gathered metrics.
 func (s *StateDB) StopPrefetcher() {
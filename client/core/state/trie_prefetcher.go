@@ -35,6 +35,7 @@ type triePrefetcher struct {
 	root     common.Hash            // Root hash of the account trie for metrics
 	fetches  map[string]Trie        // Partially or fully fetcher tries
 	fetchers map[string]*subfetcher // Subfetchers for each trie
+	workers  int                    // Number of goroutines loading each trie
 
 	deliveryMissMeter metrics.Meter
 	accountLoadMeter  metrics.Meter
@@ -47,12 +48,18 @@ type triePrefetcher struct {
 	storageWasteMeter metrics.Meter
 }
 
-func newTriePrefetcher(db Database, root common.Hash, namespace string) *triePrefetcher {
+// newTriePrefetcher creates an active prefetcher, loading each trie through the
+// given number of goroutines. Anything below one is treated as a single one.
+func newTriePrefetcher(db Database, root common.Hash, namespace string, workers int) *triePrefetcher {
+	if workers < 1 {
+		workers = 1
+	}
 	prefix := triePrefetchMetricsPrefix + namespace
 	p := &triePrefetcher{
 		db:       db,
 		root:     root,
 		fetchers: make(map[string]*subfetcher), // Active prefetchers use the fetchers map
+		workers:  workers,
 
 		deliveryMissMeter: metrics.GetOrRegisterMeter(prefix+"/deliverymiss", nil),
 		accountLoadMeter:  metrics.GetOrRegisterMeter(prefix+"/account/load", nil),
@@ -146,7 +153,7 @@ func (p *triePrefetcher) prefetch(owner common.Hash, root common.Hash, addr comm
 	id := p.trieID(owner, root)
 	fetcher := p.fetchers[id]
 	if fetcher == nil {
-		fetcher = newSubfetcher(p.db, p.root, owner, root, addr)
+		fetcher = newSubfetcher(p.db, p.root, owner, root, addr, p.workers)
 		p.fetchers[id] = fetcher
 	}
 	fetcher.schedule(keys)
@@ -211,6 +218,10 @@ type subfetcher struct {
 	tasks [][]byte   // Items queued up for retrieval
 	lock  sync.Mutex // Lock protecting the task queue
 
+	workers int            // Number of goroutines loading the trie, including the main loop
+	warm    chan []byte    // Items handed to the helpers to load ahead of the main loop
+	helpers sync.WaitGroup // Tracks the running helpers
+
 	wake chan struct{}  // Wake channel if a new task is scheduled
 	stop chan struct{}  // Channel to interrupt processing
 	term chan struct{}  // Channel to signal interruption
@@ -222,19 +233,25 @@ type subfetcher struct {
 }
 
 // newSubfetcher creates a goroutine to prefetch state items belonging to a
-// particular root hash.
-func newSubfetcher(db Database, state common.Hash, owner common.Hash, root common.Hash, addr common.Address) *subfetcher {
+// particular root hash. If more than one worker is requested, the extra ones
+// load the items ahead of it through their own copies of the trie, warming up
+// the database caches for the main goroutine.
+func newSubfetcher(db Database, state common.Hash, owner common.Hash, root common.Hash, addr common.Address, workers int) *subfetcher {
 	sf := &subfetcher{
-		db:    db,
-		state: state,
-		owner: owner,
-		root:  root,
-		addr:  addr,
-		wake:  make(chan struct{}, 1),
-		stop:  make(chan struct{}),
-		term:  make(chan struct{}),
-		copy:  make(chan chan Trie),
-		seen:  make(map[string]struct{}),
+		db:      db,
+		state:   state,
+		owner:   owner,
+		root:    root,
+		addr:    addr,
+		workers: workers,
+		wake:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		term:    make(chan struct{}),
+		copy:    make(chan chan Trie),
+		seen:    make(map[string]struct{}),
+	}
+	if workers > 1 {
+		sf.warm = make(chan []byte, 64*workers)
 	}
 	go sf.loop()
 	return sf
@@ -286,8 +303,10 @@ func (sf *subfetcher) abort() {
 // loop waits for new tasks to be scheduled and keeps loading them until it runs
 // out of tasks or its underlying trie is retrieved for committing.
 func (sf *subfetcher) loop() {
-	// No matter how the loop stops, signal anyone waiting that it's terminated
+	// No matter how the loop stops, signal anyone waiting that it's terminated.
+	// Helpers only exit on interruption too, wait for them to not leak any.
 	defer close(sf.term)
+	defer sf.helpers.Wait()
 
 	// Start by opening the trie and stop processing if it fails
 	if sf.owner == (common.Hash{}) {
@@ -305,7 +324,11 @@ func (sf *subfetcher) loop() {
 		}
 		sf.trie = trie
 	}
-	// Trie opened successfully, keep prefetching items
+	// Trie opened successfully, start any helpers and keep prefetching items
+	for i := 1; i < sf.workers; i++ {
+		sf.helpers.Add(1)
+		go sf.help(sf.db.CopyTrie(sf.trie))
+	}
 	for {
 		select {
 		case <-sf.wake:
@@ -315,6 +338,20 @@ func (sf *subfetcher) loop() {
 			sf.tasks = nil
 			sf.lock.Unlock()
 
+			// Hand out whatever the helpers can take, they are only warming
+			// the caches up so it's fine to skip items if they're lagging
+			if sf.warm != nil {
+				for _, task := range tasks {
+					if _, ok := sf.seen[string(task)]; ok {
+						continue
+					}
+					select {
+					case sf.warm <- task:
+					default:
+					}
+				}
+			}
+
 			// Prefetch any tasks until the loop is interrupted
 			for i, task := range tasks {
 				select {
@@ -334,11 +371,7 @@ func (sf *subfetcher) loop() {
 					if _, ok := sf.seen[string(task)]; ok {
 						sf.dups++
 					} else {
-						if len(task) == common.AddressLength {
-							sf.trie.GetAccount(common.BytesToAddress(task))
-						} else {
-							sf.trie.GetStorage(sf.addr, task)
-						}
+						sf.load(sf.trie, task)
 						sf.seen[string(task)] = struct{}{}
 					}
 				}
@@ -354,3 +387,27 @@ func (sf *subfetcher) loop() {
 		}
 	}
 }
+
+// help loads the items handed out by the main loop through its own copy of the
+// trie, until the subfetcher is interrupted.
+func (sf *subfetcher) help(trie Trie) {
+	defer sf.helpers.Done()
+
+	for {
+		select {
+		case task := <-sf.warm:
+			sf.load(trie, task)
+		case <-sf.stop:
+			return
+		}
+	}
+}
+
+// load retrieves a single account or storage slot from the given trie.
+func (sf *subfetcher) load(trie Trie, task []byte) {
+	if len(task) == common.AddressLength {
+		trie.GetAccount(common.BytesToAddress(task))
+	} else {
+		trie.GetStorage(sf.addr, task)
+	}
+}
@@ -13,12 +13,15 @@
 package state
 
 import (
+	"fmt"
 	"math/big"
 	"testing"
 	"time"
 
 	"github.com/r5-labs/r5-core/client/common"
 	"github.com/r5-labs/r5-core/client/core/rawdb"
+	"github.com/r5-labs/r5-core/client/ethdb"
+	"github.com/r5-labs/r5-core/client/trie"
 )
 
 func filledStateDB() *StateDB {
@@ -41,7 +44,7 @@ func filledStateDB() *StateDB {
 
 func TestCopyAndClose(t *testing.T) {
 	db := filledStateDB()
-	prefetcher := newTriePrefetcher(db.db, db.originalRoot, "")
+	prefetcher := newTriePrefetcher(db.db, db.originalRoot, "", 1)
 	skey := common.HexToHash("aaa")
 	prefetcher.prefetch(common.Hash{}, db.originalRoot, common.Address{}, [][]byte{skey.Bytes()})
 	prefetcher.prefetch(common.Hash{}, db.originalRoot, common.Address{}, [][]byte{skey.Bytes()})
@@ -66,7 +69,7 @@ func TestCopyAndClose(t *testing.T) {
 
 func TestUseAfterClose(t *testing.T) {
 	db := filledStateDB()
-	prefetcher := newTriePrefetcher(db.db, db.originalRoot, "")
+	prefetcher := newTriePrefetcher(db.db, db.originalRoot, "", 1)
 	skey := common.HexToHash("aaa")
 	prefetcher.prefetch(common.Hash{}, db.originalRoot, common.Address{}, [][]byte{skey.Bytes()})
 	a := prefetcher.trie(common.Hash{}, db.originalRoot)
@@ -82,7 +85,7 @@ func TestUseAfterClose(t *testing.T) {
 
 func TestCopyClose(t *testing.T) {
 	db := filledStateDB()
-	prefetcher := newTriePrefetcher(db.db, db.originalRoot, "")
+	prefetcher := newTriePrefetcher(db.db, db.originalRoot, "", 1)
 	skey := common.HexToHash("aaa")
 	prefetcher.prefetch(common.Hash{}, db.originalRoot, common.Address{}, [][]byte{skey.Bytes()})
 	cpy := prefetcher.copy()
@@ -104,3 +107,77 @@ func TestCopyClose(t *testing.T) {
 		t.Fatal("Copy trie should not return nil")
 	}
 }
+
+// filledAccountsDB returns a disk database with a committed state containing
+// the given number of accounts, along with the state root and the addresses.
+func filledAccountsDB(n int) (ethdb.Database, common.Hash, []common.Address) {
+	diskdb := rawdb.NewMemoryDatabase()
+	state, _ := New(common.Hash{}, NewDatabase(diskdb), nil)
+
+	addrs := make([]common.Address, n)
+	for i := range addrs {
+		addrs[i] = common.BigToAddress(big.NewInt(int64(i + 1)))
+		state.SetBalance(addrs[i], big.NewInt(int64(i+1)))
+	}
+	root, _ := state.Commit(false)
+	state.db.TrieDB().Commit(root, false)
+	return diskdb, root, addrs
+}
+
+func TestPrefetcherWorkers(t *testing.T) {
+	diskdb, root, addrs := filledAccountsDB(500)
+
+	keys := make([][]byte, len(addrs))
+	for i, addr := range addrs {
+		keys[i] = common.CopyBytes(addr[:])
+	}
+	for _, tt := range []struct{ workers, want int }{{0, 1}, {1, 1}, {4, 4}} {
+		workers := tt.workers
+		prefetcher := newTriePrefetcher(NewDatabase(diskdb), root, "", workers)
+		if prefetcher.workers != tt.want {
+			t.Errorf("workers %d: worker count mismatch: have %d, want %d", workers, prefetcher.workers, tt.want)
+		}
+		prefetcher.prefetch(common.Hash{}, root, common.Address{}, keys)
+
+		trie := prefetcher.trie(common.Hash{}, root)
+		if trie == nil {
+			t.Fatalf("workers %d: no trie prefetched", workers)
+		}
+		if trie.Hash() != root {
+			t.Errorf("workers %d: root mismatch: have %x, want %x", workers, trie.Hash(), root)
+		}
+		// Closing must return, so all helpers must have exited
+		prefetcher.close()
+	}
+}
+
+// BenchmarkPrefetcherWorkers measures the latency of reading the accounts of a
+// transaction heavy block, while they are being prefetched by various numbers
+// of workers into the clean trie cache.
+func BenchmarkPrefetcherWorkers(b *testing.B) {
+	diskdb, root, addrs := filledAccountsDB(10000)
+
+	keys := make([][]byte, len(addrs))
+	for i, addr := range addrs {
+		keys[i] = common.CopyBytes(addr[:])
+	}
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers-%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				// Start from a cold cache every iteration
+				b.StopTimer()
+				db := NewDatabaseWithConfig(diskdb, &trie.Config{Cache: 16})
+				reader, _ := db.OpenTrie(root)
+				prefetcher := newTriePrefetcher(db, root, "", workers)
+				b.StartTimer()
+
+				prefetcher.prefetch(common.Hash{}, root, common.Address{}, keys)
+				for _, addr := range addrs {
+					reader.GetAccount(addr)
+				}
+				b.StopTimer()
+				prefetcher.close()
+			}
+		})
+	}
+}
@@ -48,7 +48,7 @@ const (
 	// non-trivial consequences: larger transactions are significantly harder and
 	// more expensive to propagate; larger transactions also take more resources
 	// to validate whether they fit into the pool or not.
-	txMaxSize = 4 * txSlotSize // 128KB
+	txMaxSize = types.MaxTxSize // 4 * txSlotSize = 128KB
 )
 
 var (
@@ -77,12 +77,12 @@ var (
 
 	// ErrNegativeValue is a sanity error to ensure no one is able to specify a
 	// transaction with a negative value.
-	ErrNegativeValue = errors.New("negative value")
+	ErrNegativeValue = types.ErrNegativeValue
 
 	// ErrOversizedData is returned if the input data of a transaction is greater
 	// than some meaningful limit a user might use. This is not a consensus error
 	// making the transaction invalid, rather a DOS protection.
-	ErrOversizedData = errors.New("oversized data")
+	ErrOversizedData = types.ErrOversizedData
 
 	// ErrFutureReplacePending is returned if a future transaction replaces a pending
 	// transaction. Future transactions should only be able to replace other future transactions.
@@ -163,8 +163,9 @@ type Config struct {
 	Journal   string           // Journal of local transactions to survive node restarts
 	Rejournal time.Duration    // Time interval to regenerate the local transaction journal
 
-	PriceLimit uint64 // Minimum gas price to enforce for acceptance into the pool
-	PriceBump  uint64 // Minimum price bump percentage to replace an already existing transaction (nonce)
+	PriceLimit        uint64 // Minimum gas price to enforce for acceptance into the pool
+	PriceBump         uint64 // Minimum price bump percentage to replace an already existing transaction (nonce)
+	MinReplacementTip uint64 // Minimum effective tip increase (wei) to replace an already existing transaction (nonce)
 
 	AccountSlots uint64 // Number of executable transaction slots guaranteed per account
 	GlobalSlots  uint64 // Maximum number of executable transaction slots for all accounts
@@ -500,6 +501,27 @@ func (pool *TxPool) stats() (int, int) {
 	return pending, queued
 }
 
+// StatsByType retrieves the number of pending and the number of queued
+// (non-executable) transactions, broken down by transaction type.
+func (pool *TxPool) StatsByType() (map[uint8]int, map[uint8]int) {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	pending := make(map[uint8]int)
+	for _, list := range pool.pending {
+		for _, tx := range list.txs.items {
+			pending[tx.Type()]++
+		}
+	}
+	queued := make(map[uint8]int)
+	for _, list := range pool.queue {
+		for _, tx := range list.txs.items {
+			queued[tx.Type()]++
+		}
+	}
+	return pending, queued
+}
+
 // Content retrieves the data content of the transaction pool, returning all the
 // pending as well as queued transactions, grouped by account and sorted by nonce.
 func (pool *TxPool) Content() (map[common.Address]types.Transactions, map[common.Address]types.Transactions) {
@@ -710,6 +732,13 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 	// already validated by this point
 	from, _ := types.Sender(pool.signer, tx)
 
+	// If the transaction replaces another, enforce the absolute tip increase
+	// before making any room for it
+	if err := pool.checkReplacementTip(from, tx); err != nil {
+		log.Trace("Discarding underpriced replacement transaction", "hash", hash, "gasTipCap", tx.GasTipCap(), "gasFeeCap", tx.GasFeeCap())
+		return false, err
+	}
+
 	// If the transaction pool is full, discard underpriced transactions
 	if uint64(pool.all.Slots()+numSlots(tx)) > pool.config.GlobalSlots+pool.config.GlobalQueue {
 		// If the new transaction is underpriced, don't accept it
@@ -835,6 +864,36 @@ func (pool *TxPool) isGapped(from common.Address, tx *types.Transaction) bool {
 	return false
 }
 
+// checkReplacementTip verifies that a transaction replacing a pending or queued
+// one with the same nonce raises the effective tip by at least the configured
+// MinReplacementTip, on top of the percentage based PriceBump which is checked
+// when inserting into the lists.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) checkReplacementTip(from common.Address, tx *types.Transaction) error {
+	if pool.config.MinReplacementTip == 0 {
+		return nil
+	}
+	var old *types.Transaction
+	if list := pool.pending[from]; list != nil {
+		old = list.txs.Get(tx.Nonce())
+	}
+	if old == nil {
+		if list := pool.queue[from]; list != nil {
+			old = list.txs.Get(tx.Nonce())
+		}
+	}
+	if old == nil {
+		return nil
+	}
+	baseFee := pool.priced.urgent.baseFee
+	threshold := new(big.Int).Add(old.EffectiveGasTipValue(baseFee), new(big.Int).SetUint64(pool.config.MinReplacementTip))
+	if tx.EffectiveGasTipIntCmp(threshold, baseFee) < 0 {
+		return ErrReplaceUnderpriced
+	}
+	return nil
+}
+
 // enqueueTx inserts a new transaction into the non-executable transaction queue.
 //
 // Note, this method assumes the pool lock is held!
@@ -20,6 +20,7 @@ import (
 	"math/big"
 	"math/rand"
 	"os"
+	"reflect"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -1574,6 +1575,63 @@ func TestRepricingDynamicFee(t *testing.T) {
 	}
 }
 
+// Tests that the pool statistics are correctly broken down by transaction type.
+func TestStatsByType(t *testing.T) {
+	t.Parallel()
+
+	pool, _ := setupPoolWithConfig(eip1559Config)
+	defer pool.Stop()
+
+	keys := make([]*ecdsa.PrivateKey, 3)
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+		testAddBalance(pool, crypto.PubkeyToAddress(keys[i].PublicKey), big.NewInt(1000000))
+	}
+	accessListTx := func(nonce uint64, key *ecdsa.PrivateKey) *types.Transaction {
+		tx, _ := types.SignNewTx(key, types.LatestSignerForChainID(params.TestChainConfig.ChainID), &types.AccessListTx{
+			ChainID:  params.TestChainConfig.ChainID,
+			Nonce:    nonce,
+			GasPrice: big.NewInt(1),
+			Gas:      100000,
+			To:       &common.Address{},
+			Value:    big.NewInt(100),
+		})
+		return tx
+	}
+	txs := types.Transactions{
+		pricedTransaction(0, 100000, big.NewInt(1), keys[0]),
+		pricedTransaction(1, 100000, big.NewInt(1), keys[0]),
+		pricedTransaction(3, 100000, big.NewInt(1), keys[0]),
+
+		accessListTx(0, keys[1]),
+		accessListTx(2, keys[1]),
+		accessListTx(3, keys[1]),
+
+		dynamicFeeTx(0, 100000, big.NewInt(2), big.NewInt(1), keys[2]),
+		dynamicFeeTx(1, 100000, big.NewInt(2), big.NewInt(1), keys[2]),
+		dynamicFeeTx(2, 100000, big.NewInt(2), big.NewInt(1), keys[2]),
+		dynamicFeeTx(4, 100000, big.NewInt(2), big.NewInt(1), keys[2]),
+	}
+	for i, err := range pool.AddRemotesSync(txs) {
+		if err != nil {
+			t.Fatalf("tx %d: failed to add transaction: %v", i, err)
+		}
+	}
+	pending, queued := pool.StatsByType()
+	wantPending := map[uint8]int{types.LegacyTxType: 2, types.AccessListTxType: 1, types.DynamicFeeTxType: 3}
+	wantQueued := map[uint8]int{types.LegacyTxType: 1, types.AccessListTxType: 2, types.DynamicFeeTxType: 1}
+	if !reflect.DeepEqual(pending, wantPending) {
+		t.Errorf("pending breakdown mismatch: have %v, want %v", pending, wantPending)
+	}
+	if !reflect.DeepEqual(queued, wantQueued) {
+		t.Errorf("queued breakdown mismatch: have %v, want %v", queued, wantQueued)
+	}
+	// The breakdown must add up to the aggregate statistics
+	if p, q := pool.Stats(); p != 6 || q != 4 {
+		t.Errorf("aggregate stats mismatch: have %d/%d, want %d/%d", p, q, 6, 4)
+	}
+}
+
 // Tests that setting the transaction pool gas price to a higher value does not
 // remove local transactions (legacy & dynamic fee).
 func TestRepricingKeepsLocals(t *testing.T) {
@@ -2249,6 +2307,99 @@ func TestReplacementDynamicFee(t *testing.T) {
 	}
 }
 
+// Tests that the content of a single sender only holds its own pending and
+// queued transactions, sorted by nonce.
+func TestContentFrom(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Stop()
+
+	other, _ := crypto.GenerateKey()
+	addr, otherAddr := crypto.PubkeyToAddress(key.PublicKey), crypto.PubkeyToAddress(other.PublicKey)
+	testAddBalance(pool, addr, big.NewInt(1000000))
+	testAddBalance(pool, otherAddr, big.NewInt(1000000))
+
+	// Nonces 0-2 are executable, 4-5 are gapped, added out of order.
+	for _, nonce := range []uint64{5, 1, 0, 4, 2} {
+		pool.AddRemotesSync([]*types.Transaction{transaction(nonce, 100000, key), transaction(nonce, 100000, other)})
+	}
+	pending, queued := pool.ContentFrom(addr)
+	check := func(kind string, txs types.Transactions, nonces []uint64) {
+		if len(txs) != len(nonces) {
+			t.Fatalf("%s transaction count mismatch: have %d, want %d", kind, len(txs), len(nonces))
+		}
+		for i, tx := range txs {
+			if from, _ := types.Sender(pool.signer, tx); from != addr {
+				t.Errorf("%s transaction %d: sender mismatch: have %x, want %x", kind, i, from, addr)
+			}
+			if tx.Nonce() != nonces[i] {
+				t.Errorf("%s transaction %d: nonce mismatch: have %d, want %d", kind, i, tx.Nonce(), nonces[i])
+			}
+		}
+	}
+	check("pending", pending, []uint64{0, 1, 2})
+	check("queued", queued, []uint64{4, 5})
+
+	// An account without transactions has no content.
+	if pending, queued := pool.ContentFrom(common.Address{0xff}); len(pending) != 0 || len(queued) != 0 {
+		t.Fatalf("unknown account has content: %d pending, %d queued", len(pending), len(queued))
+	}
+}
+
+// Tests that a same-nonce replacement must satisfy both the percentage based
+// price bump and the absolute minimum effective tip increase.
+func TestReplacementMinTip(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := newTestBlockChain(10000000, statedb, new(event.Feed))
+
+	config := testTxPoolConfig
+	config.PriceBump = 10
+	config.MinReplacementTip = 500
+
+	pool := NewTxPool(config, eip1559Config, blockchain)
+	<-pool.initDoneCh
+	defer pool.Stop()
+
+	key, _ := crypto.GenerateKey()
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(params.Ether))
+
+	// Keep the fee caps well above the base fee, so the effective tips are the
+	// tip caps themselves.
+	feeCap := func(tip int64) *big.Int {
+		return big.NewInt(4 * tip)
+	}
+	for _, stage := range []string{"pending", "queued"} {
+		nonce := uint64(0)
+		if stage == "queued" {
+			nonce = 2
+		}
+		tests := []struct {
+			tip  int64
+			want error
+		}{
+			{2000, nil},                    // original
+			{2200, ErrReplaceUnderpriced},  // +10% but below the absolute +500
+			{2499, ErrReplaceUnderpriced},  // just below the absolute threshold
+			{2500, nil},                    // both thresholds met
+			{10000, nil},                   // raise the price well above the absolute threshold
+			{10500, ErrReplaceUnderpriced}, // +500 but below the +10%
+			{11000, nil},                   // both thresholds met
+		}
+		for i, tt := range tests {
+			tx := dynamicFeeTx(nonce, 100000, feeCap(tt.tip), big.NewInt(tt.tip), key)
+			if err := pool.addRemoteSync(tx); err != tt.want {
+				t.Fatalf("%s test %d (tip %d): error mismatch: have %v, want %v", stage, i, tt.tip, err, tt.want)
+			}
+		}
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 // Tests that local transactions are journaled to disk, but remote transactions
 // get discarded between restarts.
 func TestJournaling(t *testing.T)         { testJournaling(t, false) }
@@ -435,6 +435,29 @@ func (b *Block) Hash() common.Hash {
 
 type Blocks []*Block
 
+// TotalFees computes the total priority fees in Wei paid to the coinbase by the
+// transactions of the block. Block transactions and receipts have to have the
+// same order.
+//
+// Without a base fee the coinbase collects the whole gas price, which is the fee
+// cap for dynamic fee transactions. Transactions whose fee cap is below the base
+// fee pay no tip at all.
+func TotalFees(block *Block, receipts []*Receipt) *big.Int {
+	feesWei := new(big.Int)
+	for i, tx := range block.Transactions() {
+		minerFee := tx.GasPrice()
+		if baseFee := block.BaseFee(); baseFee != nil {
+			tip, err := tx.EffectiveGasTip(baseFee)
+			if err != nil {
+				continue
+			}
+			minerFee = tip
+		}
+		feesWei.Add(feesWei, new(big.Int).Mul(new(big.Int).SetUint64(receipts[i].GasUsed), minerFee))
+	}
+	return feesWei
+}
+
 // HeaderParentHashFromRLP returns the parentHash of an RLP-encoded
 // header. If 'header' is invalid, the zero hash is returned.
 func HeaderParentHashFromRLP(header []byte) common.Hash {
@@ -199,6 +199,36 @@ func TestUncleHash(t *testing.T) {
 	}
 }
 
+func TestTotalFees(t *testing.T) {
+	to := common.HexToAddress("095e7baea6a6c7c4c2dfeb977efac326af552d87")
+	txs := []*Transaction{
+		NewTx(&LegacyTx{Nonce: 0, To: &to, Gas: 21000, GasPrice: big.NewInt(150)}),
+		NewTx(&AccessListTx{ChainID: big.NewInt(1), Nonce: 1, To: &to, Gas: 30000, GasPrice: big.NewInt(120)}),
+		NewTx(&DynamicFeeTx{ChainID: big.NewInt(1), Nonce: 2, To: &to, Gas: 40000, GasFeeCap: big.NewInt(300), GasTipCap: big.NewInt(30)}),
+		NewTx(&DynamicFeeTx{ChainID: big.NewInt(1), Nonce: 3, To: &to, Gas: 50000, GasFeeCap: big.NewInt(110), GasTipCap: big.NewInt(50)}),
+		NewTx(&LegacyTx{Nonce: 4, To: &to, Gas: 60000, GasPrice: big.NewInt(90)}),
+		NewTx(&DynamicFeeTx{ChainID: big.NewInt(1), Nonce: 5, To: &to, Gas: 70000, GasFeeCap: big.NewInt(80), GasTipCap: big.NewInt(5)}),
+	}
+	receipts := []*Receipt{{GasUsed: 21000}, {GasUsed: 30000}, {GasUsed: 40000}, {GasUsed: 50000}, {GasUsed: 60000}, {GasUsed: 70000}}
+
+	// Post-London the miner only collects the part of the price above the base
+	// fee, nothing from transactions priced below it.
+	header := &Header{Number: big.NewInt(1), BaseFee: big.NewInt(100)}
+	block := NewBlock(header, txs, nil, receipts, newHasher())
+	want := big.NewInt(21000*50 + 30000*20 + 40000*30 + 50000*10)
+	if fees := TotalFees(block, receipts); fees.Cmp(want) != 0 {
+		t.Errorf("post-London fees mismatch: have %v, want %v", fees, want)
+	}
+	// Pre-London the whole gas price is collected, which is the fee cap for
+	// dynamic fee transactions.
+	header = &Header{Number: big.NewInt(1)}
+	block = NewBlock(header, txs, nil, receipts, newHasher())
+	want = big.NewInt(21000*150 + 30000*120 + 40000*300 + 50000*110 + 60000*90 + 70000*80)
+	if fees := TotalFees(block, receipts); fees.Cmp(want) != 0 {
+		t.Errorf("pre-London fees mismatch: have %v, want %v", fees, want)
+	}
+}
+
 var benchBuffer = bytes.NewBuffer(make([]byte, 0, 32000))
 
 func BenchmarkEncodeBlock(b *testing.B) {
@@ -16,19 +16,19 @@ var _ = (*receiptMarshaling)(nil)
 // MarshalJSON marshals as JSON.
 func (r Receipt) MarshalJSON() ([]byte, error) {
 	type Receipt struct {
-		Type              hexutil.Uint64 `json:"type,omitempty"`
-		PostState         hexutil.Bytes  `json:"root"`
-		Status            hexutil.Uint64 `json:"status"`
-		CumulativeGasUsed hexutil.Uint64 `json:"cumulativeGasUsed" gencodec:"required"`
-		Bloom             Bloom          `json:"logsBloom"         gencodec:"required"`
-		Logs              []*Log         `json:"logs"              gencodec:"required"`
-		TxHash            common.Hash    `json:"transactionHash" gencodec:"required"`
-		ContractAddress   common.Address `json:"contractAddress"`
-		GasUsed           hexutil.Uint64 `json:"gasUsed" gencodec:"required"`
-		EffectiveGasPrice *hexutil.Big   `json:"effectiveGasPrice,omitempty"`
-		BlockHash         common.Hash    `json:"blockHash,omitempty"`
-		BlockNumber       *hexutil.Big   `json:"blockNumber,omitempty"`
-		TransactionIndex  hexutil.Uint   `json:"transactionIndex"`
+		Type              hexutil.Uint64   `json:"type,omitempty"`
+		PostState         receiptPostState `json:"root"`
+		Status            hexutil.Uint64   `json:"status"`
+		CumulativeGasUsed hexutil.Uint64   `json:"cumulativeGasUsed" gencodec:"required"`
+		Bloom             Bloom            `json:"logsBloom"         gencodec:"required"`
+		Logs              []*Log           `json:"logs"              gencodec:"required"`
+		TxHash            common.Hash      `json:"transactionHash" gencodec:"required"`
+		ContractAddress   common.Address   `json:"contractAddress"`
+		GasUsed           hexutil.Uint64   `json:"gasUsed" gencodec:"required"`
+		EffectiveGasPrice *hexutil.Big     `json:"effectiveGasPrice,omitempty"`
+		BlockHash         common.Hash      `json:"blockHash,omitempty"`
+		BlockNumber       *hexutil.Big     `json:"blockNumber,omitempty"`
+		TransactionIndex  hexutil.Uint     `json:"transactionIndex"`
 	}
 	var enc Receipt
 	enc.Type = hexutil.Uint64(r.Type)
@@ -50,19 +50,19 @@ func (r Receipt) MarshalJSON() ([]byte, error) {
 // UnmarshalJSON unmarshals from JSON.
 func (r *Receipt) UnmarshalJSON(input []byte) error {
 	type Receipt struct {
-		Type              *hexutil.Uint64 `json:"type,omitempty"`
-		PostState         *hexutil.Bytes  `json:"root"`
-		Status            *hexutil.Uint64 `json:"status"`
-		CumulativeGasUsed *hexutil.Uint64 `json:"cumulativeGasUsed" gencodec:"required"`
-		Bloom             *Bloom          `json:"logsBloom"         gencodec:"required"`
-		Logs              []*Log          `json:"logs"              gencodec:"required"`
-		TxHash            *common.Hash    `json:"transactionHash" gencodec:"required"`
-		ContractAddress   *common.Address `json:"contractAddress"`
-		GasUsed           *hexutil.Uint64 `json:"gasUsed" gencodec:"required"`
-		EffectiveGasPrice *hexutil.Big    `json:"effectiveGasPrice,omitempty"`
-		BlockHash         *common.Hash    `json:"blockHash,omitempty"`
-		BlockNumber       *hexutil.Big    `json:"blockNumber,omitempty"`
-		TransactionIndex  *hexutil.Uint   `json:"transactionIndex"`
+		Type              *hexutil.Uint64   `json:"type,omitempty"`
+		PostState         *receiptPostState `json:"root"`
+		Status            *hexutil.Uint64   `json:"status"`
+		CumulativeGasUsed *hexutil.Uint64   `json:"cumulativeGasUsed" gencodec:"required"`
+		Bloom             *Bloom            `json:"logsBloom"         gencodec:"required"`
+		Logs              []*Log            `json:"logs"              gencodec:"required"`
+		TxHash            *common.Hash      `json:"transactionHash" gencodec:"required"`
+		ContractAddress   *common.Address   `json:"contractAddress"`
+		GasUsed           *hexutil.Uint64   `json:"gasUsed" gencodec:"required"`
+		EffectiveGasPrice *hexutil.Big      `json:"effectiveGasPrice,omitempty"`
+		BlockHash         *common.Hash      `json:"blockHash,omitempty"`
+		BlockNumber       *hexutil.Big      `json:"blockNumber,omitempty"`
+		TransactionIndex  *hexutil.Uint     `json:"transactionIndex"`
 	}
 	var dec Receipt
 	if err := json.Unmarshal(input, &dec); err != nil {
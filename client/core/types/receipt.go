@@ -18,7 +18,7 @@ import (
 	"fmt"
 	"io"
 	"math/big"
-	"unsafe"
+	"sync/atomic"
 
 	"github.com/r5-labs/r5-core/client/common"
 	"github.com/r5-labs/r5-core/client/common/hexutil"
@@ -58,18 +58,21 @@ type Receipt struct {
 	TxHash            common.Hash    `json:"transactionHash" gencodec:"required"`
 	ContractAddress   common.Address `json:"contractAddress"`
 	GasUsed           uint64         `json:"gasUsed" gencodec:"required"`
-	EffectiveGasPrice *big.Int       `json:"effectiveGasPrice"`
+	EffectiveGasPrice *big.Int       `json:"effectiveGasPrice,omitempty"`
 
 	// Inclusion information: These fields provide information about the inclusion of the
 	// transaction corresponding to this receipt.
 	BlockHash        common.Hash `json:"blockHash,omitempty"`
 	BlockNumber      *big.Int    `json:"blockNumber,omitempty"`
 	TransactionIndex uint        `json:"transactionIndex"`
+
+	// caches
+	size atomic.Value
 }
 
 type receiptMarshaling struct {
 	Type              hexutil.Uint64
-	PostState         hexutil.Bytes
+	PostState         receiptPostState
 	Status            hexutil.Uint64
 	CumulativeGasUsed hexutil.Uint64
 	GasUsed           hexutil.Uint64
@@ -77,6 +80,29 @@ type receiptMarshaling struct {
 	TransactionIndex  hexutil.Uint
 }
 
+// receiptPostState is the JSON encoding of the receipt post state. An empty root
+// decodes to nil, matching receipts created with a status code instead.
+type receiptPostState []byte
+
+// MarshalText implements encoding.TextMarshaler.
+func (b receiptPostState) MarshalText() ([]byte, error) {
+	return hexutil.Bytes(b).MarshalText()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (b *receiptPostState) UnmarshalJSON(input []byte) error {
+	var dec hexutil.Bytes
+	if err := dec.UnmarshalJSON(input); err != nil {
+		return err
+	}
+	if len(dec) == 0 {
+		*b = nil
+	} else {
+		*b = receiptPostState(dec)
+	}
+	return nil
+}
+
 // receiptRLP is the consensus encoding of a receipt.
 type receiptRLP struct {
 	PostStateOrStatus []byte
@@ -231,14 +257,21 @@ func (r *Receipt) statusEncoding() []byte {
 	return r.PostState
 }
 
-// Size returns the approximate memory used by all internal contents. It is used
-// to approximate and limit the memory consumption of various caches.
+// Size returns the consensus encoding size of the receipt, including the type
+// byte of typed receipts, either by encoding and returning it, or returning a
+// previously cached value.
 func (r *Receipt) Size() common.StorageSize {
-	size := common.StorageSize(unsafe.Sizeof(*r)) + common.StorageSize(len(r.PostState))
-	size += common.StorageSize(len(r.Logs)) * common.StorageSize(unsafe.Sizeof(Log{}))
-	for _, log := range r.Logs {
-		size += common.StorageSize(len(log.Topics)*common.HashLength + len(log.Data))
+	if size := r.size.Load(); size != nil {
+		return size.(common.StorageSize)
 	}
+	c := writeCounter(0)
+	rlp.Encode(&c, &receiptRLP{r.statusEncoding(), r.CumulativeGasUsed, r.Bloom, r.Logs})
+
+	size := common.StorageSize(c)
+	if r.Type != LegacyTxType {
+		size += 1 // type byte
+	}
+	r.size.Store(size)
 	return size
 }
 
@@ -307,6 +340,17 @@ func (rs Receipts) EncodeIndex(i int, w *bytes.Buffer) {
 	}
 }
 
+// VerifyBlooms recomputes the bloom of each receipt from its logs, returning an
+// error for the first receipt whose stored bloom doesn't match.
+func (rs Receipts) VerifyBlooms() error {
+	for i, r := range rs {
+		if bloom := CreateBloom(Receipts{r}); bloom != r.Bloom {
+			return fmt.Errorf("receipt %d: bloom mismatch: have %x, want %x", i, r.Bloom, bloom)
+		}
+	}
+	return nil
+}
+
 // DeriveFields fills the receipts with their computed fields based on consensus
 // data and contextual infos like containing block and transactions.
 func (rs Receipts) DeriveFields(config *params.ChainConfig, hash common.Hash, number uint64, baseFee *big.Int, txs []*Transaction) error {
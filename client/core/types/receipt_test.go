@@ -18,6 +18,7 @@ import (
 	"math"
 	"math/big"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/r5-labs/r5-core/client/common"
@@ -394,6 +395,96 @@ func TestReceiptUnmarshalBinary(t *testing.T) {
 	}
 }
 
+func TestReceiptSize(t *testing.T) {
+	for _, receipt := range []*Receipt{legacyReceipt, accessListReceipt, eip1559Receipt} {
+		bin, err := receipt.MarshalBinary()
+		if err != nil {
+			t.Fatalf("type %d: marshal binary error: %v", receipt.Type, err)
+		}
+		if have, want := int(receipt.Size()), len(bin); have != want {
+			t.Errorf("type %d: size mismatch: have %d, want %d", receipt.Type, have, want)
+		}
+		// Second call is served from the cache.
+		if have, want := int(receipt.Size()), len(bin); have != want {
+			t.Errorf("type %d: cached size mismatch: have %d, want %d", receipt.Type, have, want)
+		}
+	}
+}
+
+func TestReceiptsVerifyBlooms(t *testing.T) {
+	receipts := make(Receipts, 0, 3)
+	for _, receipt := range []*Receipt{legacyReceipt, accessListReceipt, eip1559Receipt} {
+		cpy := &Receipt{
+			Type:              receipt.Type,
+			Status:            receipt.Status,
+			CumulativeGasUsed: receipt.CumulativeGasUsed,
+			Logs:              append([]*Log{}, receipt.Logs...),
+		}
+		cpy.Bloom = CreateBloom(Receipts{cpy})
+		receipts = append(receipts, cpy)
+	}
+	if err := receipts.VerifyBlooms(); err != nil {
+		t.Fatalf("failed to verify valid blooms: %v", err)
+	}
+	// Drop a log from the second receipt, leaving its bloom intact.
+	receipts[1].Logs = receipts[1].Logs[:1]
+	err := receipts.VerifyBlooms()
+	if err == nil {
+		t.Fatal("verified tampered receipt blooms")
+	}
+	if !strings.HasPrefix(err.Error(), "receipt 1:") {
+		t.Fatalf("mismatch reported for wrong receipt: %v", err)
+	}
+}
+
+func TestReceiptJSONRoundtrip(t *testing.T) {
+	for _, typ := range []uint8{LegacyTxType, AccessListTxType, DynamicFeeTxType} {
+		receipt := &Receipt{
+			Type:              typ,
+			Status:            ReceiptStatusSuccessful,
+			CumulativeGasUsed: 0x5208,
+			Logs: []*Log{{
+				Address:     common.BytesToAddress([]byte{0x11}),
+				Topics:      []common.Hash{common.HexToHash("dead"), common.HexToHash("beef")},
+				Data:        []byte{0x01, 0x00, 0xff},
+				BlockNumber: 1,
+				TxHash:      common.Hash{0x01},
+				BlockHash:   common.Hash{0x02},
+			}},
+			TxHash:            common.Hash{0x01},
+			ContractAddress:   common.Address{0x03},
+			GasUsed:           0x5208,
+			EffectiveGasPrice: big.NewInt(params.InitialBaseFee),
+			BlockHash:         common.Hash{0x02},
+			BlockNumber:       big.NewInt(1),
+			TransactionIndex:  1,
+		}
+		receipt.Bloom = CreateBloom(Receipts{receipt})
+
+		blob, err := json.Marshal(receipt)
+		if err != nil {
+			t.Fatalf("type %d: failed to marshal receipt: %v", typ, err)
+		}
+		var fields map[string]interface{}
+		if err := json.Unmarshal(blob, &fields); err != nil {
+			t.Fatalf("type %d: failed to decode receipt fields: %v", typ, err)
+		}
+		if _, ok := fields["type"]; ok != (typ != LegacyTxType) {
+			t.Errorf("type %d: type field presence mismatch: have %v, want %v", typ, ok, typ != LegacyTxType)
+		}
+		if _, ok := fields["effectiveGasPrice"]; !ok {
+			t.Errorf("type %d: effective gas price missing", typ)
+		}
+		dec := new(Receipt)
+		if err := json.Unmarshal(blob, dec); err != nil {
+			t.Fatalf("type %d: failed to unmarshal receipt: %v", typ, err)
+		}
+		if !reflect.DeepEqual(dec, receipt) {
+			t.Errorf("type %d: receipt mismatch after JSON roundtrip:\nhave %+v\nwant %+v", typ, dec, receipt)
+		}
+	}
+}
+
 func clearComputedFieldsOnReceipts(receipts []*Receipt) []*Receipt {
 	r := make([]*Receipt, len(receipts))
 	for i, receipt := range receipts {
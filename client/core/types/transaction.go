@@ -18,6 +18,7 @@ import (
 	"errors"
 	"io"
 	"math/big"
+	"sort"
 	"sync/atomic"
 	"time"
 
@@ -585,6 +586,71 @@ func (t *TransactionsByPriceAndNonce) Pop() {
 	heap.Pop(&t.heads)
 }
 
+// TransactionsBySenderAndNonce represents a set of transactions that can return
+// transactions ordered by sender address and then by nonce, regardless of their
+// price. The order only depends on the set itself, making it reproducible.
+type TransactionsBySenderAndNonce struct {
+	txs     map[common.Address]Transactions // Per account nonce-sorted list of transactions
+	senders []common.Address                // Accounts with remaining transactions, in ascending order
+	signer  Signer                          // Signer for the set of transactions
+	baseFee *big.Int                        // Current base fee
+}
+
+// NewTransactionsBySenderAndNonce creates a transaction set that can retrieve
+// sender sorted transactions in a nonce-honouring way.
+//
+// Note, the input map is reowned so the caller should not interact any more with
+// if after providing it to the constructor.
+func NewTransactionsBySenderAndNonce(signer Signer, txs map[common.Address]Transactions, baseFee *big.Int) *TransactionsBySenderAndNonce {
+	senders := make([]common.Address, 0, len(txs))
+	for from, accTxs := range txs {
+		acc, _ := Sender(signer, accTxs[0])
+		// Remove transaction if sender doesn't match from, or if its tip is negative.
+		if _, err := accTxs[0].EffectiveGasTip(baseFee); acc != from || err != nil {
+			delete(txs, from)
+			continue
+		}
+		senders = append(senders, from)
+	}
+	sort.Slice(senders, func(i, j int) bool {
+		return bytes.Compare(senders[i][:], senders[j][:]) < 0
+	})
+	return &TransactionsBySenderAndNonce{
+		txs:     txs,
+		senders: senders,
+		signer:  signer,
+		baseFee: baseFee,
+	}
+}
+
+// Peek returns the next transaction by sender and nonce.
+func (t *TransactionsBySenderAndNonce) Peek() *Transaction {
+	if len(t.senders) == 0 {
+		return nil
+	}
+	return t.txs[t.senders[0]][0]
+}
+
+// Shift replaces the current head with the next one from the same account.
+func (t *TransactionsBySenderAndNonce) Shift() {
+	acc := t.senders[0]
+	if txs := t.txs[acc][1:]; len(txs) > 0 {
+		if _, err := txs[0].EffectiveGasTip(t.baseFee); err == nil {
+			t.txs[acc] = txs
+			return
+		}
+	}
+	t.Pop()
+}
+
+// Pop removes the current head, *not* replacing it with the next one from the
+// same account. This should be used when a transaction cannot be executed and
+// hence all subsequent ones should be discarded from the same account.
+func (t *TransactionsBySenderAndNonce) Pop() {
+	delete(t.txs, t.senders[0])
+	t.senders = t.senders[1:]
+}
+
 // copyAddressPtr copies an address.
 func copyAddressPtr(a *common.Address) *common.Address {
 	if a == nil {
@@ -26,6 +26,7 @@ import (
 
 	"github.com/r5-labs/r5-core/client/common"
 	"github.com/r5-labs/r5-core/client/crypto"
+	"github.com/r5-labs/r5-core/client/params"
 	"github.com/r5-labs/r5-core/client/rlp"
 )
 
@@ -595,3 +596,55 @@ func TestTransactionSizes(t *testing.T) {
 		}
 	}
 }
+
+// Tests that ValidateForPool rejects transactions whose fields or size fall
+// outside of the bounds enforced by the transaction pool.
+func TestValidateForPool(t *testing.T) {
+	var (
+		config   = params.TestChainConfig
+		baseFee  = big.NewInt(params.InitialBaseFee)
+		oversize = new(big.Int).Lsh(common.Big1, 256)
+		frontier = &params.ChainConfig{ChainID: big.NewInt(1), HomesteadBlock: big.NewInt(0)}
+	)
+	legacy := func(gas uint64, price *big.Int, value *big.Int, data []byte) *Transaction {
+		return NewTx(&LegacyTx{To: &testAddr, Gas: gas, GasPrice: price, Value: value, Data: data})
+	}
+	accessList := func(gas uint64, list AccessList) *Transaction {
+		return NewTx(&AccessListTx{ChainID: config.ChainID, To: &testAddr, Gas: gas, GasPrice: baseFee, Value: common.Big0, AccessList: list})
+	}
+	dynamic := func(gas uint64, feeCap, tipCap *big.Int) *Transaction {
+		return NewTx(&DynamicFeeTx{ChainID: config.ChainID, To: &testAddr, Gas: gas, GasFeeCap: feeCap, GasTipCap: tipCap, Value: common.Big0})
+	}
+	list := AccessList{{Address: testAddr, StorageKeys: []common.Hash{{}}}}
+	listGas := params.TxGas + params.TxAccessListAddressGas + params.TxAccessListStorageKeyGas
+
+	tests := []struct {
+		name    string
+		tx      *Transaction
+		config  *params.ChainConfig
+		baseFee *big.Int
+		want    error
+	}{
+		{"legacy", legacy(params.TxGas, baseFee, common.Big1, nil), config, baseFee, nil},
+		{"legacy-no-basefee", legacy(params.TxGas, common.Big1, common.Big0, nil), config, nil, nil},
+		{"legacy-negative-value", legacy(params.TxGas, baseFee, big.NewInt(-1), nil), config, baseFee, ErrNegativeValue},
+		{"legacy-intrinsic-gas", legacy(params.TxGas-1, baseFee, common.Big0, nil), config, baseFee, ErrIntrinsicGas},
+		{"legacy-data-gas", legacy(params.TxGas, baseFee, common.Big0, []byte{1}), config, baseFee, ErrIntrinsicGas},
+		{"legacy-oversized", legacy(params.TxGas, baseFee, common.Big0, make([]byte, MaxTxSize)), config, baseFee, ErrOversizedData},
+		{"legacy-below-basefee", legacy(params.TxGas, common.Big1, common.Big0, nil), config, baseFee, ErrFeeCapTooLow},
+		{"access-list", accessList(listGas, list), config, baseFee, nil},
+		{"access-list-intrinsic-gas", accessList(listGas-1, list), config, baseFee, ErrIntrinsicGas},
+		{"access-list-unsupported", accessList(listGas, list), frontier, nil, ErrTxTypeNotSupported},
+		{"dynamic", dynamic(params.TxGas, baseFee, common.Big1), config, baseFee, nil},
+		{"dynamic-unsupported", dynamic(params.TxGas, baseFee, common.Big1), frontier, nil, ErrTxTypeNotSupported},
+		{"dynamic-feecap-very-high", dynamic(params.TxGas, oversize, common.Big1), config, baseFee, ErrFeeCapVeryHigh},
+		{"dynamic-tip-very-high", dynamic(params.TxGas, baseFee, oversize), config, baseFee, ErrTipVeryHigh},
+		{"dynamic-tip-above-feecap", dynamic(params.TxGas, baseFee, new(big.Int).Add(baseFee, common.Big1)), config, baseFee, ErrTipAboveFeeCap},
+		{"dynamic-below-basefee", dynamic(params.TxGas, common.Big1, common.Big1), config, baseFee, ErrFeeCapTooLow},
+	}
+	for _, test := range tests {
+		if err := test.tx.ValidateForPool(test.config, test.baseFee); !errors.Is(err, test.want) {
+			t.Errorf("%s: error mismatch: have %v, want %v", test.name, err, test.want)
+		}
+	}
+}
@@ -20,16 +20,22 @@ import (
 	"io"
 	"math/big"
 	"os"
+	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/r5-labs/r5-core/client"
 	"github.com/r5-labs/r5-core/client/common"
 	"github.com/r5-labs/r5-core/client/common/hexutil"
+	"github.com/r5-labs/r5-core/client/consensus/ethash"
 	"github.com/r5-labs/r5-core/client/core"
 	"github.com/r5-labs/r5-core/client/core/rawdb"
 	"github.com/r5-labs/r5-core/client/core/state"
 	"github.com/r5-labs/r5-core/client/core/types"
+	"github.com/r5-labs/r5-core/client/core/vm"
+	"github.com/r5-labs/r5-core/client/crypto"
 	"github.com/r5-labs/r5-core/client/internal/ethapi"
 	"github.com/r5-labs/r5-core/client/log"
 	"github.com/r5-labs/r5-core/client/rlp"
@@ -130,6 +136,453 @@ func (api *MinerAPI) SetRecommitInterval(interval int) {
 	api.e.Miner().SetRecommitInterval(time.Duration(interval) * time.Millisecond)
 }
 
+// BlockTemplate is a block assembled by the miner but not sealed yet, handed out
+// to external sealers which need more than the eth_getWork work package.
+type BlockTemplate struct {
+	SealHash     common.Hash     `json:"sealHash"`
+	Header       *types.Header   `json:"header"`
+	Transactions []hexutil.Bytes `json:"transactions"`
+	Reward       *hexutil.Big    `json:"reward"`
+	Fees         *hexutil.Big    `json:"fees"`
+	Target       common.Hash     `json:"target"`
+}
+
+// BlockTemplateAPI provides block templates to external sealers and imports
+// the blocks they seal.
+type BlockTemplateAPI struct {
+	e *Ethereum
+}
+
+// NewBlockTemplateAPI creates a new BlockTemplateAPI instance.
+func NewBlockTemplateAPI(e *Ethereum) *BlockTemplateAPI {
+	return &BlockTemplateAPI{e}
+}
+
+// GetBlockTemplate assembles a new block with the pending transactions on top
+// of the current head and returns it unsealed, along with the block reward and
+// fees credited to the coinbase, and the proof-of-work target to meet.
+func (api *BlockTemplateAPI) GetBlockTemplate() (*BlockTemplate, error) {
+	block, fees, err := api.e.Miner().BlockTemplate()
+	if err != nil {
+		return nil, err
+	}
+	txs := make([]hexutil.Bytes, len(block.Transactions()))
+	for i, tx := range block.Transactions() {
+		if txs[i], err = tx.MarshalBinary(); err != nil {
+			return nil, err
+		}
+	}
+	return &BlockTemplate{
+		SealHash:     api.e.engine.SealHash(block.Header()),
+		Header:       block.Header(),
+		Transactions: txs,
+		Reward:       (*hexutil.Big)(ethash.BlockReward(block.NumberU64())),
+		Fees:         (*hexutil.Big)(fees),
+		Target:       common.BigToHash(ethash.DifficultyToTarget(block.Difficulty())),
+	}, nil
+}
+
+// SubmitBlockTemplate seals the block template with the given seal hash using
+// the provided proof-of-work solution, imports it and returns its hash.
+func (api *BlockTemplateAPI) SubmitBlockTemplate(sealHash common.Hash, nonce types.BlockNonce, mixDigest common.Hash) (common.Hash, error) {
+	block, err := api.e.Miner().SubmitBlockTemplate(sealHash, nonce, mixDigest)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return block.Hash(), nil
+}
+
+// TrieProof is the Merkle proof of an account and one of its storage slots in
+// the state with the given root.
+type TrieProof struct {
+	StateRoot    common.Hash     `json:"stateRoot"`
+	Address      common.Address  `json:"address"`
+	AccountProof []hexutil.Bytes `json:"accountProof"`
+	StorageHash  common.Hash     `json:"storageHash"`
+	StorageKey   common.Hash     `json:"storageKey"`
+	StorageValue common.Hash     `json:"storageValue"`
+	StorageProof []hexutil.Bytes `json:"storageProof"`
+}
+
+// TrieProofAPI provides Merkle proofs taken directly from the trie database.
+type TrieProofAPI struct {
+	eth *Ethereum
+}
+
+// NewTrieProofAPI creates a new TrieProofAPI instance.
+func NewTrieProofAPI(eth *Ethereum) *TrieProofAPI {
+	return &TrieProofAPI{eth}
+}
+
+// GetTrieProof returns the Merkle proof of the account and the storage slot
+// against the given state root, which doesn't need to belong to a canonical
+// block, as long as its state is still present in the trie database. For a
+// missing account or slot, the proofs prove their absence.
+func (api *TrieProofAPI) GetTrieProof(root common.Hash, address common.Address, storageKey common.Hash) (*TrieProof, error) {
+	return trieProof(api.eth.BlockChain().StateCache().TrieDB(), root, address, storageKey)
+}
+
+// proofList collects the nodes of a Merkle proof from the root downwards.
+type proofList []hexutil.Bytes
+
+func (n *proofList) Put(key []byte, value []byte) error {
+	*n = append(*n, common.CopyBytes(value))
+	return nil
+}
+
+func (n *proofList) Delete(key []byte) error {
+	panic("not supported")
+}
+
+// trieProof proves the account and its storage slot from the trie database.
+func trieProof(triedb *trie.Database, root common.Hash, address common.Address, storageKey common.Hash) (*TrieProof, error) {
+	accTrie, err := trie.NewStateTrie(trie.StateTrieID(root), triedb)
+	if err != nil {
+		return nil, err
+	}
+	var accountProof proofList
+	if err := accTrie.Prove(crypto.Keccak256(address.Bytes()), 0, &accountProof); err != nil {
+		return nil, err
+	}
+	result := &TrieProof{
+		StateRoot:    root,
+		Address:      address,
+		AccountProof: accountProof,
+		StorageHash:  types.EmptyRootHash,
+		StorageKey:   storageKey,
+		StorageProof: []hexutil.Bytes{},
+	}
+	account, err := accTrie.GetAccount(address)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil || account.Root == types.EmptyRootHash {
+		return result, nil
+	}
+	id := trie.StorageTrieID(root, crypto.Keccak256Hash(address.Bytes()), account.Root)
+	storageTrie, err := trie.NewStateTrie(id, triedb)
+	if err != nil {
+		return nil, err
+	}
+	var storageProof proofList
+	if err := storageTrie.Prove(crypto.Keccak256(storageKey.Bytes()), 0, &storageProof); err != nil {
+		return nil, err
+	}
+	enc, err := storageTrie.GetStorage(address, storageKey.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	if len(enc) > 0 {
+		_, content, _, err := rlp.Split(enc)
+		if err != nil {
+			return nil, err
+		}
+		result.StorageValue = common.BytesToHash(content)
+	}
+	result.StorageHash = account.Root
+	result.StorageProof = storageProof
+	return result, nil
+}
+
+// healthTimeout is the maximum time the health check waits for the status of
+// the node's subsystems.
+const healthTimeout = 2 * time.Second
+
+// Health summarizes the liveness of the node. Subsystems which failed to report
+// their status within the health check timeout are listed as incomplete.
+type Health struct {
+	Synced       bool           `json:"synced"`
+	CurrentBlock hexutil.Uint64 `json:"currentBlock"`
+	HighestBlock hexutil.Uint64 `json:"highestBlock"`
+	PeerCount    hexutil.Uint   `json:"peerCount"`
+	Mining       bool           `json:"mining"`
+	Hashrate     hexutil.Uint64 `json:"hashrate"`
+	Incomplete   []string       `json:"incomplete,omitempty"`
+}
+
+// healthBackend is the part of the node the health check is assembled from.
+type healthBackend interface {
+	Synced() bool
+	SyncProgress() ethereum.SyncProgress
+	PeerCount() int
+	IsMining() bool
+	Hashrate() uint64
+}
+
+// ethHealthBackend provides the health check with the status of a full node.
+type ethHealthBackend struct {
+	eth *Ethereum
+}
+
+func (b ethHealthBackend) Synced() bool                        { return b.eth.Synced() }
+func (b ethHealthBackend) SyncProgress() ethereum.SyncProgress { return b.eth.Downloader().Progress() }
+func (b ethHealthBackend) PeerCount() int                      { return b.eth.p2pServer.PeerCount() }
+func (b ethHealthBackend) IsMining() bool                      { return b.eth.IsMining() }
+func (b ethHealthBackend) Hashrate() uint64                    { return b.eth.Miner().Hashrate() }
+
+// HealthAPI provides a liveness summary of the node for monitoring.
+type HealthAPI struct {
+	b       healthBackend
+	timeout time.Duration
+}
+
+// NewHealthAPI creates a new HealthAPI instance.
+func NewHealthAPI(eth *Ethereum) *HealthAPI {
+	return &HealthAPI{b: ethHealthBackend{eth}, timeout: healthTimeout}
+}
+
+// Health returns the sync, peering and mining status of the node. The status
+// of each subsystem is retrieved concurrently, and the ones not reporting back
+// in time are left out, so the call returns timely even if some are stuck.
+func (api *HealthAPI) Health(ctx context.Context) *Health {
+	type report struct {
+		name  string
+		apply func(*Health)
+	}
+	probes := map[string]func() func(*Health){
+		"sync": func() func(*Health) {
+			synced, progress := api.b.Synced(), api.b.SyncProgress()
+			return func(h *Health) {
+				h.Synced = synced
+				h.CurrentBlock = hexutil.Uint64(progress.CurrentBlock)
+				h.HighestBlock = hexutil.Uint64(progress.HighestBlock)
+				if h.HighestBlock < h.CurrentBlock {
+					h.HighestBlock = h.CurrentBlock
+				}
+			}
+		},
+		"peers": func() func(*Health) {
+			peers := api.b.PeerCount()
+			return func(h *Health) { h.PeerCount = hexutil.Uint(peers) }
+		},
+		"mining": func() func(*Health) {
+			mining, hashrate := api.b.IsMining(), api.b.Hashrate()
+			return func(h *Health) {
+				h.Mining = mining
+				h.Hashrate = hexutil.Uint64(hashrate)
+			}
+		},
+	}
+	reports := make(chan report, len(probes))
+	for name, probe := range probes {
+		go func(name string, probe func() func(*Health)) {
+			reports <- report{name, probe()}
+		}(name, probe)
+	}
+	var (
+		health  = new(Health)
+		pending = make(map[string]struct{}, len(probes))
+		timeout = time.NewTimer(api.timeout)
+	)
+	defer timeout.Stop()
+	for name := range probes {
+		pending[name] = struct{}{}
+	}
+loop:
+	for len(pending) > 0 {
+		select {
+		case r := <-reports:
+			r.apply(health)
+			delete(pending, r.name)
+		case <-timeout.C:
+			break loop
+		case <-ctx.Done():
+			break loop
+		}
+	}
+	for name := range pending {
+		health.Incomplete = append(health.Incomplete, name)
+	}
+	sort.Strings(health.Incomplete)
+	return health
+}
+
+// GasOracleAPI provides an API to tune the gas price oracle of a running node.
+// It's served in the admin namespace, as it's meant for the node operator only.
+type GasOracleAPI struct {
+	eth *Ethereum
+}
+
+// NewGasOracleAPI creates a new GasOracleAPI instance.
+func NewGasOracleAPI(eth *Ethereum) *GasOracleAPI {
+	return &GasOracleAPI{eth: eth}
+}
+
+// SetGasOracle updates the sample percentile, the price cap and the number of
+// sampled blocks of the oracle serving eth_gasPrice and eth_maxPriorityFeePerGas.
+// Omitted settings are left unchanged.
+func (api *GasOracleAPI) SetGasOracle(percentile *int, maxPrice *hexutil.Big, blocks *int) (bool, error) {
+	if err := api.eth.APIBackend.gpo.SetParams(blocks, percentile, (*big.Int)(maxPrice)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// miningStatusBlocks is the number of recent blocks the network hashrate is
+// estimated over.
+const miningStatusBlocks = 32
+
+// MiningStatus is the proof-of-work status of the chain head.
+type MiningStatus struct {
+	Number          hexutil.Uint64 `json:"number"`
+	Hash            common.Hash    `json:"hash"`
+	Difficulty      *hexutil.Big   `json:"difficulty"`
+	Target          *hexutil.Big   `json:"target"`
+	NetworkHashrate *hexutil.Big   `json:"networkHashrate"`
+	Mining          bool           `json:"mining"`
+}
+
+// MiningStatusAPI provides the live proof-of-work target to external miners
+// and dashboards.
+type MiningStatusAPI struct {
+	eth *Ethereum
+}
+
+// NewMiningStatusAPI creates a new MiningStatusAPI instance.
+func NewMiningStatusAPI(eth *Ethereum) *MiningStatusAPI {
+	return &MiningStatusAPI{eth: eth}
+}
+
+// MiningStatus returns the difficulty and target of the current head, the
+// network hashrate estimated over the recent blocks and whether the node is
+// mining locally.
+func (api *MiningStatusAPI) MiningStatus() (*MiningStatus, error) {
+	head := api.eth.blockchain.CurrentHeader()
+	if head.Difficulty.Sign() <= 0 {
+		return nil, errors.New("chain head has no proof-of-work difficulty")
+	}
+	// Estimate the hashrate as the work done over the time it took, summing
+	// the difficulties of the blocks after the oldest one in the window.
+	var (
+		work   = new(big.Int)
+		oldest = head
+	)
+	for i := 0; i < miningStatusBlocks && oldest.Number.Sign() > 0; i++ {
+		parent := api.eth.blockchain.GetHeader(oldest.ParentHash, oldest.Number.Uint64()-1)
+		if parent == nil {
+			break
+		}
+		work.Add(work, oldest.Difficulty)
+		oldest = parent
+	}
+	hashrate := new(big.Int)
+	if head.Time > oldest.Time {
+		hashrate.Div(work, new(big.Int).SetUint64(head.Time-oldest.Time))
+	}
+	return &MiningStatus{
+		Number:          hexutil.Uint64(head.Number.Uint64()),
+		Hash:            head.Hash(),
+		Difficulty:      (*hexutil.Big)(head.Difficulty),
+		Target:          (*hexutil.Big)(ethash.DifficultyToTarget(head.Difficulty)),
+		NetworkHashrate: (*hexutil.Big)(hashrate),
+		Mining:          api.eth.IsMining(),
+	}, nil
+}
+
+// TxPoolImport is the outcome of importing a transaction pool dump.
+type TxPoolImport struct {
+	Imported hexutil.Uint `json:"imported"`
+	Skipped  hexutil.Uint `json:"skipped"`
+}
+
+// TxPoolStoreAPI provides an API to persist the local transactions of the pool
+// across restarts. As it accesses the local filesystem and is meant for the node
+// operator only, it's not served over HTTP or WebSocket.
+type TxPoolStoreAPI struct {
+	eth *Ethereum
+}
+
+// NewTxPoolStoreAPI creates a new TxPoolStoreAPI instance.
+func NewTxPoolStoreAPI(eth *Ethereum) *TxPoolStoreAPI {
+	return &TxPoolStoreAPI{eth: eth}
+}
+
+// checkDumpPath ensures a pool dump location is an absolute path without any
+// parent directory references, leaving no doubt about the file accessed.
+func checkDumpPath(file string) error {
+	if !filepath.IsAbs(file) {
+		return errors.New("location must be an absolute path")
+	}
+	for _, elem := range strings.Split(filepath.ToSlash(file), "/") {
+		if elem == ".." {
+			return errors.New("location must not traverse parent directories")
+		}
+	}
+	return nil
+}
+
+// Export writes the pending and queued transactions of all the local accounts
+// into a local file as a stream of RLP encoded transactions, and returns the
+// number of transactions written.
+func (api *TxPoolStoreAPI) Export(file string) (hexutil.Uint, error) {
+	if err := checkDumpPath(file); err != nil {
+		return 0, err
+	}
+	if _, err := os.Stat(file); err == nil {
+		// File already exists. Allowing overwrite could be a DoS vector,
+		// since the 'file' may point to arbitrary paths on the drive.
+		return 0, errors.New("location would overwrite an existing file")
+	}
+	out, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer out.Close()
+
+	var (
+		pool  = api.eth.TxPool()
+		count hexutil.Uint
+	)
+	for _, addr := range pool.Locals() {
+		pending, queued := pool.ContentFrom(addr)
+		for _, tx := range append(pending, queued...) {
+			if err := rlp.Encode(out, tx); err != nil {
+				return 0, err
+			}
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Import re-adds the transactions of a dump created by Export to the pool as
+// local ones. Transactions the pool refuses, e.g. because they have been mined
+// or replaced in the meantime, are skipped and counted as such.
+func (api *TxPoolStoreAPI) Import(file string) (*TxPoolImport, error) {
+	if err := checkDumpPath(file); err != nil {
+		return nil, err
+	}
+	in, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+
+	var (
+		stream = rlp.NewStream(in, 0)
+		txs    []*types.Transaction
+	)
+	for {
+		tx := new(types.Transaction)
+		if err := stream.Decode(tx); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("transaction %d: failed to parse: %v", len(txs), err)
+		}
+		txs = append(txs, tx)
+	}
+	result := new(TxPoolImport)
+	for i, err := range api.eth.TxPool().AddLocals(txs) {
+		if err != nil {
+			log.Debug("Skipped imported transaction", "hash", txs[i].Hash(), "err", err)
+			result.Skipped++
+			continue
+		}
+		result.Imported++
+	}
+	return result, nil
+}
+
 // AdminAPI is the collection of Ethereum full node related APIs for node
 // administration.
 type AdminAPI struct {
@@ -598,6 +1051,73 @@ func (api *DebugAPI) GetAccessibleState(from, to rpc.BlockNumber) (uint64, error
 	return 0, errors.New("no state found")
 }
 
+// StateRootResult is the outcome of re-executing a block on top of its parent
+// state, as returned by debug_recomputeStateRoot.
+type StateRootResult struct {
+	Number         hexutil.Uint64 `json:"number"`
+	Hash           common.Hash    `json:"hash"`
+	StoredRoot     common.Hash    `json:"storedRoot"`
+	RecomputedRoot common.Hash    `json:"recomputedRoot"`
+	Match          bool           `json:"match"`
+}
+
+// RecomputeStateRoot re-executes the transactions of the given block on top of
+// its parent state and compares the resulting state root to the one stored in
+// the block header. A mismatch hints at a corrupted parent state.
+func (api *DebugAPI) RecomputeStateRoot(blockNrOrHash rpc.BlockNumberOrHash) (*StateRootResult, error) {
+	var block *types.Block
+	if number, ok := blockNrOrHash.Number(); ok {
+		var header *types.Header
+		switch number {
+		case rpc.PendingBlockNumber:
+			return nil, errors.New("pending block has no stored state root")
+		case rpc.LatestBlockNumber:
+			header = api.eth.blockchain.CurrentBlock()
+		case rpc.FinalizedBlockNumber:
+			header = api.eth.blockchain.CurrentFinalBlock()
+		case rpc.SafeBlockNumber:
+			header = api.eth.blockchain.CurrentSafeBlock()
+		default:
+			header = api.eth.blockchain.GetHeaderByNumber(uint64(number))
+		}
+		if header != nil {
+			block = api.eth.blockchain.GetBlock(header.Hash(), header.Number.Uint64())
+		}
+		if block == nil {
+			return nil, fmt.Errorf("block #%d not found", number)
+		}
+	} else if hash, ok := blockNrOrHash.Hash(); ok {
+		block = api.eth.blockchain.GetBlockByHash(hash)
+		if block == nil {
+			return nil, fmt.Errorf("block %s not found", hash.Hex())
+		}
+	} else {
+		return nil, errors.New("either block number or block hash must be specified")
+	}
+	if block.NumberU64() == 0 {
+		return nil, errors.New("genesis block has no parent state to execute on")
+	}
+	parent := api.eth.blockchain.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, fmt.Errorf("parent block %s not found", block.ParentHash().Hex())
+	}
+	statedb, err := api.eth.blockchain.StateAt(parent.Root)
+	if err != nil {
+		return nil, fmt.Errorf("parent state unavailable: %v", err)
+	}
+	if _, _, _, err := api.eth.blockchain.Processor().Process(block, statedb, vm.Config{}); err != nil {
+		return nil, fmt.Errorf("failed to re-execute block: %v", err)
+	}
+	root := statedb.IntermediateRoot(api.eth.blockchain.Config().IsEIP158(block.Number()))
+	return &StateRootResult{
+		Number:         hexutil.Uint64(block.NumberU64()),
+		Hash:           block.Hash(),
+		StoredRoot:     block.Root(),
+		RecomputedRoot: root,
+		Match:          root == block.Root(),
+	}, nil
+}
+
 // SetTrieFlushInterval configures how often in-memory tries are persisted
 // to disk. The value is in terms of block processing time, not wall clock.
 func (api *DebugAPI) SetTrieFlushInterval(interval string) error {
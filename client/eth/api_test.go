@@ -14,17 +14,35 @@ package eth
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"math/big"
+	"path/filepath"
 	"reflect"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
+	"github.com/r5-labs/r5-core/client"
 	"github.com/r5-labs/r5-core/client/common"
+	"github.com/r5-labs/r5-core/client/common/hexutil"
+	"github.com/r5-labs/r5-core/client/consensus/ethash"
+	"github.com/r5-labs/r5-core/client/core"
 	"github.com/r5-labs/r5-core/client/core/rawdb"
 	"github.com/r5-labs/r5-core/client/core/state"
+	"github.com/r5-labs/r5-core/client/core/txpool"
+	"github.com/r5-labs/r5-core/client/core/types"
+	"github.com/r5-labs/r5-core/client/core/vm"
 	"github.com/r5-labs/r5-core/client/crypto"
+	"github.com/r5-labs/r5-core/client/eth/ethconfig"
+	"github.com/r5-labs/r5-core/client/eth/gasprice"
+	"github.com/r5-labs/r5-core/client/ethdb/memorydb"
+	"github.com/r5-labs/r5-core/client/internal/ethapi"
+	"github.com/r5-labs/r5-core/client/node"
+	"github.com/r5-labs/r5-core/client/params"
+	"github.com/r5-labs/r5-core/client/rlp"
+	"github.com/r5-labs/r5-core/client/rpc"
 	"github.com/r5-labs/r5-core/client/trie"
 )
 
@@ -219,3 +237,509 @@ func TestStorageRangeAt(t *testing.T) {
 		}
 	}
 }
+
+// Tests that r5_getTrieProof proves accounts and storage slots of historical
+// states, including the absence of missing ones.
+func TestGetTrieProof(t *testing.T) {
+	var (
+		contract = common.Address{0x01}
+		slot     = common.Hash{0x02}
+		value    = common.Hash{0x03}
+		gspec    = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: core.GenesisAlloc{
+				contract: {Balance: big.NewInt(1), Code: []byte{0x00}, Storage: map[common.Hash]common.Hash{slot: value}},
+			},
+		}
+	)
+	_, blocks, _ := core.GenerateChainWithGenesis(gspec, ethash.NewFaker(), 4, func(i int, gen *core.BlockGen) {
+		gen.SetCoinbase(common.Address{0xc0})
+	})
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("r5", NewTrieProofAPI(&Ethereum{blockchain: chain})); err != nil {
+		t.Fatalf("failed to register API: %v", err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	getProof := func(root common.Hash, address common.Address, key common.Hash) *TrieProof {
+		var proof TrieProof
+		if err := client.Call(&proof, "r5_getTrieProof", root, address, key); err != nil {
+			t.Fatalf("failed to get trie proof: %v", err)
+		}
+		return &proof
+	}
+	verify := func(root common.Hash, key []byte, nodes []hexutil.Bytes) []byte {
+		db := memorydb.New()
+		for _, node := range nodes {
+			db.Put(crypto.Keccak256(node), node)
+		}
+		value, err := trie.VerifyProof(root, crypto.Keccak256(key), db)
+		if err != nil {
+			t.Fatalf("failed to verify proof: %v", err)
+		}
+		return value
+	}
+	root := chain.Genesis().Root()
+	proof := getProof(root, contract, slot)
+
+	var account types.StateAccount
+	if err := rlp.DecodeBytes(verify(root, contract.Bytes(), proof.AccountProof), &account); err != nil {
+		t.Fatalf("failed to decode proven account: %v", err)
+	}
+	if account.Root != proof.StorageHash || account.Root == types.EmptyRootHash {
+		t.Fatalf("storage root mismatch: have %x, proven %x", proof.StorageHash, account.Root)
+	}
+	var proven []byte
+	if err := rlp.DecodeBytes(verify(account.Root, slot.Bytes(), proof.StorageProof), &proven); err != nil {
+		t.Fatalf("failed to decode proven slot: %v", err)
+	}
+	if common.BytesToHash(proven) != value || proof.StorageValue != value {
+		t.Errorf("slot value mismatch: have %x, proven %x, want %x", proof.StorageValue, proven, value)
+	}
+	// Missing slots and accounts are proven absent.
+	proof = getProof(root, contract, common.Hash{0xff})
+	if value := verify(account.Root, common.Hash{0xff}.Bytes(), proof.StorageProof); value != nil {
+		t.Errorf("missing slot proven present: %x", value)
+	}
+	proof = getProof(root, common.Address{0xff}, slot)
+	if value := verify(root, common.Address{0xff}.Bytes(), proof.AccountProof); value != nil {
+		t.Errorf("missing account proven present: %x", value)
+	}
+	if len(proof.StorageProof) != 0 || proof.StorageHash != types.EmptyRootHash {
+		t.Errorf("storage proven for missing account: %+v", proof)
+	}
+	// Unknown roots are rejected.
+	var unknown TrieProof
+	if err := client.Call(&unknown, "r5_getTrieProof", common.Hash{0x01}, contract, slot); err == nil {
+		t.Error("proved against unknown state root")
+	}
+}
+
+// Tests that retuning the gas price oracle is reflected in the gas price
+// suggested over a synthesized block history.
+func TestSetGasOracle(t *testing.T) {
+	var (
+		key, _ = crypto.GenerateKey()
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		gspec  = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  core.GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}},
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	// Every block carries a single transaction tipping one more gwei than the
+	// previous one, from 1 gwei in the first block to 8 gwei in the head.
+	_, blocks, _ := core.GenerateChainWithGenesis(gspec, ethash.NewFaker(), 8, func(i int, gen *core.BlockGen) {
+		gen.SetCoinbase(common.Address{0xc0})
+		gen.AddTx(types.MustSignNewTx(key, signer, &types.DynamicFeeTx{
+			ChainID:   gspec.Config.ChainID,
+			Nonce:     gen.TxNonce(addr),
+			To:        &common.Address{},
+			Gas:       params.TxGas,
+			GasFeeCap: big.NewInt(100 * params.GWei),
+			GasTipCap: big.NewInt(int64(i+1) * params.GWei),
+		}))
+	})
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	eth := &Ethereum{blockchain: chain}
+	eth.APIBackend = &EthAPIBackend{eth: eth}
+	eth.APIBackend.gpo = gasprice.NewOracle(eth.APIBackend, gasprice.Config{
+		Blocks:     3,
+		Percentile: 60,
+		Default:    big.NewInt(params.GWei),
+	})
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("eth", ethapi.NewEthereumAPI(eth.APIBackend)); err != nil {
+		t.Fatalf("failed to register API: %v", err)
+	}
+	if err := server.RegisterName("admin", NewGasOracleAPI(eth)); err != nil {
+		t.Fatalf("failed to register API: %v", err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	baseFee := chain.CurrentBlock().BaseFee
+	checkPrice := func(tip int64) {
+		t.Helper()
+		var price hexutil.Big
+		if err := client.Call(&price, "eth_gasPrice"); err != nil {
+			t.Fatalf("failed to retrieve gas price: %v", err)
+		}
+		want := new(big.Int).Add(baseFee, big.NewInt(tip*params.GWei))
+		if price.ToInt().Cmp(want) != 0 {
+			t.Fatalf("gas price mismatch: have %v, want %v", price.ToInt(), want)
+		}
+	}
+	setOracle := func(args ...interface{}) error {
+		var ok bool
+		return client.Call(&ok, "admin_setGasOracle", args...)
+	}
+	// The tips sampled from the last 3 blocks, doubled over the blocks with a
+	// single sample, are 3 to 8 gwei.
+	checkPrice(6)
+
+	if err := setOracle(0); err != nil {
+		t.Fatalf("failed to set percentile: %v", err)
+	}
+	checkPrice(3)
+	if err := setOracle(100); err != nil {
+		t.Fatalf("failed to set percentile: %v", err)
+	}
+	checkPrice(8)
+	if err := setOracle(nil, (*hexutil.Big)(big.NewInt(5*params.GWei))); err != nil {
+		t.Fatalf("failed to set price cap: %v", err)
+	}
+	checkPrice(5)
+	if err := setOracle(nil, nil, 1); err != nil {
+		t.Fatalf("failed to set sample blocks: %v", err)
+	}
+	if err := setOracle(0, (*hexutil.Big)(big.NewInt(100*params.GWei))); err != nil {
+		t.Fatalf("failed to reset oracle: %v", err)
+	}
+	checkPrice(7)
+
+	// Out of range settings are rejected, leaving the oracle untouched.
+	for _, args := range [][]interface{}{{-1}, {101}, {nil, (*hexutil.Big)(big.NewInt(0))}, {nil, nil, 0}} {
+		if err := setOracle(args...); err == nil {
+			t.Errorf("invalid settings %v accepted", args)
+		}
+	}
+	checkPrice(7)
+}
+
+// testHealthBackend is a mocked node status for the health check.
+type testHealthBackend struct {
+	synced   bool
+	progress ethereum.SyncProgress
+	peers    int
+	mining   bool
+	hashrate uint64
+	stall    chan struct{} // Blocks the peer count until closed, if set
+}
+
+func (b *testHealthBackend) Synced() bool                        { return b.synced }
+func (b *testHealthBackend) SyncProgress() ethereum.SyncProgress { return b.progress }
+func (b *testHealthBackend) IsMining() bool                      { return b.mining }
+func (b *testHealthBackend) Hashrate() uint64                    { return b.hashrate }
+
+func (b *testHealthBackend) PeerCount() int {
+	if b.stall != nil {
+		<-b.stall
+	}
+	return b.peers
+}
+
+func TestHealth(t *testing.T) {
+	tests := []struct {
+		backend *testHealthBackend
+		want    Health
+	}{
+		// Synced node mining on top of the head
+		{
+			backend: &testHealthBackend{synced: true, progress: ethereum.SyncProgress{CurrentBlock: 100}, peers: 5, mining: true, hashrate: 1234},
+			want:    Health{Synced: true, CurrentBlock: 100, HighestBlock: 100, PeerCount: 5, Mining: true, Hashrate: 1234},
+		},
+		// Syncing node not mining
+		{
+			backend: &testHealthBackend{progress: ethereum.SyncProgress{CurrentBlock: 10, HighestBlock: 200}, peers: 2},
+			want:    Health{CurrentBlock: 10, HighestBlock: 200, PeerCount: 2},
+		},
+	}
+	for i, test := range tests {
+		api := &HealthAPI{b: test.backend, timeout: time.Second}
+		if have := api.Health(context.Background()); !reflect.DeepEqual(*have, test.want) {
+			t.Errorf("test %d: health mismatch: have %+v, want %+v", i, *have, test.want)
+		}
+	}
+	// A stuck subsystem is reported as incomplete without delaying the others.
+	backend := &testHealthBackend{synced: true, progress: ethereum.SyncProgress{CurrentBlock: 7}, peers: 3, mining: true, hashrate: 1, stall: make(chan struct{})}
+	defer close(backend.stall)
+
+	api := &HealthAPI{b: backend, timeout: 50 * time.Millisecond}
+	start := time.Now()
+	have := api.Health(context.Background())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("health check took %v despite the timeout", elapsed)
+	}
+	want := Health{Synced: true, CurrentBlock: 7, HighestBlock: 7, Mining: true, Hashrate: 1, Incomplete: []string{"peers"}}
+	if !reflect.DeepEqual(*have, want) {
+		t.Errorf("stalled health mismatch: have %+v, want %+v", *have, want)
+	}
+}
+
+func TestTxPoolExportImport(t *testing.T) {
+	var (
+		key, _ = crypto.GenerateKey()
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		gspec  = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  core.GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}},
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	config := txpool.DefaultConfig
+	config.Journal = ""
+	newPool := func() *txpool.TxPool {
+		return txpool.NewTxPool(config, gspec.Config, chain)
+	}
+	// Fill the pool with two executable transactions and a gapped one.
+	var txs types.Transactions
+	for _, nonce := range []uint64{0, 1, 3} {
+		txs = append(txs, types.MustSignNewTx(key, signer, &types.DynamicFeeTx{
+			ChainID:   gspec.Config.ChainID,
+			Nonce:     nonce,
+			To:        &common.Address{},
+			Gas:       params.TxGas,
+			GasFeeCap: big.NewInt(100 * params.GWei),
+			GasTipCap: big.NewInt(params.GWei),
+		}))
+	}
+	pool := newPool()
+	for i, err := range pool.AddLocals(txs) {
+		if err != nil {
+			t.Fatalf("failed to add transaction %d: %v", i, err)
+		}
+	}
+	file := filepath.Join(t.TempDir(), "txpool.rlp")
+	count, err := NewTxPoolStoreAPI(&Ethereum{txPool: pool}).Export(file)
+	if err != nil {
+		t.Fatalf("failed to export pool: %v", err)
+	}
+	if int(count) != len(txs) {
+		t.Fatalf("exported transaction count mismatch: have %d, want %d", count, len(txs))
+	}
+	pool.Stop()
+	if _, err := NewTxPoolStoreAPI(&Ethereum{txPool: pool}).Export(file); err == nil {
+		t.Fatal("export overwrote an existing file")
+	}
+	// Locations relative to the working directory or climbing out of the given
+	// one are rejected.
+	for _, bad := range []string{"txpool.rlp", "../txpool.rlp", filepath.Dir(file) + "/../txpool.rlp"} {
+		if _, err := NewTxPoolStoreAPI(&Ethereum{txPool: pool}).Export(bad); err == nil {
+			t.Errorf("export to %q accepted", bad)
+		}
+		if _, err := NewTxPoolStoreAPI(&Ethereum{txPool: pool}).Import(bad); err == nil {
+			t.Errorf("import from %q accepted", bad)
+		}
+	}
+	// Restore the dump into a fresh pool, twice to check that the transactions
+	// known already are skipped.
+	pool = newPool()
+	defer pool.Stop()
+
+	api := NewTxPoolStoreAPI(&Ethereum{txPool: pool})
+	result, err := api.Import(file)
+	if err != nil {
+		t.Fatalf("failed to import pool: %v", err)
+	}
+	if want := (TxPoolImport{Imported: 3}); *result != want {
+		t.Fatalf("import result mismatch: have %+v, want %+v", *result, want)
+	}
+	if result, err = api.Import(file); err != nil {
+		t.Fatalf("failed to reimport pool: %v", err)
+	}
+	if want := (TxPoolImport{Skipped: 3}); *result != want {
+		t.Fatalf("reimport result mismatch: have %+v, want %+v", *result, want)
+	}
+	if locals := pool.Locals(); len(locals) != 1 || locals[0] != addr {
+		t.Fatalf("local accounts mismatch: have %v, want [%v]", locals, addr)
+	}
+	pending, queued := pool.ContentFrom(addr)
+	if len(pending) != 2 || pending[0].Hash() != txs[0].Hash() || pending[1].Hash() != txs[1].Hash() {
+		t.Errorf("pending transactions not restored: %v", pending)
+	}
+	if len(queued) != 1 || queued[0].Hash() != txs[2].Hash() {
+		t.Errorf("queued transactions not restored: %v", queued)
+	}
+}
+
+func TestRecomputeStateRoot(t *testing.T) {
+	var (
+		key, _    = crypto.GenerateKey()
+		sender    = crypto.PubkeyToAddress(key.PublicKey)
+		recipient = common.Address{0xaa}
+		gspec     = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  core.GenesisAlloc{sender: {Balance: big.NewInt(params.Ether)}},
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	_, blocks, _ := core.GenerateChainWithGenesis(gspec, ethash.NewFaker(), 3, func(i int, gen *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(sender), recipient, big.NewInt(1000), params.TxGas, gen.BaseFee(), nil), signer, key)
+		gen.AddTx(tx)
+	})
+	// Write the tries straight to disk without any caching, so corrupting the
+	// database is picked up by later reads
+	db := rawdb.NewMemoryDatabase()
+	chain, err := core.NewBlockChain(db, &core.CacheConfig{TrieDirtyDisabled: true}, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	api := NewDebugAPI(&Ethereum{blockchain: chain})
+
+	for _, block := range blocks {
+		res, err := api.RecomputeStateRoot(rpc.BlockNumberOrHashWithHash(block.Hash(), false))
+		if err != nil {
+			t.Fatalf("block %d: failed to recompute root: %v", block.NumberU64(), err)
+		}
+		if !res.Match || res.RecomputedRoot != block.Root() || res.StoredRoot != block.Root() {
+			t.Errorf("block %d: roots mismatch: stored %x, recomputed %x", block.NumberU64(), res.StoredRoot, res.RecomputedRoot)
+		}
+	}
+	if _, err := api.RecomputeStateRoot(rpc.BlockNumberOrHashWithNumber(0)); err == nil {
+		t.Error("recomputed the root of the genesis block")
+	}
+	// Corrupt the balance of the recipient in the parent state of the last block,
+	// re-executing the transfer on top must not yield the stored root anymore
+	parent := blocks[len(blocks)-2]
+	tr, err := trie.New(trie.StateTrieID(parent.Root()), trie.NewDatabase(db))
+	if err != nil {
+		t.Fatalf("failed to open parent state: %v", err)
+	}
+	var corrupted bool
+	for it := tr.NodeIterator(nil); it.Next(true); {
+		if !it.Leaf() || !bytes.Equal(it.LeafKey(), crypto.Keccak256(recipient[:])) {
+			continue
+		}
+		var leaf [][]byte
+		if err := rlp.DecodeBytes(rawdb.ReadLegacyTrieNode(db, it.Parent()), &leaf); err != nil {
+			t.Fatalf("failed to decode leaf node: %v", err)
+		}
+		var account types.StateAccount
+		if err := rlp.DecodeBytes(leaf[1], &account); err != nil {
+			t.Fatalf("failed to decode account: %v", err)
+		}
+		account.Balance = new(big.Int).Add(account.Balance, big.NewInt(1))
+		leaf[1], _ = rlp.EncodeToBytes(&account)
+		blob, _ := rlp.EncodeToBytes(leaf)
+		rawdb.WriteLegacyTrieNode(db, it.Parent(), blob)
+		corrupted = true
+	}
+	if !corrupted {
+		t.Fatal("account to corrupt not found")
+	}
+	head := blocks[len(blocks)-1]
+	res, err := api.RecomputeStateRoot(rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber))
+	if err != nil {
+		t.Fatalf("failed to recompute root on corrupted state: %v", err)
+	}
+	if res.Match || res.RecomputedRoot == head.Root() || res.StoredRoot != head.Root() {
+		t.Errorf("corruption undetected: stored %x, recomputed %x", res.StoredRoot, res.RecomputedRoot)
+	}
+}
+
+func TestMiningStatus(t *testing.T) {
+	gspec := &core.Genesis{
+		Config:     params.TestChainConfig,
+		Difficulty: big.NewInt(131072),
+		GasLimit:   8000000,
+	}
+	_, blocks, _ := core.GenerateChainWithGenesis(gspec, ethash.NewFaker(), 8, func(i int, gen *core.BlockGen) {
+		gen.SetCoinbase(common.Address{0xc0})
+	})
+	stack, err := node.New(&node.Config{})
+	if err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	defer stack.Close()
+
+	config := ethconfig.Defaults
+	config.Genesis = gspec
+	config.Ethash = ethash.Config{PowMode: ethash.ModeFake}
+	eth, err := New(stack, &config)
+	if err != nil {
+		t.Fatalf("failed to create eth service: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start node: %v", err)
+	}
+	if _, err := eth.BlockChain().InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	eth.SetEtherbase(common.Address{0xc0})
+
+	api := NewMiningStatusAPI(eth)
+	checkStatus := func(mining bool) {
+		t.Helper()
+
+		status, err := api.MiningStatus()
+		if err != nil {
+			t.Fatalf("failed to retrieve mining status: %v", err)
+		}
+		head := eth.BlockChain().CurrentHeader()
+		if status.Hash != head.Hash() {
+			t.Errorf("head mismatch: have %x, want %x", status.Hash, head.Hash())
+		}
+		if status.Difficulty.ToInt().Cmp(head.Difficulty) != 0 {
+			t.Errorf("difficulty mismatch: have %v, want %v", status.Difficulty, head.Difficulty)
+		}
+		two256 := new(big.Int).Lsh(big.NewInt(1), 256)
+		if want := new(big.Int).Div(two256, head.Difficulty); status.Target.ToInt().Cmp(want) != 0 {
+			t.Errorf("target mismatch: have %v, want %v", status.Target, want)
+		}
+		if status.Mining != mining {
+			t.Errorf("mining flag mismatch: have %v, want %v", status.Mining, mining)
+		}
+	}
+	checkStatus(false)
+
+	// The hashrate is the work of the imported blocks over the time they took
+	status, _ := api.MiningStatus()
+	work := new(big.Int)
+	for _, block := range blocks {
+		work.Add(work, block.Difficulty())
+	}
+	elapsed := blocks[len(blocks)-1].Time() - eth.BlockChain().Genesis().Time()
+	if want := work.Div(work, new(big.Int).SetUint64(elapsed)); status.NetworkHashrate.ToInt().Cmp(want) != 0 {
+		t.Errorf("network hashrate mismatch: have %v, want %v", status.NetworkHashrate, want)
+	}
+	// Starting and stopping the miner is asynchronous, wait for it to settle
+	waitMining := func(mining bool) {
+		t.Helper()
+		for deadline := time.Now().Add(5 * time.Second); eth.IsMining() != mining; {
+			if time.Now().After(deadline) {
+				t.Fatalf("miner did not settle to mining=%v", mining)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	if err := eth.StartMining(1); err != nil {
+		t.Fatalf("failed to start mining: %v", err)
+	}
+	waitMining(true)
+	if status, err := api.MiningStatus(); err != nil || !status.Mining {
+		t.Errorf("mining not reported while running: %v", err)
+	}
+
+	eth.StopMining()
+	waitMining(false)
+	checkStatus(false)
+}
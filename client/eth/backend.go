@@ -52,6 +52,7 @@ import (
 	"github.com/r5-labs/r5-core/client/params"
 	"github.com/r5-labs/r5-core/client/rlp"
 	"github.com/r5-labs/r5-core/client/rpc"
+	"github.com/r5-labs/r5-core/client/trie"
 )
 
 // Config contains the configuration options of the ETH protocol.
@@ -133,11 +134,19 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	// Transfer mining-related config to the ethash config.
 	ethashConfig := config.Ethash
 	ethashConfig.NotifyFull = config.Miner.NotifyFull
-	cliqueConfig, err := core.LoadCliqueConfig(chainDb, config.Genesis)
+	// Override the chain config with provided settings.
+	var overrides core.ChainOverrides
+	if config.OverrideShanghai != nil {
+		overrides.OverrideShanghai = config.OverrideShanghai
+	}
+	chainConfig, _, genesisErr := core.SetupGenesisBlockWithOverride(chainDb, trie.NewDatabase(chainDb), config.Genesis, &overrides)
+	if _, isCompat := genesisErr.(*params.ConfigCompatError); genesisErr != nil && !isCompat {
+		return nil, genesisErr
+	}
+	engine, err := ethconfig.CreateConsensusEngine(stack, &ethashConfig, chainConfig, config.Miner.Notify, config.Miner.Noverify, chainDb)
 	if err != nil {
 		return nil, err
 	}
-	engine := ethconfig.CreateConsensusEngine(stack, &ethashConfig, cliqueConfig, config.Miner.Notify, config.Miner.Noverify, chainDb)
 
 	eth := &Ethereum{
 		config:            config,
@@ -187,13 +196,9 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 			TrieTimeLimit:       config.TrieTimeout,
 			SnapshotLimit:       config.SnapshotCache,
 			Preimages:           config.Preimages,
+			MaxReorgDepth:       config.MaxReorgDepth,
 		}
 	)
-	// Override the chain config with provided settings.
-	var overrides core.ChainOverrides
-	if config.OverrideShanghai != nil {
-		overrides.OverrideShanghai = config.OverrideShanghai
-	}
 	eth.blockchain, err = core.NewBlockChain(chainDb, cacheConfig, config.Genesis, &overrides, eth.engine, vmConfig, eth.shouldPreserve, &config.TxLookupLimit)
 	if err != nil {
 		return nil, err
@@ -226,8 +231,12 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		return nil, err
 	}
 
-	eth.miner = miner.New(eth, &config.Miner, eth.blockchain.Config(), eth.EventMux(), eth.engine, eth.isLocalBlock)
-	eth.miner.SetExtra(makeExtraData(config.Miner.ExtraData))
+	if eth.miner, err = miner.New(eth, &config.Miner, eth.blockchain.Config(), eth.EventMux(), eth.engine, eth.isLocalBlock); err != nil {
+		return nil, err
+	}
+	if err := eth.miner.SetExtra(makeExtraData(config.Miner.ExtraData)); err != nil {
+		log.Warn("Miner extra data rejected", "err", err)
+	}
 
 	eth.APIBackend = &EthAPIBackend{stack.Config().ExtRPCEnabled(), stack.Config().AllowUnprotectedTxs, eth, nil}
 	if eth.APIBackend.allowUnprotectedTxs {
@@ -297,6 +306,26 @@ func (s *Ethereum) APIs() []rpc.API {
 		}, {
 			Namespace: "miner",
 			Service:   NewMinerAPI(s),
+		}, {
+			Namespace: "r5",
+			Service:   NewBlockTemplateAPI(s),
+		}, {
+			Namespace: "r5",
+			Service:   NewTrieProofAPI(s),
+		}, {
+			Namespace: "r5",
+			Service:   NewHealthAPI(s),
+		}, {
+			Namespace: "admin",
+			Service:   NewGasOracleAPI(s),
+		}, {
+			Namespace: "r5",
+			Service:   NewMiningStatusAPI(s),
+		}, {
+			// Only served over IPC and in-process, as it accesses the local filesystem.
+			Namespace:     "txpool",
+			Service:       NewTxPoolStoreAPI(s),
+			Authenticated: true,
 		}, {
 			Namespace: "eth",
 			Service:   downloader.NewDownloaderAPI(s.handler.downloader, s.eventMux),
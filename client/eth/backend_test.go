@@ -0,0 +1,94 @@
+// Copyright 2025 R5 Labs
+// This file is part of the R5 Core library.
+//
+// This software is provided "as is", without warranty of any kind,
+// express or implied, including but not limited to the warranties
+// of merchantability, fitness for a particular purpose and
+// noninfringement. In no event shall the authors or copyright
+// holders be liable for any claim, damages, or other liability,
+// whether in an action of contract, tort or otherwise, arising
+// from, out of or in connection with the software or the use or
+// other dealings in the software.
+
+package eth
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/r5-labs/r5-core/client/consensus/ethash"
+	"github.com/r5-labs/r5-core/client/core"
+	"github.com/r5-labs/r5-core/client/eth/ethconfig"
+	"github.com/r5-labs/r5-core/client/node"
+	"github.com/r5-labs/r5-core/client/params"
+)
+
+// Tests that a proof-of-work chain scheduling forks ethash cannot verify is
+// rejected when the node is created, instead of failing during block import.
+func TestEthashUnsupportedForks(t *testing.T) {
+	shanghai, cancun := uint64(0), uint64(100)
+	for _, tt := range []struct {
+		name   string
+		config func(*params.ChainConfig)
+		fail   bool
+	}{
+		{"proof-of-work", func(*params.ChainConfig) {}, false},
+		{"shanghai", func(c *params.ChainConfig) { c.ShanghaiTime = &shanghai }, true},
+		{"shanghai and cancun", func(c *params.ChainConfig) {
+			c.ShanghaiTime = &shanghai
+			c.CancunTime = &cancun
+		}, true},
+		{"merged shanghai", func(c *params.ChainConfig) {
+			c.ShanghaiTime = &shanghai
+			c.TerminalTotalDifficulty = big.NewInt(0)
+		}, false},
+	} {
+		config := *params.AllEthashProtocolChanges
+		tt.config(&config)
+
+		stack, err := node.New(&node.Config{})
+		if err != nil {
+			t.Fatalf("%s: failed to create node: %v", tt.name, err)
+		}
+		_, err = New(stack, &ethconfig.Config{
+			Genesis: &core.Genesis{Config: &config, Difficulty: big.NewInt(1), GasLimit: 8000000},
+			Ethash:  ethash.Config{PowMode: ethash.ModeFake},
+		})
+		stack.Close()
+
+		if tt.fail && (err == nil || !strings.Contains(err.Error(), "ethash does not support")) {
+			t.Errorf("%s: node creation error mismatch: %v", tt.name, err)
+		}
+		if !tt.fail && err != nil {
+			t.Errorf("%s: failed to create node: %v", tt.name, err)
+		}
+	}
+}
+
+// Tests that the pool dump methods, which access the local filesystem, are kept
+// off the HTTP and WebSocket endpoints.
+func TestTxPoolStoreAPIPrivate(t *testing.T) {
+	stack, err := node.New(&node.Config{})
+	if err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	defer stack.Close()
+
+	backend, err := New(stack, &ethconfig.Config{
+		Genesis: &core.Genesis{Config: params.AllEthashProtocolChanges, Difficulty: big.NewInt(1), GasLimit: 8000000},
+		Ethash:  ethash.Config{PowMode: ethash.ModeFake},
+	})
+	if err != nil {
+		t.Fatalf("failed to create backend: %v", err)
+	}
+	for _, api := range backend.APIs() {
+		if _, ok := api.Service.(*TxPoolStoreAPI); ok {
+			if api.Namespace != "txpool" || !api.Authenticated {
+				t.Fatalf("pool dump api exposed: namespace %q, authenticated %v", api.Namespace, api.Authenticated)
+			}
+			return
+		}
+	}
+	t.Fatal("pool dump api not registered")
+}
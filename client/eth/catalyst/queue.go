@@ -18,6 +18,7 @@ import (
 	"github.com/r5-labs/r5-core/client/beacon/engine"
 	"github.com/r5-labs/r5-core/client/common"
 	"github.com/r5-labs/r5-core/client/core/types"
+	"github.com/r5-labs/r5-core/client/log"
 	"github.com/r5-labs/r5-core/client/miner"
 )
 
@@ -78,7 +79,11 @@ func (q *payloadQueue) get(id engine.PayloadID) *engine.ExecutionPayloadEnvelope
 			return nil // no more items
 		}
 		if item.id == id {
-			return item.payload.Resolve()
+			data := item.payload.Resolve()
+			if err := item.payload.Interrupted(); err != nil {
+				log.Warn("Delivering incomplete payload", "id", id, "reason", err)
+			}
+			return data
 		}
 	}
 	return nil
@@ -70,6 +70,7 @@ var Defaults = Config{
 	},
 	NetworkId:               337,
 	TxLookupLimit:           2350000,
+	MaxReorgDepth:           3 * params.FullImmutabilityThreshold,
 	LightPeers:              100,
 	UltraLightFraction:      75,
 	DatabaseCache:           512,
@@ -130,6 +131,7 @@ type Config struct {
 	NoPrefetch bool // Whether to disable prefetching and only load state on demand
 
 	TxLookupLimit uint64 `toml:",omitempty"` // The maximum number of blocks from head whose tx indices are reserved.
+	MaxReorgDepth uint64 `toml:",omitempty"` // The maximum number of canonical blocks a reorg may drop (0 = unlimited).
 
 	// RequiredBlocks is a set of block number -> hash mappings which must be in the
 	// canonical chain of all remote peers. Setting the option makes geth verify the
@@ -206,12 +208,16 @@ type Config struct {
 }
 
 // CreateConsensusEngine creates a consensus engine for the given chain configuration.
-func CreateConsensusEngine(stack *node.Node, ethashConfig *ethash.Config, cliqueConfig *params.CliqueConfig, notify []string, noverify bool, db ethdb.Database) consensus.Engine {
+// An error is returned if the engine is unable to run the chain.
+func CreateConsensusEngine(stack *node.Node, ethashConfig *ethash.Config, chainConfig *params.ChainConfig, notify []string, noverify bool, db ethdb.Database) (consensus.Engine, error) {
 	// If proof-of-authority is requested, set it up
 	var engine consensus.Engine
-	if cliqueConfig != nil {
-		engine = clique.New(cliqueConfig, db)
+	if chainConfig.Clique != nil {
+		engine = clique.New(chainConfig.Clique, db)
 	} else {
+		if err := ethash.VerifyChainConfig(chainConfig); err != nil {
+			return nil, err
+		}
 		switch ethashConfig.PowMode {
 		case ethash.ModeFake:
 			log.Warn("Ethash used in fake mode")
@@ -231,8 +237,11 @@ func CreateConsensusEngine(stack *node.Node, ethashConfig *ethash.Config, clique
 			DatasetsOnDisk:   ethashConfig.DatasetsOnDisk,
 			DatasetsLockMmap: ethashConfig.DatasetsLockMmap,
 			NotifyFull:       ethashConfig.NotifyFull,
+
+			AllowedFutureBlockTime: ethashConfig.AllowedFutureBlockTime,
+			MaxUncles:              ethashConfig.MaxUncles,
 		}, notify, noverify)
 		engine.(*ethash.Ethash).SetThreads(-1) // Disable CPU mining
 	}
-	return beacon.New(engine)
+	return beacon.New(engine), nil
 }
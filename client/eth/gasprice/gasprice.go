@@ -14,6 +14,7 @@ package gasprice
 
 import (
 	"context"
+	"fmt"
 	"math/big"
 	"sort"
 	"sync"
@@ -137,6 +138,40 @@ func NewOracle(backend OracleBackend, params Config) *Oracle {
 	}
 }
 
+// SetParams updates the number of sampled blocks, the sample percentile and
+// the price cap of the oracle, discarding the last suggested price. Nil values
+// leave the corresponding setting unchanged.
+func (oracle *Oracle) SetParams(blocks, percentile *int, maxPrice *big.Int) error {
+	if blocks != nil && *blocks < 1 {
+		return fmt.Errorf("invalid sample blocks %d, must be at least 1", *blocks)
+	}
+	if percentile != nil && (*percentile < 0 || *percentile > 100) {
+		return fmt.Errorf("invalid sample percentile %d, must be within 0-100", *percentile)
+	}
+	if maxPrice != nil && maxPrice.Sign() <= 0 {
+		return fmt.Errorf("invalid price cap %v, must be positive", maxPrice)
+	}
+	// The sampling settings are only read during a fetch
+	oracle.fetchLock.Lock()
+	defer oracle.fetchLock.Unlock()
+
+	if blocks != nil {
+		oracle.checkBlocks = *blocks
+	}
+	if percentile != nil {
+		oracle.percentile = *percentile
+	}
+	if maxPrice != nil {
+		oracle.maxPrice = new(big.Int).Set(maxPrice)
+	}
+	oracle.cacheLock.Lock()
+	oracle.lastHead = common.Hash{}
+	oracle.cacheLock.Unlock()
+
+	log.Info("Updated gasprice oracle", "blocks", oracle.checkBlocks, "percentile", oracle.percentile, "maxprice", oracle.maxPrice)
+	return nil
+}
+
 // SuggestTipCap returns a tip cap so that newly created transaction can have a
 // very high chance to be included in the following blocks.
 //
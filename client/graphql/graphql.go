@@ -20,12 +20,14 @@ import (
 	"math/big"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/r5-labs/r5-core/client"
 	"github.com/r5-labs/r5-core/client/common"
 	"github.com/r5-labs/r5-core/client/common/hexutil"
 	"github.com/r5-labs/r5-core/client/common/math"
+	"github.com/r5-labs/r5-core/client/consensus/ethash"
 	"github.com/r5-labs/r5-core/client/consensus/misc"
 	"github.com/r5-labs/r5-core/client/core/state"
 	"github.com/r5-labs/r5-core/client/core/types"
@@ -49,17 +51,20 @@ func (b *Long) UnmarshalGraphQL(input interface{}) error {
 	var err error
 	switch input := input.(type) {
 	case string:
-		// uncomment to support hex values
-		//if strings.HasPrefix(input, "0x") {
-		//	// apply leniency and support hex representations of longs.
-		//	value, err := hexutil.DecodeUint64(input)
-		//	*b = Long(value)
-		//	return err
-		//} else {
+		if strings.HasPrefix(input, "0x") {
+			// apply leniency and support hex representations of longs. Values
+			// not fitting into a Long are clamped, referencing no block.
+			value, err := hexutil.DecodeUint64(input)
+			if errors.Is(err, hexutil.ErrUint64Range) || value > math.MaxInt64 {
+				*b = Long(math.MaxInt64)
+				return nil
+			}
+			*b = Long(value)
+			return err
+		}
 		value, err := strconv.ParseInt(input, 10, 64)
 		*b = Long(value)
 		return err
-		//}
 	case int32:
 		*b = Long(input)
 	case int64:
@@ -703,6 +708,26 @@ func (b *Block) NextBaseFeePerGas(ctx context.Context) (*hexutil.Big, error) {
 	return (*hexutil.Big)(nextBaseFee), nil
 }
 
+// CirculatingSupply returns the circulating supply (in wei) at this block as a
+// decimal string.
+func (b *Block) CirculatingSupply(ctx context.Context) (string, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return "", err
+	}
+	return ethash.CalculateCirculatingSupply(header.Number.Uint64()).String(), nil
+}
+
+// BlockReward returns the reward (in wei) issued for mining this block as a
+// decimal string.
+func (b *Block) BlockReward(ctx context.Context) (string, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return "", err
+	}
+	return ethash.BlockReward(header.Number.Uint64()).String(), nil
+}
+
 func (b *Block) Parent(ctx context.Context) (*Block, error) {
 	if _, err := b.resolveHeader(ctx); err != nil {
 		return nil, err
@@ -1112,6 +1137,34 @@ func (p *Pending) Transactions(ctx context.Context) (*[]*Transaction, error) {
 	return &ret, nil
 }
 
+// Block returns the block currently being assembled by the miner, or nil if no
+// pending snapshot is available.
+func (p *Pending) Block(ctx context.Context) *Block {
+	block, receipts := p.r.backend.PendingBlockAndReceipts()
+	if block == nil {
+		return nil
+	}
+	pendingBlockNr := rpc.BlockNumberOrHashWithNumber(rpc.PendingBlockNumber)
+	return &Block{
+		r:            p.r,
+		numberOrHash: &pendingBlockNr,
+		hash:         block.Hash(),
+		header:       block.Header(),
+		block:        block,
+		receipts:     receipts,
+	}
+}
+
+// EstimatedBaseFee returns the base fee of the block currently being assembled
+// by the miner, or nil if it's unknown.
+func (p *Pending) EstimatedBaseFee(ctx context.Context) *hexutil.Big {
+	block, _ := p.r.backend.PendingBlockAndReceipts()
+	if block == nil || block.BaseFee() == nil {
+		return nil
+	}
+	return (*hexutil.Big)(block.BaseFee())
+}
+
 func (p *Pending) Account(ctx context.Context, args struct {
 	Address common.Address
 }) *Account {
@@ -1320,6 +1373,11 @@ func (r *Resolver) ChainID(ctx context.Context) (hexutil.Big, error) {
 	return hexutil.Big(*r.backend.ChainConfig().ChainID), nil
 }
 
+// SupplyCap returns the maximum supply (in wei) as a decimal string.
+func (r *Resolver) SupplyCap(ctx context.Context) string {
+	return ethash.SupplyCap.String()
+}
+
 // SyncState represents the synchronisation status returned from the `syncing` accessor.
 type SyncState struct {
 	progress ethereum.SyncProgress
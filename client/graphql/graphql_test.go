@@ -24,7 +24,9 @@ import (
 	"time"
 
 	"github.com/r5-labs/r5-core/client/common"
+	"github.com/r5-labs/r5-core/client/common/hexutil"
 	"github.com/r5-labs/r5-core/client/consensus/ethash"
+	"github.com/r5-labs/r5-core/client/consensus/misc"
 	"github.com/r5-labs/r5-core/client/core"
 	"github.com/r5-labs/r5-core/client/core/types"
 	"github.com/r5-labs/r5-core/client/core/vm"
@@ -114,14 +116,39 @@ func TestGraphQLBlockSerialization(t *testing.T) {
 			want: `{"data":{"block":null}}`,
 			code: 200,
 		},
+		{ // hex strings are accepted as well
+			body: `{"query": "{block(number:\"0x0\"){number,gasUsed,gasLimit}}","variables": null}`,
+			want: `{"data":{"block":{"number":0,"gasUsed":0,"gasLimit":11500000}}}`,
+			code: 200,
+		},
 		{
+			body: `{"query": "{block(number:\"0xa\"){number,gasUsed,gasLimit}}","variables": null}`,
+			want: `{"data":{"block":{"number":10,"gasUsed":0,"gasLimit":11500000}}}`,
+			code: 200,
+		},
+		{ // 0xbad is well-formed hex, referencing a block not yet mined
 			body: `{"query": "{block(number:\"0xbad\"){number,gasUsed,gasLimit}}","variables": null}`,
-			want: `{"errors":[{"message":"strconv.ParseInt: parsing \"0xbad\": invalid syntax"}],"data":{}}`,
+			want: `{"data":{"block":null}}`,
+			code: 200,
+		},
+		{
+			body: `{"query": "{block(number:\"0x10000000000000000\"){number,gasUsed,gasLimit}}","variables": null}`,
+			want: `{"data":{"block":null}}`,
+			code: 200,
+		},
+		{
+			body: `{"query": "{block(number:\"0x8000000000000000\"){number,gasUsed,gasLimit}}","variables": null}`,
+			want: `{"data":{"block":null}}`,
+			code: 200,
+		},
+		{
+			body: `{"query": "{block(number:\"0xbadz\"){number,gasUsed,gasLimit}}","variables": null}`,
+			want: `{"errors":[{"message":"invalid hex string"}],"data":{}}`,
 			code: 400,
 		},
-		{ // hex strings are currently not supported. If that's added to the spec, this test will need to change
-			body: `{"query": "{block(number:\"0x0\"){number,gasUsed,gasLimit}}","variables": null}`,
-			want: `{"errors":[{"message":"strconv.ParseInt: parsing \"0x0\": invalid syntax"}],"data":{}}`,
+		{
+			body: `{"query": "{block(number:\"0x01\"){number,gasUsed,gasLimit}}","variables": null}`,
+			want: `{"errors":[{"message":"hex number with leading zero digits"}],"data":{}}`,
 			code: 400,
 		},
 		{
@@ -165,6 +192,59 @@ func TestGraphQLBlockSerialization(t *testing.T) {
 	}
 }
 
+func TestGraphQLSupplySerialization(t *testing.T) {
+	stack := createNode(t)
+	defer stack.Close()
+	genesis := &core.Genesis{
+		Config:     params.AllEthashProtocolChanges,
+		GasLimit:   11500000,
+		Difficulty: big.NewInt(1048576),
+	}
+	newGQLService(t, stack, genesis, 10, func(i int, gen *core.BlockGen) {})
+	// start node
+	if err := stack.Start(); err != nil {
+		t.Fatalf("could not start node: %v", err)
+	}
+
+	for i, tt := range []struct {
+		body string
+		want string
+		code int
+	}{
+		{ // Genesis only holds the premined supply and pays no reward
+			body: `{"query": "{block(number:0){number,circulatingSupply,blockReward}}","variables": null}`,
+			want: `{"data":{"block":{"number":0,"circulatingSupply":"2000000000000000000000000","blockReward":"0"}}}`,
+			code: 200,
+		},
+		{
+			body: `{"query": "{block(number:10){number,circulatingSupply,blockReward}}","variables": null}`,
+			want: `{"data":{"block":{"number":10,"circulatingSupply":"2000020000000000000000000","blockReward":"2000000000000000000"}}}`,
+			code: 200,
+		},
+		{
+			body: `{"query": "{supplyCap}","variables": null}`,
+			want: `{"data":{"supplyCap":"66337700000000000000000000"}}`,
+			code: 200,
+		},
+	} {
+		resp, err := http.Post(fmt.Sprintf("%s/graphql", stack.HTTPEndpoint()), "application/json", strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatalf("could not post: %v", err)
+		}
+		bodyBytes, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("could not read from response body: %v", err)
+		}
+		if have := string(bodyBytes); have != tt.want {
+			t.Errorf("testcase %d %s,\nhave:\n%v\nwant:\n%v", i, tt.body, have, tt.want)
+		}
+		if tt.code != resp.StatusCode {
+			t.Errorf("testcase %d %s,\nwrong statuscode, have: %v, want: %v", i, tt.body, resp.StatusCode, tt.code)
+		}
+	}
+}
+
 func TestGraphQLBlockSerializationEIP2718(t *testing.T) {
 	// Account for signing txes
 	var (
@@ -360,6 +440,63 @@ func TestGraphQLConcurrentResolvers(t *testing.T) {
 	}
 }
 
+func TestGraphQLPendingBlock(t *testing.T) {
+	var (
+		key, _  = crypto.GenerateKey()
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		dad     = common.HexToAddress("0x0000000000000000000000000000000000000dad")
+		genesis = &core.Genesis{
+			Config:     params.AllEthashProtocolChanges,
+			GasLimit:   11500000,
+			Difficulty: big.NewInt(1048576),
+			Alloc:      core.GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}},
+		}
+		signer = types.LatestSigner(genesis.Config)
+		stack  = createNode(t)
+	)
+	defer stack.Close()
+
+	handler, chain := newGQLService(t, stack, genesis, 1, func(i int, gen *core.BlockGen) {})
+	// start node
+	if err := stack.Start(); err != nil {
+		t.Fatalf("could not start node: %v", err)
+	}
+	exec := func(query string) string {
+		res := handler.Schema.Exec(context.Background(), query, "", map[string]interface{}{})
+		if res.Errors != nil {
+			t.Fatalf("failed to execute query %s: %v", query, res.Errors)
+		}
+		have, err := json.Marshal(res.Data)
+		if err != nil {
+			t.Fatalf("failed to encode graphql response: %v", err)
+		}
+		return string(have)
+	}
+	// Inject a few transactions into the pool.
+	var hashes []string
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		tx, _ := types.SignNewTx(key, signer, &types.LegacyTx{To: &dad, Nonce: nonce, Gas: params.TxGas, GasPrice: big.NewInt(params.InitialBaseFee)})
+		blob, err := tx.MarshalBinary()
+		if err != nil {
+			t.Fatalf("failed to encode transaction: %v", err)
+		}
+		exec(fmt.Sprintf(`mutation { sendRawTransaction(data: "%s") }`, hexutil.Encode(blob)))
+		hashes = append(hashes, fmt.Sprintf(`{"hash":"%s"}`, tx.Hash().Hex()))
+	}
+	// Wait for the miner to include them in the pending block.
+	want := fmt.Sprintf(`{"pending":{"block":{"number":2,"gasUsed":%d,"transactions":[%s]},"estimatedBaseFee":"0x%x"}}`, 2*params.TxGas, strings.Join(hashes, ","), misc.CalcBaseFee(genesis.Config, chain[0].Header()))
+	query := "{ pending { block { number gasUsed transactions { hash } } estimatedBaseFee } }"
+
+	var have string
+	for i := 0; i < 50; i++ {
+		if have = exec(query); have == want {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Fatalf("pending block mismatch:\nhave:\n%s\nwant:\n%s", have, want)
+}
+
 func createNode(t *testing.T) *node.Node {
 	stack, err := node.New(&node.Config{
 		HTTPHost:     "127.0.0.1",
@@ -196,6 +196,12 @@ const schema string = `
         baseFeePerGas: BigInt
         # NextBaseFeePerGas is the fee per unit of gas which needs to be burned in the next block.
         nextBaseFeePerGas: BigInt
+        # CirculatingSupply is the total supply in wei, as a decimal string,
+        # issued up to and including this block.
+        circulatingSupply: String!
+        # BlockReward is the reward in wei, as a decimal string, issued for
+        # mining this block.
+        blockReward: String!
         # Timestamp is the unix timestamp at which this block was mined.
         timestamp: Long!
         # LogsBloom is a bloom filter that can be used to check if a block may
@@ -316,6 +322,12 @@ const schema string = `
       transactionCount: Int!
       # Transactions is a list of transactions in the current pending state.
       transactions: [Transaction!]
+      # Block is the block currently being assembled by the miner. If no
+      # pending block is available, this field will be null.
+      block: Block
+      # EstimatedBaseFee is the base fee of the block currently being assembled
+      # by the miner. If no pending block is available, this field will be null.
+      estimatedBaseFee: BigInt
       # Account fetches an Ethereum account for the pending state.
       account(address: Address!): Account!
       # Call executes a local call operation for the pending state.
@@ -348,6 +360,8 @@ const schema string = `
         syncing: SyncState
         # ChainID returns the current chain ID for transaction replay protection.
         chainID: BigInt!
+        # SupplyCap is the maximum supply in wei, as a decimal string.
+        supplyCap: String!
     }
 
     type Mutation {
@@ -214,6 +214,23 @@ func (s *TxPoolAPI) Status() map[string]hexutil.Uint {
 	}
 }
 
+// StatusByType returns the number of pending and queued transactions in the
+// pool, keyed by transaction type.
+func (s *TxPoolAPI) StatusByType() map[string]map[hexutil.Uint64]hexutil.Uint {
+	pending, queue := s.b.StatsByType()
+	status := map[string]map[hexutil.Uint64]hexutil.Uint{
+		"pending": make(map[hexutil.Uint64]hexutil.Uint),
+		"queued":  make(map[hexutil.Uint64]hexutil.Uint),
+	}
+	for typ, count := range pending {
+		status["pending"][hexutil.Uint64(typ)] = hexutil.Uint(count)
+	}
+	for typ, count := range queue {
+		status["queued"][hexutil.Uint64(typ)] = hexutil.Uint(count)
+	}
+	return status
+}
+
 // Inspect retrieves the content of the transaction pool and flattens it into an
 // easily inspectable list.
 func (s *TxPoolAPI) Inspect() map[string]map[string]map[string]string {
@@ -2044,6 +2061,32 @@ func (api *DebugAPI) SetHead(number hexutil.Uint64) {
 	api.b.SetHead(uint64(number))
 }
 
+// SupplyReconciliation compares the scheduled circulating supply at a block to
+// the sum of all account balances in its state.
+type SupplyReconciliation struct {
+	Number    hexutil.Uint64 `json:"number"`
+	Scheduled *hexutil.Big   `json:"scheduled"`
+	Actual    *hexutil.Big   `json:"actual"`
+	Diff      *hexutil.Big   `json:"diff"`
+}
+
+// ReconcileSupply sums up all account balances in the state of the given block
+// and reports the discrepancy against the scheduled circulating supply. It
+// iterates the entire state, so it's slow on large chains.
+func (api *DebugAPI) ReconcileSupply(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*SupplyReconciliation, error) {
+	state, header, err := api.b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	scheduled, actual, diff := ethash.ReconcileSupply(state, header.Number.Uint64())
+	return &SupplyReconciliation{
+		Number:    hexutil.Uint64(header.Number.Uint64()),
+		Scheduled: (*hexutil.Big)(scheduled),
+		Actual:    (*hexutil.Big)(actual),
+		Diff:      (*hexutil.Big)(diff),
+	}, nil
+}
+
 // NetAPI offers network related RPC methods
 type NetAPI struct {
 	net            *p2p.Server
@@ -2107,3 +2150,58 @@ func (s *EthereumAPI) GetSupply(ctx context.Context) (string, error) {
 	// Return the supply as a hex string.
 	return fmt.Sprintf("0x%x", supply), nil
 }
+
+// NextBaseFee returns the base fee of the block following the current head, as
+// computed by the miner when preparing it. Nil is returned if that block is not
+// subject to EIP-1559.
+func (s *EthereumAPI) NextBaseFee(ctx context.Context) (*hexutil.Big, error) {
+	header := s.b.CurrentHeader()
+	if header == nil {
+		return nil, fmt.Errorf("no current block header available")
+	}
+	return nextBaseFee(s.b.ChainConfig(), header), nil
+}
+
+// nextBaseFee computes the base fee of the child of the given header.
+func nextBaseFee(config *params.ChainConfig, parent *types.Header) *hexutil.Big {
+	if !config.IsLondon(new(big.Int).Add(parent.Number, common.Big1)) {
+		return nil
+	}
+	return (*hexutil.Big)(misc.CalcBaseFee(config, parent))
+}
+
+// EmissionInfo describes the position of a block in the super epoch emission
+// schedule. The next halving fields are nil once block rewards have ended.
+type EmissionInfo struct {
+	Number          hexutil.Uint64  `json:"number"`
+	Reward          *hexutil.Big    `json:"reward"`
+	Epoch           hexutil.Uint64  `json:"epoch"`
+	NextHalving     *hexutil.Uint64 `json:"nextHalving"`
+	BlocksRemaining *hexutil.Uint64 `json:"blocksRemaining"`
+}
+
+// EmissionInfo returns the block reward and super epoch of the current block,
+// along with the block at which the reward changes next.
+func (s *EthereumAPI) EmissionInfo(ctx context.Context) (*EmissionInfo, error) {
+	header := s.b.CurrentHeader()
+	if header == nil {
+		return nil, fmt.Errorf("no current block header available")
+	}
+	return emissionInfo(header.Number.Uint64()), nil
+}
+
+// emissionInfo computes the emission schedule position of the given block.
+func emissionInfo(number uint64) *EmissionInfo {
+	epoch, next, ok := ethash.EmissionEpoch(number)
+	info := &EmissionInfo{
+		Number: hexutil.Uint64(number),
+		Reward: (*hexutil.Big)(ethash.BlockReward(number)),
+		Epoch:  hexutil.Uint64(epoch),
+	}
+	if ok {
+		remaining := hexutil.Uint64(next - number)
+		info.NextHalving = (*hexutil.Uint64)(&next)
+		info.BlocksRemaining = &remaining
+	}
+	return info
+}
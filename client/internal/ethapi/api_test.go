@@ -18,6 +18,7 @@ import (
 	"testing"
 
 	"github.com/r5-labs/r5-core/client/common"
+	"github.com/r5-labs/r5-core/client/consensus/ethash"
 	"github.com/r5-labs/r5-core/client/core/types"
 	"github.com/r5-labs/r5-core/client/crypto"
 	"github.com/r5-labs/r5-core/client/params"
@@ -153,3 +154,85 @@ func allTransactionTypes(addr common.Address, config *params.ChainConfig) []type
 		},
 	}
 }
+
+func TestEmissionInfo(t *testing.T) {
+	// The last blocks of each super epoch, the reward stops after the final one.
+	steps := []uint64{4000000, 8000000, 16000000, 32000000, 64000000, 128000000, 1290406399}
+	for i, last := range steps {
+		epoch := uint64(i + 1)
+		for _, number := range []uint64{last - 1, last} {
+			info := emissionInfo(number)
+			if uint64(info.Epoch) != epoch {
+				t.Errorf("block %d: epoch mismatch: have %d, want %d", number, info.Epoch, epoch)
+			}
+			if info.NextHalving == nil || uint64(*info.NextHalving) != last+1 {
+				t.Errorf("block %d: next halving mismatch: have %v, want %d", number, info.NextHalving, last+1)
+			}
+			if info.BlocksRemaining == nil || uint64(*info.BlocksRemaining) != last+1-number {
+				t.Errorf("block %d: blocks remaining mismatch: have %v, want %d", number, info.BlocksRemaining, last+1-number)
+			}
+			if info.Reward.ToInt().Cmp(ethash.BlockReward(number)) != 0 {
+				t.Errorf("block %d: reward mismatch: have %v, want %v", number, info.Reward, ethash.BlockReward(number))
+			}
+		}
+		// The block after the step starts the next epoch with a lower reward.
+		info := emissionInfo(last + 1)
+		if uint64(info.Epoch) != epoch+1 {
+			t.Errorf("block %d: epoch mismatch: have %d, want %d", last+1, info.Epoch, epoch+1)
+		}
+		if info.Reward.ToInt().Cmp(emissionInfo(last).Reward.ToInt()) >= 0 {
+			t.Errorf("block %d: reward %v not below previous epoch", last+1, info.Reward)
+		}
+	}
+	// Past the final block only the terminal reward is reported.
+	for _, number := range []uint64{1290406400, 1290406401, 2000000000} {
+		info := emissionInfo(number)
+		if info.Reward.ToInt().Sign() != 0 {
+			t.Errorf("block %d: non-zero terminal reward %v", number, info.Reward)
+		}
+		if info.NextHalving != nil || info.BlocksRemaining != nil {
+			t.Errorf("block %d: next halving reported after the final block", number)
+		}
+		enc, _ := json.Marshal(info)
+		var dec map[string]interface{}
+		json.Unmarshal(enc, &dec)
+		if v, ok := dec["nextHalving"]; !ok || v != nil {
+			t.Errorf("block %d: next halving not encoded as null: %s", number, enc)
+		}
+	}
+	if reward := emissionInfo(1).Reward.ToInt(); reward.Cmp(new(big.Int).Mul(big.NewInt(2), big.NewInt(params.Ether))) != 0 {
+		t.Errorf("genesis epoch reward mismatch: have %v", reward)
+	}
+}
+
+func TestNextBaseFee(t *testing.T) {
+	config := *params.TestChainConfig
+	config.LondonBlock, config.ArrowGlacierBlock, config.GrayGlacierBlock = big.NewInt(2), nil, nil
+
+	tests := []struct {
+		number  int64
+		gasUsed uint64
+		want    *big.Int
+	}{
+		{0, 0, nil}, // next block predates London
+		{1, 0, big.NewInt(params.InitialBaseFee)},   // next block activates London
+		{10, 20_000_000, big.NewInt(1_125_000_000)}, // full parent raises the fee by 1/8
+		{10, 10_000_000, big.NewInt(1_000_000_000)}, // half full parent keeps the fee
+		{10, 0, big.NewInt(875_000_000)},            // empty parent lowers the fee by 1/8
+	}
+	for i, tt := range tests {
+		parent := &types.Header{
+			Number:   big.NewInt(tt.number),
+			GasLimit: 20_000_000,
+			GasUsed:  tt.gasUsed,
+			BaseFee:  big.NewInt(1_000_000_000),
+		}
+		fee := nextBaseFee(&config, parent)
+		switch {
+		case tt.want == nil && fee != nil:
+			t.Errorf("test %d: pre-London base fee reported: %v", i, fee)
+		case tt.want != nil && (fee == nil || fee.ToInt().Cmp(tt.want) != 0):
+			t.Errorf("test %d: next base fee mismatch: have %v, want %v", i, fee, tt.want)
+		}
+	}
+}
@@ -321,6 +321,9 @@ func (b *backendMock) GetPoolNonce(ctx context.Context, addr common.Address) (ui
 	return 0, nil
 }
 func (b *backendMock) Stats() (pending int, queued int) { return 0, 0 }
+func (b *backendMock) StatsByType() (pending map[uint8]int, queued map[uint8]int) {
+	return nil, nil
+}
 func (b *backendMock) TxPoolContent() (map[common.Address]types.Transactions, map[common.Address]types.Transactions) {
 	return nil, nil
 }
@@ -94,6 +94,11 @@ web3._extend({
 			call: 'ethash_getWork',
 			params: 0
 		}),
+		new web3._extend.Method({
+			name: 'getWorkDetailed',
+			call: 'ethash_getWorkDetailed',
+			params: 0
+		}),
 		new web3._extend.Method({
 			name: 'getHashrate',
 			call: 'ethash_getHashrate',
@@ -234,6 +239,12 @@ web3._extend({
 			call: 'debug_getRawReceipts',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'reconcileSupply',
+			call: 'debug_reconcileSupply',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
 		new web3._extend.Method({
 			name: 'getRawTransaction',
 			call: 'debug_getRawTransaction',
@@ -491,6 +502,12 @@ web3._extend({
 			call: 'debug_setTrieFlushInterval',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'recomputeStateRoot',
+			call: 'debug_recomputeStateRoot',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputDefaultBlockNumberFormatter],
+		}),
 	],
 	properties: []
 });
@@ -766,7 +783,18 @@ web3._extend({
 const TxpoolJs = `
 web3._extend({
 	property: 'txpool',
-	methods: [],
+	methods: [
+		new web3._extend.Method({
+			name: 'export',
+			call: 'txpool_export',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'import',
+			call: 'txpool_import',
+			params: 1
+		}),
+	],
 	properties:
 	[
 		new web3._extend.Property({
@@ -786,6 +814,10 @@ web3._extend({
 				return status;
 			}
 		}),
+		new web3._extend.Property({
+			name: 'statusByType',
+			getter: 'txpool_statusByType'
+		}),
 		new web3._extend.Method({
 			name: 'contentFrom',
 			call: 'txpool_contentFrom',
@@ -805,6 +837,11 @@ web3._extend({
 			call: 'les_getCheckpoint',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'getChtEntry',
+			call: 'les_getChtEntry',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'clientInfo',
 			call: 'les_clientInfo',
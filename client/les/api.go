@@ -17,9 +17,11 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/r5-labs/r5-core/client/common"
 	"github.com/r5-labs/r5-core/client/common/hexutil"
 	"github.com/r5-labs/r5-core/client/common/mclock"
 	vfs "github.com/r5-labs/r5-core/client/les/vflux/server"
+	"github.com/r5-labs/r5-core/client/light"
 	"github.com/r5-labs/r5-core/client/p2p/enode"
 )
 
@@ -320,6 +322,29 @@ func (api *LightServerAPI) Benchmark(setups []map[string]interface{}, passCount,
 	return result, nil
 }
 
+// ChtEntry is the canonical hash and total difficulty of a block as recorded in
+// the canonical hash trie, along with the Merkle proof of the entry.
+type ChtEntry struct {
+	Hash  common.Hash     `json:"hash"`
+	Td    *hexutil.Big    `json:"totalDifficulty"`
+	Proof []hexutil.Bytes `json:"proof"`
+}
+
+// GetChtEntry returns the canonical hash and total difficulty of the block with
+// the given number straight from the CHT of its section, without walking the
+// chain. The section must have been committed by the local CHT indexer.
+func (api *LightServerAPI) GetChtEntry(number hexutil.Uint64) (*ChtEntry, error) {
+	node, proof, err := light.ReadChtEntry(api.server.chainDb, api.server.iConfig.ChtSize, uint64(number))
+	if err != nil {
+		return nil, err
+	}
+	entry := &ChtEntry{Hash: node.Hash, Td: (*hexutil.Big)(node.Td)}
+	for _, blob := range proof.NodeList() {
+		entry.Proof = append(entry.Proof, hexutil.Bytes(blob))
+	}
+	return entry, nil
+}
+
 // DebugAPI provides an API to debug LES light server functionality.
 type DebugAPI struct {
 	server *LesServer
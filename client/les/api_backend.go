@@ -217,6 +217,10 @@ func (b *LesApiBackend) Stats() (pending int, queued int) {
 	return b.eth.txPool.Stats(), 0
 }
 
+func (b *LesApiBackend) StatsByType() (pending map[uint8]int, queued map[uint8]int) {
+	return b.eth.txPool.StatsByType(), make(map[uint8]int)
+}
+
 func (b *LesApiBackend) TxPoolContent() (map[common.Address]types.Transactions, map[common.Address]types.Transactions) {
 	return b.eth.txPool.Content()
 }
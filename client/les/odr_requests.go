@@ -170,7 +170,7 @@ func (r *ReceiptsRequest) Validate(db ethdb.Database, msg *Msg) error {
 	if r.Header == nil {
 		return errHeaderUnavailable
 	}
-	if r.Header.ReceiptHash != types.DeriveSha(receipt, trie.NewStackTrie(nil)) {
+	if r.Header.ReceiptHash != trie.DeriveReceiptsRoot(receipt) {
 		return errReceiptHashMismatch
 	}
 	// Validations passed, store and return
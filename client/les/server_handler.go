@@ -379,10 +379,10 @@ func (h *serverHandler) GetHelperTrie(typ uint, index uint64) *trie.Trie {
 	)
 	switch typ {
 	case htCanonical:
-		sectionHead := rawdb.ReadCanonicalHash(h.chainDb, (index+1)*h.server.iConfig.ChtSize-1)
+		sectionHead := rawdb.ReadCanonicalHash(h.chainDb, light.SectionHeadNumber(index, h.server.iConfig.ChtSize))
 		root, prefix = light.GetChtRoot(h.chainDb, index, sectionHead), string(rawdb.ChtTablePrefix)
 	case htBloomBits:
-		sectionHead := rawdb.ReadCanonicalHash(h.chainDb, (index+1)*h.server.iConfig.BloomTrieSize-1)
+		sectionHead := rawdb.ReadCanonicalHash(h.chainDb, light.SectionHeadNumber(index, h.server.iConfig.BloomTrieSize))
 		root, prefix = light.GetBloomTrieRoot(h.chainDb, index, sectionHead), string(rawdb.BloomTrieTablePrefix)
 	}
 	if root == (common.Hash{}) {
@@ -136,7 +136,7 @@ func (h *clientHandler) synchronise(peer *serverPeer) {
 	case checkpoint.Empty():
 		mode = lightSync
 		log.Debug("Disable checkpoint syncing", "reason", "empty checkpoint")
-	case latest.Number.Uint64() >= (checkpoint.SectionIndex+1)*h.backend.iConfig.ChtSize-1:
+	case latest.Number.Uint64() >= light.SectionHeadNumber(checkpoint.SectionIndex, h.backend.iConfig.ChtSize):
 		mode = lightSync
 		log.Debug("Disable checkpoint syncing", "reason", "local chain beyond the checkpoint")
 	case local:
@@ -228,7 +228,7 @@ func GetBloomBits(ctx context.Context, odr OdrBackend, bit uint, sections []uint
 	)
 	blooms, _, sectionHead := odr.BloomTrieIndexer().Sections()
 	for i, section := range sections {
-		sectionHead := rawdb.ReadCanonicalHash(db, (section+1)*odr.IndexerConfig().BloomSize-1)
+		sectionHead := rawdb.ReadCanonicalHash(db, SectionHeadNumber(section, odr.IndexerConfig().BloomSize))
 		// If we don't have the canonical hash stored for this section head number,
 		// we'll still look for an entry with a zero sectionHead (we store it with
 		// zero section head too if we don't know it at the time of the retrieval)
@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sync"
 	"time"
 
 	"github.com/r5-labs/r5-core/client/common"
@@ -28,6 +29,7 @@ import (
 	"github.com/r5-labs/r5-core/client/core/types"
 	"github.com/r5-labs/r5-core/client/ethdb"
 	"github.com/r5-labs/r5-core/client/log"
+	"github.com/r5-labs/r5-core/client/metrics"
 	"github.com/r5-labs/r5-core/client/params"
 	"github.com/r5-labs/r5-core/client/rlp"
 	"github.com/r5-labs/r5-core/client/trie"
@@ -53,8 +55,16 @@ type IndexerConfig struct {
 
 	// The number of confirmations needed to generate/accept a bloom trie.
 	BloomTrieConfirms uint64
+
+	// The number of concurrent workers retrieving the bloom bits of a missing
+	// bloom trie section, zero means the default.
+	BloomTrieFetchWorkers int
 }
 
+// defaultBloomTrieFetchWorkers is the number of concurrent bloom bit retrievals
+// used if the indexer config doesn't specify one.
+const defaultBloomTrieFetchWorkers = 20
+
 var (
 	// DefaultServerIndexerConfig wraps a set of configs as a default indexer config for server side.
 	DefaultServerIndexerConfig = &IndexerConfig{
@@ -94,10 +104,31 @@ var (
 	}
 )
 
+// SectionHead returns the index of the section the given block belongs to and
+// the number of the last block in that section (the section head), for a chain
+// indexer producing sections of sectionSize blocks. A zero section size is
+// rejected with an error.
+func SectionHead(number, sectionSize uint64) (section uint64, head uint64, err error) {
+	if sectionSize == 0 {
+		return 0, 0, errZeroSectionSize
+	}
+	section = number / sectionSize
+	return section, SectionHeadNumber(section, sectionSize), nil
+}
+
+// SectionHeadNumber returns the number of the last block in the section with
+// the given index, for a chain indexer producing sections of sectionSize blocks.
+func SectionHeadNumber(section, sectionSize uint64) uint64 {
+	return (section+1)*sectionSize - 1
+}
+
+var chtSectionGauge = metrics.NewRegisteredGauge("light/cht/section", nil)
+
 var (
 	errNoTrustedCht       = errors.New("no trusted canonical hash trie")
 	errNoTrustedBloomTrie = errors.New("no trusted bloom trie")
 	errNoHeader           = errors.New("header not found")
+	errZeroSectionSize    = errors.New("zero section size")
 )
 
 // ChtNode structures are stored in the Canonical Hash Trie in an RLP encoded format
@@ -121,6 +152,52 @@ func StoreChtRoot(db ethdb.Database, sectionIdx uint64, sectionHead, root common
 	db.Put(append(append(rawdb.ChtPrefix, encNumber[:]...), sectionHead.Bytes()...), root.Bytes())
 }
 
+// ReadChtEntry looks up the canonical hash and total difficulty of the block with
+// the given number in the CHT of the section containing it, as committed by the
+// local CHT indexer producing sections of sectionSize blocks. The Merkle proof of
+// the entry against the CHT root is returned along with it.
+func ReadChtEntry(db ethdb.Database, sectionSize, number uint64) (*ChtNode, *NodeSet, error) {
+	section, head, err := SectionHead(number, sectionSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	sectionHead := rawdb.ReadCanonicalHash(db, head)
+	if sectionHead == (common.Hash{}) {
+		return nil, nil, errNoHeader
+	}
+	root := GetChtRoot(db, section, sectionHead)
+	if root == (common.Hash{}) {
+		return nil, nil, errNoTrustedCht
+	}
+	cht, err := trie.New(trie.TrieID(root), trie.NewDatabase(rawdb.NewTable(db, string(rawdb.ChtTablePrefix))))
+	if err != nil {
+		return nil, nil, err
+	}
+	var encNumber [8]byte
+	binary.BigEndian.PutUint64(encNumber[:], number)
+	blob, err := cht.Get(encNumber[:])
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(blob) == 0 {
+		return nil, nil, errNoHeader
+	}
+	node := new(ChtNode)
+	if err := rlp.DecodeBytes(blob, node); err != nil {
+		return nil, nil, err
+	}
+	proof := NewNodeSet()
+	if err := cht.Prove(encNumber[:], 0, proof); err != nil {
+		return nil, nil, err
+	}
+	return node, proof, nil
+}
+
+// ChtProgressFn is invoked by the CHT indexer after each processed header with
+// the section being built, the number of headers processed in it so far and the
+// total number of headers in a section.
+type ChtProgressFn func(section, processed, total uint64)
+
 // ChtIndexerBackend implements core.ChainIndexerBackend.
 type ChtIndexerBackend struct {
 	disablePruning       bool
@@ -128,22 +205,31 @@ type ChtIndexerBackend struct {
 	odr                  OdrBackend
 	triedb               *trie.Database
 	section, sectionSize uint64
+	processed            uint64
+	progress             ChtProgressFn
 	lastHash             common.Hash
 	trie                 *trie.Trie
 }
 
-// NewChtIndexer creates a Cht chain indexer
-func NewChtIndexer(db ethdb.Database, odr OdrBackend, size, confirms uint64, disablePruning bool) *core.ChainIndexer {
+// NewChtIndexer creates a Cht chain indexer. The optional progress callback is
+// notified after each header added to the section being built.
+func NewChtIndexer(db ethdb.Database, odr OdrBackend, size, confirms uint64, disablePruning bool, progress ChtProgressFn) *core.ChainIndexer {
+	backend := newChtIndexerBackend(db, odr, size, disablePruning, progress)
+	return core.NewChainIndexer(db, rawdb.NewTable(db, string(rawdb.ChtIndexTablePrefix)), backend, size, confirms, time.Millisecond*100, "cht")
+}
+
+// newChtIndexerBackend creates the backend of a Cht chain indexer.
+func newChtIndexerBackend(db ethdb.Database, odr OdrBackend, size uint64, disablePruning bool, progress ChtProgressFn) *ChtIndexerBackend {
 	trieTable := rawdb.NewTable(db, string(rawdb.ChtTablePrefix))
-	backend := &ChtIndexerBackend{
+	return &ChtIndexerBackend{
 		diskdb:         db,
 		odr:            odr,
 		trieTable:      trieTable,
 		triedb:         trie.NewDatabaseWithConfig(trieTable, &trie.Config{Cache: 1}), // Use a tiny cache only to keep memory down
 		sectionSize:    size,
 		disablePruning: disablePruning,
+		progress:       progress,
 	}
-	return core.NewChainIndexer(db, rawdb.NewTable(db, string(rawdb.ChtIndexTablePrefix)), backend, size, confirms, time.Millisecond*100, "cht")
 }
 
 // fetchMissingNodes tries to retrieve the last entry of the latest trusted CHT from the
@@ -187,6 +273,8 @@ func (c *ChtIndexerBackend) Reset(ctx context.Context, section uint64, lastSecti
 		}
 	}
 	c.section = section
+	c.processed = 0
+	chtSectionGauge.Update(int64(section))
 	return err
 }
 
@@ -202,7 +290,14 @@ func (c *ChtIndexerBackend) Process(ctx context.Context, header *types.Header) e
 	var encNumber [8]byte
 	binary.BigEndian.PutUint64(encNumber[:], num)
 	data, _ := rlp.EncodeToBytes(ChtNode{hash, td})
-	return c.trie.Update(encNumber[:], data)
+	if err := c.trie.Update(encNumber[:], data); err != nil {
+		return err
+	}
+	c.processed++
+	if c.progress != nil {
+		c.progress(c.section, c.processed, c.sectionSize)
+	}
+	return nil
 }
 
 // Commit implements core.ChainIndexerBackend
@@ -266,15 +361,9 @@ func (c *ChtIndexerBackend) Prune(threshold uint64) error {
 		return nil
 	}
 	t := time.Now()
-	// Always keep genesis header in database.
-	start, end := uint64(1), (threshold+1)*c.sectionSize
 
 	var batch = c.diskdb.NewBatch()
-	for {
-		numbers, hashes := rawdb.ReadAllCanonicalHashes(c.diskdb, start, end, 10240)
-		if len(numbers) == 0 {
-			break
-		}
+	err := c.iteratePrunable(threshold, func(numbers []uint64, hashes []common.Hash) error {
 		for i := 0; i < len(numbers); i++ {
 			// Keep hash<->number mapping in database otherwise the hash based
 			// API(e.g. GetReceipt, GetLogs) will be broken.
@@ -293,7 +382,10 @@ func (c *ChtIndexerBackend) Prune(threshold uint64) error {
 			}
 			batch.Reset()
 		}
-		start = numbers[len(numbers)-1] + 1
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 	if err := batch.Write(); err != nil {
 		return err
@@ -302,6 +394,54 @@ func (c *ChtIndexerBackend) Prune(threshold uint64) error {
 	return nil
 }
 
+// EstimatePrune scans the same block range as Prune without deleting anything,
+// returning the number of blocks which would be pruned and the approximate
+// size of their data.
+func (c *ChtIndexerBackend) EstimatePrune(threshold uint64) (int, common.StorageSize, error) {
+	// Short circuit if the light pruning is disabled.
+	if c.disablePruning {
+		return 0, 0, nil
+	}
+	var (
+		blocks int
+		size   common.StorageSize
+	)
+	err := c.iteratePrunable(threshold, func(numbers []uint64, hashes []common.Hash) error {
+		for i := 0; i < len(numbers); i++ {
+			// Account for the canonical mapping (key and hash) and the block data.
+			size += common.StorageSize(10 + common.HashLength)
+			size += common.StorageSize(len(rawdb.ReadHeaderRLP(c.diskdb, hashes[i], numbers[i])))
+			size += common.StorageSize(len(rawdb.ReadBodyRLP(c.diskdb, hashes[i], numbers[i])))
+			size += common.StorageSize(len(rawdb.ReadReceiptsRLP(c.diskdb, hashes[i], numbers[i])))
+			size += common.StorageSize(len(rawdb.ReadTdRLP(c.diskdb, hashes[i], numbers[i])))
+		}
+		blocks += len(numbers)
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	log.Info("Estimated history header pruning", "threshold", threshold, "blocks", blocks, "size", size)
+	return blocks, size, nil
+}
+
+// iteratePrunable invokes the callback with the batches of canonical blocks
+// prunable below the given section threshold.
+func (c *ChtIndexerBackend) iteratePrunable(threshold uint64, callback func(numbers []uint64, hashes []common.Hash) error) error {
+	// Always keep genesis header in database.
+	start, end := uint64(1), (threshold+1)*c.sectionSize
+	for {
+		numbers, hashes := rawdb.ReadAllCanonicalHashes(c.diskdb, start, end, 10240)
+		if len(numbers) == 0 {
+			return nil
+		}
+		if err := callback(numbers, hashes); err != nil {
+			return err
+		}
+		start = numbers[len(numbers)-1] + 1
+	}
+}
+
 // GetBloomTrieRoot reads the BloomTrie root associated to the given section from the database
 func GetBloomTrieRoot(db ethdb.Database, sectionIdx uint64, sectionHead common.Hash) common.Hash {
 	var encNumber [8]byte
@@ -351,16 +491,28 @@ func NewBloomTrieIndexer(db ethdb.Database, odr OdrBackend, parentSize, size uin
 // fetchMissingNodes tries to retrieve the last entries of the latest trusted bloom trie from the
 // ODR backend in order to be able to add new entries and calculate subsequent root hashes
 func (b *BloomTrieIndexerBackend) fetchMissingNodes(ctx context.Context, section uint64, root common.Hash) error {
-	indexCh := make(chan uint, types.BloomBitLength)
+	config := b.odr.IndexerConfig()
+	workers := config.BloomTrieFetchWorkers
+	if workers <= 0 {
+		workers = defaultBloomTrieFetchWorkers
+	}
+	ctx, cancel := context.WithCancel(ctx)
+
+	indexCh := make(chan uint)
 	type res struct {
 		nodes *NodeSet
 		err   error
 	}
-	resCh := make(chan res, types.BloomBitLength)
-	for i := 0; i < 20; i++ {
+	var (
+		resCh = make(chan res, types.BloomBitLength)
+		wg    sync.WaitGroup
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
 		go func() {
+			defer wg.Done()
 			for bitIndex := range indexCh {
-				r := &BloomRequest{BloomTrieRoot: root, BloomTrieNum: section - 1, BitIdx: bitIndex, SectionIndexList: []uint64{section - 1}, Config: b.odr.IndexerConfig()}
+				r := &BloomRequest{BloomTrieRoot: root, BloomTrieNum: section - 1, BitIdx: bitIndex, SectionIndexList: []uint64{section - 1}, Config: config}
 				for {
 					if err := b.odr.Retrieve(ctx, r); err == ErrNoPeers {
 						// if there are no peers to serve, retry later
@@ -379,13 +531,32 @@ func (b *BloomTrieIndexerBackend) fetchMissingNodes(ctx context.Context, section
 			}
 		}()
 	}
-	for i := uint(0); i < types.BloomBitLength; i++ {
-		indexCh <- i
-	}
-	close(indexCh)
+	// Abort all the remaining retrievals as soon as any of them fails, and
+	// wait for the workers to exit so none of them outlives the call.
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+	// Feed the workers until all the bits are scheduled or the retrieval is
+	// aborted.
+	go func() {
+		defer close(indexCh)
+		for i := uint(0); i < types.BloomBitLength; i++ {
+			select {
+			case indexCh <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
 	batch := b.trieTable.NewBatch()
 	for i := uint(0); i < types.BloomBitLength; i++ {
-		res := <-resCh
+		var res res
+		select {
+		case res = <-resCh:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
 		if res.err != nil {
 			return res.err
 		}
@@ -523,3 +694,24 @@ func (b *BloomTrieIndexerBackend) Prune(threshold uint64) error {
 	log.Debug("Prune history bloombits", "threshold", threshold, "elapsed", common.PrettyDuration(time.Since(start)))
 	return nil
 }
+
+// EstimatePrune scans the same bloom bits as Prune without deleting anything,
+// returning the number of entries which would be pruned and their approximate
+// size.
+func (b *BloomTrieIndexerBackend) EstimatePrune(threshold uint64) (int, common.StorageSize, error) {
+	// Short circuit if the light pruning is disabled.
+	if b.disablePruning {
+		return 0, 0, nil
+	}
+	var (
+		entries int
+		size    common.StorageSize
+	)
+	for i := uint(0); i < types.BloomBitLength; i++ {
+		count, bitsSize := rawdb.CountBloombits(b.diskdb, i, 0, threshold*b.bloomTrieRatio+b.bloomTrieRatio)
+		entries += count
+		size += bitsSize
+	}
+	log.Info("Estimated history bloombits pruning", "threshold", threshold, "entries", entries, "size", size)
+	return entries, size, nil
+}
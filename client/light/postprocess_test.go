@@ -0,0 +1,364 @@
+// Copyright 2025 R5 Labs
+// This file is part of the R5 Core library.
+//
+// This software is provided "as is", without warranty of any kind,
+// express or implied, including but not limited to the warranties
+// of merchantability, fitness for a particular purpose and
+// noninfringement. In no event shall the authors or copyright
+// holders be liable for any claim, damages, or other liability,
+// whether in an action of contract, tort or otherwise, arising
+// from, out of or in connection with the software or the use or
+// other dealings in the software.
+
+package light
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/r5-labs/r5-core/client/common"
+	"github.com/r5-labs/r5-core/client/core/rawdb"
+	"github.com/r5-labs/r5-core/client/core/types"
+	"github.com/r5-labs/r5-core/client/rlp"
+	"github.com/r5-labs/r5-core/client/trie"
+)
+
+func TestSectionHead(t *testing.T) {
+	tests := []struct {
+		number, size  uint64
+		section, head uint64
+	}{
+		{0, 128, 0, 127},
+		{1, 128, 0, 127},
+		{127, 128, 0, 127},
+		{128, 128, 1, 255},
+		{255, 128, 1, 255},
+		{256, 128, 2, 383},
+		{32767, 32768, 0, 32767},
+		{32768, 32768, 1, 65535},
+		{100000, 32768, 3, 131071},
+		{4095, 4096, 0, 4095},
+		{4096, 4096, 1, 8191},
+	}
+	for i, tt := range tests {
+		section, head, err := SectionHead(tt.number, tt.size)
+		if err != nil {
+			t.Fatalf("test %d: failed to compute section head: %v", i, err)
+		}
+		if section != tt.section || head != tt.head {
+			t.Errorf("test %d: section head mismatch for block %d (size %d): have (%d, %d), want (%d, %d)",
+				i, tt.number, tt.size, section, head, tt.section, tt.head)
+		}
+		if head < tt.number {
+			t.Errorf("test %d: section head %d before block %d", i, head, tt.number)
+		}
+		if next, _, _ := SectionHead(head+1, tt.size); next != section+1 {
+			t.Errorf("test %d: block after head in section %d, want %d", i, next, section+1)
+		}
+	}
+	if _, _, err := SectionHead(1, 0); err != errZeroSectionSize {
+		t.Errorf("zero section size error mismatch: have %v, want %v", err, errZeroSectionSize)
+	}
+}
+
+func TestChtIndexerProgress(t *testing.T) {
+	type progress struct{ section, processed, total uint64 }
+	var (
+		db       = rawdb.NewMemoryDatabase()
+		size     = TestClientIndexerConfig.ChtSize
+		sections = uint64(2)
+		reports  []progress
+	)
+	backend := newChtIndexerBackend(db, nil, size, true, func(section, processed, total uint64) {
+		reports = append(reports, progress{section, processed, total})
+	})
+	var (
+		parent common.Hash
+		td     = new(big.Int)
+	)
+	for section := uint64(0); section < sections; section++ {
+		if err := backend.Reset(context.Background(), section, parent); err != nil {
+			t.Fatalf("section %d: failed to reset: %v", section, err)
+		}
+		for i := uint64(0); i < size; i++ {
+			header := &types.Header{ParentHash: parent, Number: new(big.Int).SetUint64(section*size + i), Difficulty: big.NewInt(1)}
+			td.Add(td, header.Difficulty)
+			rawdb.WriteTd(db, header.Hash(), header.Number.Uint64(), td)
+			if err := backend.Process(context.Background(), header); err != nil {
+				t.Fatalf("section %d: failed to process header %d: %v", section, header.Number, err)
+			}
+			parent = header.Hash()
+		}
+		if err := backend.Commit(); err != nil {
+			t.Fatalf("section %d: failed to commit: %v", section, err)
+		}
+		if root := GetChtRoot(db, section, parent); root == (common.Hash{}) {
+			t.Fatalf("section %d: missing CHT root", section)
+		}
+	}
+	if len(reports) != int(sections*size) {
+		t.Fatalf("progress report count mismatch: have %d, want %d", len(reports), sections*size)
+	}
+	for i, report := range reports {
+		want := progress{uint64(i) / size, uint64(i)%size + 1, size}
+		if report != want {
+			t.Errorf("report %d: progress mismatch: have %+v, want %+v", i, report, want)
+		}
+	}
+	// The indexer must also work without a progress callback.
+	backend = newChtIndexerBackend(rawdb.NewMemoryDatabase(), nil, size, true, nil)
+	if err := backend.Reset(context.Background(), 0, common.Hash{}); err != nil {
+		t.Fatalf("failed to reset: %v", err)
+	}
+	header := &types.Header{Number: big.NewInt(0), Difficulty: big.NewInt(1)}
+	rawdb.WriteTd(backend.diskdb, header.Hash(), 0, header.Difficulty)
+	if err := backend.Process(context.Background(), header); err != nil {
+		t.Fatalf("failed to process header without callback: %v", err)
+	}
+}
+
+// bloomFetchOdr is an ODR backend serving empty bloom bit proofs, tracking the
+// number of concurrent retrievals.
+type bloomFetchOdr struct {
+	OdrBackend
+	config   *IndexerConfig
+	failBit  int // Bit index to fail the retrieval of, -1 for none
+	block    bool
+	inflight int32
+	peak     int32
+	calls    int32
+}
+
+func (odr *bloomFetchOdr) IndexerConfig() *IndexerConfig { return odr.config }
+
+func (odr *bloomFetchOdr) Retrieve(ctx context.Context, req OdrRequest) error {
+	r := req.(*BloomRequest)
+	atomic.AddInt32(&odr.calls, 1)
+	inflight := atomic.AddInt32(&odr.inflight, 1)
+	defer atomic.AddInt32(&odr.inflight, -1)
+	for {
+		peak := atomic.LoadInt32(&odr.peak)
+		if inflight <= peak || atomic.CompareAndSwapInt32(&odr.peak, peak, inflight) {
+			break
+		}
+	}
+	if odr.block {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	time.Sleep(time.Millisecond)
+	if int(r.BitIdx) == odr.failBit {
+		return errors.New("retrieval failed")
+	}
+	r.Proofs = NewNodeSet()
+	return nil
+}
+
+func TestBloomTrieFetchWorkers(t *testing.T) {
+	newBackend := func(odr *bloomFetchOdr) *BloomTrieIndexerBackend {
+		db := rawdb.NewMemoryDatabase()
+		return &BloomTrieIndexerBackend{diskdb: db, odr: odr, trieTable: rawdb.NewTable(db, string(rawdb.BloomTrieTablePrefix))}
+	}
+	// The configured worker count must bound the concurrent retrievals.
+	for _, workers := range []int{0, 1, 4} {
+		config := *TestClientIndexerConfig
+		config.BloomTrieFetchWorkers = workers
+
+		odr := &bloomFetchOdr{config: &config, failBit: -1}
+		if err := newBackend(odr).fetchMissingNodes(context.Background(), 1, common.Hash{}); err != nil {
+			t.Fatalf("workers %d: failed to fetch bloom bits: %v", workers, err)
+		}
+		limit := workers
+		if limit == 0 {
+			limit = defaultBloomTrieFetchWorkers
+		}
+		if peak := atomic.LoadInt32(&odr.peak); peak > int32(limit) || peak == 0 {
+			t.Errorf("workers %d: concurrent retrievals %d exceeding limit %d", workers, peak, limit)
+		}
+		if calls := atomic.LoadInt32(&odr.calls); calls != types.BloomBitLength {
+			t.Errorf("workers %d: retrieval count mismatch: have %d, want %d", workers, calls, types.BloomBitLength)
+		}
+	}
+	// A failing retrieval must stop all the workers before returning.
+	config := *TestClientIndexerConfig
+	config.BloomTrieFetchWorkers = 4
+
+	odr := &bloomFetchOdr{config: &config, failBit: 10}
+	if err := newBackend(odr).fetchMissingNodes(context.Background(), 1, common.Hash{}); err == nil {
+		t.Fatal("fetched bloom bits with failing retrieval")
+	}
+	if inflight := atomic.LoadInt32(&odr.inflight); inflight != 0 {
+		t.Fatalf("retrievals still running after failure: %d", inflight)
+	}
+	calls := atomic.LoadInt32(&odr.calls)
+	time.Sleep(10 * time.Millisecond)
+	if calls == types.BloomBitLength || atomic.LoadInt32(&odr.calls) != calls {
+		t.Fatalf("retrievals continued after failure: %d", atomic.LoadInt32(&odr.calls))
+	}
+	// Cancelling the context must unblock the pending retrievals.
+	odr = &bloomFetchOdr{config: &config, failBit: -1, block: true}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := newBackend(odr).fetchMissingNodes(ctx, 1, common.Hash{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error mismatch: have %v, want %v", err, context.DeadlineExceeded)
+	}
+	if inflight := atomic.LoadInt32(&odr.inflight); inflight != 0 {
+		t.Fatalf("retrievals still running after cancellation: %d", inflight)
+	}
+}
+
+func TestChtEstimatePrune(t *testing.T) {
+	var (
+		db       = rawdb.NewMemoryDatabase()
+		size     = TestClientIndexerConfig.ChtSize
+		sections = uint64(3)
+		parent   common.Hash
+	)
+	for number := uint64(0); number < sections*size; number++ {
+		header := &types.Header{ParentHash: parent, Number: new(big.Int).SetUint64(number), Difficulty: big.NewInt(1)}
+		rawdb.WriteHeader(db, header)
+		rawdb.WriteCanonicalHash(db, header.Hash(), number)
+		rawdb.WriteTd(db, header.Hash(), number, new(big.Int).SetUint64(number+1))
+		parent = header.Hash()
+	}
+	countCanonical := func() int {
+		numbers, _ := rawdb.ReadAllCanonicalHashes(db, 0, sections*size, int(sections*size))
+		return len(numbers)
+	}
+	backend := newChtIndexerBackend(db, nil, size, false, nil)
+
+	// The estimate must not delete anything.
+	blocks, estimate, err := backend.EstimatePrune(1)
+	if err != nil {
+		t.Fatalf("failed to estimate pruning: %v", err)
+	}
+	if have := countCanonical(); have != int(sections*size) {
+		t.Fatalf("dry run removed canonical hashes: have %d, want %d", have, sections*size)
+	}
+	// Genesis is retained, the rest of the first two sections is pruned.
+	if want := int(2*size - 1); blocks != want {
+		t.Fatalf("estimated block count mismatch: have %d, want %d", blocks, want)
+	}
+	if estimate == 0 {
+		t.Fatal("estimated zero pruning size")
+	}
+	if err := backend.Prune(1); err != nil {
+		t.Fatalf("failed to prune: %v", err)
+	}
+	if deleted := int(sections*size) - countCanonical(); deleted != blocks {
+		t.Fatalf("pruned block count mismatch: have %d, want %d", deleted, blocks)
+	}
+	if blocks, _, _ := backend.EstimatePrune(1); blocks != 0 {
+		t.Fatalf("prunable blocks left after pruning: %d", blocks)
+	}
+	// Disabled pruning reports nothing to do.
+	if blocks, estimate, err := newChtIndexerBackend(db, nil, size, true, nil).EstimatePrune(2); blocks != 0 || estimate != 0 || err != nil {
+		t.Fatalf("unexpected estimate with pruning disabled: %d, %v, %v", blocks, estimate, err)
+	}
+}
+
+func TestBloomTrieEstimatePrune(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	backend := &BloomTrieIndexerBackend{diskdb: db, bloomTrieRatio: 2}
+
+	bits := []uint{0, 1, types.BloomBitLength - 1}
+	for _, bit := range bits {
+		for section := uint64(0); section < 6; section++ {
+			rawdb.WriteBloomBits(db, bit, section, common.Hash{byte(section)}, []byte{0x01, 0x02})
+		}
+	}
+	countBits := func() int {
+		var total int
+		for _, bit := range bits {
+			count, _ := rawdb.CountBloombits(db, bit, 0, 6)
+			total += count
+		}
+		return total
+	}
+	// Threshold 1 prunes the bloom bits sections of the first two bloom tries.
+	entries, size, err := backend.EstimatePrune(1)
+	if err != nil {
+		t.Fatalf("failed to estimate pruning: %v", err)
+	}
+	if want := len(bits) * 4; entries != want || size == 0 {
+		t.Fatalf("estimate mismatch: have %d (%v), want %d", entries, size, want)
+	}
+	if have := countBits(); have != len(bits)*6 {
+		t.Fatalf("dry run removed bloom bits: have %d, want %d", have, len(bits)*6)
+	}
+	if err := backend.Prune(1); err != nil {
+		t.Fatalf("failed to prune: %v", err)
+	}
+	if deleted := len(bits)*6 - countBits(); deleted != entries {
+		t.Fatalf("pruned entry count mismatch: have %d, want %d", deleted, entries)
+	}
+}
+
+func TestReadChtEntry(t *testing.T) {
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		size   = TestServerIndexerConfig.ChtSize
+		parent common.Hash
+		td     = new(big.Int)
+	)
+	backend := newChtIndexerBackend(db, nil, size, true, nil)
+	if err := backend.Reset(context.Background(), 0, common.Hash{}); err != nil {
+		t.Fatalf("failed to reset: %v", err)
+	}
+	// Index a full section and leave the following one incomplete.
+	for i := uint64(0); i < size+size/2; i++ {
+		header := &types.Header{ParentHash: parent, Number: new(big.Int).SetUint64(i), Difficulty: big.NewInt(int64(i + 1))}
+		td.Add(td, header.Difficulty)
+		rawdb.WriteHeader(db, header)
+		rawdb.WriteTd(db, header.Hash(), i, td)
+		rawdb.WriteCanonicalHash(db, header.Hash(), i)
+		parent = header.Hash()
+
+		if i < size {
+			if err := backend.Process(context.Background(), header); err != nil {
+				t.Fatalf("failed to process header %d: %v", i, err)
+			}
+		}
+	}
+	if err := backend.Commit(); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	sectionHead := rawdb.ReadCanonicalHash(db, size-1)
+	root := GetChtRoot(db, 0, sectionHead)
+
+	for _, number := range []uint64{0, 1, size / 2, size - 1} {
+		entry, proof, err := ReadChtEntry(db, size, number)
+		if err != nil {
+			t.Fatalf("block %d: failed to read CHT entry: %v", number, err)
+		}
+		hash := rawdb.ReadCanonicalHash(db, number)
+		if entry.Hash != hash {
+			t.Errorf("block %d: hash mismatch: have %x, want %x", number, entry.Hash, hash)
+		}
+		if want := rawdb.ReadTd(db, hash, number); entry.Td.Cmp(want) != 0 {
+			t.Errorf("block %d: td mismatch: have %v, want %v", number, entry.Td, want)
+		}
+		var key [8]byte
+		binary.BigEndian.PutUint64(key[:], number)
+		blob, err := trie.VerifyProof(root, key[:], proof)
+		if err != nil {
+			t.Fatalf("block %d: invalid proof: %v", number, err)
+		}
+		var proven ChtNode
+		if err := rlp.DecodeBytes(blob, &proven); err != nil {
+			t.Fatalf("block %d: failed to decode proven entry: %v", number, err)
+		}
+		if proven.Hash != entry.Hash || proven.Td.Cmp(entry.Td) != 0 {
+			t.Errorf("block %d: proven entry mismatch: have %+v, want %+v", number, proven, *entry)
+		}
+	}
+	// Blocks of a section not committed yet can't be served from the CHT.
+	if _, _, err := ReadChtEntry(db, size, size); err == nil {
+		t.Fatal("read CHT entry of an uncommitted section")
+	}
+}
@@ -488,6 +488,19 @@ func (pool *TxPool) GetTransactions() (txs types.Transactions, err error) {
 	return txs, nil
 }
 
+// StatsByType returns the number of currently pending (locally created)
+// transactions, broken down by transaction type.
+func (pool *TxPool) StatsByType() (pending map[uint8]int) {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	pending = make(map[uint8]int)
+	for _, tx := range pool.pending {
+		pending[tx.Type()]++
+	}
+	return
+}
+
 // Content retrieves the data content of the transaction pool, returning all the
 // pending as well as queued transactions, grouped by account and nonce.
 func (pool *TxPool) Content() (map[common.Address]types.Transactions, map[common.Address]types.Transactions) {
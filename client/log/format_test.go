@@ -1,6 +1,8 @@
 package log
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"math"
 	"math/big"
@@ -159,3 +161,36 @@ func TestSanitation(t *testing.T) {
 		}
 	}
 }
+
+func TestJSONHandler(t *testing.T) {
+	var (
+		out bytes.Buffer
+		l   = New()
+	)
+	l.SetHandler(LvlFilterHandler(LvlInfo, JSONHandler(&out)))
+	l.Info("Imported new chain segment", "blocks", 2, "hash", "0xabcd")
+	l.Debug("Filtered out by verbosity")
+	l.Warn("Dropping peer", "id", 1)
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("record count mismatch: have %d, want 2\n%s", len(lines), out.String())
+	}
+	for i, want := range []map[string]interface{}{
+		{"lvl": "info", "msg": "Imported new chain segment", "blocks": float64(2), "hash": "0xabcd"},
+		{"lvl": "warn", "msg": "Dropping peer", "id": float64(1)},
+	} {
+		var have map[string]interface{}
+		if err := json.Unmarshal([]byte(lines[i]), &have); err != nil {
+			t.Fatalf("record %d: invalid JSON %q: %v", i, lines[i], err)
+		}
+		if _, ok := have["t"]; !ok {
+			t.Errorf("record %d: missing time field: %s", i, lines[i])
+		}
+		for key, val := range want {
+			if have[key] != val {
+				t.Errorf("record %d: field %q mismatch: have %v, want %v", i, key, have[key], val)
+			}
+		}
+	}
+}
@@ -46,6 +46,13 @@ func StreamHandler(wr io.Writer, fmtr Format) Handler {
 	return LazyHandler(SyncHandler(h))
 }
 
+// JSONHandler returns a handler that writes log records to the given writer as
+// newline separated JSON objects, holding the time, level, message and context
+// fields of each record. It can be wrapped by filter handlers like any other.
+func JSONHandler(wr io.Writer) Handler {
+	return StreamHandler(wr, JSONFormat())
+}
+
 // SyncHandler can be wrapped around a handler to guarantee that
 // only a single Log operation can proceed at a time. It's necessary
 // for thread-safe concurrent writes.
@@ -14,6 +14,7 @@
 package miner
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 	"sync"
@@ -21,6 +22,8 @@ import (
 
 	"github.com/r5-labs/r5-core/client/common"
 	"github.com/r5-labs/r5-core/client/common/hexutil"
+	"github.com/r5-labs/r5-core/client/common/lru"
+	"github.com/r5-labs/r5-core/client/common/mclock"
 	"github.com/r5-labs/r5-core/client/consensus"
 	"github.com/r5-labs/r5-core/client/core"
 	"github.com/r5-labs/r5-core/client/core/state"
@@ -32,6 +35,10 @@ import (
 	"github.com/r5-labs/r5-core/client/params"
 )
 
+// maxBlockTemplates is the maximum number of block templates tracked for external
+// sealers, bounding the memory used by clients requesting them over and over.
+const maxBlockTemplates = 64
+
 // Backend wraps all methods required for mining. Only full node is capable
 // to offer all the functions here.
 type Backend interface {
@@ -45,13 +52,29 @@ type Config struct {
 	Notify     []string       `toml:",omitempty"` // HTTP URL list to be notified of new work packages (only useful in ethash).
 	NotifyFull bool           `toml:",omitempty"` // Notify with pending block headers instead of work packages
 	ExtraData  hexutil.Bytes  `toml:",omitempty"` // Block extra data set by the miner
-	GasFloor   uint64         // Target gas floor for mined blocks.
-	GasCeil    uint64         // Target gas ceiling for mined blocks.
-	GasPrice   *big.Int       // Minimum gas price for mining a transaction
-	Recommit   time.Duration  // The time interval for miner to re-create mining work.
-	Noverify   bool           // Disable remote mining solution verification(only useful in ethash).
 
-	NewPayloadTimeout time.Duration // The maximum time allowance for creating a new payload
+	ExtraDataPrefix  hexutil.Bytes `toml:",omitempty"` // Prefix the block extra data is required to start with
+	MaxExtraDataSize uint64        `toml:",omitempty"` // Maximum size of the block extra data, zero or anything above the consensus limit means the consensus limit
+
+	GasFloor  uint64        // Target gas floor for mined blocks.
+	GasCeil   uint64        // Target gas ceiling for mined blocks.
+	GasTarget uint64        // Gas limit to steer mined blocks toward within the floor and ceiling, zero means the ceiling
+	GasPrice  *big.Int      // Minimum gas price for mining a transaction
+	Recommit  time.Duration // The time interval for miner to re-create mining work.
+	Noverify  bool          // Disable remote mining solution verification(only useful in ethash).
+
+	MaxFinalizeFailures int // Consecutive block assembly failures after which mining is paused, zero disables it
+
+	DeterministicOrdering bool `toml:",omitempty"` // Order the block transactions by sender and nonce instead of price
+
+	NewPayloadTimeout    time.Duration // The maximum time allowance for creating a new payload
+	UncleCleanInterval   time.Duration // The time interval for dropping stale uncle candidates
+	UncleLookback        int           // Number of recent blocks whose uncles and ancestors are tracked for uncle inclusion
+	ShutdownDrainTimeout time.Duration // The maximum time allowance for writing sealed blocks on shutdown
+	MinBlockInterval     time.Duration // The minimum time between the timestamps of consecutive mined blocks, zero disables it
+	PrefetchWorkers      int           // Number of goroutines prefetching each state trie while building a block
+
+	clock mclock.Clock // Source of time for the uncle cleanup, nil means the system clock
 }
 
 // DefaultConfig contains default settings for miner.
@@ -59,12 +82,19 @@ var DefaultConfig = Config{
 	GasCeil:  147000000,
 	GasPrice: big.NewInt(params.GWei),
 
+	// Mining is paused after ten consecutive block assembly failures.
+	MaxFinalizeFailures: 10,
+
 	// The default recommit time is chosen as two seconds since
 	// consensus-layer usually will wait a half slot of time(6s)
 	// for payload generation. It should be enough for Geth to
 	// run 3 rounds.
-	Recommit:          2 * time.Second,
-	NewPayloadTimeout: 2 * time.Second,
+	Recommit:             2 * time.Second,
+	NewPayloadTimeout:    2 * time.Second,
+	UncleCleanInterval:   10 * time.Second,
+	UncleLookback:        7,
+	ShutdownDrainTimeout: 5 * time.Second,
+	PrefetchWorkers:      1,
 }
 
 // Miner creates blocks and searches for proof-of-work values.
@@ -77,22 +107,40 @@ type Miner struct {
 	stopCh  chan struct{}
 	worker  *worker
 
+	templates   lru.BasicLRU[common.Hash, *types.Block] // Block templates handed out to external sealers, keyed by seal hash
+	templatesMu sync.Mutex
+
 	wg sync.WaitGroup
 }
 
-func New(eth Backend, config *Config, chainConfig *params.ChainConfig, mux *event.TypeMux, engine consensus.Engine, isLocalBlock func(header *types.Header) bool) *Miner {
+// extraDataLimit returns the maximum size of the block extra data, the configured
+// one capped by the consensus limit.
+func (c *Config) extraDataLimit() uint64 {
+	if c.MaxExtraDataSize != 0 && c.MaxExtraDataSize < params.MaximumExtraDataSize {
+		return c.MaxExtraDataSize
+	}
+	return params.MaximumExtraDataSize
+}
+
+// New creates a miner. It refuses to if the configured extra data prefix exceeds
+// the extra data size limit, as no block could be built then.
+func New(eth Backend, config *Config, chainConfig *params.ChainConfig, mux *event.TypeMux, engine consensus.Engine, isLocalBlock func(header *types.Header) bool) (*Miner, error) {
+	if limit := config.extraDataLimit(); uint64(len(config.ExtraDataPrefix)) > limit {
+		return nil, fmt.Errorf("extra data prefix exceeds max length. %d > %v", len(config.ExtraDataPrefix), limit)
+	}
 	miner := &Miner{
-		mux:     mux,
-		eth:     eth,
-		engine:  engine,
-		exitCh:  make(chan struct{}),
-		startCh: make(chan struct{}),
-		stopCh:  make(chan struct{}),
-		worker:  newWorker(config, chainConfig, engine, eth, mux, isLocalBlock, true),
+		mux:       mux,
+		eth:       eth,
+		engine:    engine,
+		exitCh:    make(chan struct{}),
+		startCh:   make(chan struct{}),
+		stopCh:    make(chan struct{}),
+		worker:    newWorker(config, chainConfig, engine, eth, mux, isLocalBlock, true),
+		templates: lru.NewBasicLRU[common.Hash, *types.Block](maxBlockTemplates),
 	}
 	miner.wg.Add(1)
 	go miner.update()
-	return miner
+	return miner, nil
 }
 
 // update keeps track of the downloader events. Please be aware that this is a one shot type of update loop.
@@ -175,6 +223,12 @@ func (miner *Miner) Mining() bool {
 	return miner.worker.isRunning()
 }
 
+// Status returns the error which paused mining after repeated block assembly
+// failures, or nil if the miner is healthy.
+func (miner *Miner) Status() error {
+	return miner.worker.status()
+}
+
 func (miner *Miner) Hashrate() uint64 {
 	if pow, ok := miner.engine.(consensus.PoW); ok {
 		return uint64(pow.Hashrate())
@@ -183,11 +237,7 @@ func (miner *Miner) Hashrate() uint64 {
 }
 
 func (miner *Miner) SetExtra(extra []byte) error {
-	if uint64(len(extra)) > params.MaximumExtraDataSize {
-		return fmt.Errorf("extra exceeds max length. %d > %v", len(extra), params.MaximumExtraDataSize)
-	}
-	miner.worker.setExtra(extra)
-	return nil
+	return miner.worker.setExtra(extra)
 }
 
 // SetRecommitInterval sets the interval for sealing work resubmitting.
@@ -218,6 +268,12 @@ func (miner *Miner) SetEtherbase(addr common.Address) {
 	miner.worker.setEtherbase(addr)
 }
 
+// SetEtherbases sets the addresses the coinbase of the mined blocks rotates
+// through round-robin, one per block. An empty list reverts to the etherbase.
+func (miner *Miner) SetEtherbases(addrs []common.Address) {
+	miner.worker.setEtherbases(addrs)
+}
+
 // SetGasCeil sets the gaslimit to strive for when mining blocks post 1559.
 // For pre-1559 blocks, it sets the ceiling.
 func (miner *Miner) SetGasCeil(ceil uint64) {
@@ -251,3 +307,65 @@ func (miner *Miner) SubscribePendingLogs(ch chan<- []*types.Log) event.Subscript
 func (miner *Miner) BuildPayload(args *BuildPayloadArgs) (*Payload, error) {
 	return miner.worker.buildPayload(args)
 }
+
+// BlockTemplate assembles a new block with the pending transactions on top of
+// the current head and returns it unsealed, along with the fees collected by
+// its coinbase. The template is tracked so that it can be submitted back with
+// a proof-of-work solution through SubmitBlockTemplate.
+func (miner *Miner) BlockTemplate() (*types.Block, *big.Int, error) {
+	coinbase := miner.worker.etherbase()
+	if coinbase == (common.Address{}) {
+		return nil, nil, errors.New("etherbase missing")
+	}
+	parent := miner.eth.BlockChain().CurrentBlock()
+	timestamp := uint64(time.Now().Unix())
+	if timestamp <= parent.Time {
+		timestamp = parent.Time + 1
+	}
+	res := miner.worker.getSealingBlock(parent.Hash(), timestamp, coinbase, common.Hash{}, nil, false)
+	if res.err != nil {
+		return nil, nil, res.err
+	}
+	miner.trackTemplate(res.block)
+	return res.block, res.fees, nil
+}
+
+// trackTemplate records a block template handed out to an external sealer. The
+// templates too old to be sealed anymore are dropped, and the least recently
+// used ones are evicted once maxBlockTemplates are tracked.
+func (miner *Miner) trackTemplate(block *types.Block) {
+	miner.templatesMu.Lock()
+	defer miner.templatesMu.Unlock()
+
+	for _, hash := range miner.templates.Keys() {
+		if old, _ := miner.templates.Peek(hash); old.NumberU64()+staleThreshold <= block.NumberU64() {
+			miner.templates.Remove(hash)
+		}
+	}
+	miner.templates.Add(miner.engine.SealHash(block.Header()), block)
+}
+
+// SubmitBlockTemplate seals the block template with the given seal hash using
+// the provided proof-of-work solution and imports it into the chain.
+func (miner *Miner) SubmitBlockTemplate(sealhash common.Hash, nonce types.BlockNonce, mixDigest common.Hash) (*types.Block, error) {
+	miner.templatesMu.Lock()
+	block, _ := miner.templates.Get(sealhash)
+	miner.templatesMu.Unlock()
+	if block == nil {
+		return nil, errors.New("unknown block template")
+	}
+	header := block.Header()
+	header.Nonce, header.MixDigest = nonce, mixDigest
+	sealed := block.WithSeal(header)
+
+	if _, err := miner.eth.BlockChain().InsertChain(types.Blocks{sealed}); err != nil {
+		return nil, err
+	}
+	miner.templatesMu.Lock()
+	miner.templates.Remove(sealhash)
+	miner.templatesMu.Unlock()
+
+	log.Info("Imported sealed block template", "number", sealed.Number(), "sealhash", sealhash, "hash", sealed.Hash())
+	miner.mux.Post(core.NewMinedBlockEvent{Block: sealed})
+	return sealed, nil
+}
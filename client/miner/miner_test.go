@@ -16,11 +16,14 @@ package miner
 import (
 	"errors"
 	"math/big"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/r5-labs/r5-core/client/common"
+	"github.com/r5-labs/r5-core/client/common/lru"
 	"github.com/r5-labs/r5-core/client/consensus/clique"
+	"github.com/r5-labs/r5-core/client/consensus/ethash"
 	"github.com/r5-labs/r5-core/client/core"
 	"github.com/r5-labs/r5-core/client/core/rawdb"
 	"github.com/r5-labs/r5-core/client/core/state"
@@ -29,6 +32,7 @@ import (
 	"github.com/r5-labs/r5-core/client/core/vm"
 	"github.com/r5-labs/r5-core/client/eth/downloader"
 	"github.com/r5-labs/r5-core/client/event"
+	"github.com/r5-labs/r5-core/client/params"
 	"github.com/r5-labs/r5-core/client/trie"
 )
 
@@ -202,6 +206,129 @@ func TestCloseMiner(t *testing.T) {
 	waitForMiningState(t, miner, false)
 }
 
+// Tests that a block template can be sealed externally with a real proof-of-work
+// solution and submitted back into the chain.
+func TestBlockTemplate(t *testing.T) {
+	engine := ethash.NewTester(nil, false)
+	defer engine.Close()
+
+	backend := newTestWorkerBackend(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	backend.txPool.AddLocals(pendingTxs)
+	miner, err := New(backend, testConfig, ethashChainConfig, new(event.TypeMux), engine, nil)
+	if err != nil {
+		t.Fatalf("failed to create miner: %v", err)
+	}
+	defer miner.Close()
+
+	if _, _, err := miner.BlockTemplate(); err == nil {
+		t.Fatal("block template assembled without etherbase")
+	}
+	miner.SetEtherbase(testBankAddress)
+
+	block, fees, err := miner.BlockTemplate()
+	if err != nil {
+		t.Fatalf("failed to assemble block template: %v", err)
+	}
+	if block.NumberU64() != 1 || block.Coinbase() != testBankAddress || len(block.Transactions()) != len(pendingTxs) {
+		t.Fatalf("template mismatch: number %d, coinbase %x, %d txs", block.NumberU64(), block.Coinbase(), len(block.Transactions()))
+	}
+	if fees.Sign() <= 0 {
+		t.Fatalf("template collects no fees: %v", fees)
+	}
+	sealhash := engine.SealHash(block.Header())
+
+	// A bogus solution must be rejected without consuming the template.
+	if _, err := miner.SubmitBlockTemplate(sealhash, types.BlockNonce{}, common.Hash{}); err == nil {
+		t.Fatal("bogus solution accepted")
+	}
+	// Mine the template with the ethash engine and submit the solution.
+	results := make(chan *types.Block, 1)
+	if err := engine.Seal(backend.chain, block, results, nil); err != nil {
+		t.Fatalf("failed to seal template: %v", err)
+	}
+	var sealed *types.Block
+	select {
+	case sealed = <-results:
+	case <-time.After(time.Minute):
+		t.Fatal("sealing timed out")
+	}
+	imported, err := miner.SubmitBlockTemplate(sealhash, types.EncodeNonce(sealed.Nonce()), sealed.MixDigest())
+	if err != nil {
+		t.Fatalf("failed to submit sealed template: %v", err)
+	}
+	if head := backend.chain.CurrentBlock(); head.Hash() != imported.Hash() || imported.Hash() != sealed.Hash() {
+		t.Fatalf("head mismatch: have %x, want %x", head.Hash(), sealed.Hash())
+	}
+	if _, err := miner.SubmitBlockTemplate(sealhash, types.EncodeNonce(sealed.Nonce()), sealed.MixDigest()); err == nil {
+		t.Fatal("template submitted twice")
+	}
+}
+
+// Tests that the number of tracked block templates is bounded, even if they are
+// all requested at the same height, and that stale templates are dropped.
+func TestBlockTemplateLimit(t *testing.T) {
+	miner := &Miner{
+		engine:    ethash.NewFaker(),
+		templates: lru.NewBasicLRU[common.Hash, *types.Block](maxBlockTemplates),
+	}
+	template := func(number, time uint64) *types.Block {
+		return types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(number), Time: time})
+	}
+	for i := uint64(0); i < 2*maxBlockTemplates; i++ {
+		miner.trackTemplate(template(1, i))
+	}
+	if n := miner.templates.Len(); n != maxBlockTemplates {
+		t.Fatalf("tracked template count mismatch: have %d, want %d", n, maxBlockTemplates)
+	}
+	last := template(1, 2*maxBlockTemplates-1)
+	if !miner.templates.Contains(miner.engine.SealHash(last.Header())) {
+		t.Fatal("latest template evicted")
+	}
+	// Templates too old to be sealed are dropped.
+	miner.trackTemplate(template(1+staleThreshold, 0))
+	if n := miner.templates.Len(); n != 1 {
+		t.Fatalf("stale templates retained: have %d templates, want 1", n)
+	}
+}
+
+// Tests that a miner isn't created if the required extra data prefix doesn't fit
+// the extra data size limit.
+func TestExtraDataPrefixTooLong(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	backend := newTestWorkerBackend(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	for i, tc := range []struct {
+		prefix  string
+		maxSize uint64
+		fail    bool
+	}{
+		{prefix: "pool", maxSize: 4},
+		{prefix: "pool", maxSize: 3, fail: true},
+		{prefix: strings.Repeat("p", int(params.MaximumExtraDataSize))},
+		{prefix: strings.Repeat("p", int(params.MaximumExtraDataSize)+1), fail: true},
+		{prefix: strings.Repeat("p", int(params.MaximumExtraDataSize)+1), maxSize: 64, fail: true},
+	} {
+		config := *testConfig
+		config.ExtraDataPrefix = []byte(tc.prefix)
+		config.MaxExtraDataSize = tc.maxSize
+
+		miner, err := New(backend, &config, ethashChainConfig, new(event.TypeMux), engine, nil)
+		if tc.fail {
+			if err == nil {
+				miner.Close()
+				t.Errorf("test %d: miner created with a %d byte prefix and a %d byte limit", i, len(tc.prefix), tc.maxSize)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("test %d: failed to create miner: %v", i, err)
+			continue
+		}
+		miner.Close()
+	}
+}
+
 // TestMinerSetEtherbase checks that etherbase becomes set even if mining isn't
 // possible at the moment
 func TestMinerSetEtherbase(t *testing.T) {
@@ -268,7 +395,10 @@ func createMiner(t *testing.T) (*Miner, *event.TypeMux, func(skipMiner bool)) {
 	// Create event Mux
 	mux := new(event.TypeMux)
 	// Create Miner
-	miner := New(backend, &config, chainConfig, mux, engine, nil)
+	miner, err := New(backend, &config, chainConfig, mux, engine, nil)
+	if err != nil {
+		t.Fatalf("can't create miner: %v", err)
+	}
 	cleanup := func(skipMiner bool) {
 		bc.Stop()
 		engine.Close()
@@ -62,13 +62,14 @@ func (args *BuildPayloadArgs) Id() engine.PayloadID {
 // the revenue. Therefore, the empty-block here is always available and full-block
 // will be set/updated afterwards.
 type Payload struct {
-	id       engine.PayloadID
-	empty    *types.Block
-	full     *types.Block
-	fullFees *big.Int
-	stop     chan struct{}
-	lock     sync.Mutex
-	cond     *sync.Cond
+	id        engine.PayloadID
+	empty     *types.Block
+	full      *types.Block
+	fullFees  *big.Int
+	interrupt int32 // Interruption signal of the full block building
+	stop      chan struct{}
+	lock      sync.Mutex
+	cond      *sync.Cond
 }
 
 // newPayload initializes the payload object.
@@ -84,7 +85,7 @@ func newPayload(empty *types.Block, id engine.PayloadID) *Payload {
 }
 
 // update updates the full-block with latest built version.
-func (payload *Payload) update(block *types.Block, fees *big.Int, elapsed time.Duration) {
+func (payload *Payload) update(block *types.Block, fees *big.Int, interrupt int32, elapsed time.Duration) {
 	payload.lock.Lock()
 	defer payload.lock.Unlock()
 
@@ -99,6 +100,7 @@ func (payload *Payload) update(block *types.Block, fees *big.Int, elapsed time.D
 	if payload.full == nil || fees.Cmp(payload.fullFees) > 0 {
 		payload.full = block
 		payload.fullFees = fees
+		payload.interrupt = interrupt
 
 		feesInEther := new(big.Float).Quo(new(big.Float).SetInt(fees), big.NewFloat(params.Ether))
 		log.Info("Updated payload", "id", payload.id, "number", block.NumberU64(), "hash", block.Hash(),
@@ -108,8 +110,24 @@ func (payload *Payload) update(block *types.Block, fees *big.Int, elapsed time.D
 	payload.cond.Broadcast() // fire signal for notifying full block
 }
 
+// Interrupted returns the reason the transaction filling of the latest full
+// block was cut short, or nil if it completed or no full block was built yet.
+// A block interrupted by the building timeout may include more transactions
+// if it is requested again with a higher allowance.
+func (payload *Payload) Interrupted() error {
+	payload.lock.Lock()
+	defer payload.lock.Unlock()
+
+	if payload.interrupt == commitInterruptNone {
+		return nil
+	}
+	return signalToErr(payload.interrupt)
+}
+
 // Resolve returns the latest built payload and also terminates the background
-// thread for updating payload. It's safe to be called multiple times.
+// thread for updating payload. It's safe to be called multiple times. If the
+// transaction filling of the full block was cut short, the envelope carries the
+// reason.
 func (payload *Payload) Resolve() *engine.ExecutionPayloadEnvelope {
 	payload.lock.Lock()
 	defer payload.lock.Unlock()
@@ -120,7 +138,11 @@ func (payload *Payload) Resolve() *engine.ExecutionPayloadEnvelope {
 		close(payload.stop)
 	}
 	if payload.full != nil {
-		return engine.BlockToExecutableData(payload.full, payload.fullFees)
+		envelope := engine.BlockToExecutableData(payload.full, payload.fullFees)
+		if payload.interrupt != commitInterruptNone {
+			envelope.InterruptReason = signalToErr(payload.interrupt).Error()
+		}
+		return envelope
 	}
 	return engine.BlockToExecutableData(payload.empty, big.NewInt(0))
 }
@@ -151,17 +173,43 @@ func (payload *Payload) ResolveFull() *engine.ExecutionPayloadEnvelope {
 	return engine.BlockToExecutableData(payload.full, payload.fullFees)
 }
 
+// WaitFull blocks until a full block has been built or the timeout elapses,
+// reporting whether one is available. Unlike ResolveFull, it neither waits
+// forever nor terminates the background updating.
+func (payload *Payload) WaitFull(timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	timer := time.AfterFunc(timeout, func() {
+		payload.lock.Lock()
+		defer payload.lock.Unlock()
+		payload.cond.Broadcast()
+	})
+	defer timer.Stop()
+
+	payload.lock.Lock()
+	defer payload.lock.Unlock()
+
+	for payload.full == nil && time.Now().Before(deadline) {
+		select {
+		case <-payload.stop:
+			return false
+		default:
+		}
+		payload.cond.Wait()
+	}
+	return payload.full != nil
+}
+
 // buildPayload builds the payload according to the provided parameters.
 func (w *worker) buildPayload(args *BuildPayloadArgs) (*Payload, error) {
 	// Build the initial version with no transaction included. It should be fast
 	// enough to run. The empty payload can at least make sure there is something
 	// to deliver for not missing slot.
-	empty, _, err := w.getSealingBlock(args.Parent, args.Timestamp, args.FeeRecipient, args.Random, args.Withdrawals, true)
-	if err != nil {
-		return nil, err
+	empty := w.getSealingBlock(args.Parent, args.Timestamp, args.FeeRecipient, args.Random, args.Withdrawals, true)
+	if empty.err != nil {
+		return nil, empty.err
 	}
 	// Construct a payload object for return.
-	payload := newPayload(empty, args.Id())
+	payload := newPayload(empty.block, args.Id())
 
 	// Spin up a routine for updating the payload in background. This strategy
 	// can maximum the revenue for including transactions with highest fee.
@@ -180,9 +228,9 @@ func (w *worker) buildPayload(args *BuildPayloadArgs) (*Payload, error) {
 			select {
 			case <-timer.C:
 				start := time.Now()
-				block, fees, err := w.getSealingBlock(args.Parent, args.Timestamp, args.FeeRecipient, args.Random, args.Withdrawals, false)
-				if err == nil {
-					payload.update(block, fees, time.Since(start))
+				r := w.getSealingBlock(args.Parent, args.Timestamp, args.FeeRecipient, args.Random, args.Withdrawals, false)
+				if r.err == nil {
+					payload.update(r.block, r.fees, r.interruptReason, time.Since(start))
 				}
 				timer.Reset(w.recommit)
 			case <-payload.stop:
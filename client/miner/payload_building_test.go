@@ -17,6 +17,7 @@
 package miner
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 	"time"
@@ -71,6 +72,9 @@ func TestBuildPayload(t *testing.T) {
 
 	full := payload.ResolveFull()
 	verify(full, len(pendingTxs))
+	if err := payload.Interrupted(); err != nil {
+		t.Fatalf("Unexpected payload interruption: %v", err)
+	}
 
 	// Ensure resolve can be called multiple times and the
 	// result should be unchanged
@@ -79,6 +83,76 @@ func TestBuildPayload(t *testing.T) {
 	if !reflect.DeepEqual(dataOne, dataTwo) {
 		t.Fatal("Unexpected payload data")
 	}
+	if dataOne.InterruptReason != "" {
+		t.Fatalf("Unexpected interrupt reason: %s", dataOne.InterruptReason)
+	}
+}
+
+func TestPayloadWaitFull(t *testing.T) {
+	w, b := newTestWorker(t, params.TestChainConfig, ethash.NewFaker(), rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+
+	args := &BuildPayloadArgs{
+		Parent:    b.chain.CurrentBlock().Hash(),
+		Timestamp: uint64(time.Now().Unix()),
+	}
+	// Hold back the full block building to ensure the wait is bounded
+	release := make(chan struct{})
+	w.payloadHook = func() { <-release }
+
+	payload, err := w.buildPayload(args)
+	if err != nil {
+		t.Fatalf("Failed to build payload %v", err)
+	}
+	if payload.WaitFull(10 * time.Millisecond) {
+		t.Fatal("Full block reported before being built")
+	}
+	close(release)
+	if !payload.WaitFull(time.Second) {
+		t.Fatal("Full block not reported after being built")
+	}
+	if txs := len(payload.Resolve().ExecutionPayload.Transactions); txs != len(pendingTxs) {
+		t.Fatalf("Transaction count mismatch: have %d, want %d", txs, len(pendingTxs))
+	}
+}
+
+func TestBuildPayloadTimeout(t *testing.T) {
+	w, b := newTestWorker(t, params.TestChainConfig, ethash.NewFaker(), rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+
+	// Queue up some transactions and let the allowance expire before they
+	// are executed
+	for i := 0; i < 16; i++ {
+		if err := b.txPool.AddLocal(b.newRandomTx(false)); err != nil {
+			t.Fatalf("Failed to add transaction %d: %v", i, err)
+		}
+	}
+	w.newpayloadTimeout = time.Millisecond
+	w.payloadHook = func() { time.Sleep(10 * time.Millisecond) }
+
+	parent, timestamp := b.chain.CurrentBlock().Hash(), uint64(time.Now().Unix())
+	r := w.getSealingBlock(parent, timestamp, common.Address{}, common.Hash{}, nil, false)
+	if r.err != nil {
+		t.Fatalf("Failed to build block: %v", r.err)
+	}
+	if len(r.block.Transactions()) != 0 {
+		t.Fatalf("Transactions included after timeout: %d", len(r.block.Transactions()))
+	}
+	if r.interruptReason != commitInterruptTimeout {
+		t.Fatalf("Interrupt reason mismatch: have %d, want %d", r.interruptReason, commitInterruptTimeout)
+	}
+	// The reason must be surfaced through the payload as well
+	payload, err := w.buildPayload(&BuildPayloadArgs{Parent: parent, Timestamp: timestamp})
+	if err != nil {
+		t.Fatalf("Failed to build payload %v", err)
+	}
+	payload.ResolveFull()
+	if err := payload.Interrupted(); !errors.Is(err, errBlockInterruptedByTimeout) {
+		t.Fatalf("Payload interruption mismatch: have %v, want %v", err, errBlockInterruptedByTimeout)
+	}
+	if reason := payload.Resolve().InterruptReason; reason != errBlockInterruptedByTimeout.Error() {
+		t.Fatalf("Delivered interrupt reason mismatch: have %q, want %q", reason, errBlockInterruptedByTimeout)
+	}
 }
 
 func TestPayloadId(t *testing.T) {
@@ -34,6 +34,7 @@ import (
 	"github.com/r5-labs/r5-core/client/eth/ethconfig"
 	"github.com/r5-labs/r5-core/client/log"
 	"github.com/r5-labs/r5-core/client/miner"
+	"github.com/r5-labs/r5-core/client/miner/stress/internal/dagprogress"
 	"github.com/r5-labs/r5-core/client/node"
 	"github.com/r5-labs/r5-core/client/p2p"
 	"github.com/r5-labs/r5-core/client/p2p/enode"
@@ -54,7 +55,7 @@ func main() {
 		faucets[i], _ = crypto.GenerateKey()
 	}
 	// Pre-generate the ethash mining DAG so we don't race
-	ethash.MakeDataset(1, ethconfig.Defaults.Ethash.DatasetDir)
+	ethash.MakeDatasetWithProgress(1, ethconfig.Defaults.Ethash.DatasetDir, dagprogress.Print)
 
 	// Create an Ethash network
 	genesis := makeGenesis(faucets)
@@ -16,6 +16,7 @@ package main
 import (
 	"crypto/ecdsa"
 	"errors"
+	"fmt"
 	"math/big"
 	"math/rand"
 	"os"
@@ -39,6 +40,7 @@ import (
 	lescatalyst "github.com/r5-labs/r5-core/client/les/catalyst"
 	"github.com/r5-labs/r5-core/client/log"
 	"github.com/r5-labs/r5-core/client/miner"
+	"github.com/r5-labs/r5-core/client/miner/stress/internal/dagprogress"
 	"github.com/r5-labs/r5-core/client/node"
 	"github.com/r5-labs/r5-core/client/p2p"
 	"github.com/r5-labs/r5-core/client/p2p/enode"
@@ -82,6 +84,10 @@ var (
 
 	// finalizationDist is the block distance for finalizing block
 	finalizationDist = 10
+
+	// recommitInterval is the time interval for the mining nodes to refill the
+	// blocks and payloads being assembled
+	recommitInterval = time.Second
 )
 
 type ethNode struct {
@@ -150,22 +156,34 @@ func (n *ethNode) assembleBlock(parentHash common.Hash, parentTimestamp uint64)
 	if timestamp <= parentTimestamp {
 		timestamp = parentTimestamp + 1
 	}
-	payloadAttribute := engine.PayloadAttributes{
-		Timestamp:             timestamp,
-		Random:                common.Hash{},
-		SuggestedFeeRecipient: common.HexToAddress("0xdeadbeef"),
-	}
-	fcState := engine.ForkchoiceStateV1{
-		HeadBlockHash:      parentHash,
-		SafeBlockHash:      common.Hash{},
-		FinalizedBlockHash: common.Hash{},
-	}
-	payload, err := n.api.ForkchoiceUpdatedV1(fcState, &payloadAttribute)
+	payload, err := n.ethBackend.Miner().BuildPayload(&miner.BuildPayloadArgs{
+		Parent:       parentHash,
+		Timestamp:    timestamp,
+		FeeRecipient: common.HexToAddress("0xdeadbeef"),
+		Random:       common.Hash{},
+	})
 	if err != nil {
 		return nil, err
 	}
-	time.Sleep(time.Second * 5) // give enough time for block creation
-	return n.api.GetPayloadV1(*payload.PayloadID)
+	return waitPayload(payload, blockInterval)
+}
+
+// payloadWaiter is the subset of a payload under construction needed to wait
+// for its full version and retrieve it.
+type payloadWaiter interface {
+	WaitFull(timeout time.Duration) bool
+	Resolve() *engine.ExecutionPayloadEnvelope
+}
+
+// waitPayload retrieves the payload once the node filled it with the pending
+// transactions. A payload starts out as an empty block and retrieving it stops
+// any further building, so it fails if no full block is ready within timeout.
+func waitPayload(payload payloadWaiter, timeout time.Duration) (*engine.ExecutableData, error) {
+	if !payload.WaitFull(timeout) {
+		payload.Resolve()
+		return nil, fmt.Errorf("payload not filled within %v", timeout)
+	}
+	return payload.Resolve().ExecutionPayload, nil
 }
 
 func (n *ethNode) insertBlock(eb engine.ExecutableData) error {
@@ -390,7 +408,7 @@ func main() {
 		faucets[i], _ = crypto.GenerateKey()
 	}
 	// Pre-generate the ethash mining DAG so we don't race
-	ethash.MakeDataset(1, filepath.Join(os.Getenv("HOME"), ".ethash"))
+	ethash.MakeDatasetWithProgress(1, filepath.Join(os.Getenv("HOME"), ".ethash"), dagprogress.Print)
 
 	// Create an Ethash network
 	genesis := makeGenesis(faucets)
@@ -490,7 +508,7 @@ func makeFullNode(genesis *core.Genesis) (*node.Node, *eth.Ethereum, *ethcatalys
 			GasFloor: genesis.GasLimit * 9 / 10,
 			GasCeil:  genesis.GasLimit * 11 / 10,
 			GasPrice: big.NewInt(1),
-			Recommit: 1 * time.Second,
+			Recommit: recommitInterval,
 		},
 		LightServ:        100,
 		LightPeers:       10,
@@ -0,0 +1,83 @@
+// Copyright 2025 R5 Labs
+// This file is part of the R5 Core library.
+//
+// This software is provided "as is", without warranty of any kind,
+// express or implied, including but not limited to the warranties
+// of merchantability, fitness for a particular purpose and
+// noninfringement. In no event shall the authors or copyright
+// holders be liable for any claim, damages, or other liability,
+// whether in an action of contract, tort or otherwise, arising
+// from, out of or in connection with the software or the use or
+// other dealings in the software.
+
+package main
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/r5-labs/r5-core/client/beacon/engine"
+	"github.com/r5-labs/r5-core/client/core"
+	"github.com/r5-labs/r5-core/client/core/types"
+	"github.com/r5-labs/r5-core/client/crypto"
+	"github.com/r5-labs/r5-core/client/params"
+)
+
+func TestWaitPayload(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+
+	// Start a mining node on a chain that is merged from genesis on
+	config := *params.AllEthashProtocolChanges
+	config.TerminalTotalDifficulty = new(big.Int).Set(params.MinimumDifficulty)
+	config.TerminalTotalDifficultyPassed = true
+
+	genesis := &core.Genesis{
+		Config:     &config,
+		Difficulty: params.MinimumDifficulty,
+		GasLimit:   25000000,
+		BaseFee:    big.NewInt(params.InitialBaseFee),
+		Alloc:      core.GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}},
+	}
+	stack, backend, api, err := makeFullNode(genesis)
+	if err != nil {
+		t.Fatalf("failed to start node: %v", err)
+	}
+	defer stack.Close()
+
+	tx, err := types.SignTx(types.NewTransaction(0, addr, new(big.Int), 21000, big.NewInt(10_000_000_000), nil), types.HomesteadSigner{}, key)
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+	if err := backend.TxPool().AddLocal(tx); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	// The assembled payload must include the pending transaction instead of
+	// being the empty block the payload building starts out with
+	n := &ethNode{typ: eth2MiningNode, stack: stack, api: api, ethBackend: backend}
+	head := backend.BlockChain().CurrentBlock()
+	data, err := n.assembleBlock(head.Hash(), head.Time)
+	if err != nil {
+		t.Fatalf("failed to assemble block: %v", err)
+	}
+	if len(data.Transactions) != 1 {
+		t.Fatalf("payload transaction count mismatch: have %d, want 1", len(data.Transactions))
+	}
+	// Payloads never filled are given up on once the timeout elapses
+	if _, err := waitPayload(stalledPayload{}, time.Millisecond); err == nil {
+		t.Fatal("expected error for payload never filled")
+	}
+}
+
+// stalledPayload is a payload under construction that never gets a full block.
+type stalledPayload struct{}
+
+func (stalledPayload) WaitFull(timeout time.Duration) bool {
+	time.Sleep(timeout)
+	return false
+}
+
+func (stalledPayload) Resolve() *engine.ExecutionPayloadEnvelope {
+	return nil
+}
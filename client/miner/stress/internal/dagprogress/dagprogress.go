@@ -0,0 +1,28 @@
+// Copyright 2025 R5 Labs
+// This file is part of the R5 Core library.
+//
+// This software is provided "as is", without warranty of any kind,
+// express or implied, including but not limited to the warranties
+// of merchantability, fitness for a particular purpose and
+// noninfringement. In no event shall the authors or copyright
+// holders be liable for any claim, damages, or other liability,
+// whether in an action of contract, tort or otherwise, arising
+// from, out of or in connection with the software or the use or
+// other dealings in the software.
+
+// Package dagprogress reports the ethash DAG generation of the stress tests.
+package dagprogress
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Print draws a progress bar of the ethash DAG generation.
+func Print(percent uint64) {
+	fmt.Fprintf(os.Stderr, "\rGenerating ethash DAG [%-50s] %3d%%", strings.Repeat("=", int(percent/2)), percent)
+	if percent == 100 {
+		fmt.Fprintln(os.Stderr)
+	}
+}
@@ -13,16 +13,20 @@
 package miner
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/r5-labs/r5-core/client/common"
+	"github.com/r5-labs/r5-core/client/common/mclock"
 	"github.com/r5-labs/r5-core/client/consensus"
+	"github.com/r5-labs/r5-core/client/consensus/beacon"
 	"github.com/r5-labs/r5-core/client/consensus/misc"
 	"github.com/r5-labs/r5-core/client/core"
 	"github.com/r5-labs/r5-core/client/core/state"
@@ -71,6 +75,10 @@ const (
 
 	// staleThreshold is the maximum depth of the acceptable stale block.
 	staleThreshold = 7
+
+	// slowTxLogLimit is the number of slowest transactions to report when the
+	// payload building is interrupted by timeout.
+	slowTxLogLimit = 5
 )
 
 var (
@@ -90,13 +98,22 @@ type environment struct {
 	tcount    int                     // tx count in cycle
 	gasPool   *core.GasPool           // available gas used to pack transactions
 	coinbase  common.Address
+	prefetch  bool // whether a state prefetcher was started for the block
 
 	header   *types.Header
 	txs      []*types.Transaction
 	receipts []*types.Receipt
+	timings  []txTiming // Execution times of the included transactions
 	uncles   map[common.Hash]*types.Header
 }
 
+// txTiming records the wall-clock time spent executing a transaction.
+type txTiming struct {
+	hash    common.Hash
+	gasUsed uint64
+	elapsed time.Duration
+}
+
 // copy creates a deep copy of environment.
 func (env *environment) copy() *environment {
 	cpy := &environment{
@@ -106,6 +123,7 @@ func (env *environment) copy() *environment {
 		family:    env.family.Clone(),
 		tcount:    env.tcount,
 		coinbase:  env.coinbase,
+		prefetch:  env.prefetch,
 		header:    types.CopyHeader(env.header),
 		receipts:  copyReceipts(env.receipts),
 	}
@@ -117,6 +135,8 @@ func (env *environment) copy() *environment {
 	// to do the expensive deep copy for them.
 	cpy.txs = make([]*types.Transaction, len(env.txs))
 	copy(cpy.txs, env.txs)
+	cpy.timings = make([]txTiming, len(env.timings))
+	copy(cpy.timings, env.timings)
 	cpy.uncles = make(map[common.Hash]*types.Header)
 	for hash, uncle := range env.uncles {
 		cpy.uncles[hash] = uncle
@@ -137,10 +157,11 @@ func (env *environment) unclelist() []*types.Header {
 // always be called for all created environment instances otherwise
 // the go-routine leak can happen.
 func (env *environment) discard() {
-	if env.state == nil {
+	if env.state == nil || !env.prefetch {
 		return
 	}
 	env.state.StopPrefetcher()
+	env.prefetch = false
 }
 
 // task contains all information for consensus engine sealing and result submitting.
@@ -170,6 +191,10 @@ type newPayloadResult struct {
 	err   error
 	block *types.Block
 	fees  *big.Int
+
+	// interruptReason is the signal which cut the transaction filling short,
+	// or commitInterruptNone if the block was filled completely.
+	interruptReason int32
 }
 
 // getWorkReq represents a request for getting a new sealing work with provided parameters.
@@ -211,24 +236,33 @@ type worker struct {
 	taskCh             chan *task
 	resultCh           chan *types.Block
 	startCh            chan struct{}
+	resumeCh           chan struct{}
 	exitCh             chan struct{}
 	resubmitIntervalCh chan time.Duration
 	resubmitAdjustCh   chan *intervalAdjust
 
 	wg sync.WaitGroup
 
-	current      *environment                 // An environment for current running cycle.
+	current     *environment       // An environment for current running cycle.
+	unconfirmed *unconfirmedBlocks // A set of locally mined blocks pending canonicalness confirmations.
+
+	uncleMu      sync.RWMutex                 // The lock used to protect the uncle sets below
 	localUncles  map[common.Hash]*types.Block // A set of side blocks generated locally as the possible uncle blocks.
 	remoteUncles map[common.Hash]*types.Block // A set of side blocks as the possible uncle blocks.
-	unconfirmed  *unconfirmedBlocks           // A set of locally mined blocks pending canonicalness confirmations.
 
-	mu       sync.RWMutex // The lock used to protect the coinbase and extra fields
-	coinbase common.Address
-	extra    []byte
+	mu            sync.RWMutex // The lock used to protect the coinbase and extra fields
+	coinbase      common.Address
+	coinbases     []common.Address // Coinbases rotated through round-robin, overriding coinbase if set
+	rotationStart uint64           // Number of the first block sealed with the rotated coinbases
+	extra         []byte
 
 	pendingMu    sync.RWMutex
 	pendingTasks map[common.Hash]*task
 
+	breakerMu        sync.Mutex // The lock used to protect the circuit breaker fields below
+	finalizeFailures int        // Number of consecutive block assembly failures
+	breakerErr       error      // Last assembly error once the breaker tripped
+
 	snapshotMu       sync.RWMutex // The lock used to protect the snapshots below
 	snapshotBlock    *types.Block
 	snapshotReceipts types.Receipts
@@ -236,6 +270,7 @@ type worker struct {
 
 	// atomic status counters
 	running atomic.Bool  // The indicator whether the consensus engine is running or not.
+	paused  atomic.Bool  // The indicator whether sealing is paused, freezing the current environment.
 	newTxs  atomic.Int32 // New arrival transaction count since last sealing work submitting.
 
 	// noempty is the flag used to control whether the feature of pre-seal empty
@@ -256,6 +291,18 @@ type worker struct {
 	// payload in proof-of-stake stage.
 	recommit time.Duration
 
+	// uncleClean is the time interval to drop stale blocks from the uncle sets.
+	uncleClean time.Duration
+	clock      mclock.Clock
+
+	// uncleLookback is the number of recent blocks scanned for uncle ancestry
+	// and already included uncles.
+	uncleLookback int
+
+	// drainTimeout is the time allowance for writing the sealed blocks queued
+	// in resultCh when the worker is closed.
+	drainTimeout time.Duration
+
 	// External functions
 	isLocalBlock func(header *types.Header) bool // Function used to determine whether the specified block is mined by local miner.
 
@@ -263,6 +310,7 @@ type worker struct {
 	newTaskHook  func(*task)                        // Method to call upon receiving a new sealing task.
 	skipSealHook func(*task) bool                   // Method to decide whether skipping the sealing.
 	fullTaskHook func()                             // Method to call before pushing the full sealing task.
+	payloadHook  func()                             // Method to call before filling a payload with transactions.
 	resubmitHook func(time.Duration, time.Duration) // Method to call upon updating resubmitting interval.
 }
 
@@ -289,6 +337,7 @@ func newWorker(config *Config, chainConfig *params.ChainConfig, engine consensus
 		taskCh:             make(chan *task),
 		resultCh:           make(chan *types.Block, resultQueueSize),
 		startCh:            make(chan struct{}, 1),
+		resumeCh:           make(chan struct{}),
 		exitCh:             make(chan struct{}),
 		resubmitIntervalCh: make(chan time.Duration),
 		resubmitAdjustCh:   make(chan *intervalAdjust, resubmitAdjustChanSize),
@@ -318,6 +367,24 @@ func newWorker(config *Config, chainConfig *params.ChainConfig, engine consensus
 	}
 	worker.newpayloadTimeout = newpayloadTimeout
 
+	// Fall back to the default uncle cleanup interval and clock if unspecified.
+	worker.uncleClean = worker.config.UncleCleanInterval
+	if worker.uncleClean <= 0 {
+		worker.uncleClean = DefaultConfig.UncleCleanInterval
+	}
+	worker.uncleLookback = worker.config.UncleLookback
+	if worker.uncleLookback <= 0 {
+		worker.uncleLookback = DefaultConfig.UncleLookback
+	}
+	worker.clock = worker.config.clock
+	if worker.clock == nil {
+		worker.clock = mclock.System{}
+	}
+	worker.drainTimeout = worker.config.ShutdownDrainTimeout
+	if worker.drainTimeout <= 0 {
+		worker.drainTimeout = DefaultConfig.ShutdownDrainTimeout
+	}
+
 	worker.wg.Add(4)
 	go worker.mainLoop()
 	go worker.newWorkLoop(recommit)
@@ -345,17 +412,75 @@ func (w *worker) etherbase() common.Address {
 	return w.coinbase
 }
 
+// setEtherbases sets the addresses the block coinbase rotates through, one
+// per block height, starting with the next block on top of the current head.
+// An empty list falls back to the single etherbase.
+func (w *worker) setEtherbases(addrs []common.Address) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.coinbases = append([]common.Address(nil), addrs...)
+	w.rotationStart = w.chain.CurrentBlock().Number.Uint64() + 1
+}
+
+// etherbaseAt retrieves the coinbase of the sealing block at the given height,
+// which is the next one in the rotation if set or the etherbase otherwise.
+// Heights before the start of the rotation, e.g. after a reorg to a shorter
+// chain, use the first address of the rotation.
+func (w *worker) etherbaseAt(number uint64) common.Address {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if len(w.coinbases) == 0 {
+		return w.coinbase
+	}
+	if number < w.rotationStart {
+		return w.coinbases[0]
+	}
+	return w.coinbases[(number-w.rotationStart)%uint64(len(w.coinbases))]
+}
+
 func (w *worker) setGasCeil(ceil uint64) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 	w.config.GasCeil = ceil
 }
 
-// setExtra sets the content used to initialize the block extra field.
-func (w *worker) setExtra(extra []byte) {
+// gasLimit computes the gas limit of the block following one with the given
+// limit, moving it toward the configured target by at most the protocol step.
+// The target is kept within the floor and the ceiling, the latter winning if
+// the two conflict.
+func (w *worker) gasLimit(parentGasLimit uint64) uint64 {
+	target := w.config.GasTarget
+	if target == 0 || target > w.config.GasCeil {
+		target = w.config.GasCeil
+	}
+	if target < w.config.GasFloor && w.config.GasFloor <= w.config.GasCeil {
+		target = w.config.GasFloor
+	}
+	return core.CalcGasLimit(parentGasLimit, target)
+}
+
+// setExtra sets the content used to initialize the block extra field, if it
+// satisfies the configured prefix and size limit.
+func (w *worker) setExtra(extra []byte) error {
+	if err := w.checkExtra(extra); err != nil {
+		return err
+	}
 	w.mu.Lock()
 	defer w.mu.Unlock()
 	w.extra = extra
+	return nil
+}
+
+// checkExtra verifies that the given block extra data starts with the configured
+// prefix and fits both the configured and the consensus size limits.
+func (w *worker) checkExtra(extra []byte) error {
+	if limit := w.config.extraDataLimit(); uint64(len(extra)) > limit {
+		return fmt.Errorf("extra exceeds max length. %d > %v", len(extra), limit)
+	}
+	if !bytes.HasPrefix(extra, w.config.ExtraDataPrefix) {
+		return fmt.Errorf("extra %#x lacks required prefix %#x", extra, []byte(w.config.ExtraDataPrefix))
+	}
+	return nil
 }
 
 // setRecommitInterval updates the interval for miner sealing work recommitting.
@@ -403,17 +528,83 @@ func (w *worker) pendingBlockAndReceipts() (*types.Block, types.Receipts) {
 	return w.snapshotBlock, w.snapshotReceipts
 }
 
-// start sets the running status as 1 and triggers new work submitting.
+// start sets the running status as 1 and triggers new work submitting. Any
+// tripped circuit breaker is reset, giving block assembly a fresh chance.
 func (w *worker) start() {
+	w.breakerMu.Lock()
+	w.finalizeFailures, w.breakerErr = 0, nil
+	w.breakerMu.Unlock()
+
+	w.paused.Store(false)
 	w.running.Store(true)
 	w.startCh <- struct{}{}
 }
 
 // stop sets the running status as 0.
 func (w *worker) stop() {
+	w.paused.Store(false)
 	w.running.Store(false)
 }
 
+// status returns the error which tripped the circuit breaker, if any.
+func (w *worker) status() error {
+	w.breakerMu.Lock()
+	defer w.breakerMu.Unlock()
+
+	return w.breakerErr
+}
+
+// recordFinalize tracks the outcome of assembling a sealing block. Once the
+// configured number of consecutive assembly failures is reached, mining is
+// paused and the error kept for reporting, rather than silently retrying on
+// every new work cycle.
+func (w *worker) recordFinalize(err error) {
+	w.breakerMu.Lock()
+	defer w.breakerMu.Unlock()
+
+	if err == nil {
+		w.finalizeFailures = 0
+		return
+	}
+	w.finalizeFailures++
+	limit := w.config.MaxFinalizeFailures
+	if limit <= 0 || w.finalizeFailures < limit || w.breakerErr != nil {
+		log.Warn("Failed to assemble sealing block", "failures", w.finalizeFailures, "err", err)
+		return
+	}
+	w.breakerErr = fmt.Errorf("block assembly failed %d times in a row: %w", w.finalizeFailures, err)
+	w.running.Store(false)
+	log.Error("Pausing mining after repeated block assembly failures", "failures", w.finalizeFailures, "err", err)
+}
+
+// pause stops sealing like stop, but freezes the current environment instead
+// of rebuilding it on new heads and transactions, so that resume can continue
+// from the already assembled work.
+func (w *worker) pause() {
+	if w.running.CompareAndSwap(true, false) {
+		w.paused.Store(true)
+	}
+}
+
+// resume restarts sealing after a pause. The frozen environment is filled up
+// further and resubmitted if the chain head is still its parent, otherwise a
+// fresh sealing block is created.
+func (w *worker) resume() {
+	if !w.paused.CompareAndSwap(true, false) {
+		return
+	}
+	w.running.Store(true)
+	select {
+	case w.resumeCh <- struct{}{}:
+	case <-w.exitCh:
+	}
+}
+
+// isPaused returns an indicator whether sealing is paused or not.
+func (w *worker) isPaused() bool {
+	return w.paused.Load()
+}
+
 // isRunning returns an indicator whether worker is running or not.
 func (w *worker) isRunning() bool {
 	return w.running.Load()
@@ -561,27 +752,31 @@ func (w *worker) mainLoop() {
 		}
 	}()
 
-	cleanTicker := time.NewTicker(time.Second * 10)
-	defer cleanTicker.Stop()
+	cleanTimer := w.clock.NewTimer(w.uncleClean)
+	defer cleanTimer.Stop()
 
 	for {
 		select {
 		case req := <-w.newWorkCh:
+			// Leave the frozen environment untouched while paused
+			if w.isPaused() {
+				continue
+			}
 			w.commitWork(req.interrupt, req.noempty, req.timestamp)
 
+		case <-w.resumeCh:
+			w.resumeWork()
+
 		case req := <-w.getWorkCh:
-			block, fees, err := w.generateWork(req.params)
-			req.result <- &newPayloadResult{
-				err:   err,
-				block: block,
-				fees:  fees,
-			}
+			req.result <- w.generateWork(req.params)
+
 		case ev := <-w.chainSideCh:
+			w.uncleMu.Lock()
 			// Short circuit for duplicate side blocks
-			if _, exist := w.localUncles[ev.Block.Hash()]; exist {
-				continue
-			}
-			if _, exist := w.remoteUncles[ev.Block.Hash()]; exist {
+			_, local := w.localUncles[ev.Block.Hash()]
+			_, remote := w.remoteUncles[ev.Block.Hash()]
+			if local || remote {
+				w.uncleMu.Unlock()
 				continue
 			}
 			// Add side block to possible uncle block set depending on the author.
@@ -590,18 +785,21 @@ func (w *worker) mainLoop() {
 			} else {
 				w.remoteUncles[ev.Block.Hash()] = ev.Block
 			}
-			// If our sealing block contains less than 2 uncle blocks,
-			// add the new uncle block if valid and regenerate a new
-			// sealing block for higher profit.
-			if w.isRunning() && w.current != nil && len(w.current.uncles) < 2 {
+			w.uncleMu.Unlock()
+
+			// If our sealing block contains less than the allowed number of
+			// uncle blocks, add the new uncle block if valid and regenerate
+			// a new sealing block for higher profit.
+			if w.isRunning() && w.current != nil && len(w.current.uncles) < w.maxUncles() {
 				start := time.Now()
 				if err := w.commitUncle(w.current, ev.Block.Header()); err == nil {
 					w.commit(w.current.copy(), nil, true, start)
 				}
 			}
 
-		case <-cleanTicker.C:
+		case <-cleanTimer.C():
 			chainHead := w.chain.CurrentBlock()
+			w.uncleMu.Lock()
 			for hash, uncle := range w.localUncles {
 				if uncle.NumberU64()+staleThreshold <= chainHead.Number.Uint64() {
 					delete(w.localUncles, hash)
@@ -612,6 +810,8 @@ func (w *worker) mainLoop() {
 					delete(w.remoteUncles, hash)
 				}
 			}
+			w.uncleMu.Unlock()
+			cleanTimer.Reset(w.uncleClean)
 
 		case ev := <-w.txsCh:
 			// Apply transactions to the pending state if we're not sealing
@@ -619,6 +819,10 @@ func (w *worker) mainLoop() {
 			// Note all transactions received may not be continuous with transactions
 			// already included in the current sealing block. These transactions will
 			// be automatically eliminated.
+			if w.isPaused() {
+				w.newTxs.Add(int32(len(ev.Txs)))
+				continue
+			}
 			if !w.isRunning() && w.current != nil {
 				// If block is already full, abort
 				if gp := w.current.gasPool; gp != nil && gp.Gas() < params.TxGas {
@@ -629,7 +833,7 @@ func (w *worker) mainLoop() {
 					acc, _ := types.Sender(w.current.signer, tx)
 					txs[acc] = append(txs[acc], tx)
 				}
-				txset := types.NewTransactionsByPriceAndNonce(w.current.signer, txs, w.current.header.BaseFee)
+				txset := w.newTransactionSet(w.current.signer, txs, w.current.header.BaseFee)
 				tcount := w.current.tcount
 				w.commitTransactions(w.current, txset, nil)
 
@@ -719,103 +923,221 @@ func (w *worker) resultLoop() {
 	for {
 		select {
 		case block := <-w.resultCh:
-			// Short circuit when receiving empty result.
-			if block == nil {
-				continue
-			}
-			// Short circuit when receiving duplicate result caused by resubmitting.
-			if w.chain.HasBlock(block.Hash(), block.NumberU64()) {
-				continue
-			}
-			var (
-				sealhash = w.engine.SealHash(block.Header())
-				hash     = block.Hash()
-			)
-			w.pendingMu.RLock()
-			task, exist := w.pendingTasks[sealhash]
-			w.pendingMu.RUnlock()
-			if !exist {
-				log.Error("Block found but no relative pending task", "number", block.Number(), "sealhash", sealhash, "hash", hash)
-				continue
-			}
-			// Different block could share same sealhash, deep copy here to prevent write-write conflict.
-			var (
-				receipts = make([]*types.Receipt, len(task.receipts))
-				logs     []*types.Log
-			)
-			for i, taskReceipt := range task.receipts {
-				receipt := new(types.Receipt)
-				receipts[i] = receipt
-				*receipt = *taskReceipt
-
-				// add block location fields
-				receipt.BlockHash = hash
-				receipt.BlockNumber = block.Number()
-				receipt.TransactionIndex = uint(i)
-
-				// Update the block hash in all logs since it is now available and not when the
-				// receipt/log of individual transactions were created.
-				receipt.Logs = make([]*types.Log, len(taskReceipt.Logs))
-				for i, taskLog := range taskReceipt.Logs {
-					log := new(types.Log)
-					receipt.Logs[i] = log
-					*log = *taskLog
-					log.BlockHash = hash
-				}
-				logs = append(logs, receipt.Logs...)
+			if !w.waitBlockInterval(block) {
+				// Closed while holding the block back, write it rather than
+				// losing it along with the ones still queued.
+				w.writeResult(block)
+				w.drainResults()
+				return
 			}
-			// Commit block and state to database.
-			_, err := w.chain.WriteBlockAndSetHead(block, receipts, logs, task.state, true)
-			if err != nil {
-				log.Error("Failed writing block to chain", "err", err)
-				continue
-			}
-			log.Info("Successfully sealed new block", "number", block.Number(), "sealhash", sealhash, "hash", hash,
-				"elapsed", common.PrettyDuration(time.Since(task.createdAt)))
+			w.writeResult(block)
+
+		case <-w.exitCh:
+			w.drainResults()
+			return
+		}
+	}
+}
+
+// waitBlockInterval holds a sealed block back until the configured minimum
+// block interval has elapsed since the timestamp of its parent. It returns false
+// if the worker is closed while waiting.
+func (w *worker) waitBlockInterval(block *types.Block) bool {
+	interval := w.config.MinBlockInterval
+	if interval <= 0 || block == nil {
+		return true
+	}
+	parent := w.chain.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return true
+	}
+	wait := time.Until(time.Unix(int64(parent.Time), 0).Add(interval))
+	if wait <= 0 {
+		return true
+	}
+	log.Debug("Delaying sealed block for minimum interval", "number", block.Number(), "wait", common.PrettyDuration(wait))
 
-			// Broadcast the block and announce chain insertion event
-			w.mux.Post(core.NewMinedBlockEvent{Block: block})
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
 
-			// Insert the block into the set of pending ones to resultLoop for confirmations
-			w.unconfirmed.Insert(block.NumberU64(), block.Hash())
+	select {
+	case <-timer.C:
+		return true
+	case <-w.exitCh:
+		return false
+	}
+}
 
-		case <-w.exitCh:
+// drainResults writes the sealed blocks already queued in resultCh when the
+// worker is closed, so that a found block isn't lost on shutdown. The blocks
+// still queued once the drain timeout elapses are abandoned.
+func (w *worker) drainResults() {
+	deadline := time.NewTimer(w.drainTimeout)
+	defer deadline.Stop()
+
+	for {
+		select {
+		case <-deadline.C:
+			if queued := len(w.resultCh); queued > 0 {
+				log.Warn("Abandoning sealed blocks on shutdown", "queued", queued, "timeout", w.drainTimeout)
+			}
 			return
+		default:
+		}
+		select {
+		case block := <-w.resultCh:
+			w.writeResult(block)
+		default:
+			return
+		}
+	}
+}
+
+// writeResult commits a sealed block and its state to the database, then
+// broadcasts it.
+func (w *worker) writeResult(block *types.Block) {
+	// Short circuit when receiving empty result.
+	if block == nil {
+		return
+	}
+	// Short circuit when receiving duplicate result caused by resubmitting.
+	if w.chain.HasBlock(block.Hash(), block.NumberU64()) {
+		return
+	}
+	var (
+		sealhash = w.engine.SealHash(block.Header())
+		hash     = block.Hash()
+	)
+	w.pendingMu.RLock()
+	task, exist := w.pendingTasks[sealhash]
+	w.pendingMu.RUnlock()
+	if !exist {
+		log.Error("Block found but no relative pending task", "number", block.Number(), "sealhash", sealhash, "hash", hash)
+		return
+	}
+	// Different block could share same sealhash, deep copy here to prevent write-write conflict.
+	var (
+		receipts = make([]*types.Receipt, len(task.receipts))
+		logs     []*types.Log
+	)
+	for i, taskReceipt := range task.receipts {
+		receipt := new(types.Receipt)
+		receipts[i] = receipt
+		*receipt = *taskReceipt
+
+		// add block location fields
+		receipt.BlockHash = hash
+		receipt.BlockNumber = block.Number()
+		receipt.TransactionIndex = uint(i)
+
+		// Update the block hash in all logs since it is now available and not when the
+		// receipt/log of individual transactions were created.
+		receipt.Logs = make([]*types.Log, len(taskReceipt.Logs))
+		for i, taskLog := range taskReceipt.Logs {
+			log := new(types.Log)
+			receipt.Logs[i] = log
+			*log = *taskLog
+			log.BlockHash = hash
 		}
+		logs = append(logs, receipt.Logs...)
+	}
+	// Commit block and state to database.
+	_, err := w.chain.WriteBlockAndSetHead(block, receipts, logs, task.state, true)
+	if err != nil {
+		log.Error("Failed writing block to chain", "err", err)
+		return
 	}
+	log.Info("Successfully sealed new block", "number", block.Number(), "sealhash", sealhash, "hash", hash,
+		"elapsed", common.PrettyDuration(time.Since(task.createdAt)))
+
+	// Broadcast the block and announce chain insertion event
+	w.mux.Post(core.NewMinedBlockEvent{Block: block})
+
+	// Insert the block into the set of pending ones to resultLoop for confirmations
+	w.unconfirmed.Insert(block.NumberU64(), block.Hash())
 }
 
-// makeEnv creates a new environment for the sealing block.
-func (w *worker) makeEnv(parent *types.Header, header *types.Header, coinbase common.Address) (*environment, error) {
+// makeEnv creates a new environment for the sealing block. The state prefetcher
+// is only started if transactions are going to be executed on top.
+func (w *worker) makeEnv(parent *types.Header, header *types.Header, coinbase common.Address, prefetch bool) (*environment, error) {
 	// Retrieve the parent state to execute on top and start a prefetcher for
 	// the miner to speed block sealing up a bit.
 	state, err := w.chain.StateAt(parent.Root)
 	if err != nil {
 		return nil, err
 	}
-	state.StartPrefetcher("miner")
+	if prefetch {
+		state.StartPrefetcher("miner", w.config.PrefetchWorkers)
+	}
 
 	// Note the passed coinbase may be different with header.Coinbase.
+	ancestors, family := w.uncleFamily(parent.Hash())
 	env := &environment{
 		signer:    types.MakeSigner(w.chainConfig, header.Number),
 		state:     state,
 		coinbase:  coinbase,
-		ancestors: mapset.NewSet[common.Hash](),
-		family:    mapset.NewSet[common.Hash](),
+		prefetch:  prefetch,
+		ancestors: ancestors,
+		family:    family,
 		header:    header,
 		uncles:    make(map[common.Hash]*types.Header),
 	}
+	// Keep track of transactions which return errors so they can be removed
+	env.tcount = 0
+	return env, nil
+}
+
+// uncleFamily collects the hashes of the given block and its ancestors within
+// the uncle lookback, along with the family set of these blocks and all uncles
+// they already include.
+func (w *worker) uncleFamily(hash common.Hash) (ancestors, family mapset.Set[common.Hash]) {
+	ancestors, family = mapset.NewSet[common.Hash](), mapset.NewSet[common.Hash]()
+
 	// when 08 is processed ancestors contain 07 (quick block)
-	for _, ancestor := range w.chain.GetBlocksFromHash(parent.Hash(), 7) {
+	for _, ancestor := range w.chain.GetBlocksFromHash(hash, w.uncleLookback) {
 		for _, uncle := range ancestor.Uncles() {
-			env.family.Add(uncle.Hash())
+			family.Add(uncle.Hash())
 		}
-		env.family.Add(ancestor.Hash())
-		env.ancestors.Add(ancestor.Hash())
+		family.Add(ancestor.Hash())
+		ancestors.Add(ancestor.Hash())
 	}
-	// Keep track of transactions which return errors so they can be removed
-	env.tcount = 0
-	return env, nil
+	return ancestors, family
+}
+
+// uncleCandidates returns the known side blocks which could be included as an
+// uncle by a block built on top of the current chain head, locally generated
+// ones first. It is meant for inspecting the worker.
+func (w *worker) uncleCandidates() []*types.Block {
+	head := w.chain.CurrentBlock().Hash()
+	ancestors, family := w.uncleFamily(head)
+
+	w.uncleMu.RLock()
+	defer w.uncleMu.RUnlock()
+
+	var candidates []*types.Block
+	for _, uncles := range []map[common.Hash]*types.Block{w.localUncles, w.remoteUncles} {
+		for hash, uncle := range uncles {
+			if uncle.ParentHash() == head || !ancestors.Contains(uncle.ParentHash()) || family.Contains(hash) {
+				continue
+			}
+			candidates = append(candidates, uncle)
+		}
+	}
+	return candidates
+}
+
+// maxUncles returns the maximum number of uncles to include in a block, which
+// is the protocol limit of 2 unless the consensus engine is configured to
+// include fewer, looking through the beacon wrapper.
+func (w *worker) maxUncles() int {
+	engine := w.engine
+	if b, ok := engine.(*beacon.Beacon); ok {
+		engine = b.InnerEngine()
+	}
+	if limiter, ok := engine.(interface{ MaxUncles() int }); ok {
+		return limiter.MaxUncles()
+	}
+	return 2
 }
 
 // commitUncle adds the given block to uncle block set, returns error if failed to add.
@@ -860,6 +1182,7 @@ func (w *worker) commitTransaction(env *environment, tx *types.Transaction) ([]*
 	var (
 		snap = env.state.Snapshot()
 		gp   = env.gasPool.Gas()
+		now  = time.Now()
 	)
 	receipt, err := core.ApplyTransaction(w.chainConfig, w.chain, &env.coinbase, env.gasPool, env.state, env.header, tx, &env.header.GasUsed, *w.chain.GetVMConfig())
 	if err != nil {
@@ -869,11 +1192,26 @@ func (w *worker) commitTransaction(env *environment, tx *types.Transaction) ([]*
 	}
 	env.txs = append(env.txs, tx)
 	env.receipts = append(env.receipts, receipt)
+	env.timings = append(env.timings, txTiming{hash: tx.Hash(), gasUsed: receipt.GasUsed, elapsed: time.Since(now)})
 
 	return receipt.Logs, nil
 }
 
-func (w *worker) commitTransactions(env *environment, txs *types.TransactionsByPriceAndNonce, interrupt *atomic.Int32) error {
+// slowestTxs returns the n transactions of the given timings which took the
+// longest to execute, slowest first.
+func slowestTxs(timings []txTiming, n int) []txTiming {
+	sorted := make([]txTiming, len(timings))
+	copy(sorted, timings)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].elapsed > sorted[j].elapsed
+	})
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+func (w *worker) commitTransactions(env *environment, txs transactionSet, interrupt *atomic.Int32) error {
 	gasLimit := env.header.GasLimit
 	if env.gasPool == nil {
 		env.gasPool = new(core.GasPool).AddGas(gasLimit)
@@ -987,17 +1325,29 @@ func (w *worker) prepareWork(genParams *generateParams) (*environment, error) {
 		}
 		timestamp = parent.Time + 1
 	}
+	// Space the timestamp out from the parent if a minimum block interval is
+	// configured, the sealed block is held back until it's due.
+	if interval := w.config.MinBlockInterval; interval > 0 && !genParams.forceTime {
+		if min := parent.Time + uint64((interval+time.Second-1)/time.Second); timestamp < min {
+			timestamp = min
+		}
+	}
 	// Construct the sealing block header.
 	header := &types.Header{
 		ParentHash: parent.Hash(),
 		Number:     new(big.Int).Add(parent.Number, common.Big1),
-		GasLimit:   core.CalcGasLimit(parent.GasLimit, w.config.GasCeil),
+		GasLimit:   w.gasLimit(parent.GasLimit),
 		Time:       timestamp,
 		Coinbase:   genParams.coinbase,
 	}
-	// Set the extra field.
+	// Set the extra field, defaulting to the required prefix if none was set.
 	if len(w.extra) != 0 {
 		header.Extra = w.extra
+	} else if len(w.config.ExtraDataPrefix) != 0 {
+		header.Extra = common.CopyBytes(w.config.ExtraDataPrefix)
+	}
+	if err := w.checkExtra(header.Extra); err != nil {
+		return nil, err
 	}
 	// Set the randomness field from the beacon chain if it's available.
 	if genParams.random != (common.Hash{}) {
@@ -1008,7 +1358,7 @@ func (w *worker) prepareWork(genParams *generateParams) (*environment, error) {
 		header.BaseFee = misc.CalcBaseFee(w.chainConfig, parent)
 		if !w.chainConfig.IsLondon(parent.Number) {
 			parentGasLimit := parent.GasLimit * w.chainConfig.ElasticityMultiplier()
-			header.GasLimit = core.CalcGasLimit(parentGasLimit, w.config.GasCeil)
+			header.GasLimit = w.gasLimit(parentGasLimit)
 		}
 	}
 	// Run the consensus preparation with the default or customized consensus engine.
@@ -1016,19 +1366,27 @@ func (w *worker) prepareWork(genParams *generateParams) (*environment, error) {
 		log.Error("Failed to prepare header for sealing", "err", err)
 		return nil, err
 	}
+	// The state prefetcher is skipped if no transactions are going to be executed,
+	// either because an empty block is requested or nothing is pending.
+	prefetch := !genParams.noTxs
+	if prefetch {
+		pending, _ := w.eth.TxPool().Stats()
+		prefetch = pending > 0
+	}
 	// Could potentially happen if starting to mine in an odd state.
 	// Note genParams.coinbase can be different with header.Coinbase
 	// since clique algorithm can modify the coinbase field in header.
-	env, err := w.makeEnv(parent, header, genParams.coinbase)
+	env, err := w.makeEnv(parent, header, genParams.coinbase, prefetch)
 	if err != nil {
 		log.Error("Failed to create sealing context", "err", err)
 		return nil, err
 	}
 	// Accumulate the uncles for the sealing work only if it's allowed.
 	if !genParams.noUncle {
+		maxUncles := w.maxUncles()
 		commitUncles := func(blocks map[common.Hash]*types.Block) {
 			for hash, uncle := range blocks {
-				if len(env.uncles) == 2 {
+				if len(env.uncles) >= maxUncles {
 					break
 				}
 				if err := w.commitUncle(env, uncle.Header()); err != nil {
@@ -1039,12 +1397,31 @@ func (w *worker) prepareWork(genParams *generateParams) (*environment, error) {
 			}
 		}
 		// Prefer to locally generated uncle
+		w.uncleMu.RLock()
 		commitUncles(w.localUncles)
 		commitUncles(w.remoteUncles)
+		w.uncleMu.RUnlock()
 	}
 	return env, nil
 }
 
+// transactionSet is an ordered set of transactions to be included into a block,
+// which supports discarding the remaining transactions of an account.
+type transactionSet interface {
+	Peek() *types.Transaction
+	Shift()
+	Pop()
+}
+
+// newTransactionSet orders the given transactions for inclusion, by price or,
+// in deterministic mode, by sender and nonce.
+func (w *worker) newTransactionSet(signer types.Signer, txs map[common.Address]types.Transactions, baseFee *big.Int) transactionSet {
+	if w.config.DeterministicOrdering {
+		return types.NewTransactionsBySenderAndNonce(signer, txs, baseFee)
+	}
+	return types.NewTransactionsByPriceAndNonce(signer, txs, baseFee)
+}
+
 // fillTransactions retrieves the pending transactions from the txpool and fills them
 // into the given sealing block. The transaction selection and ordering strategy can
 // be customized with the plugin in the future.
@@ -1060,13 +1437,13 @@ func (w *worker) fillTransactions(interrupt *atomic.Int32, env *environment) err
 		}
 	}
 	if len(localTxs) > 0 {
-		txs := types.NewTransactionsByPriceAndNonce(env.signer, localTxs, env.header.BaseFee)
+		txs := w.newTransactionSet(env.signer, localTxs, env.header.BaseFee)
 		if err := w.commitTransactions(env, txs, interrupt); err != nil {
 			return err
 		}
 	}
 	if len(remoteTxs) > 0 {
-		txs := types.NewTransactionsByPriceAndNonce(env.signer, remoteTxs, env.header.BaseFee)
+		txs := w.newTransactionSet(env.signer, remoteTxs, env.header.BaseFee)
 		if err := w.commitTransactions(env, txs, interrupt); err != nil {
 			return err
 		}
@@ -1075,13 +1452,14 @@ func (w *worker) fillTransactions(interrupt *atomic.Int32, env *environment) err
 }
 
 // generateWork generates a sealing block based on the given parameters.
-func (w *worker) generateWork(params *generateParams) (*types.Block, *big.Int, error) {
+func (w *worker) generateWork(params *generateParams) *newPayloadResult {
 	work, err := w.prepareWork(params)
 	if err != nil {
-		return nil, nil, err
+		return &newPayloadResult{err: err}
 	}
 	defer work.discard()
 
+	reason := commitInterruptNone
 	if !params.noTxs {
 		interrupt := new(atomic.Int32)
 		timer := time.AfterFunc(w.newpayloadTimeout, func() {
@@ -1089,16 +1467,43 @@ func (w *worker) generateWork(params *generateParams) (*types.Block, *big.Int, e
 		})
 		defer timer.Stop()
 
+		if w.payloadHook != nil {
+			w.payloadHook()
+		}
 		err := w.fillTransactions(interrupt, work)
 		if errors.Is(err, errBlockInterruptedByTimeout) {
 			log.Warn("Block building is interrupted", "allowance", common.PrettyDuration(w.newpayloadTimeout))
+			for _, slow := range slowestTxs(work.timings, slowTxLogLimit) {
+				log.Warn("Slow transaction in interrupted block", "hash", slow.hash, "gas", slow.gasUsed, "elapsed", common.PrettyDuration(slow.elapsed))
+			}
+			reason = commitInterruptTimeout
 		}
 	}
 	block, err := w.engine.FinalizeAndAssemble(w.chain, work.header, work.state, work.txs, work.unclelist(), work.receipts, params.withdrawals)
 	if err != nil {
-		return nil, nil, err
+		return &newPayloadResult{err: err}
+	}
+	return &newPayloadResult{
+		block:           block,
+		fees:            types.TotalFees(block, work.receipts),
+		interruptReason: reason,
 	}
-	return block, totalFees(block, work.receipts), nil
+}
+
+// resumeWork continues sealing the environment frozen by a pause. If the chain
+// has moved on, or the etherbase changed in the meantime, the frozen work is
+// discarded and a new sealing block is created instead.
+func (w *worker) resumeWork() {
+	if w.current == nil || w.current.header.ParentHash != w.chain.CurrentBlock().Hash() || w.current.coinbase != w.etherbaseAt(w.current.header.Number.Uint64()) {
+		w.commitWork(nil, false, time.Now().Unix())
+		return
+	}
+	start := time.Now()
+	if err := w.fillTransactions(nil, w.current); err != nil {
+		log.Error("Failed to refill paused sealing block", "err", err)
+		return
+	}
+	w.commit(w.current.copy(), w.fullTaskHook, true, start)
 }
 
 // commitWork generates several new sealing tasks based on the parent block
@@ -1109,7 +1514,7 @@ func (w *worker) commitWork(interrupt *atomic.Int32, noempty bool, timestamp int
 	// Set the coinbase if the worker is running or it's required
 	var coinbase common.Address
 	if w.isRunning() {
-		coinbase = w.etherbase()
+		coinbase = w.etherbaseAt(w.chain.CurrentBlock().Number.Uint64() + 1)
 		if coinbase == (common.Address{}) {
 			log.Error("Refusing to mine without etherbase")
 			return
@@ -1181,6 +1586,7 @@ func (w *worker) commit(env *environment, interval func(), update bool, start ti
 		env := env.copy()
 		// Withdrawals are set to nil here, because this is only called in PoW.
 		block, err := w.engine.FinalizeAndAssemble(w.chain, env.header, env.state, env.txs, env.unclelist(), env.receipts, nil)
+		w.recordFinalize(err)
 		if err != nil {
 			return err
 		}
@@ -1190,7 +1596,7 @@ func (w *worker) commit(env *environment, interval func(), update bool, start ti
 			case w.taskCh <- &task{receipts: env.receipts, state: env.state, block: block, createdAt: time.Now()}:
 				w.unconfirmed.Shift(block.NumberU64() - 1)
 
-				fees := totalFees(block, env.receipts)
+				fees := types.TotalFees(block, env.receipts)
 				feesInEther := new(big.Float).Quo(new(big.Float).SetInt(fees), big.NewFloat(params.Ether))
 				log.Info("Commit new sealing work", "number", block.Number(), "sealhash", w.engine.SealHash(block.Header()),
 					"uncles", len(env.uncles), "txs", env.tcount,
@@ -1211,7 +1617,7 @@ func (w *worker) commit(env *environment, interval func(), update bool, start ti
 // getSealingBlock generates the sealing block based on the given parameters.
 // The generation result will be passed back via the given channel no matter
 // the generation itself succeeds or not.
-func (w *worker) getSealingBlock(parent common.Hash, timestamp uint64, coinbase common.Address, random common.Hash, withdrawals types.Withdrawals, noTxs bool) (*types.Block, *big.Int, error) {
+func (w *worker) getSealingBlock(parent common.Hash, timestamp uint64, coinbase common.Address, random common.Hash, withdrawals types.Withdrawals, noTxs bool) *newPayloadResult {
 	req := &getWorkReq{
 		params: &generateParams{
 			timestamp:   timestamp,
@@ -1227,13 +1633,9 @@ func (w *worker) getSealingBlock(parent common.Hash, timestamp uint64, coinbase
 	}
 	select {
 	case w.getWorkCh <- req:
-		result := <-req.result
-		if result.err != nil {
-			return nil, nil, result.err
-		}
-		return result.block, result.fees, nil
+		return <-req.result
 	case <-w.exitCh:
-		return nil, nil, errors.New("miner closed")
+		return &newPayloadResult{err: errors.New("miner closed")}
 	}
 }
 
@@ -1262,16 +1664,6 @@ func (w *worker) postSideBlock(event core.ChainSideEvent) {
 	}
 }
 
-// totalFees computes total consumed miner fees in Wei. Block transactions and receipts have to have the same order.
-func totalFees(block *types.Block, receipts []*types.Receipt) *big.Int {
-	feesWei := new(big.Int)
-	for i, tx := range block.Transactions() {
-		minerFee, _ := tx.EffectiveGasTip(block.BaseFee())
-		feesWei.Add(feesWei, new(big.Int).Mul(new(big.Int).SetUint64(receipts[i].GasUsed), minerFee))
-	}
-	return feesWei
-}
-
 // signalToErr converts the interruption signal to a concrete error type for return.
 // The given signal must be a valid interruption signal.
 func signalToErr(signal int32) error {
@@ -13,6 +13,8 @@
 package miner
 
 import (
+	"bytes"
+	"crypto/ecdsa"
 	"crypto/rand"
 	"errors"
 	"math/big"
@@ -22,6 +24,7 @@ import (
 
 	"github.com/r5-labs/r5-core/client/accounts"
 	"github.com/r5-labs/r5-core/client/common"
+	"github.com/r5-labs/r5-core/client/common/mclock"
 	"github.com/r5-labs/r5-core/client/consensus"
 	"github.com/r5-labs/r5-core/client/consensus/clique"
 	"github.com/r5-labs/r5-core/client/consensus/ethash"
@@ -631,32 +634,874 @@ func testGetSealingWork(t *testing.T, chainConfig *params.ChainConfig, engine co
 
 	// This API should work even when the automatic sealing is not enabled
 	for _, c := range cases {
-		block, _, err := w.getSealingBlock(c.parent, timestamp, c.coinbase, c.random, nil, false)
+		r := w.getSealingBlock(c.parent, timestamp, c.coinbase, c.random, nil, false)
 		if c.expectErr {
-			if err == nil {
+			if r.err == nil {
 				t.Error("Expect error but get nil")
 			}
 		} else {
-			if err != nil {
-				t.Errorf("Unexpected error %v", err)
+			if r.err != nil {
+				t.Errorf("Unexpected error %v", r.err)
 			}
-			assertBlock(block, c.expectNumber, c.coinbase, c.random)
+			assertBlock(r.block, c.expectNumber, c.coinbase, c.random)
 		}
 	}
 
 	// This API should work even when the automatic sealing is enabled
 	w.start()
 	for _, c := range cases {
-		block, _, err := w.getSealingBlock(c.parent, timestamp, c.coinbase, c.random, nil, false)
+		r := w.getSealingBlock(c.parent, timestamp, c.coinbase, c.random, nil, false)
 		if c.expectErr {
-			if err == nil {
+			if r.err == nil {
 				t.Error("Expect error but get nil")
 			}
 		} else {
+			if r.err != nil {
+				t.Errorf("Unexpected error %v", r.err)
+			}
+			assertBlock(r.block, c.expectNumber, c.coinbase, c.random)
+		}
+	}
+}
+
+// failingEngine wraps a consensus engine, failing block assembly on demand.
+type failingEngine struct {
+	consensus.Engine
+	fail atomic.Bool
+}
+
+var errAssembly = errors.New("assembly failure")
+
+func (e *failingEngine) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, receipts []*types.Receipt, withdrawals []*types.Withdrawal) (*types.Block, error) {
+	if e.fail.Load() {
+		return nil, errAssembly
+	}
+	return e.Engine.FinalizeAndAssemble(chain, header, state, txs, uncles, receipts, withdrawals)
+}
+
+// Tests that repeated block assembly failures trip the circuit breaker, pausing
+// the worker and reporting the error until mining is restarted.
+func TestFinalizeCircuitBreaker(t *testing.T) {
+	config := *testConfig
+	config.MaxFinalizeFailures = 3
+
+	faker := ethash.NewFaker()
+	defer faker.Close()
+	engine := &failingEngine{Engine: faker}
+	engine.fail.Store(true)
+
+	b := newTestWorkerBackend(t, ethashChainConfig, faker, rawdb.NewMemoryDatabase(), 0)
+	w := newWorker(&config, ethashChainConfig, engine, b, new(event.TypeMux), nil, false)
+	w.setEtherbase(testBankAddress)
+	defer w.close()
+
+	// A single work cycle fails twice, keep transactions arriving so that the
+	// recommits fail too.
+	w.start()
+	for deadline := time.Now().Add(5 * time.Second); w.status() == nil; {
+		if time.Now().After(deadline) {
+			t.Fatal("circuit breaker did not trip")
+		}
+		b.txPool.AddLocal(b.newRandomTx(false))
+		time.Sleep(50 * time.Millisecond)
+	}
+	if err := w.status(); !errors.Is(err, errAssembly) {
+		t.Fatalf("breaker error mismatch: have %v, want %v", err, errAssembly)
+	}
+	if w.isRunning() {
+		t.Fatal("worker still running after the breaker tripped")
+	}
+	// Restarting resets the breaker, successful assemblies keep it closed
+	engine.fail.Store(false)
+	sub := w.mux.Subscribe(core.NewMinedBlockEvent{})
+	defer sub.Unsubscribe()
+
+	w.start()
+	select {
+	case <-sub.Chan():
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout waiting for sealed block")
+	}
+	if err := w.status(); err != nil {
+		t.Fatalf("breaker not reset on restart: %v", err)
+	}
+	if !w.isRunning() {
+		t.Fatal("worker not running after restart")
+	}
+}
+
+func TestEmptyBlockSkipsPrefetcher(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	w, _ := newTestWorker(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+
+	for _, noTxs := range []bool{true, false} {
+		env, err := w.prepareWork(&generateParams{
+			timestamp: uint64(time.Now().Unix()),
+			coinbase:  testBankAddress,
+			noTxs:     noTxs,
+		})
+		if err != nil {
+			t.Fatalf("noTxs %v: failed to prepare work: %v", noTxs, err)
+		}
+		if env.prefetch == noTxs {
+			t.Errorf("noTxs %v: prefetcher started mismatch: have %v, want %v", noTxs, env.prefetch, !noTxs)
+		}
+		if cpy := env.copy(); cpy.prefetch != env.prefetch {
+			t.Errorf("noTxs %v: copied prefetcher flag mismatch: have %v, want %v", noTxs, cpy.prefetch, env.prefetch)
+		}
+		env.discard()
+	}
+	// Building an empty block must not execute the pending transactions.
+	r := w.getSealingBlock(common.Hash{}, uint64(time.Now().Unix()), testBankAddress, common.Hash{}, nil, true)
+	if r.err != nil {
+		t.Fatalf("failed to build empty block: %v", r.err)
+	}
+	if len(r.block.Transactions()) != 0 {
+		t.Fatalf("empty block contains %d transactions", len(r.block.Transactions()))
+	}
+	// Building a full block on top of an empty pool skips the prefetcher too.
+	b := newTestWorkerBackend(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	empty := newWorker(testConfig, ethashChainConfig, engine, b, new(event.TypeMux), nil, false)
+	defer empty.close()
+
+	env, err := empty.prepareWork(&generateParams{
+		timestamp: uint64(time.Now().Unix()),
+		coinbase:  testBankAddress,
+	})
+	if err != nil {
+		t.Fatalf("failed to prepare work: %v", err)
+	}
+	if env.prefetch {
+		t.Error("prefetcher started for an empty pool")
+	}
+	env.discard()
+}
+
+func TestUncleCandidatesLookback(t *testing.T) {
+	for _, lookback := range []int{0, 3} {
+		config := *testConfig
+		config.UncleLookback = lookback
+
+		engine := ethash.NewFaker()
+		b := newTestWorkerBackend(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 10)
+		w := newWorker(&config, ethashChainConfig, engine, b, new(event.TypeMux), nil, false)
+
+		// Post a side block forking off each of the recent ancestors of the head.
+		head := b.chain.CurrentBlock().Number.Uint64()
+		for depth := uint64(0); depth < 8; depth++ {
+			parent := b.chain.GetBlockByNumber(head - depth)
+			blocks, _ := core.GenerateChain(ethashChainConfig, parent, engine, b.db, 1, func(i int, gen *core.BlockGen) {
+				gen.SetCoinbase(common.Address{byte(depth + 1)})
+			})
+			w.postSideBlock(core.ChainSideEvent{Block: blocks[0]})
+		}
+		for i := 0; ; i++ {
+			w.uncleMu.RLock()
+			known := len(w.localUncles) + len(w.remoteUncles)
+			w.uncleMu.RUnlock()
+			if known == 8 {
+				break
+			}
+			if i == 100 {
+				t.Fatalf("lookback %d: side blocks not tracked: have %d, want 8", lookback, known)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		// Siblings of the next block are excluded, the rest must be within the lookback.
+		want := w.uncleLookback - 1
+		candidates := w.uncleCandidates()
+		if len(candidates) != want {
+			t.Errorf("lookback %d: candidate count mismatch: have %d, want %d", lookback, len(candidates), want)
+		}
+		for _, uncle := range candidates {
+			if depth := head - uncle.NumberU64() + 1; depth < 1 || depth >= uint64(w.uncleLookback) {
+				t.Errorf("lookback %d: candidate %d forking off depth %d", lookback, uncle.NumberU64(), depth)
+			}
+		}
+		w.close()
+		engine.Close()
+	}
+}
+
+func TestUncleCleanInterval(t *testing.T) {
+	var (
+		clock  = new(mclock.Simulated)
+		config = *testConfig
+		engine = ethash.NewFaker()
+	)
+	config.UncleCleanInterval = time.Minute
+	config.clock = clock
+
+	b := newTestWorkerBackend(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 10)
+	w := newWorker(&config, ethashChainConfig, engine, b, new(event.TypeMux), nil, false)
+	defer w.close()
+	defer engine.Close()
+
+	// sideBlock posts a block forking off the given ancestor and waits for the
+	// worker to track it.
+	sideBlock := func(number uint64, seed byte) common.Hash {
+		parent := b.chain.GetBlockByNumber(number)
+		blocks, _ := core.GenerateChain(ethashChainConfig, parent, engine, b.db, 1, func(i int, gen *core.BlockGen) {
+			gen.SetCoinbase(common.Address{seed})
+		})
+		w.postSideBlock(core.ChainSideEvent{Block: blocks[0]})
+		waitUncle(t, w, blocks[0].Hash(), true)
+		return blocks[0].Hash()
+	}
+	fresh := sideBlock(9, 1)
+	stale := sideBlock(1, 2)
+
+	// Nothing is dropped until the configured interval elapses.
+	clock.WaitForTimers(1)
+	clock.Run(time.Minute - time.Second)
+	if !hasUncle(w, stale) {
+		t.Fatalf("stale uncle dropped before the cleanup interval")
+	}
+	clock.Run(time.Second)
+	waitUncle(t, w, stale, false)
+	if !hasUncle(w, fresh) {
+		t.Fatalf("fresh uncle dropped by cleanup")
+	}
+	// The cleanup is rescheduled with the same cadence.
+	stale = sideBlock(2, 3)
+	clock.WaitForTimers(1)
+	clock.Run(time.Minute - time.Second)
+	if !hasUncle(w, stale) {
+		t.Fatalf("stale uncle dropped before the second cleanup interval")
+	}
+	clock.Run(time.Second)
+	waitUncle(t, w, stale, false)
+}
+
+// Tests that the worker attaches no more uncles than the protocol allows, and no
+// more than the consensus engine is configured to include.
+func TestMaxUncles(t *testing.T) {
+	zero, one, five := 0, 1, 5
+	for i, tt := range []struct {
+		local *int
+		want  int
+	}{
+		{nil, 2},
+		{&zero, 0},
+		{&one, 1},
+		{&five, 2},
+	} {
+		engine := ethash.New(ethash.Config{PowMode: ethash.ModeFake, MaxUncles: tt.local}, nil, false)
+		defer engine.Close()
+
+		w, b := newTestWorker(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 1)
+		defer w.close()
+
+		for i := 0; i < 3; i++ {
+			uncle := b.newRandomUncle()
+			w.postSideBlock(core.ChainSideEvent{Block: uncle})
+			waitUncle(t, w, uncle.Hash(), true)
+		}
+		env, err := w.prepareWork(&generateParams{
+			timestamp: b.chain.CurrentBlock().Time + 1,
+			coinbase:  testBankAddress,
+		})
+		if err != nil {
+			t.Fatalf("test %d: failed to prepare work: %v", i, err)
+		}
+		env.discard()
+		if len(env.uncles) != tt.want {
+			t.Errorf("test %d: uncle count mismatch: have %d, want %d", i, len(env.uncles), tt.want)
+		}
+		if have := w.maxUncles(); have != tt.want {
+			t.Errorf("test %d: worker uncle limit mismatch: have %d, want %d", i, have, tt.want)
+		}
+	}
+}
+
+// Tests that the coinbase of the mined blocks rotates through the configured
+// addresses, crediting each of them the block reward in turn.
+func TestEtherbaseRotation(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	w, b := newTestWorker(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+
+	coinbases := []common.Address{{0x01}, {0x02}, {0x03}}
+	w.setEtherbases(coinbases)
+
+	// Ignore empty commits to seal a single block per height.
+	w.skipSealHook = func(task *task) bool {
+		return len(task.receipts) == 0
+	}
+	sub := w.mux.Subscribe(core.NewMinedBlockEvent{})
+	defer sub.Unsubscribe()
+
+	w.start()
+	const mined = 7
+	for b.chain.CurrentBlock().Number.Uint64() < mined {
+		b.txPool.AddLocal(b.newRandomTx(false))
+		select {
+		case <-sub.Chan():
+		case <-time.After(3 * time.Second):
+			t.Fatalf("timeout mining block %d", b.chain.CurrentBlock().Number.Uint64()+1)
+		}
+	}
+	w.stop()
+
+	for number := uint64(1); number <= mined; number++ {
+		block := b.chain.GetBlockByNumber(number)
+		want := coinbases[(number-1)%uint64(len(coinbases))]
+		if block.Coinbase() != want {
+			t.Errorf("block %d: coinbase mismatch: have %x, want %x", number, block.Coinbase(), want)
+		}
+		parent, _ := b.chain.StateAt(b.chain.GetBlockByNumber(number - 1).Root())
+		state, _ := b.chain.StateAt(block.Root())
+		credit := new(big.Int).Sub(state.GetBalance(want), parent.GetBalance(want))
+		if credit.Cmp(ethash.BlockReward(number)) < 0 {
+			t.Errorf("block %d: coinbase credit %v below block reward", number, credit)
+		}
+		for _, other := range coinbases {
+			if other != want && state.GetBalance(other).Cmp(parent.GetBalance(other)) != 0 {
+				t.Errorf("block %d: non-coinbase %x credited", number, other)
+			}
+		}
+	}
+	// Heights before the start of the rotation use its first address.
+	w.setEtherbases(coinbases)
+	for _, number := range []uint64{0, 1, mined} {
+		if have := w.etherbaseAt(number); have != coinbases[0] {
+			t.Errorf("height %d before rotation start: etherbase mismatch: have %x, want %x", number, have, coinbases[0])
+		}
+	}
+	// Clearing the rotation reverts to the single etherbase.
+	w.setEtherbases(nil)
+	if have := w.etherbaseAt(mined + 1); have != testBankAddress {
+		t.Errorf("etherbase mismatch after clearing rotation: have %x, want %x", have, testBankAddress)
+	}
+}
+
+// Tests that in deterministic mode the block transactions are ordered by sender
+// and nonce, regardless of their price and of the order they reached the pool.
+func TestDeterministicOrdering(t *testing.T) {
+	var (
+		keys   []*ecdsa.PrivateKey
+		alloc  = make(core.GenesisAlloc)
+		signer = types.LatestSigner(ethashChainConfig)
+		txs    []*types.Transaction
+	)
+	for i := 0; i < 4; i++ {
+		key, _ := crypto.GenerateKey()
+		keys = append(keys, key)
+		alloc[crypto.PubkeyToAddress(key.PublicKey)] = core.GenesisAccount{Balance: testBankFunds}
+	}
+	for i, key := range keys {
+		for nonce := uint64(0); nonce < 2; nonce++ {
+			txs = append(txs, types.MustSignNewTx(key, signer, &types.LegacyTx{
+				Nonce:    nonce,
+				To:       &testUserAddress,
+				Value:    big.NewInt(1000),
+				Gas:      params.TxGas,
+				GasPrice: big.NewInt(int64(i+1) * params.InitialBaseFee),
+			}))
+		}
+	}
+	config := *testConfig
+	config.DeterministicOrdering = true
+
+	// build injects the transactions in the given order into a fresh pool and
+	// returns the sealing block assembled from it.
+	build := func(txs []*types.Transaction) types.Transactions {
+		engine := ethash.NewFaker()
+		defer engine.Close()
+
+		gspec := &core.Genesis{Config: ethashChainConfig, Alloc: alloc}
+		chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, engine, vm.Config{}, nil, nil)
+		if err != nil {
+			t.Fatalf("core.NewBlockChain failed: %v", err)
+		}
+		defer chain.Stop()
+		pool := txpool.NewTxPool(testTxPoolConfig, ethashChainConfig, chain)
+		defer pool.Stop()
+
+		w := newWorker(&config, ethashChainConfig, engine, &testWorkerBackend{chain: chain, txPool: pool, genesis: gspec}, new(event.TypeMux), nil, false)
+		defer w.close()
+
+		for _, err := range pool.AddRemotesSync(txs) {
 			if err != nil {
-				t.Errorf("Unexpected error %v", err)
+				t.Fatalf("failed to add transaction: %v", err)
 			}
-			assertBlock(block, c.expectNumber, c.coinbase, c.random)
 		}
+		r := w.getSealingBlock(chain.CurrentBlock().Hash(), chain.CurrentBlock().Time+1, testBankAddress, common.Hash{}, nil, false)
+		if r.err != nil {
+			t.Fatalf("failed to build block: %v", r.err)
+		}
+		return r.block.Transactions()
+	}
+	first := build(txs)
+
+	reversed := make([]*types.Transaction, len(txs))
+	for i, tx := range txs {
+		reversed[len(txs)-1-i] = tx
+	}
+	second := build(reversed)
+
+	if len(first) != len(txs) || len(second) != len(txs) {
+		t.Fatalf("transaction count mismatch: have %d and %d, want %d", len(first), len(second), len(txs))
+	}
+	for i := range first {
+		if first[i].Hash() != second[i].Hash() {
+			t.Fatalf("transaction %d mismatch: have %x, want %x", i, second[i].Hash(), first[i].Hash())
+		}
+		if i == 0 {
+			continue
+		}
+		prev, _ := types.Sender(signer, first[i-1])
+		from, _ := types.Sender(signer, first[i])
+		if cmp := bytes.Compare(prev[:], from[:]); cmp > 0 || (cmp == 0 && first[i-1].Nonce() >= first[i].Nonce()) {
+			t.Fatalf("transaction %d out of sender and nonce order", i)
+		}
+	}
+}
+
+// hasUncle reports whether the worker tracks the given block as an uncle candidate.
+func hasUncle(w *worker, hash common.Hash) bool {
+	w.uncleMu.RLock()
+	defer w.uncleMu.RUnlock()
+
+	_, local := w.localUncles[hash]
+	_, remote := w.remoteUncles[hash]
+	return local || remote
+}
+
+// waitUncle waits until the worker's tracking of the given uncle matches.
+func waitUncle(t *testing.T, w *worker, hash common.Hash, tracked bool) {
+	t.Helper()
+	for i := 0; hasUncle(w, hash) != tracked; i++ {
+		if i == 100 {
+			t.Fatalf("uncle %x tracking mismatch: want %v", hash, tracked)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Tests that pausing the worker freezes the sealing block, and resuming it
+// continues filling the same block while the head is unchanged.
+func TestPauseResumeRetainsWork(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	w, b := newTestWorker(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+
+	taskCh := make(chan *task, 16)
+	w.newTaskHook = func(task *task) { taskCh <- task }
+	w.skipSealHook = func(task *task) bool { return true }
+
+	w.start()
+	sealing := waitTask(t, taskCh, func(task *task) bool { return len(task.block.Transactions()) == 1 })
+
+	w.pause()
+	if w.isRunning() || !w.isPaused() {
+		t.Fatalf("worker status mismatch after pause: running %v, paused %v", w.isRunning(), w.isPaused())
+	}
+	b.txPool.AddLocals(newTxs)
+	select {
+	case task := <-taskCh:
+		t.Fatalf("sealing task %d with %d txs created while paused", task.block.NumberU64(), len(task.block.Transactions()))
+	case <-time.After(200 * time.Millisecond):
+	}
+	// The first task after resuming must extend the frozen block instead of
+	// starting again from an empty one.
+	w.resume()
+	select {
+	case task := <-taskCh:
+		txs := task.block.Transactions()
+		if task.block.ParentHash() != sealing.block.ParentHash() {
+			t.Fatalf("resumed block parent mismatch: have %x, want %x", task.block.ParentHash(), sealing.block.ParentHash())
+		}
+		if len(txs) != 2 || txs[0].Hash() != sealing.block.Transactions()[0].Hash() {
+			t.Fatalf("resumed block txs mismatch: have %d txs, want the frozen one and the new one", len(txs))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no sealing task after resume")
+	}
+}
+
+// Tests that resuming the worker after the head moved discards the frozen block
+// and starts sealing on top of the new head.
+func TestPauseResumeDiscardsStaleWork(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	w, b := newTestWorker(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+
+	taskCh := make(chan *task, 16)
+	w.newTaskHook = func(task *task) { taskCh <- task }
+	w.skipSealHook = func(task *task) bool { return true }
+
+	w.start()
+	waitTask(t, taskCh, func(task *task) bool { return len(task.block.Transactions()) == 1 })
+
+	w.pause()
+	blocks, _ := core.GenerateChain(ethashChainConfig, b.chain.GetBlockByHash(b.chain.CurrentBlock().Hash()), engine, b.db, 1, func(i int, gen *core.BlockGen) {
+		gen.SetCoinbase(testUserAddress)
+	})
+	if _, err := b.chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert new head: %v", err)
+	}
+	head := blocks[0]
+	select {
+	case task := <-taskCh:
+		t.Fatalf("sealing task %d created while paused", task.block.NumberU64())
+	case <-time.After(200 * time.Millisecond):
+	}
+	w.resume()
+	select {
+	case task := <-taskCh:
+		if task.block.ParentHash() != head.Hash() || task.block.NumberU64() != head.NumberU64()+1 {
+			t.Fatalf("resumed block not on new head: have %d (parent %x), want %d (parent %x)",
+				task.block.NumberU64(), task.block.ParentHash(), head.NumberU64()+1, head.Hash())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no sealing task after resume")
+	}
+}
+
+// waitTask waits for a sealing task matching the given condition, dropping any
+// other tasks in the meantime.
+func waitTask(t *testing.T, taskCh chan *task, match func(*task) bool) *task {
+	t.Helper()
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case task := <-taskCh:
+			if match(task) {
+				return task
+			}
+		case <-timeout:
+			t.Fatal("sealing task timeout")
+			return nil
+		}
+	}
+}
+
+// slowTxTracer is an EVM logger stalling the execution of the transactions
+// with the given gas limit.
+type slowTxTracer struct {
+	gas   uint64
+	delay time.Duration
+}
+
+func (t *slowTxTracer) CaptureTxStart(gasLimit uint64) {
+	if gasLimit == t.gas {
+		time.Sleep(t.delay)
+	}
+}
+func (t *slowTxTracer) CaptureTxEnd(restGas uint64) {}
+func (t *slowTxTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+}
+func (t *slowTxTracer) CaptureEnd(output []byte, gasUsed uint64, err error) {}
+func (t *slowTxTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+}
+func (t *slowTxTracer) CaptureExit(output []byte, gasUsed uint64, err error) {}
+func (t *slowTxTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+}
+func (t *slowTxTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+// Tests that the execution time of each included transaction is recorded, and
+// that an artificially slow transaction is reported as the slowest one.
+func TestSlowTransactionTiming(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	// The tracer is installed before the worker starts using the chain config.
+	const slowGas = params.TxGas + 1000
+	b := newTestWorkerBackend(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	*b.chain.GetVMConfig() = vm.Config{Tracer: &slowTxTracer{gas: slowGas, delay: 20 * time.Millisecond}}
+
+	b.txPool.AddLocals(pendingTxs)
+	w := newWorker(testConfig, ethashChainConfig, engine, b, new(event.TypeMux), nil, false)
+	w.setEtherbase(testBankAddress)
+	defer w.close()
+
+	var slow *types.Transaction
+	for i := 0; i < 6; i++ {
+		gas := params.TxGas
+		if i == 3 {
+			gas = slowGas
+		}
+		tx, _ := types.SignTx(types.NewTransaction(b.txPool.Nonce(testBankAddress), testUserAddress, big.NewInt(1000), gas, big.NewInt(10*params.InitialBaseFee), nil), types.HomesteadSigner{}, testBankKey)
+		if err := b.txPool.AddLocal(tx); err != nil {
+			t.Fatalf("failed to add transaction %d: %v", i, err)
+		}
+		if gas == slowGas {
+			slow = tx
+		}
+	}
+	env, err := w.prepareWork(&generateParams{timestamp: uint64(time.Now().Unix()), coinbase: testBankAddress})
+	if err != nil {
+		t.Fatalf("failed to prepare work: %v", err)
+	}
+	defer env.discard()
+
+	if err := w.fillTransactions(nil, env); err != nil {
+		t.Fatalf("failed to fill transactions: %v", err)
+	}
+	if len(env.timings) != len(env.txs) || len(env.txs) != 7 {
+		t.Fatalf("timing count mismatch: have %d timings for %d txs, want 7", len(env.timings), len(env.txs))
+	}
+	slowest := slowestTxs(env.timings, 3)
+	if len(slowest) != 3 {
+		t.Fatalf("slowest tx count mismatch: have %d, want 3", len(slowest))
+	}
+	if slowest[0].hash != slow.Hash() || slowest[0].gasUsed != params.TxGas {
+		t.Fatalf("slowest tx mismatch: have %x (gas %d), want %x", slowest[0].hash, slowest[0].gasUsed, slow.Hash())
+	}
+	if slowest[0].elapsed < 20*time.Millisecond {
+		t.Fatalf("slow tx timing too low: %v", slowest[0].elapsed)
+	}
+	for i := 1; i < len(slowest); i++ {
+		if slowest[i].elapsed > slowest[i-1].elapsed {
+			t.Fatalf("slowest txs not sorted: %v after %v", slowest[i].elapsed, slowest[i-1].elapsed)
+		}
+	}
+}
+
+// Tests that closing the worker while a sealed block is still queued for
+// writing either commits the block with its state and receipts in full, or
+// leaves the chain untouched.
+func TestCloseDrainsSealedBlocks(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	w, b := newTestWorker(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	parent := b.chain.CurrentBlock()
+
+	env, err := w.prepareWork(&generateParams{timestamp: uint64(time.Now().Unix()), coinbase: testBankAddress})
+	if err != nil {
+		t.Fatalf("failed to prepare work: %v", err)
+	}
+	defer env.discard()
+
+	if err := w.fillTransactions(nil, env); err != nil {
+		t.Fatalf("failed to fill transactions: %v", err)
+	}
+	block, err := engine.FinalizeAndAssemble(b.chain, env.header, env.state, env.txs, env.unclelist(), env.receipts, nil)
+	if err != nil {
+		t.Fatalf("failed to assemble block: %v", err)
+	}
+	if len(block.Transactions()) == 0 {
+		t.Fatal("sealed block has no transactions")
+	}
+	w.pendingMu.Lock()
+	w.pendingTasks[engine.SealHash(block.Header())] = &task{receipts: env.receipts, state: env.state, block: block, createdAt: time.Now()}
+	w.pendingMu.Unlock()
+
+	w.resultCh <- block
+	w.close()
+
+	if b.chain.HasBlock(block.Hash(), block.NumberU64()) {
+		if !b.chain.HasState(block.Root()) {
+			t.Fatal("sealed block written without its state")
+		}
+		if receipts := b.chain.GetReceiptsByHash(block.Hash()); len(receipts) != len(block.Transactions()) {
+			t.Fatalf("receipt count mismatch: have %d, want %d", len(receipts), len(block.Transactions()))
+		}
+		if head := b.chain.CurrentBlock(); head.Hash() != block.Hash() {
+			t.Fatalf("head mismatch: have %x, want %x", head.Hash(), block.Hash())
+		}
+	} else if head := b.chain.CurrentBlock(); head.Hash() != parent.Hash() {
+		t.Fatalf("dropped block moved the head: have %x, want %x", head.Hash(), parent.Hash())
+	}
+	// The block was queued well within the drain timeout, it must not be lost.
+	if !b.chain.HasBlock(block.Hash(), block.NumberU64()) {
+		t.Fatal("queued sealed block abandoned on close")
+	}
+}
+
+// Tests that the worker holds sealed blocks back to honour the minimum block
+// interval, and that it still shuts down promptly while doing so.
+func TestMinBlockInterval(t *testing.T) {
+	config := *testConfig
+	config.MinBlockInterval = 2 * time.Second
+
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	b := newTestWorkerBackend(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	w := newWorker(&config, ethashChainConfig, engine, b, new(event.TypeMux), nil, false)
+	w.setEtherbase(testBankAddress)
+
+	sub := w.mux.Subscribe(core.NewMinedBlockEvent{})
+	defer sub.Unsubscribe()
+
+	w.start()
+	for i := 0; i < 3; i++ {
+		select {
+		case ev := <-sub.Chan():
+			block := ev.Data.(core.NewMinedBlockEvent).Block
+			parent := b.chain.GetHeader(block.ParentHash(), block.NumberU64()-1)
+			if block.Time() < parent.Time+2 {
+				t.Fatalf("block %d: timestamp too close to parent: have %d, parent %d", block.NumberU64(), block.Time(), parent.Time)
+			}
+			if due := time.Unix(int64(parent.Time), 0).Add(config.MinBlockInterval); time.Now().Before(due) {
+				t.Fatalf("block %d: written %v before the minimum interval elapsed", block.NumberU64(), time.Until(due))
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for block %d", i+1)
+		}
+	}
+	// The next block is being held back now, closing must not wait for it.
+	start := time.Now()
+	w.close()
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("closing the worker took %v", elapsed)
+	}
+}
+
+// Tests that every sealing environment starts its own state prefetcher with
+// the configured number of workers, and that discarding it stops the prefetcher
+// only once, however many times it's called.
+func TestPrefetcherLifecycle(t *testing.T) {
+	config := *testConfig
+	config.PrefetchWorkers = 4
+
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	b := newTestWorkerBackend(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	b.txPool.AddLocals(pendingTxs)
+
+	// Prefetchers only run on top of snapshots, swap in a chain maintaining them
+	b.chain.Stop()
+	chain, err := core.NewBlockChain(b.db, &core.CacheConfig{TrieCleanLimit: 16, SnapshotLimit: 16, SnapshotWait: true}, b.genesis, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+	b.chain = chain
+
+	w := newWorker(&config, ethashChainConfig, engine, b, new(event.TypeMux), nil, false)
+	defer w.close()
+
+	for i := 0; i < 2; i++ {
+		env, err := w.prepareWork(&generateParams{
+			timestamp: uint64(time.Now().Unix()),
+			coinbase:  testBankAddress,
+		})
+		if err != nil {
+			t.Fatalf("env %d: failed to prepare work: %v", i, err)
+		}
+		if !env.prefetch || !env.state.Prefetching() {
+			t.Fatalf("env %d: prefetcher not started", i)
+		}
+		cpy := env.copy()
+		if cpy.state.Prefetching() {
+			t.Errorf("env %d: copied environment runs its own prefetcher", i)
+		}
+		cpy.discard()
+
+		env.discard()
+		if env.prefetch || env.state.Prefetching() {
+			t.Fatalf("env %d: prefetcher not stopped", i)
+		}
+		env.discard()
+	}
+}
+
+// Tests that extra data lacking the configured prefix or exceeding the configured
+// limit is rejected, while conforming extra data ends up in the sealed blocks.
+func TestExtraDataPrefix(t *testing.T) {
+	config := *testConfig
+	config.ExtraDataPrefix = []byte("pool")
+	config.MaxExtraDataSize = 8
+
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	b := newTestWorkerBackend(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	w := newWorker(&config, ethashChainConfig, engine, b, new(event.TypeMux), nil, false)
+	w.setEtherbase(testBankAddress)
+	defer w.close()
+
+	// Without any extra data set, the prefix alone is used
+	r := w.getSealingBlock(common.Hash{}, uint64(time.Now().Unix()), testBankAddress, common.Hash{}, nil, true)
+	if r.err != nil {
+		t.Fatalf("failed to build block: %v", r.err)
+	}
+	if extra := r.block.Extra(); string(extra) != "pool" {
+		t.Fatalf("default extra mismatch: have %q, want %q", extra, "pool")
+	}
+	for _, extra := range []string{"", "other", "xpool", "pool-1234"} {
+		if err := w.setExtra([]byte(extra)); err == nil {
+			t.Errorf("extra %q accepted", extra)
+		}
+	}
+	if err := w.setExtra([]byte("pool-1")); err != nil {
+		t.Fatalf("conforming extra rejected: %v", err)
+	}
+	sub := w.mux.Subscribe(core.NewMinedBlockEvent{})
+	defer sub.Unsubscribe()
+
+	w.start()
+	select {
+	case ev := <-sub.Chan():
+		if extra := ev.Data.(core.NewMinedBlockEvent).Block.Extra(); string(extra) != "pool-1" {
+			t.Fatalf("sealed extra mismatch: have %q, want %q", extra, "pool-1")
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout waiting for sealed block")
+	}
+}
+
+func TestGasLimitTarget(t *testing.T) {
+	genesis := params.GenesisGasLimit
+	tests := []struct {
+		floor, ceil, target uint64
+		want                uint64
+	}{
+		{ceil: genesis * 2, target: genesis + 10000, want: genesis + 10000},                     // raise toward the target
+		{ceil: genesis * 2, target: genesis - 10000, want: genesis - 10000},                     // lower toward the target
+		{ceil: genesis + 10000, want: genesis + 10000},                                          // no target, steer toward the ceiling
+		{ceil: genesis + 10000, target: genesis * 2, want: genesis + 10000},                     // target capped by the ceiling
+		{floor: genesis - 10000, ceil: genesis * 2, target: genesis / 2, want: genesis - 10000}, // target raised to the floor
+	}
+	for i, tt := range tests {
+		config := *testConfig
+		config.GasFloor, config.GasCeil, config.GasTarget = tt.floor, tt.ceil, tt.target
+
+		engine := ethash.NewFaker()
+		b := newTestWorkerBackend(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+		w := newWorker(&config, ethashChainConfig, engine, b, new(event.TypeMux), nil, false)
+
+		// Mine a handful of blocks, each of which may only move the limit by
+		// the protocol step toward the target, until the target is reached.
+		for n := 0; n < 5; n++ {
+			parent := b.chain.CurrentBlock()
+			r := w.getSealingBlock(parent.Hash(), parent.Time+1, testBankAddress, common.Hash{}, nil, true)
+			if r.err != nil {
+				t.Fatalf("test %d: failed to build block: %v", i, r.err)
+			}
+			limit, step := r.block.GasLimit(), parent.GasLimit/params.GasLimitBoundDivisor-1
+			switch {
+			case parent.GasLimit < tt.want && (limit <= parent.GasLimit || limit > parent.GasLimit+step || limit > tt.want):
+				t.Errorf("test %d: block %d limit %d not raised toward %d from %d", i, n+1, limit, tt.want, parent.GasLimit)
+			case parent.GasLimit > tt.want && (limit >= parent.GasLimit || limit < parent.GasLimit-step || limit < tt.want):
+				t.Errorf("test %d: block %d limit %d not lowered toward %d from %d", i, n+1, limit, tt.want, parent.GasLimit)
+			case parent.GasLimit == tt.want && limit != tt.want:
+				t.Errorf("test %d: block %d limit %d drifted from %d", i, n+1, limit, tt.want)
+			}
+			if _, err := b.chain.InsertChain(types.Blocks{r.block}); err != nil {
+				t.Fatalf("test %d: failed to insert block: %v", i, err)
+			}
+		}
+		if have := b.chain.CurrentBlock().GasLimit; have != tt.want {
+			t.Errorf("test %d: gas limit mismatch: have %d, want %d", i, have, tt.want)
+		}
+		w.close()
+		b.chain.Stop()
+		engine.Close()
 	}
 }
@@ -415,13 +415,52 @@ type ChainConfig struct {
 }
 
 // EthashConfig is the consensus engine configs for proof-of-work based sealing.
-type EthashConfig struct{}
+type EthashConfig struct {
+	// AllowEqualTimestamps accepts blocks stamped with the same time as their
+	// parent, instead of requiring strictly increasing timestamps. All nodes of
+	// a chain must agree on this rule.
+	AllowEqualTimestamps bool `json:"allowEqualTimestamps,omitempty"`
+
+	// MinimumDifficulty overrides the floor the difficulty adjustment may never
+	// descend below, allowing private networks to mine faster. Unset or
+	// non-positive values use the protocol default MinimumDifficulty.
+	MinimumDifficulty *big.Int `json:"minimumDifficulty,omitempty"`
+
+	// DurationLimit overrides the block time, in seconds, below which the
+	// Frontier difficulty adjustment raises the difficulty and above which it
+	// lowers it. Zero uses the protocol default DurationLimit.
+	DurationLimit uint64 `json:"durationLimit,omitempty"`
+}
 
 // String implements the stringer interface, returning the consensus engine details.
 func (c *EthashConfig) String() string {
 	return "ethash"
 }
 
+// allowEqualTimestamps reports whether the configured rules accept blocks with
+// the same timestamp as their parent.
+func (c *EthashConfig) allowEqualTimestamps() bool {
+	return c != nil && c.AllowEqualTimestamps
+}
+
+// minimumDifficulty returns the configured difficulty floor, falling back to
+// the protocol default if unset.
+func (c *EthashConfig) minimumDifficulty() *big.Int {
+	if c != nil && c.MinimumDifficulty != nil && c.MinimumDifficulty.Sign() > 0 {
+		return c.MinimumDifficulty
+	}
+	return MinimumDifficulty
+}
+
+// durationLimit returns the configured difficulty adjustment block time,
+// falling back to the protocol default if unset.
+func (c *EthashConfig) durationLimit() uint64 {
+	if c != nil && c.DurationLimit != 0 {
+		return c.DurationLimit
+	}
+	return DurationLimit.Uint64()
+}
+
 // CliqueConfig is the consensus engine configs for proof-of-authority based sealing.
 type CliqueConfig struct {
 	Period uint64 `json:"period"` // Number of seconds between blocks to enforce
@@ -729,6 +768,31 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, headNumber *big.Int,
 	return nil
 }
 
+// CheckEthashCompatible checks whether the ethash rules of newcfg match the
+// stored ones once the chain has progressed past genesis. The rules have no
+// activation block, so there's no rewinding to a point where they'd apply: a
+// change is a hard error instead of a ConfigCompatError.
+func (c *ChainConfig) CheckEthashCompatible(newcfg *ChainConfig, height uint64) error {
+	if height == 0 {
+		return nil
+	}
+	var (
+		what       string
+		stored, nw interface{}
+	)
+	switch {
+	case c.Ethash.allowEqualTimestamps() != newcfg.Ethash.allowEqualTimestamps():
+		what, stored, nw = "equal timestamps rule", c.Ethash.allowEqualTimestamps(), newcfg.Ethash.allowEqualTimestamps()
+	case c.Ethash.minimumDifficulty().Cmp(newcfg.Ethash.minimumDifficulty()) != 0:
+		what, stored, nw = "minimum difficulty", c.Ethash.minimumDifficulty(), newcfg.Ethash.minimumDifficulty()
+	case c.Ethash.durationLimit() != newcfg.Ethash.durationLimit():
+		what, stored, nw = "duration limit", c.Ethash.durationLimit(), newcfg.Ethash.durationLimit()
+	default:
+		return nil
+	}
+	return fmt.Errorf("incompatible ethash %s: have %v, want %v at block %d", what, stored, nw, height)
+}
+
 // BaseFeeChangeDenominator bounds the amount the base fee can change between blocks.
 func (c *ChainConfig) BaseFeeChangeDenominator() uint64 {
 	return DefaultBaseFeeChangeDenominator
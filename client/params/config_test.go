@@ -115,6 +115,60 @@ func TestCheckCompatible(t *testing.T) {
 	}
 }
 
+func TestCheckEthashCompatible(t *testing.T) {
+	tests := []struct {
+		stored, new *ChainConfig
+		headBlock   uint64
+		wantErr     string
+	}{
+		{
+			stored:    &ChainConfig{Ethash: &EthashConfig{MinimumDifficulty: big.NewInt(1000)}},
+			new:       &ChainConfig{Ethash: &EthashConfig{MinimumDifficulty: big.NewInt(1000)}},
+			headBlock: 100,
+		},
+		{
+			stored:    &ChainConfig{Ethash: new(EthashConfig)},
+			new:       &ChainConfig{Ethash: &EthashConfig{MinimumDifficulty: new(big.Int).Set(MinimumDifficulty), DurationLimit: DurationLimit.Uint64()}},
+			headBlock: 100,
+		},
+		{
+			stored:    &ChainConfig{Ethash: new(EthashConfig)},
+			new:       &ChainConfig{Ethash: &EthashConfig{MinimumDifficulty: big.NewInt(1000)}},
+			headBlock: 0,
+		},
+		{
+			stored:    &ChainConfig{Ethash: new(EthashConfig)},
+			new:       &ChainConfig{Ethash: &EthashConfig{MinimumDifficulty: big.NewInt(1000)}},
+			headBlock: 100,
+			wantErr:   "incompatible ethash minimum difficulty: have 131072, want 1000 at block 100",
+		},
+		{
+			stored:    &ChainConfig{Ethash: new(EthashConfig)},
+			new:       &ChainConfig{Ethash: &EthashConfig{AllowEqualTimestamps: true}},
+			headBlock: 100,
+			wantErr:   "incompatible ethash equal timestamps rule: have false, want true at block 100",
+		},
+		{
+			stored:    &ChainConfig{Ethash: &EthashConfig{DurationLimit: 13}},
+			new:       &ChainConfig{Ethash: new(EthashConfig)},
+			headBlock: 100,
+			wantErr:   "incompatible ethash duration limit: have 13, want 7 at block 100",
+		},
+	}
+	for i, test := range tests {
+		err := test.stored.CheckEthashCompatible(test.new, test.headBlock)
+		if test.wantErr == "" {
+			if err != nil {
+				t.Errorf("test %d: unexpected error: %v", i, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != test.wantErr {
+			t.Errorf("test %d: error mismatch: have %v, want %q", i, err, test.wantErr)
+		}
+	}
+}
+
 func TestConfigRules(t *testing.T) {
 	c := &ChainConfig{
 		ShanghaiTime: newUint64(500),
@@ -20,6 +20,10 @@ const (
 	MaxGasLimit          uint64 = 0x7fffffffffffffff // Maximum the gas limit (2^63-1).
 	GenesisGasLimit      uint64 = 4712388            // Gas limit of the Genesis block.
 
+	// AllowedFutureBlockTime is the number of seconds a block timestamp may be
+	// ahead of the local clock before the block is considered a future block.
+	AllowedFutureBlockTime int64 = 8
+
 	MaximumExtraDataSize  uint64 = 32    // Maximum size extra data may be after Genesis.
 	ExpByteGas            uint64 = 10    // Times ceil(log256(exponent)) for the EXP instruction.
 	SloadGas              uint64 = 50    // Multiplied by the number of 32-byte words that are copied (round up) for any *COPY operation and added.
@@ -169,3 +173,7 @@ var (
 	MinimumDifficulty      = big.NewInt(131072) // The minimum that the difficulty may ever be.
 	DurationLimit          = big.NewInt(7)     // The decision boundary on the blocktime duration used to determine whether difficulty should go up or not.
 )
+
+// PreminedSupply is the amount of wei allocated in the R5 genesis block. Block
+// rewards are emitted on top of it up to the supply cap.
+var PreminedSupply = new(big.Int).Mul(big.NewInt(2000000), big.NewInt(Ether))
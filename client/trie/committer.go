@@ -20,8 +20,10 @@ import (
 
 // leaf represents a trie leaf node
 type leaf struct {
-	blob   []byte      // raw blob of leaf
-	parent common.Hash // the hash of parent node
+	key      []byte      // key of leaf, in keybytes form
+	blob     []byte      // raw blob of leaf
+	parent   common.Hash // the hash of parent node
+	embedded bool        // whether the leaf is embedded in its parent, leaving parent unset
 }
 
 // committer is the tool used for the trie Commit operation. The committer will
@@ -133,6 +135,16 @@ func (c *committer) store(path []byte, n node) node {
 		if _, ok := c.nodes.accessList[string(path)]; ok {
 			c.nodes.markDeleted(path)
 		}
+		// Embedded leaves are still collected if they were modified, they
+		// just can't reference their parent which isn't hashed yet.
+		if c.collectLeaf {
+			if sn, ok := n.(*shortNode); ok && sn.flags.dirty {
+				if val, ok := sn.Val.(valueNode); ok {
+					key := append(common.CopyBytes(path), compactToHex(sn.Key)...)
+					c.nodes.addLeaf(&leaf{key: hexToKeybytes(key), blob: val, embedded: true})
+				}
+			}
+		}
 		return n
 	}
 	// We have the hash already, estimate the RLP encoding-size of the node.
@@ -155,7 +167,8 @@ func (c *committer) store(path []byte, n node) node {
 	if c.collectLeaf {
 		if sn, ok := n.(*shortNode); ok {
 			if val, ok := sn.Val.(valueNode); ok {
-				c.nodes.addLeaf(&leaf{blob: val, parent: nhash})
+				key := append(common.CopyBytes(path), compactToHex(sn.Key)...)
+				c.nodes.addLeaf(&leaf{key: hexToKeybytes(key), blob: val, parent: nhash})
 			}
 		}
 	}
@@ -19,6 +19,7 @@ import (
 	"reflect"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/VictoriaMetrics/fastcache"
@@ -53,6 +54,10 @@ var (
 	memcacheCommitTimeTimer  = metrics.NewRegisteredResettingTimer("trie/memcache/commit/time", nil)
 	memcacheCommitNodesMeter = metrics.NewRegisteredMeter("trie/memcache/commit/nodes", nil)
 	memcacheCommitSizeMeter  = metrics.NewRegisteredMeter("trie/memcache/commit/size", nil)
+
+	memcacheCleanSizeGauge  = metrics.NewRegisteredGauge("trie/memcache/clean/size", nil)
+	memcacheDirtyNodesGauge = metrics.NewRegisteredGauge("trie/memcache/dirty/nodes", nil)
+	memcacheDirtySizeGauge  = metrics.NewRegisteredGauge("trie/memcache/dirty/size", nil)
 )
 
 // Database is an intermediate write layer between the trie data structures and
@@ -71,6 +76,10 @@ type Database struct {
 	oldest  common.Hash                 // Oldest tracked node, flush-list head
 	newest  common.Hash                 // Newest tracked node, flush-list tail
 
+	pinned  map[common.Hash]*pinnedNode   // Clean node RLPs exempt from cache eviction
+	pins    map[common.Hash][]common.Hash // Nodes held by each pinned subtree root
+	pinLock sync.RWMutex                  // Lock protecting the pinned node set
+
 	gctime  time.Duration      // Time spent on garbage collection since last commit
 	gcnodes uint64             // Nodes garbage collected since last commit
 	gcsize  common.StorageSize // Data storage garbage collected since last commit
@@ -83,9 +92,28 @@ type Database struct {
 	childrenSize common.StorageSize // Storage size of the external children tracking
 	preimages    *preimageStore     // The store for caching preimages
 
+	cleanHits   uint64 // Number of clean cache hits since the database was opened (atomic)
+	cleanMisses uint64 // Number of clean cache misses since the database was opened (atomic)
+	persisted   uint64 // Number of nodes flushed to disk since the database was opened
+
 	lock sync.RWMutex
 }
 
+// Stats contains a snapshot of the memory cache statistics of the trie database.
+type Stats struct {
+	DirtyNodes    int                // Number of dirty nodes held in memory
+	DirtySize     common.StorageSize // Approximate memory used by the dirty nodes, including metadata
+	CleanHitRatio float64            // Ratio of clean cache hits to all clean cache lookups
+	FlushedNodes  uint64             // Number of nodes flushed to disk since the database was opened
+}
+
+// pinnedNode is a clean trie node held in memory on behalf of one or more
+// pinned subtrees, regardless of the pressure on the clean cache.
+type pinnedNode struct {
+	blob []byte // Encoded RLP of the node
+	refs int    // Number of pinned subtrees referencing the node
+}
+
 // rawNode is a simple binary blob used to differentiate between collapsed trie
 // nodes and already encoded RLP binary blobs (while at the same time store them
 // in the same cache fields).
@@ -295,6 +323,8 @@ func NewDatabaseWithConfig(diskdb ethdb.Database, config *Config) *Database {
 		dirties: map[common.Hash]*cachedNode{{}: {
 			children: make(map[common.Hash]uint16),
 		}},
+		pinned:    make(map[common.Hash]*pinnedNode),
+		pins:      make(map[common.Hash][]common.Hash),
 		preimages: preimage,
 	}
 	return db
@@ -335,11 +365,20 @@ func (db *Database) insert(hash common.Hash, size int, node node) {
 // node retrieves a cached trie node from memory, or returns nil if none can be
 // found in the memory cache.
 func (db *Database) node(hash common.Hash) node {
+	// Retrieve the node from the pinned set if available. The blob is shared
+	// between readers, so it must be decoded into its own copy.
+	if enc := db.pinnedBlob(hash); enc != nil {
+		memcacheCleanHitMeter.Mark(1)
+		memcacheCleanReadMeter.Mark(int64(len(enc)))
+		atomic.AddUint64(&db.cleanHits, 1)
+		return mustDecodeNode(hash[:], enc)
+	}
 	// Retrieve the node from the clean cache if available
 	if db.cleans != nil {
 		if enc := db.cleans.Get(nil, hash[:]); enc != nil {
 			memcacheCleanHitMeter.Mark(1)
 			memcacheCleanReadMeter.Mark(int64(len(enc)))
+			atomic.AddUint64(&db.cleanHits, 1)
 
 			// The returned value from cache is in its own copy,
 			// safe to use mustDecodeNodeUnsafe for decoding.
@@ -366,6 +405,7 @@ func (db *Database) node(hash common.Hash) node {
 	if db.cleans != nil {
 		db.cleans.Set(hash[:], enc)
 		memcacheCleanMissMeter.Mark(1)
+		atomic.AddUint64(&db.cleanMisses, 1)
 		memcacheCleanWriteMeter.Mark(int64(len(enc)))
 	}
 	// The returned value from database is in its own copy,
@@ -380,11 +420,19 @@ func (db *Database) Node(hash common.Hash) ([]byte, error) {
 	if hash == (common.Hash{}) {
 		return nil, errors.New("not found")
 	}
+	// Retrieve the node from the pinned set if available
+	if enc := db.pinnedBlob(hash); enc != nil {
+		memcacheCleanHitMeter.Mark(1)
+		memcacheCleanReadMeter.Mark(int64(len(enc)))
+		atomic.AddUint64(&db.cleanHits, 1)
+		return common.CopyBytes(enc), nil
+	}
 	// Retrieve the node from the clean cache if available
 	if db.cleans != nil {
 		if enc := db.cleans.Get(nil, hash[:]); enc != nil {
 			memcacheCleanHitMeter.Mark(1)
 			memcacheCleanReadMeter.Mark(int64(len(enc)))
+			atomic.AddUint64(&db.cleanHits, 1)
 			return enc, nil
 		}
 	}
@@ -406,6 +454,7 @@ func (db *Database) Node(hash common.Hash) ([]byte, error) {
 		if db.cleans != nil {
 			db.cleans.Set(hash[:], enc)
 			memcacheCleanMissMeter.Mark(1)
+			atomic.AddUint64(&db.cleanMisses, 1)
 			memcacheCleanWriteMeter.Mark(int64(len(enc)))
 		}
 		return enc, nil
@@ -413,6 +462,115 @@ func (db *Database) Node(hash common.Hash) ([]byte, error) {
 	return nil, errors.New("not found")
 }
 
+// pinnedBlob retrieves the encoded node from the pinned set, or nil if the
+// node is not held by any pinned subtree.
+func (db *Database) pinnedBlob(hash common.Hash) []byte {
+	db.pinLock.RLock()
+	defer db.pinLock.RUnlock()
+
+	if n := db.pinned[hash]; n != nil {
+		return n.blob
+	}
+	return nil
+}
+
+// Pin loads every node of the subtree rooted at the given hash and holds it in
+// memory until the matching Unpin, shielding it from clean cache eviction. The
+// walk stops at the subtree's leaves, so storage tries referenced by account
+// leaves are not pinned along. Pinning an already pinned root is a noop.
+//
+// Note, the whole subtree is kept in memory, so this is only meant for small,
+// frequently accessed tries.
+func (db *Database) Pin(root common.Hash) error {
+	if root == (common.Hash{}) || root == types.EmptyRootHash {
+		return errors.New("cannot pin empty trie")
+	}
+	db.pinLock.RLock()
+	_, ok := db.pins[root]
+	db.pinLock.RUnlock()
+	if ok {
+		return nil
+	}
+	// Collect the subtree outside of the lock, resolving the nodes through the
+	// regular read path so dirty nodes are picked up too.
+	var (
+		hashes []common.Hash
+		blobs  = make(map[common.Hash][]byte)
+		queue  = []common.Hash{root}
+	)
+	for len(queue) > 0 {
+		hash := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		if _, ok := blobs[hash]; ok {
+			continue
+		}
+		enc, err := db.Node(hash)
+		if err != nil {
+			return fmt.Errorf("missing trie node %x: %v", hash, err)
+		}
+		n, err := decodeNode(hash[:], enc)
+		if err != nil {
+			return err
+		}
+		blobs[hash] = enc
+		hashes = append(hashes, hash)
+		queue = appendHashChildren(queue, n)
+	}
+	db.pinLock.Lock()
+	defer db.pinLock.Unlock()
+
+	if _, ok := db.pins[root]; ok {
+		return nil
+	}
+	for _, hash := range hashes {
+		if n := db.pinned[hash]; n != nil {
+			n.refs++
+			continue
+		}
+		db.pinned[hash] = &pinnedNode{blob: blobs[hash], refs: 1}
+	}
+	db.pins[root] = hashes
+	return nil
+}
+
+// Unpin releases a subtree previously pinned via Pin. Nodes shared with other
+// pinned subtrees are retained until the last of them is released. Unpinning a
+// root which is not pinned is a noop.
+func (db *Database) Unpin(root common.Hash) {
+	db.pinLock.Lock()
+	defer db.pinLock.Unlock()
+
+	hashes, ok := db.pins[root]
+	if !ok {
+		return
+	}
+	for _, hash := range hashes {
+		n := db.pinned[hash]
+		if n.refs--; n.refs == 0 {
+			delete(db.pinned, hash)
+		}
+	}
+	delete(db.pins, root)
+}
+
+// appendHashChildren appends the hashes of all the externally stored children
+// of the given node to the list, descending into embedded nodes.
+func appendHashChildren(hashes []common.Hash, n node) []common.Hash {
+	switch n := n.(type) {
+	case *shortNode:
+		return appendHashChildren(hashes, n.Val)
+	case *fullNode:
+		for i := 0; i < 16; i++ {
+			hashes = appendHashChildren(hashes, n.Children[i])
+		}
+		return hashes
+	case hashNode:
+		return append(hashes, common.BytesToHash(n))
+	default:
+		return hashes
+	}
+}
+
 // Nodes retrieves the hashes of all the nodes cached within the memory database.
 // This method is extremely expensive and should only be used to validate internal
 // states in test code.
@@ -481,6 +639,7 @@ func (db *Database) Dereference(root common.Hash) {
 	memcacheGCTimeTimer.Update(time.Since(start))
 	memcacheGCSizeMeter.Mark(int64(storage - db.dirtiesSize))
 	memcacheGCNodesMeter.Mark(int64(nodes - len(db.dirties)))
+	db.updateGauges()
 
 	log.Debug("Dereferenced trie from memory database", "nodes", nodes-len(db.dirties), "size", storage-db.dirtiesSize, "time", time.Since(start),
 		"gcnodes", db.gcnodes, "gcsize", db.gcsize, "gctime", db.gctime, "livenodes", len(db.dirties), "livesize", db.dirtiesSize)
@@ -611,10 +770,12 @@ func (db *Database) Cap(limit common.StorageSize) error {
 	db.flushnodes += uint64(nodes - len(db.dirties))
 	db.flushsize += storage - db.dirtiesSize
 	db.flushtime += time.Since(start)
+	db.persisted += uint64(nodes - len(db.dirties))
 
 	memcacheFlushTimeTimer.Update(time.Since(start))
 	memcacheFlushSizeMeter.Mark(int64(storage - db.dirtiesSize))
 	memcacheFlushNodesMeter.Mark(int64(nodes - len(db.dirties)))
+	db.updateGauges()
 
 	log.Debug("Persisted nodes from memory database", "nodes", nodes-len(db.dirties), "size", storage-db.dirtiesSize, "time", time.Since(start),
 		"flushnodes", db.flushnodes, "flushsize", db.flushsize, "flushtime", db.flushtime, "livenodes", len(db.dirties), "livesize", db.dirtiesSize)
@@ -664,9 +825,12 @@ func (db *Database) Commit(node common.Hash, report bool) error {
 	batch.Reset()
 
 	// Reset the storage counters and bumped metrics
+	db.persisted += uint64(nodes - len(db.dirties))
+
 	memcacheCommitTimeTimer.Update(time.Since(start))
 	memcacheCommitSizeMeter.Mark(int64(storage - db.dirtiesSize))
 	memcacheCommitNodesMeter.Mark(int64(nodes - len(db.dirties)))
+	db.updateGauges()
 
 	logger := log.Info
 	if !report {
@@ -799,6 +963,9 @@ func (db *Database) Update(nodes *MergedNodeSet) error {
 	// to an account trie leaf.
 	if set, present := nodes.sets[common.Hash{}]; present {
 		for _, n := range set.leaves {
+			if n.embedded {
+				continue // accounts are never small enough to be embedded
+			}
 			var account types.StateAccount
 			if err := rlp.DecodeBytes(n.blob, &account); err != nil {
 				return err
@@ -808,6 +975,7 @@ func (db *Database) Update(nodes *MergedNodeSet) error {
 			}
 		}
 	}
+	db.updateGauges()
 	return nil
 }
 
@@ -817,16 +985,53 @@ func (db *Database) Size() (common.StorageSize, common.StorageSize) {
 	db.lock.RLock()
 	defer db.lock.RUnlock()
 
+	var preimageSize common.StorageSize
+	if db.preimages != nil {
+		preimageSize = db.preimages.size()
+	}
+	return db.dirtySize(), preimageSize
+}
+
+// dirtySize returns the memory consumption of the dirty node cache. The caller
+// must hold the database lock.
+func (db *Database) dirtySize() common.StorageSize {
 	// db.dirtiesSize only contains the useful data in the cache, but when reporting
 	// the total memory consumption, the maintenance metadata is also needed to be
 	// counted.
 	var metadataSize = common.StorageSize((len(db.dirties) - 1) * cachedNodeSize)
 	var metarootRefs = common.StorageSize(len(db.dirties[common.Hash{}].children) * (common.HashLength + 2))
-	var preimageSize common.StorageSize
-	if db.preimages != nil {
-		preimageSize = db.preimages.size()
+	return db.dirtiesSize + db.childrenSize + metadataSize - metarootRefs
+}
+
+// updateGauges refreshes the clean and dirty cache gauges. The caller must hold
+// the database lock.
+func (db *Database) updateGauges() {
+	if db.cleans != nil {
+		var stats fastcache.Stats
+		db.cleans.UpdateStats(&stats)
+		memcacheCleanSizeGauge.Update(int64(stats.BytesSize))
+	}
+	memcacheDirtyNodesGauge.Update(int64(len(db.dirties) - 1))
+	memcacheDirtySizeGauge.Update(int64(db.dirtySize()))
+}
+
+// Stats returns a snapshot of the memory cache statistics of the database,
+// allowing operators to monitor the dirty node pressure ahead of a Cap or
+// Commit.
+func (db *Database) Stats() Stats {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	stats := Stats{
+		DirtyNodes:   len(db.dirties) - 1, // exclude the metaroot
+		DirtySize:    db.dirtySize(),
+		FlushedNodes: db.persisted,
+	}
+	hits, misses := atomic.LoadUint64(&db.cleanHits), atomic.LoadUint64(&db.cleanMisses)
+	if hits+misses > 0 {
+		stats.CleanHitRatio = float64(hits) / float64(hits+misses)
 	}
-	return db.dirtiesSize + db.childrenSize + metadataSize - metarootRefs, preimageSize
+	return stats
 }
 
 // GetReader retrieves a node reader belonging to the given state root.
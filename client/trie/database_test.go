@@ -17,6 +17,7 @@ import (
 
 	"github.com/r5-labs/r5-core/client/common"
 	"github.com/r5-labs/r5-core/client/core/rawdb"
+	"github.com/r5-labs/r5-core/client/metrics"
 )
 
 // Tests that the trie database returns a missing trie node error if attempting
@@ -27,3 +28,149 @@ func TestDatabaseMetarootFetch(t *testing.T) {
 		t.Fatalf("metaroot retrieval succeeded")
 	}
 }
+
+// Tests that the database statistics track the dirty nodes inserted via Update
+// and the nodes flushed to disk on Commit.
+func TestDatabaseStats(t *testing.T) {
+	db := NewDatabaseWithConfig(rawdb.NewMemoryDatabase(), &Config{Cache: 16})
+	if stats := db.Stats(); stats.DirtyNodes != 0 || stats.DirtySize != 0 || stats.FlushedNodes != 0 {
+		t.Fatalf("non-empty stats for fresh database: %+v", stats)
+	}
+	trie := NewEmpty(db)
+	for i := 0; i < 512; i++ {
+		trie.MustUpdate(randBytes(32), randBytes(32))
+	}
+	root, nodes := trie.Commit(false)
+	updated, _ := nodes.Size()
+	if err := db.Update(NewWithNodeSet(nodes)); err != nil {
+		t.Fatalf("failed to update database: %v", err)
+	}
+	stats := db.Stats()
+	if stats.DirtyNodes != updated {
+		t.Fatalf("dirty node count mismatch: have %d, want %d", stats.DirtyNodes, updated)
+	}
+	if size, _ := db.Size(); stats.DirtySize != size {
+		t.Fatalf("dirty size mismatch: have %v, want %v", stats.DirtySize, size)
+	}
+	if err := db.Commit(root, false); err != nil {
+		t.Fatalf("failed to commit database: %v", err)
+	}
+	stats = db.Stats()
+	if stats.DirtyNodes != 0 {
+		t.Fatalf("dirty nodes left after commit: %d", stats.DirtyNodes)
+	}
+	if stats.FlushedNodes != uint64(updated) {
+		t.Fatalf("flushed node count mismatch: have %d, want %d", stats.FlushedNodes, updated)
+	}
+	// Committed nodes are moved into the clean cache, so a reopened database
+	// misses on the first read of the root and hits on the second one.
+	db = NewDatabaseWithConfig(db.diskdb, &Config{Cache: 16})
+	db.Node(root)
+	db.Node(root)
+	if stats := db.Stats(); stats.CleanHitRatio != 0.5 {
+		t.Fatalf("clean cache hit ratio mismatch: have %v, want %v", stats.CleanHitRatio, 0.5)
+	}
+}
+
+// Tests that the cache gauges follow the dirty nodes inserted via Update and
+// their move into the clean cache on Commit.
+func TestDatabaseGauges(t *testing.T) {
+	// The registered gauges are no-ops unless metrics are enabled at startup,
+	// swap in live ones for the duration of the test.
+	cleanSize, dirtyNodes, dirtySize := memcacheCleanSizeGauge, memcacheDirtyNodesGauge, memcacheDirtySizeGauge
+	defer func() {
+		memcacheCleanSizeGauge, memcacheDirtyNodesGauge, memcacheDirtySizeGauge = cleanSize, dirtyNodes, dirtySize
+	}()
+	memcacheCleanSizeGauge, memcacheDirtyNodesGauge, memcacheDirtySizeGauge = new(metrics.StandardGauge), new(metrics.StandardGauge), new(metrics.StandardGauge)
+
+	db := NewDatabaseWithConfig(rawdb.NewMemoryDatabase(), &Config{Cache: 16})
+	trie := NewEmpty(db)
+	for i := 0; i < 512; i++ {
+		trie.MustUpdate(randBytes(32), randBytes(32))
+	}
+	root, nodes := trie.Commit(false)
+	updated, _ := nodes.Size()
+	if err := db.Update(NewWithNodeSet(nodes)); err != nil {
+		t.Fatalf("failed to update database: %v", err)
+	}
+	if have := memcacheDirtyNodesGauge.Value(); have != int64(updated) {
+		t.Fatalf("dirty node gauge mismatch: have %d, want %d", have, updated)
+	}
+	if size, _ := db.Size(); memcacheDirtySizeGauge.Value() != int64(size) {
+		t.Fatalf("dirty size gauge mismatch: have %d, want %d", memcacheDirtySizeGauge.Value(), int64(size))
+	}
+	if have := memcacheCleanSizeGauge.Value(); have != 0 {
+		t.Fatalf("clean size gauge non-zero before commit: %d", have)
+	}
+	if err := db.Commit(root, false); err != nil {
+		t.Fatalf("failed to commit database: %v", err)
+	}
+	if have := memcacheDirtyNodesGauge.Value(); have != 0 {
+		t.Fatalf("dirty node gauge non-zero after commit: %d", have)
+	}
+	if have := memcacheCleanSizeGauge.Value(); have == 0 {
+		t.Fatal("clean size gauge not updated on commit")
+	}
+}
+
+// Tests that nodes of a pinned subtree survive clean cache pressure which evicts
+// everything else, and that they are released again once unpinned.
+func TestDatabasePinning(t *testing.T) {
+	diskdb := rawdb.NewMemoryDatabase()
+	db := NewDatabase(diskdb)
+
+	var roots []common.Hash
+	for i := 0; i < 2; i++ {
+		trie := NewEmpty(db)
+		for j := 0; j < 256; j++ {
+			trie.MustUpdate(randBytes(32), randBytes(32))
+		}
+		root, nodes := trie.Commit(false)
+		if err := db.Update(NewWithNodeSet(nodes)); err != nil {
+			t.Fatalf("failed to update database: %v", err)
+		}
+		if err := db.Commit(root, false); err != nil {
+			t.Fatalf("failed to commit database: %v", err)
+		}
+		roots = append(roots, root)
+	}
+	db = NewDatabaseWithConfig(diskdb, &Config{Cache: 32})
+	if err := db.Pin(roots[0]); err != nil {
+		t.Fatalf("failed to pin trie: %v", err)
+	}
+	// Load the unpinned trie into the clean cache, then flood the cache until
+	// all of it is evicted and wipe the disk so only memory can serve reads.
+	if _, err := db.Node(roots[1]); err != nil {
+		t.Fatalf("failed to load unpinned root: %v", err)
+	}
+	for i := 0; i < 1024*1024; i++ {
+		db.cleans.Set(randBytes(32), randBytes(64))
+	}
+	if db.cleans.Has(roots[1][:]) {
+		t.Fatalf("unpinned root survived cache pressure")
+	}
+	it := diskdb.NewIterator(nil, nil)
+	for it.Next() {
+		diskdb.Delete(it.Key())
+	}
+	it.Release()
+
+	if _, err := db.Node(roots[1]); err == nil {
+		t.Fatalf("unpinned root retrievable after eviction")
+	}
+	trie, err := New(TrieID(roots[0]), db)
+	if err != nil {
+		t.Fatalf("failed to open pinned trie: %v", err)
+	}
+	entries := 0
+	for iter := NewIterator(trie.NodeIterator(nil)); iter.Next(); {
+		entries++
+	}
+	if entries != 256 {
+		t.Fatalf("pinned trie entry count mismatch: have %d, want %d", entries, 256)
+	}
+	db.Unpin(roots[0])
+	if _, err := db.Node(roots[0]); err == nil {
+		t.Fatalf("pinned root retrievable after unpin")
+	}
+}
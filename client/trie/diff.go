@@ -0,0 +1,70 @@
+// Copyright 2025 R5 Labs
+// This file is part of the R5 Core library.
+//
+// This software is provided "as is", without warranty of any kind,
+// express or implied, including but not limited to the warranties
+// of merchantability, fitness for a particular purpose and
+// noninfringement. In no event shall the authors or copyright
+// holders be liable for any claim, damages, or other liability,
+// whether in an action of contract, tort or otherwise, arising
+// from, out of or in connection with the software or the use or
+// other dealings in the software.
+
+package trie
+
+import (
+	"bytes"
+
+	"github.com/r5-labs/r5-core/client/common"
+)
+
+// DiffTries computes the node-level difference between two tries, considering
+// only the nodes stored on their own (embedded nodes are part of their parent).
+// All the returned sets are keyed by node path: deletes holds the nodes only
+// present in the old trie, inserts the nodes only present in the new trie and
+// updates the nodes present in both but with different content. The blobs of
+// deletes and updates are the original values from the old trie, matching the
+// access list tracked during commit, while the inserts carry the new values.
+func DiffTries(old, new *Trie) (deletes, inserts, updates map[string][]byte) {
+	var (
+		nodesA = forHashedNodes(old)
+		nodesB = forHashedNodes(new)
+	)
+	deletes = make(map[string][]byte) // hashed nodes in the old trie but not the new
+	inserts = make(map[string][]byte) // hashed nodes in the new trie but not the old
+	updates = make(map[string][]byte) // hashed nodes in both tries but different value
+
+	for path, blobA := range nodesA {
+		if blobB, ok := nodesB[path]; ok {
+			if bytes.Equal(blobA, blobB) {
+				continue
+			}
+			updates[path] = blobA
+			continue
+		}
+		deletes[path] = blobA
+	}
+	for path, blobB := range nodesB {
+		if _, ok := nodesA[path]; ok {
+			continue
+		}
+		inserts[path] = blobB
+	}
+	return deletes, inserts, updates
+}
+
+// forHashedNodes collects the blobs of all the non-embedded nodes of the trie,
+// keyed by their path.
+func forHashedNodes(tr *Trie) map[string][]byte {
+	var (
+		it    = tr.NodeIterator(nil)
+		nodes = make(map[string][]byte)
+	)
+	for it.Next(true) {
+		if it.Hash() == (common.Hash{}) {
+			continue
+		}
+		nodes[string(it.Path())] = common.CopyBytes(it.NodeBlob())
+	}
+	return nodes
+}
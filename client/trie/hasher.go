@@ -20,6 +20,11 @@ import (
 	"golang.org/x/crypto/sha3"
 )
 
+// defaultParallelHashThreshold is the number of unhashed leaves at or above
+// which a trie hashes the children of its root node concurrently, unless it
+// was configured otherwise via SetParallelHash.
+const defaultParallelHashThreshold = 100
+
 // hasher is a type used for the trie Hash operation. A hasher has some
 // internal preallocated temp space
 type hasher struct {
@@ -31,6 +31,7 @@ type NodeResolver func(owner common.Hash, path []byte, hash common.Hash) []byte
 // Iterator is a key-value trie iterator that traverses a Trie.
 type Iterator struct {
 	nodeIt NodeIterator
+	prefix []byte // Key prefix the iteration is restricted to, nil for the full trie
 
 	Key   []byte // Current data key on which the iterator is positioned on
 	Value []byte // Current data value on which the iterator is positioned on
@@ -50,7 +51,12 @@ func NewIterator(it NodeIterator) *Iterator {
 func (it *Iterator) Next() bool {
 	for it.nodeIt.Next(true) {
 		if it.nodeIt.Leaf() {
-			it.Key = it.nodeIt.LeafKey()
+			key := it.nodeIt.LeafKey()
+			if it.prefix != nil && !bytes.HasPrefix(key, it.prefix) {
+				// Entries sharing the prefix are visited contiguously, we're done
+				break
+			}
+			it.Key = key
 			it.Value = it.nodeIt.LeafBlob()
 			return true
 		}
@@ -277,6 +283,21 @@ func (it *nodeIterator) Next(descend bool) bool {
 	return true
 }
 
+// skipMissing steps the iterator over the child that failed to resolve with a
+// missing node error, allowing the iteration to continue with its siblings. It
+// returns false if the failure can't be skipped, e.g. if the root is missing.
+func (it *nodeIterator) skipMissing() bool {
+	if _, ok := it.err.(*MissingNodeError); !ok || len(it.stack) == 0 {
+		return false
+	}
+	// The parent of the missing node is still on top of the stack, with its index
+	// pointing right before the failing child. Moving it forward is enough for
+	// the next peek to pick up the following sibling.
+	it.stack[len(it.stack)-1].index++
+	it.err = nil
+	return true
+}
+
 func (it *nodeIterator) seek(prefix []byte) error {
 	// The path we're looking for is the hex encoded key without terminator.
 	key := keybytesToHex(prefix)
@@ -196,6 +196,31 @@ func TestIteratorSeek(t *testing.T) {
 	}
 }
 
+func TestPrefixIterator(t *testing.T) {
+	trie := NewEmpty(NewDatabase(rawdb.NewMemoryDatabase()))
+	for _, val := range testdata1 {
+		trie.MustUpdate([]byte(val.k), []byte(val.v))
+	}
+	tests := []struct {
+		prefix string
+		want   []kvs
+	}{
+		{"ba", testdata1[:4]},   // all the "bar*" keys
+		{"bar", testdata1[:4]},  // prefix matching a key itself
+		{"barb", testdata1[:1]}, // single leaf
+		{"f", testdata1[4:]},    // everything beyond the "b" keys
+		{"foo", testdata1[5:]},  // trailing keys
+		{"", testdata1},         // whole trie
+		{"bax", nil},            // no matching keys between existing ones
+		{"z", nil},              // prefix beyond the end
+	}
+	for _, test := range tests {
+		if err := checkIteratorOrder(test.want, trie.PrefixIterator([]byte(test.prefix))); err != nil {
+			t.Errorf("prefix %q: %v", test.prefix, err)
+		}
+	}
+}
+
 func checkIteratorOrder(want []kvs, it *Iterator) error {
 	for it.Next() {
 		if len(want) == 0 {
@@ -307,6 +332,50 @@ func TestUnionIterator(t *testing.T) {
 	}
 }
 
+func TestIteratorPathScheme(t *testing.T) {
+	tr := NewEmpty(NewDatabase(rawdb.NewMemoryDatabase()))
+	for _, val := range testdata1 {
+		tr.MustUpdate([]byte(val.k), []byte(val.v))
+	}
+	root, nodes := tr.Commit(false)
+
+	// Store the committed nodes keyed by their path instead of their hash.
+	diskdb := rawdb.NewMemoryDatabase()
+	nodes.forEachWithOrder(func(path string, n *memoryNode) {
+		rawdb.WriteTrieNode(diskdb, common.Hash{}, []byte(path), n.hash, n.rlp(), rawdb.PathScheme)
+	})
+	tr, err := New(TrieID(root), NewPathNodeReader(diskdb))
+	if err != nil {
+		t.Fatalf("failed to open path-based trie: %v", err)
+	}
+	found := make(map[string]string)
+	it := NewIterator(tr.NodeIterator(nil))
+	for it.Next() {
+		found[string(it.Key)] = string(it.Value)
+	}
+	if it.Err != nil {
+		t.Fatalf("iteration failed: %v", it.Err)
+	}
+	for _, kv := range testdata1 {
+		if found[kv.k] != kv.v {
+			t.Errorf("iterator value mismatch for %s: got %q want %q", kv.k, found[kv.k], kv.v)
+		}
+	}
+	if len(found) != len(testdata1) {
+		t.Errorf("iterator count mismatch: got %d want %d", len(found), len(testdata1))
+	}
+	checkIteratorNoDups(t, tr.NodeIterator(nil), nil)
+
+	// Nodes stored by hash only must not be resolvable by path.
+	hashdb := rawdb.NewMemoryDatabase()
+	nodes.forEachWithOrder(func(path string, n *memoryNode) {
+		rawdb.WriteTrieNode(hashdb, common.Hash{}, []byte(path), n.hash, n.rlp(), rawdb.HashScheme)
+	})
+	if _, err := New(TrieID(root), NewPathNodeReader(hashdb)); err == nil {
+		t.Fatal("opened hash-based trie with the path-based reader")
+	}
+}
+
 func TestIteratorNoDups(t *testing.T) {
 	tr := NewEmpty(NewDatabase(rawdb.NewMemoryDatabase()))
 	for _, val := range testdata1 {
@@ -458,6 +527,66 @@ func testIteratorContinueAfterSeekError(t *testing.T, memonly bool) {
 	}
 }
 
+// Tests that CollectMissing reports every node deleted from the database, not
+// just the first one hit by the iteration.
+func TestCollectMissing(t *testing.T) {
+	diskdb := rawdb.NewMemoryDatabase()
+	triedb := NewDatabase(diskdb)
+
+	tr := NewEmpty(triedb)
+	for i := 0; i < 1000; i++ {
+		key := crypto.Keccak256(binary.BigEndian.AppendUint64(nil, uint64(i)))
+		tr.MustUpdate(key, key)
+	}
+	root, nodes := tr.Commit(false)
+	triedb.Update(NewWithNodeSet(nodes))
+	triedb.Commit(root, false)
+
+	tr, _ = New(TrieID(root), triedb)
+	if missing, err := tr.CollectMissing(); err != nil || len(missing) != 0 {
+		t.Fatalf("intact trie reported missing nodes: %v, err %v", missing, err)
+	}
+	// Delete every third standalone node at depth two. None of them is an
+	// ancestor of another, so all of them must be reported.
+	var (
+		wantHashes []common.Hash
+		wantPaths  [][]byte
+		count      int
+	)
+	it := tr.NodeIterator(nil)
+	for it.Next(true) {
+		if len(it.Path()) != 2 || it.Hash() == (common.Hash{}) {
+			continue
+		}
+		if count++; count%3 == 0 {
+			wantHashes = append(wantHashes, it.Hash())
+			wantPaths = append(wantPaths, common.CopyBytes(it.Path()))
+		}
+	}
+	if len(wantHashes) < 2 {
+		t.Fatalf("too few nodes selected for deletion: %d", len(wantHashes))
+	}
+	for _, hash := range wantHashes {
+		diskdb.Delete(hash[:])
+	}
+	tr, _ = New(TrieID(root), NewDatabase(diskdb))
+	missing, err := tr.CollectMissing()
+	if err != nil {
+		t.Fatalf("failed to collect missing nodes: %v", err)
+	}
+	if len(missing) != len(wantHashes) {
+		t.Fatalf("missing node count mismatch: have %d, want %d", len(missing), len(wantHashes))
+	}
+	for i, m := range missing {
+		if m.NodeHash != wantHashes[i] {
+			t.Errorf("missing node %d: hash mismatch: have %x, want %x", i, m.NodeHash, wantHashes[i])
+		}
+		if !bytes.Equal(m.Path, wantPaths[i]) {
+			t.Errorf("missing node %d: path mismatch: have %x, want %x", i, m.Path, wantPaths[i])
+		}
+	}
+}
+
 func checkIteratorNoDups(t *testing.T, it NodeIterator, seen map[string]bool) int {
 	if seen == nil {
 		seen = make(map[string]bool)
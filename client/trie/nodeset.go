@@ -19,6 +19,8 @@ import (
 	"strings"
 
 	"github.com/r5-labs/r5-core/client/common"
+	"github.com/r5-labs/r5-core/client/crypto"
+	"github.com/r5-labs/r5-core/client/rlp"
 )
 
 // memoryNode is all the information we know about a single cached trie node
@@ -92,6 +94,7 @@ type NodeSet struct {
 	owner   common.Hash            // the identifier of the trie
 	nodes   map[string]*memoryNode // the set of dirty nodes(inserted, updated, deleted)
 	leaves  []*leaf                // the list of dirty leaves
+	collect bool                   // whether the dirty leaves were collected
 	updates int                    // the count of updated and inserted nodes
 	deletes int                    // the count of deleted nodes
 
@@ -143,6 +146,21 @@ func (set *NodeSet) addLeaf(node *leaf) {
 	set.leaves = append(set.leaves, node)
 }
 
+// Leaves returns the dirty leaves collected during commit, keyed by their trie
+// key, along with whether leaf collection was requested at all. Both the leaves
+// stored as standalone nodes and the small ones embedded in their parent node
+// are collected. A nil set, as returned for a clean trie, holds no leaves.
+func (set *NodeSet) Leaves() (map[string][]byte, bool) {
+	if set == nil {
+		return nil, false
+	}
+	leaves := make(map[string][]byte, len(set.leaves))
+	for _, n := range set.leaves {
+		leaves[string(n.key)] = n.blob
+	}
+	return leaves, set.collect
+}
+
 // Size returns the number of dirty nodes in set.
 func (set *NodeSet) Size() (int, int) {
 	return set.updates, set.deletes
@@ -185,6 +203,114 @@ func (set *NodeSet) Summary() string {
 	return out.String()
 }
 
+// encodedNode is the serialized form of a dirty node, the hash and blob are
+// both empty for deleted nodes.
+type encodedNode struct {
+	Path []byte
+	Hash common.Hash
+	Blob []byte
+}
+
+// encodedOrigin is the serialized form of an accessed node's original value.
+type encodedOrigin struct {
+	Path []byte
+	Blob []byte
+}
+
+// encodedLeaf is the serialized form of a dirty leaf.
+type encodedLeaf struct {
+	Key      []byte
+	Blob     []byte
+	Parent   common.Hash
+	Embedded bool `rlp:"optional"`
+}
+
+// encodedNodeSet is the serialized form of a node set.
+type encodedNodeSet struct {
+	Owner   common.Hash
+	Nodes   []encodedNode
+	Collect bool
+	Leaves  []encodedLeaf
+	Origin  []encodedOrigin
+}
+
+// MarshalBinary encodes the node set into an RLP blob, so that the dirty nodes
+// of a commit can be transported and applied to another database.
+func (set *NodeSet) MarshalBinary() ([]byte, error) {
+	enc := encodedNodeSet{Owner: set.owner, Collect: set.collect}
+	set.forEachWithOrder(func(path string, n *memoryNode) {
+		if n.isDeleted() {
+			enc.Nodes = append(enc.Nodes, encodedNode{Path: []byte(path)})
+			return
+		}
+		enc.Nodes = append(enc.Nodes, encodedNode{Path: []byte(path), Hash: n.hash, Blob: n.rlp()})
+	})
+	for _, n := range set.leaves {
+		enc.Leaves = append(enc.Leaves, encodedLeaf{Key: n.key, Blob: n.blob, Parent: n.parent, Embedded: n.embedded})
+	}
+	paths := make([]string, 0, len(set.accessList))
+	for path := range set.accessList {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		enc.Origin = append(enc.Origin, encodedOrigin{Path: []byte(path), Blob: set.accessList[path]})
+	}
+	return rlp.EncodeToBytes(&enc)
+}
+
+// UnmarshalBinary decodes a node set previously encoded with MarshalBinary,
+// replacing the content of the set.
+func (set *NodeSet) UnmarshalBinary(blob []byte) error {
+	var dec encodedNodeSet
+	if err := rlp.DecodeBytes(blob, &dec); err != nil {
+		return err
+	}
+	*set = *NewNodeSet(dec.Owner, make(map[string][]byte))
+	for _, n := range dec.Nodes {
+		if n.Hash == (common.Hash{}) {
+			set.markDeleted(n.Path)
+			continue
+		}
+		if crypto.Keccak256Hash(n.Blob) != n.Hash {
+			return fmt.Errorf("node %x hash mismatch, want %x", n.Path, n.Hash)
+		}
+		decoded, err := decodeNode(n.Hash.Bytes(), n.Blob)
+		if err != nil {
+			return fmt.Errorf("invalid node %x: %v", n.Path, err)
+		}
+		set.markUpdated(n.Path, &memoryNode{hash: n.Hash, size: uint16(len(n.Blob)), node: collapseNode(decoded)})
+	}
+	set.collect = dec.Collect
+	for _, n := range dec.Leaves {
+		set.addLeaf(&leaf{key: n.Key, blob: n.Blob, parent: n.Parent, embedded: n.Embedded})
+	}
+	for _, n := range dec.Origin {
+		set.accessList[string(n.Path)] = n.Blob
+	}
+	return nil
+}
+
+// collapseNode converts a node decoded from its RLP encoding into the collapsed
+// form produced by the committer, with the keys of short nodes in compact
+// encoding and all the internal caches discarded.
+func collapseNode(n node) node {
+	switch n := n.(type) {
+	case *shortNode:
+		return &rawShortNode{Key: hexToCompact(n.Key), Val: collapseNode(n.Val)}
+	case *fullNode:
+		node := rawFullNode(n.Children)
+		for i := 0; i < len(node); i++ {
+			if node[i] != nil {
+				node[i] = collapseNode(node[i])
+			}
+		}
+		return node
+	default:
+		return n
+	}
+}
+
 // MergedNodeSet represents a merged dirty node set for a group of tries.
 type MergedNodeSet struct {
 	sets map[common.Hash]*NodeSet
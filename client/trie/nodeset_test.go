@@ -0,0 +1,124 @@
+// Copyright 2025 R5 Labs
+// This file is part of the R5 Core library.
+//
+// This software is provided "as is", without warranty of any kind,
+// express or implied, including but not limited to the warranties
+// of merchantability, fitness for a particular purpose and
+// noninfringement. In no event shall the authors or copyright
+// holders be liable for any claim, damages, or other liability,
+// whether in an action of contract, tort or otherwise, arising
+// from, out of or in connection with the software or the use or
+// other dealings in the software.
+
+package trie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/r5-labs/r5-core/client/core/rawdb"
+)
+
+func TestNodeSetBinaryRoundtrip(t *testing.T) {
+	var (
+		srcdb = NewDatabase(rawdb.NewMemoryDatabase())
+		dstdb = NewDatabase(rawdb.NewMemoryDatabase())
+		trie  = NewEmpty(srcdb)
+		vals  = make(map[string][]byte)
+	)
+	for i := 0; i < 500; i++ {
+		key, val := randBytes(32), randBytes(20)
+		if i%10 == 0 {
+			val = randBytes(1) // Produce some embedded nodes too
+		}
+		trie.MustUpdate(key, val)
+		vals[string(key)] = val
+	}
+	// Ship two generations of the trie, the second one deleting entries.
+	for gen := 0; gen < 2; gen++ {
+		root, nodes := trie.Commit(false)
+		blob, err := nodes.MarshalBinary()
+		if err != nil {
+			t.Fatalf("gen %d: failed to encode node set: %v", gen, err)
+		}
+		dec := new(NodeSet)
+		if err := dec.UnmarshalBinary(blob); err != nil {
+			t.Fatalf("gen %d: failed to decode node set: %v", gen, err)
+		}
+		updates, deletes := dec.Size()
+		if wantUpdates, wantDeletes := nodes.Size(); updates != wantUpdates || deletes != wantDeletes {
+			t.Fatalf("gen %d: size mismatch: have (%d, %d), want (%d, %d)", gen, updates, deletes, wantUpdates, wantDeletes)
+		}
+		if len(dec.leaves) != len(nodes.leaves) {
+			t.Fatalf("gen %d: leaf count mismatch: have %d, want %d", gen, len(dec.leaves), len(nodes.leaves))
+		}
+		for path, n := range nodes.nodes {
+			have, ok := dec.nodes[path]
+			switch {
+			case !ok:
+				t.Fatalf("gen %d: node %x missing", gen, path)
+			case n.isDeleted() != have.isDeleted():
+				t.Fatalf("gen %d: node %x deletion mismatch", gen, path)
+			case !n.isDeleted() && !bytes.Equal(have.rlp(), n.rlp()):
+				t.Fatalf("gen %d: node %x mismatch", gen, path)
+			}
+		}
+		// Apply the shipped nodes on both sides and ensure the roots match.
+		if err := srcdb.Update(NewWithNodeSet(nodes)); err != nil {
+			t.Fatalf("gen %d: failed to update source: %v", gen, err)
+		}
+		if err := dstdb.Update(NewWithNodeSet(dec)); err != nil {
+			t.Fatalf("gen %d: failed to update destination: %v", gen, err)
+		}
+		if err := dstdb.Commit(root, false); err != nil {
+			t.Fatalf("gen %d: failed to commit destination: %v", gen, err)
+		}
+		copied, err := New(TrieID(root), NewDatabase(dstdb.diskdb))
+		if err != nil {
+			t.Fatalf("gen %d: failed to open shipped trie: %v", gen, err)
+		}
+		if have := copied.Hash(); have != root {
+			t.Fatalf("gen %d: root mismatch: have %x, want %x", gen, have, root)
+		}
+		for key, val := range vals {
+			if have := copied.MustGet([]byte(key)); !bytes.Equal(have, val) {
+				t.Fatalf("gen %d: value mismatch for %x: have %x, want %x", gen, key, have, val)
+			}
+		}
+		// Delete a batch of entries for the next generation.
+		trie, _ = New(TrieID(root), srcdb)
+		for key := range vals {
+			if len(vals) <= 250 {
+				break
+			}
+			trie.MustDelete([]byte(key))
+			delete(vals, key)
+		}
+	}
+}
+
+func TestNodeSetUnmarshalInvalid(t *testing.T) {
+	trie := NewEmpty(NewDatabase(rawdb.NewMemoryDatabase()))
+	for _, val := range testdata1 {
+		trie.MustUpdate([]byte(val.k), []byte(val.v))
+	}
+	_, nodes := trie.Commit(false)
+
+	// Tamper with a node blob and ensure the hash check rejects it.
+	for path, n := range nodes.nodes {
+		blob := n.rlp()
+		blob[len(blob)-1]++
+		nodes.nodes[path] = &memoryNode{hash: n.hash, size: n.size, node: rawNode(blob)}
+		break
+	}
+	blob, err := nodes.MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to encode node set: %v", err)
+	}
+	if err := new(NodeSet).UnmarshalBinary(blob); err == nil {
+		t.Fatal("decoded tampered node set")
+	}
+	if err := new(NodeSet).UnmarshalBinary([]byte{0x01, 0x02}); err == nil {
+		t.Fatal("decoded garbage node set")
+	}
+}
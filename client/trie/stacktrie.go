@@ -528,3 +528,10 @@ func (st *StackTrie) Commit() (h common.Hash, err error) {
 	st.writeFn(st.owner, nil, h, st.val)
 	return h, nil
 }
+
+// DeriveReceiptsRoot computes the receipts root of a block, as stored in the
+// header's ReceiptHash field. The root is derived with a stack trie, so memory
+// usage stays constant regardless of the number of receipts.
+func DeriveReceiptsRoot(receipts types.Receipts) common.Hash {
+	return types.DeriveSha(receipts, NewStackTrie(nil))
+}
@@ -19,6 +19,7 @@ import (
 
 	"github.com/r5-labs/r5-core/client/common"
 	"github.com/r5-labs/r5-core/client/core/rawdb"
+	"github.com/r5-labs/r5-core/client/core/types"
 	"github.com/r5-labs/r5-core/client/crypto"
 )
 
@@ -386,3 +387,29 @@ func TestStacktrieSerialization(t *testing.T) {
 		t.Fatalf("have %#x want %#x", have, want)
 	}
 }
+
+func TestDeriveReceiptsRoot(t *testing.T) {
+	for _, n := range []int{0, 1, 300} {
+		receipts := make(types.Receipts, n)
+		for i := range receipts {
+			receipts[i] = &types.Receipt{
+				Type:              uint8(i % 3),
+				Status:            uint64(i % 2),
+				CumulativeGasUsed: uint64(21000 * (i + 1)),
+				Logs: []*types.Log{{
+					Address: common.BytesToAddress([]byte{byte(i)}),
+					Topics:  []common.Hash{common.BigToHash(big.NewInt(int64(i)))},
+					Data:    bytes.Repeat([]byte{byte(i)}, i%64),
+				}},
+			}
+			receipts[i].Bloom = types.CreateBloom(types.Receipts{receipts[i]})
+		}
+		want := types.DeriveSha(receipts, NewEmpty(NewDatabase(rawdb.NewMemoryDatabase())))
+		if have := DeriveReceiptsRoot(receipts); have != want {
+			t.Errorf("%d receipts: root mismatch: have %x, want %x", n, have, want)
+		}
+	}
+	if have := DeriveReceiptsRoot(nil); have != types.EmptyReceiptsHash {
+		t.Errorf("empty receipts root mismatch: have %x, want %x", have, types.EmptyReceiptsHash)
+	}
+}
@@ -13,7 +13,6 @@
 package trie
 
 import (
-	"bytes"
 	"testing"
 
 	"github.com/r5-labs/r5-core/client/common"
@@ -306,47 +305,6 @@ func iterNodes(db *Database, root common.Hash) map[string][]byte {
 	return forNodes(tr)
 }
 
-func forHashedNodes(tr *Trie) map[string][]byte {
-	var (
-		it    = tr.NodeIterator(nil)
-		nodes = make(map[string][]byte)
-	)
-	for it.Next(true) {
-		if it.Hash() == (common.Hash{}) {
-			continue
-		}
-		nodes[string(it.Path())] = common.CopyBytes(it.NodeBlob())
-	}
-	return nodes
-}
-
-func diffTries(trieA, trieB *Trie) (map[string][]byte, map[string][]byte, map[string][]byte) {
-	var (
-		nodesA = forHashedNodes(trieA)
-		nodesB = forHashedNodes(trieB)
-		inA    = make(map[string][]byte) // hashed nodes in trie a but not b
-		inB    = make(map[string][]byte) // hashed nodes in trie b but not a
-		both   = make(map[string][]byte) // hashed nodes in both tries but different value
-	)
-	for path, blobA := range nodesA {
-		if blobB, ok := nodesB[path]; ok {
-			if bytes.Equal(blobA, blobB) {
-				continue
-			}
-			both[path] = blobA
-			continue
-		}
-		inA[path] = blobA
-	}
-	for path, blobB := range nodesB {
-		if _, ok := nodesA[path]; ok {
-			continue
-		}
-		inB[path] = blobB
-	}
-	return inA, inB, both
-}
-
 func setKeys(set map[string][]byte) map[string]struct{} {
 	keys := make(map[string]struct{})
 	for k := range set {
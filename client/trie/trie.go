@@ -39,12 +39,21 @@ type Trie struct {
 	// actually unhashed nodes.
 	unhashed int
 
+	// parallelHash is the number of unhashed leaves at or above which the
+	// children of the root branch node are hashed concurrently. Zero selects
+	// the default threshold, a negative value disables concurrent hashing.
+	parallelHash int
+
 	// reader is the handler trie can retrieve nodes from.
 	reader *trieReader
 
 	// tracer is the tool to track the trie changes.
 	// It will be reset after each commit operation.
 	tracer *tracer
+
+	// sharedTracer is set if the tracer is shared with a snapshot of the trie
+	// and must be replaced by a private copy before it's modified.
+	sharedTracer bool
 }
 
 // newFlag returns the cache flag value for a newly created node.
@@ -55,12 +64,62 @@ func (t *Trie) newFlag() nodeFlag {
 // Copy returns a copy of Trie.
 func (t *Trie) Copy() *Trie {
 	return &Trie{
-		root:     t.root,
-		owner:    t.owner,
-		unhashed: t.unhashed,
-		reader:   t.reader,
-		tracer:   t.tracer.copy(),
+		root:         t.root,
+		owner:        t.owner,
+		unhashed:     t.unhashed,
+		parallelHash: t.parallelHash,
+		reader:       t.reader,
+		tracer:       t.tracer.copy(),
+	}
+}
+
+// Snapshot returns a copy-on-write view of the trie. The snapshot shares all the
+// nodes and the change tracking with the parent, and only diverges from it on
+// write: modifications of either one are never visible in the other, so their
+// roots stay independent. Unlike Copy, creating a snapshot takes constant time.
+//
+// Trie nodes are never mutated in place, so the parent and the snapshot can be
+// used concurrently from different goroutines once the snapshot is created.
+// Snapshot itself must not be called concurrently with other trie operations.
+func (t *Trie) Snapshot() *Trie {
+	t.sharedTracer = true
+	return &Trie{
+		root:         t.root,
+		owner:        t.owner,
+		unhashed:     t.unhashed,
+		parallelHash: t.parallelHash,
+		reader:       t.reader,
+		tracer:       t.tracer,
+		sharedTracer: true,
+	}
+}
+
+// ownTracer returns the tracer of the trie, replacing it with a private copy
+// first if it's shared with a snapshot.
+func (t *Trie) ownTracer() *tracer {
+	if t.sharedTracer {
+		t.tracer, t.sharedTracer = t.tracer.copy(), false
+	}
+	return t.tracer
+}
+
+// resetTracer clears the changes tracked by the trie, detaching it from any
+// tracer shared with a snapshot.
+func (t *Trie) resetTracer() {
+	if t.sharedTracer {
+		t.tracer, t.sharedTracer = newTracer(), false
+		return
 	}
+	t.tracer.reset()
+}
+
+// SetParallelHash configures concurrent hashing for the trie. Once at least
+// threshold leaves have been modified since the last hash, Hash fans out the
+// 16 children of the root branch node across goroutines and joins them before
+// computing the root. The result is identical to the serial hasher. A zero
+// threshold restores the default, a negative one always hashes serially.
+func (t *Trie) SetParallelHash(threshold int) {
+	t.parallelHash = threshold
 }
 
 // New creates the trie instance with provided trie id and the read-only
@@ -101,6 +160,41 @@ func (t *Trie) NodeIterator(start []byte) NodeIterator {
 	return newNodeIterator(t, start)
 }
 
+// CollectMissing walks the whole trie and returns all the nodes that are absent
+// from the backing database, in iteration order. Unlike the other operations it
+// doesn't stop at the first missing node, rather skips the unreachable subtrie
+// and carries on with the rest. An error is returned if the root itself is
+// missing or if a node fails for any other reason.
+func (t *Trie) CollectMissing() ([]*MissingNodeError, error) {
+	var (
+		missing []*MissingNodeError
+		it      = newNodeIterator(t, nil).(*nodeIterator)
+	)
+	for {
+		for it.Next(true) {
+		}
+		if it.Error() == nil {
+			return missing, nil
+		}
+		err, ok := it.err.(*MissingNodeError)
+		if !ok || !it.skipMissing() {
+			return missing, it.Error()
+		}
+		// The path may alias the iterator's internal buffer, detach it.
+		err.Path = common.CopyBytes(err.Path)
+		missing = append(missing, err)
+	}
+}
+
+// PrefixIterator returns a key-value iterator over the entries of the trie whose
+// keys start with the given prefix, in ascending key order. The iteration stops
+// as soon as a key no longer shares the prefix.
+func (t *Trie) PrefixIterator(prefix []byte) *Iterator {
+	it := NewIterator(t.NodeIterator(prefix))
+	it.prefix = common.CopyBytes(prefix)
+	return it
+}
+
 // MustGet is a wrapper of Get and will omit any encountered error but just
 // print out an error message.
 func (t *Trie) MustGet(key []byte) []byte {
@@ -327,7 +421,7 @@ func (t *Trie) insert(n node, prefix, key []byte, value node) (bool, node, error
 		// New branch node is created as a child of the original short node.
 		// Track the newly inserted node in the tracer. The node identifier
 		// passed is the path from the root node.
-		t.tracer.onInsert(append(prefix, key[:matchlen]...))
+		t.ownTracer().onInsert(append(prefix, key[:matchlen]...))
 
 		// Replace it with a short node leading up to the branch.
 		return true, &shortNode{key[:matchlen], branch, t.newFlag()}, nil
@@ -346,7 +440,7 @@ func (t *Trie) insert(n node, prefix, key []byte, value node) (bool, node, error
 		// New short node is created and track it in the tracer. The node identifier
 		// passed is the path from the root node. Note the valueNode won't be tracked
 		// since it's always embedded in its parent.
-		t.tracer.onInsert(prefix)
+		t.ownTracer().onInsert(prefix)
 
 		return true, &shortNode{key, value, t.newFlag()}, nil
 
@@ -406,7 +500,7 @@ func (t *Trie) delete(n node, prefix, key []byte) (bool, node, error) {
 			// The matched short node is deleted entirely and track
 			// it in the deletion set. The same the valueNode doesn't
 			// need to be tracked at all since it's always embedded.
-			t.tracer.onDelete(prefix)
+			t.ownTracer().onDelete(prefix)
 
 			return true, nil, nil // remove n entirely for whole matches
 		}
@@ -422,7 +516,7 @@ func (t *Trie) delete(n node, prefix, key []byte) (bool, node, error) {
 		case *shortNode:
 			// The child shortNode is merged into its parent, track
 			// is deleted as well.
-			t.tracer.onDelete(append(prefix, n.Key...))
+			t.ownTracer().onDelete(append(prefix, n.Key...))
 
 			// Deleting from the subtrie reduced it to another
 			// short node. Merge the nodes to avoid creating a
@@ -488,7 +582,7 @@ func (t *Trie) delete(n node, prefix, key []byte) (bool, node, error) {
 					// Replace the entire full node with the short node.
 					// Mark the original short node as deleted since the
 					// value is embedded into the parent now.
-					t.tracer.onDelete(append(prefix, byte(pos)))
+					t.ownTracer().onDelete(append(prefix, byte(pos)))
 
 					k := append([]byte{byte(pos)}, cnode.Key...)
 					return true, &shortNode{k, cnode.Val, t.newFlag()}, nil
@@ -549,7 +643,7 @@ func (t *Trie) resolveAndTrack(n hashNode, prefix []byte) (node, error) {
 	if err != nil {
 		return nil, err
 	}
-	t.tracer.onRead(prefix, blob)
+	t.ownTracer().onRead(prefix, blob)
 	return mustDecodeNode(n, blob), nil
 }
 
@@ -563,14 +657,15 @@ func (t *Trie) Hash() common.Hash {
 
 // Commit collects all dirty nodes in the trie and replaces them with the
 // corresponding node hash. All collected nodes (including dirty leaves if
-// collectLeaf is true) will be encapsulated into a nodeset for return.
-// The returned nodeset can be nil if the trie is clean (nothing to commit).
-// Once the trie is committed, it's not usable anymore. A new trie must
+// collectLeaf is true, see NodeSet.Leaves) will be encapsulated into a nodeset
+// for return. The returned nodeset can be nil if the trie is clean (nothing to
+// commit). Once the trie is committed, it's not usable anymore. A new trie must
 // be created with new root and updated trie database for following usage
 func (t *Trie) Commit(collectLeaf bool) (common.Hash, *NodeSet) {
-	defer t.tracer.reset()
+	defer t.resetTracer()
 
 	nodes := NewNodeSet(t.owner, t.tracer.accessList)
+	nodes.collect = collectLeaf
 	t.tracer.markDeletions(nodes)
 
 	// Trie is empty and can be classified into two types of situations:
@@ -600,8 +695,12 @@ func (t *Trie) hashRoot() (node, node) {
 	if t.root == nil {
 		return hashNode(types.EmptyRootHash.Bytes()), nil
 	}
-	// If the number of changes is below 100, we let one thread handle it
-	h := newHasher(t.unhashed >= 100)
+	// If the number of changes is below the threshold, we let one thread handle it
+	threshold := t.parallelHash
+	if threshold == 0 {
+		threshold = defaultParallelHashThreshold
+	}
+	h := newHasher(threshold > 0 && t.unhashed >= threshold)
 	defer func() {
 		returnHasherToPool(h)
 		t.unhashed = 0
@@ -615,5 +714,5 @@ func (t *Trie) Reset() {
 	t.root = nil
 	t.owner = common.Hash{}
 	t.unhashed = 0
-	t.tracer.reset()
+	t.resetTracer()
 }
@@ -16,6 +16,8 @@ import (
 	"fmt"
 
 	"github.com/r5-labs/r5-core/client/common"
+	"github.com/r5-labs/r5-core/client/core/rawdb"
+	"github.com/r5-labs/r5-core/client/ethdb"
 )
 
 // Reader wraps the Node and NodeBlob method of a backing trie store.
@@ -100,3 +102,51 @@ func (r *trieReader) nodeBlob(path []byte, hash common.Hash) ([]byte, error) {
 	}
 	return blob, nil
 }
+
+// PathNodeReader is a node reader resolving trie nodes from a disk database laid
+// out in the path-based scheme, where nodes are keyed by their owner and path
+// rather than by their hash.
+type PathNodeReader struct {
+	diskdb ethdb.KeyValueReader
+}
+
+// NewPathNodeReader creates a node reader on top of a path-based disk database.
+func NewPathNodeReader(diskdb ethdb.KeyValueReader) *PathNodeReader {
+	return &PathNodeReader{diskdb: diskdb}
+}
+
+// GetReader returns a reader for accessing the trie nodes of the given state
+// root. As only one version of each node is kept on disk, any state root is
+// served by the same reader.
+func (db *PathNodeReader) GetReader(root common.Hash) Reader {
+	return db
+}
+
+// Node retrieves the trie node stored at the given path, if its hash matches
+// the requested one. No error will be returned if the node is not found.
+func (db *PathNodeReader) Node(owner common.Hash, path []byte, hash common.Hash) (node, error) {
+	blob, _ := db.NodeBlob(owner, path, hash)
+	if len(blob) == 0 {
+		return nil, nil
+	}
+	return decodeNode(hash[:], blob)
+}
+
+// NodeBlob retrieves the RLP-encoded trie node stored at the given path, if its
+// hash matches the requested one. No error will be returned if the node is not
+// found.
+func (db *PathNodeReader) NodeBlob(owner common.Hash, path []byte, hash common.Hash) ([]byte, error) {
+	return rawdb.ReadTrieNode(db.diskdb, owner, path, hash, rawdb.PathScheme), nil
+}
+
+// rawNodeBlob retrieves the RLP-encoded trie node stored at the given path
+// without checking its hash, so integrity checks can tell corrupted nodes apart
+// from missing ones.
+func (db *PathNodeReader) rawNodeBlob(owner common.Hash, path []byte) []byte {
+	if owner == (common.Hash{}) {
+		blob, _ := rawdb.ReadAccountTrieNode(db.diskdb, path)
+		return blob
+	}
+	blob, _ := rawdb.ReadStorageTrieNode(db.diskdb, owner, path)
+	return blob
+}
@@ -399,7 +399,7 @@ func (randTest) Generate(r *rand.Rand, size int) reflect.Value {
 }
 
 func verifyAccessList(old *Trie, new *Trie, set *NodeSet) error {
-	deletes, inserts, updates := diffTries(old, new)
+	deletes, inserts, updates := DiffTries(old, new)
 
 	// Check insertion set
 	for path := range inserts {
@@ -443,6 +443,154 @@ func verifyAccessList(old *Trie, new *Trie, set *NodeSet) error {
 	return nil
 }
 
+func TestCommitCollectLeaves(t *testing.T) {
+	db := NewDatabase(rawdb.NewMemoryDatabase())
+	tr := NewEmpty(db)
+	vals := make(map[string][]byte)
+	for i := 0; i < 200; i++ {
+		// Values are large enough for the leaves to be stored standalone.
+		key, val := randBytes(32), randBytes(32)
+		tr.MustUpdate(key, val)
+		vals[string(key)] = val
+	}
+	// Without collection, no leaves are returned.
+	_, nodes := tr.Copy().Commit(false)
+	if leaves, collected := nodes.Leaves(); collected || len(leaves) != 0 {
+		t.Fatalf("leaves returned without collection: %d, collected %v", len(leaves), collected)
+	}
+	root, nodes := tr.Commit(true)
+	leaves, collected := nodes.Leaves()
+	if !collected {
+		t.Fatal("leaf collection not reported")
+	}
+	if len(leaves) != len(vals) {
+		t.Fatalf("leaf count mismatch: have %d, want %d", len(leaves), len(vals))
+	}
+	for key, val := range vals {
+		if !bytes.Equal(leaves[key], val) {
+			t.Fatalf("leaf %x mismatch: have %x, want %x", key, leaves[key], val)
+		}
+	}
+	db.Update(NewWithNodeSet(nodes))
+
+	// Only the dirty leaves are collected by subsequent commits.
+	tr, _ = New(TrieID(root), db)
+	updated := make(map[string][]byte)
+	for key := range vals {
+		if len(updated) == 10 {
+			break
+		}
+		val := randBytes(32)
+		tr.MustUpdate([]byte(key), val)
+		updated[key] = val
+	}
+	_, nodes = tr.Commit(true)
+	if leaves, _ = nodes.Leaves(); len(leaves) != len(updated) {
+		t.Fatalf("dirty leaf count mismatch: have %d, want %d", len(leaves), len(updated))
+	}
+	for key, val := range updated {
+		if !bytes.Equal(leaves[key], val) {
+			t.Fatalf("dirty leaf %x mismatch: have %x, want %x", key, leaves[key], val)
+		}
+	}
+	// Committing a clean trie yields no nodeset, which holds no leaves.
+	tr, _ = New(TrieID(root), db)
+	if _, nodes = tr.Commit(true); nodes != nil {
+		t.Fatal("nodeset returned for clean trie")
+	}
+	if leaves, collected := nodes.Leaves(); collected || leaves != nil {
+		t.Fatalf("leaves returned from nil nodeset: %d, collected %v", len(leaves), collected)
+	}
+}
+
+func TestCommitCollectEmbeddedLeaves(t *testing.T) {
+	db := NewDatabase(rawdb.NewMemoryDatabase())
+	tr := NewEmpty(db)
+	vals := make(map[string][]byte)
+	for i := 0; i < 64; i++ {
+		// Short keys and values keep the leaves embedded in their parents.
+		key, val := []byte{byte(i * 4), byte(i)}, []byte{byte(i + 1)}
+		tr.MustUpdate(key, val)
+		vals[string(key)] = val
+	}
+	root, nodes := tr.Commit(true)
+	leaves, _ := nodes.Leaves()
+	if len(leaves) != len(vals) {
+		t.Fatalf("leaf count mismatch: have %d, want %d", len(leaves), len(vals))
+	}
+	for key, val := range vals {
+		if !bytes.Equal(leaves[key], val) {
+			t.Fatalf("leaf %x mismatch: have %x, want %x", key, leaves[key], val)
+		}
+	}
+	if err := db.Update(NewWithNodeSet(nodes)); err != nil {
+		t.Fatalf("failed to update database: %v", err)
+	}
+	// Only the modified embedded leaf is collected, not its clean siblings.
+	tr, _ = New(TrieID(root), db)
+	key := []byte{8, 2}
+	tr.MustUpdate(key, []byte{0xff})
+	_, nodes = tr.Commit(true)
+	if leaves, _ = nodes.Leaves(); len(leaves) != 1 || !bytes.Equal(leaves[string(key)], []byte{0xff}) {
+		t.Fatalf("dirty embedded leaves mismatch: have %x", leaves)
+	}
+}
+
+func TestDiffTries(t *testing.T) {
+	db := NewDatabase(rawdb.NewMemoryDatabase())
+	tr := NewEmpty(db)
+	for i := 0; i < 100; i++ {
+		tr.MustUpdate(binary.BigEndian.AppendUint64(nil, uint64(i)), bytes.Repeat([]byte{byte(i)}, 32))
+	}
+	root, nodes := tr.Commit(false)
+	db.Update(NewWithNodeSet(nodes))
+
+	old, _ := New(TrieID(root), db)
+	if deletes, inserts, updates := DiffTries(old, old); len(deletes)+len(inserts)+len(updates) != 0 {
+		t.Fatalf("difference of identical tries: %d deletes, %d inserts, %d updates", len(deletes), len(inserts), len(updates))
+	}
+	// Modify the trie with a mix of deletions, updates and insertions.
+	tr, _ = New(TrieID(root), db)
+	for i := 0; i < 100; i += 3 {
+		tr.MustDelete(binary.BigEndian.AppendUint64(nil, uint64(i)))
+	}
+	for i := 1; i < 100; i += 7 {
+		tr.MustUpdate(binary.BigEndian.AppendUint64(nil, uint64(i)), bytes.Repeat([]byte{0xff}, 32))
+	}
+	for i := 1000; i < 1020; i++ {
+		tr.MustUpdate(binary.BigEndian.AppendUint64(nil, uint64(i)), bytes.Repeat([]byte{byte(i)}, 32))
+	}
+	root, nodes = tr.Commit(false)
+	db.Update(NewWithNodeSet(nodes))
+	new, _ := New(TrieID(root), db)
+
+	// The difference must match the dirty nodes and access list of the commit.
+	deletes, inserts, updates := DiffTries(old, new)
+	if have, want := len(deletes)+len(inserts)+len(updates), len(nodes.nodes); have != want {
+		t.Fatalf("difference size mismatch: have %d, want %d", have, want)
+	}
+	for path, n := range nodes.nodes {
+		origin, accessed := nodes.accessList[path]
+		switch {
+		case n.isDeleted():
+			if blob, ok := deletes[path]; !ok || !bytes.Equal(blob, origin) {
+				t.Errorf("deleted node %x mismatch: have %x, want %x", path, blob, origin)
+			}
+		case accessed:
+			if blob, ok := updates[path]; !ok || !bytes.Equal(blob, origin) {
+				t.Errorf("updated node %x mismatch: have %x, want %x", path, blob, origin)
+			}
+		default:
+			if blob, ok := inserts[path]; !ok || !bytes.Equal(blob, n.rlp()) {
+				t.Errorf("inserted node %x mismatch: have %x, want %x", path, blob, n.rlp())
+			}
+		}
+	}
+	if err := verifyAccessList(old, new, nodes); err != nil {
+		t.Fatalf("access list mismatch: %v", err)
+	}
+}
+
 func runRandTest(rt randTest) bool {
 	var (
 		triedb   = NewDatabase(rawdb.NewMemoryDatabase())
@@ -648,6 +796,57 @@ func BenchmarkHash(b *testing.B) {
 	trie.Hash()
 }
 
+// Tests that concurrent hashing produces exactly the same root as serial
+// hashing, across a range of random tries and incremental updates.
+func TestParallelHash(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		var (
+			serial   = NewEmpty(NewDatabase(rawdb.NewMemoryDatabase()))
+			parallel = NewEmpty(NewDatabase(rawdb.NewMemoryDatabase()))
+		)
+		serial.SetParallelHash(-1)
+		parallel.SetParallelHash(1)
+
+		for round := 0; round < 3; round++ {
+			for n := random.Intn(500) + 1; n > 0; n-- {
+				key, val := randBytes(random.Intn(40)+1), randBytes(random.Intn(64)+1)
+				serial.MustUpdate(key, val)
+				parallel.MustUpdate(key, val)
+			}
+			if have, want := parallel.Hash(), serial.Hash(); have != want {
+				t.Fatalf("trie %d round %d: root mismatch: have %x, want %x", i, round, have, want)
+			}
+		}
+	}
+}
+
+// BenchmarkHashParallel compares the serial and concurrent hashers on account
+// tries of the size produced by large post-block updates.
+func BenchmarkHashParallel(b *testing.B) {
+	for _, size := range []int{10000, 100000} {
+		addresses, accounts := makeAccounts(size)
+		for _, mode := range []struct {
+			name      string
+			threshold int
+		}{{"serial", -1}, {"parallel", 1}} {
+			b.Run(fmt.Sprintf("%dK/%s", size/1000, mode.name), func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					b.StopTimer()
+					trie := NewEmpty(NewDatabase(rawdb.NewMemoryDatabase()))
+					trie.SetParallelHash(mode.threshold)
+					for j := 0; j < len(addresses); j++ {
+						trie.MustUpdate(crypto.Keccak256(addresses[j][:]), accounts[j])
+					}
+					b.StartTimer()
+					trie.Hash()
+				}
+			})
+		}
+	}
+}
+
 // Benchmarks the trie Commit following a Hash. Since the trie caches the result of any operation,
 // we cannot use b.N as the number of hashing rounds, since all rounds apart from
 // the first one will be NOOP. As such, we'll use b.N as the number of account to
@@ -722,6 +921,88 @@ func TestCommitAfterHash(t *testing.T) {
 	}
 }
 
+func TestSnapshotIsolation(t *testing.T) {
+	// Create a committed trie and reopen it, so nodes get resolved from the
+	// database and tracked by both the trie and its snapshot.
+	db := NewDatabase(rawdb.NewMemoryDatabase())
+	trie := NewEmpty(db)
+	for i := 0; i < 100; i++ {
+		trie.MustUpdate([]byte(fmt.Sprintf("key-%03d", i)), []byte(fmt.Sprintf("val-%03d", i)))
+	}
+	root, nodes := trie.Commit(false)
+	db.Update(NewWithNodeSet(nodes))
+
+	trie, _ = New(TrieID(root), db)
+	trie.MustUpdate([]byte("key-000"), []byte("parent"))
+	parentRoot := trie.Hash()
+
+	// Mutate the snapshot and ensure the parent is left intact
+	snap := trie.Snapshot()
+	snap.MustUpdate([]byte("key-001"), []byte("snapshot"))
+	snap.MustDelete([]byte("key-002"))
+	if have := trie.Hash(); have != parentRoot {
+		t.Fatalf("parent root changed by snapshot: have %x, want %x", have, parentRoot)
+	}
+	if have := getString(trie, "key-002"); string(have) != "val-002" {
+		t.Fatalf("parent value changed by snapshot: have %q", have)
+	}
+	snapRoot := snap.Hash()
+	if snapRoot == parentRoot {
+		t.Fatal("snapshot root not changed")
+	}
+	// Mutate the parent and ensure the snapshot is left intact
+	trie.MustUpdate([]byte("key-003"), []byte("parent"))
+	if have := snap.Hash(); have != snapRoot {
+		t.Fatalf("snapshot root changed by parent: have %x, want %x", have, snapRoot)
+	}
+	if have := getString(snap, "key-003"); string(have) != "val-003" {
+		t.Fatalf("snapshot value changed by parent: have %q", have)
+	}
+	// Both tries should commit the same nodes as independently built ones
+	for i, tr := range []*Trie{trie, snap} {
+		want, _ := New(TrieID(root), db)
+		want.MustUpdate([]byte("key-000"), []byte("parent"))
+		if i == 0 {
+			want.MustUpdate([]byte("key-003"), []byte("parent"))
+		} else {
+			want.MustUpdate([]byte("key-001"), []byte("snapshot"))
+			want.MustDelete([]byte("key-002"))
+		}
+		wantRoot, wantSet := want.Commit(false)
+		haveRoot, haveSet := tr.Commit(false)
+		if haveRoot != wantRoot {
+			t.Fatalf("trie %d: root mismatch: have %x, want %x", i, haveRoot, wantRoot)
+		}
+		if !reflect.DeepEqual(haveSet, wantSet) {
+			t.Fatalf("trie %d: committed node set mismatch", i)
+		}
+	}
+}
+
+func BenchmarkSnapshot(b *testing.B) {
+	// Create a large trie with uncommitted changes to snapshot
+	trie := NewEmpty(NewDatabase(rawdb.NewMemoryDatabase()))
+	k := make([]byte, 32)
+	for i := 0; i < 100000; i++ {
+		binary.BigEndian.PutUint64(k, uint64(i))
+		trie.MustUpdate(crypto.Keccak256(k), k)
+	}
+	trie.Hash()
+
+	b.Run("snapshot", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			trie.Snapshot()
+		}
+	})
+	b.Run("copy", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			trie.Copy()
+		}
+	})
+}
+
 func makeAccounts(size int) (addresses [][20]byte, accounts [][]byte) {
 	// Make the random benchmark deterministic
 	random := rand.New(rand.NewSource(0))
@@ -0,0 +1,131 @@
+// Copyright 2025 R5 Labs
+// This file is part of the R5 Core library.
+//
+// This software is provided "as is", without warranty of any kind,
+// express or implied, including but not limited to the warranties
+// of merchantability, fitness for a particular purpose and
+// noninfringement. In no event shall the authors or copyright
+// holders be liable for any claim, damages, or other liability,
+// whether in an action of contract, tort or otherwise, arising
+// from, out of or in connection with the software or the use or
+// other dealings in the software.
+
+package trie
+
+import (
+	"github.com/r5-labs/r5-core/client/common"
+	"github.com/r5-labs/r5-core/client/core/types"
+	"github.com/r5-labs/r5-core/client/crypto"
+)
+
+// VerifyNodeBlobs walks all the nodes of the trie with the given identifier and
+// checks that each node blob loaded from the database hashes to the node hash it
+// is referenced by. The number of nodes visited and the hashes of the corrupted
+// ones are returned, the subtries below them are skipped as their content can't
+// be trusted.
+//
+// Path-based databases only serve nodes matching the requested hash, so with a
+// PathNodeReader the blobs are read by path directly, telling corrupted nodes
+// apart from missing ones.
+//
+// If onLeaf is not nil, it's invoked with the key and value of every leaf found
+// in the uncorrupted part of the trie, allowing callers to dig into subtries
+// (e.g. storage tries of accounts) within the same pass.
+//
+// An error is returned if a node is missing, or if onLeaf fails.
+func VerifyNodeBlobs(id *ID, db NodeReader, onLeaf func(key, value []byte) error) (int, []common.Hash, error) {
+	if id.Root == types.EmptyRootHash || id.Root == (common.Hash{}) {
+		return 0, nil, nil
+	}
+	reader, err := newTrieReader(id.StateRoot, id.Owner, db)
+	if err != nil {
+		return 0, nil, err
+	}
+	v := &blobVerifier{
+		reader: reader,
+		hasher: crypto.NewKeccakState(),
+		onLeaf: onLeaf,
+	}
+	if pdb, ok := db.(*PathNodeReader); ok {
+		v.pathdb = pdb
+	}
+	if err := v.verify(nil, id.Root); err != nil {
+		return 0, nil, err
+	}
+	return v.nodes, v.corrupted, nil
+}
+
+// blobVerifier is the state of a trie integrity scan.
+type blobVerifier struct {
+	reader    *trieReader
+	pathdb    *PathNodeReader // Set if blobs are to be read by path, regardless of their hash
+	hasher    crypto.KeccakState
+	onLeaf    func(key, value []byte) error
+	nodes     int
+	corrupted []common.Hash
+}
+
+// verify loads the blob of the node with the given path and hash, checks its
+// integrity and descends into its children if it's intact.
+func (v *blobVerifier) verify(path []byte, hash common.Hash) error {
+	blob, err := v.nodeBlob(path, hash)
+	if err != nil {
+		return err
+	}
+	var got common.Hash
+	v.hasher.Reset()
+	v.hasher.Write(blob)
+	v.hasher.Read(got[:])
+	if got != hash {
+		v.corrupted = append(v.corrupted, hash)
+		return nil
+	}
+	n, err := decodeNode(hash[:], blob)
+	if err != nil {
+		return err
+	}
+	return v.walk(path, n)
+}
+
+// nodeBlob loads the blob of the node with the given path and hash, without
+// checking the hash if it's read by path.
+func (v *blobVerifier) nodeBlob(path []byte, hash common.Hash) ([]byte, error) {
+	if v.pathdb == nil {
+		return v.reader.nodeBlob(path, hash)
+	}
+	blob := v.pathdb.rawNodeBlob(v.reader.owner, path)
+	if len(blob) == 0 {
+		return nil, &MissingNodeError{Owner: v.reader.owner, NodeHash: hash, Path: path}
+	}
+	return blob, nil
+}
+
+// walk descends into the children of a decoded node, verifying the ones stored
+// separately and walking the embedded ones in place.
+func (v *blobVerifier) walk(path []byte, n node) error {
+	switch n := n.(type) {
+	case *shortNode:
+		v.nodes++
+		return v.walk(concat(path, n.Key...), n.Val)
+	case *fullNode:
+		v.nodes++
+		for i, child := range n.Children {
+			if child != nil {
+				if err := v.walk(concat(path, byte(i)), child); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	case hashNode:
+		return v.verify(path, common.BytesToHash(n))
+	case valueNode:
+		v.nodes++
+		if v.onLeaf != nil {
+			return v.onLeaf(hexToKeybytes(path), n)
+		}
+		return nil
+	default:
+		return nil
+	}
+}